@@ -0,0 +1,161 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrAttemptTimeout is returned for attempts cut short by an adaptive
+// timeout. It counts as a timeout error, so the retry policy retries it.
+var ErrAttemptTimeout = errors.New("attempt exceeded adaptive timeout")
+
+// AdaptiveTimeout derives a per-attempt timeout for each route from its
+// recent latencies: Percentile of the last Window attempts times Factor,
+// bounded by Min and Max. Routes with fewer than MinSamples observations use
+// Max. Zero fields take the defaults listed on each field.
+type AdaptiveTimeout struct {
+	Percentile float64       `json:"percentile"`  // 0.99
+	Factor     float64       `json:"factor"`      // 2
+	Min        time.Duration `json:"min"`         // 100ms
+	Max        time.Duration `json:"max"`         // DefaultClientTimeout
+	Window     int           `json:"window"`      // 200
+	MinSamples int           `json:"min_samples"` // 20
+	// Route groups requests whose latencies are comparable. The default
+	// is the method, host and route template (see Request.Route).
+	Route func(method, url string) string `json:"-"`
+}
+
+// maxAdaptiveRoutes bounds the latencies kept for distinct routes, so URLs
+// with IDs in them don't grow the client without limit. Further routes
+// share one window.
+const maxAdaptiveRoutes = 1000
+
+func (a AdaptiveTimeout) withDefaults() AdaptiveTimeout {
+	if a.Percentile <= 0 || a.Percentile > 1 {
+		a.Percentile = 0.99
+	}
+	if a.Factor <= 0 {
+		a.Factor = 2
+	}
+	if a.Min <= 0 {
+		a.Min = 100 * time.Millisecond
+	}
+	if a.Max <= 0 {
+		a.Max = DefaultClientTimeout
+	}
+	if a.Window <= 0 {
+		a.Window = 200
+	}
+	if a.MinSamples <= 0 {
+		a.MinSamples = min(20, a.Window)
+	}
+	return a
+}
+
+// route groups an attempt with comparable ones: by default the method, host
+// and route template of the request.
+func (a *adaptiveTimeouts) route(ctx context.Context, method, rawURL string) string {
+	if a.cfg.Route != nil {
+		return a.cfg.Route(method, rawURL)
+	}
+	route := RouteFromContext(ctx)
+	if route == "" {
+		route = NormalizeRoute(rawURL)
+	}
+	return method + " " + hostOf(rawURL) + route
+}
+
+// adaptiveTimeouts keeps a ring of recent latencies per route.
+type adaptiveTimeouts struct {
+	cfg    AdaptiveTimeout
+	mu     sync.Mutex
+	routes map[string]*latencyWindow
+}
+
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+func newAdaptiveTimeouts(cfg AdaptiveTimeout) *adaptiveTimeouts {
+	return &adaptiveTimeouts{cfg: cfg.withDefaults(), routes: make(map[string]*latencyWindow)}
+}
+
+// window returns the latencies of route. a.mu must be held.
+func (a *adaptiveTimeouts) window(route string) *latencyWindow {
+	w, ok := a.routes[route]
+	if ok {
+		return w
+	}
+	if len(a.routes) >= maxAdaptiveRoutes {
+		route = ""
+		if w, ok = a.routes[route]; ok {
+			return w
+		}
+	}
+	w = &latencyWindow{samples: make([]time.Duration, 0, a.cfg.Window)}
+	a.routes[route] = w
+	return w
+}
+
+func (a *adaptiveTimeouts) timeout(route string) time.Duration {
+	a.mu.Lock()
+	w := a.window(route)
+	if len(w.samples) < a.cfg.MinSamples {
+		a.mu.Unlock()
+		return a.cfg.Max
+	}
+	samples := slices.Clone(w.samples)
+	a.mu.Unlock()
+
+	slices.Sort(samples)
+	index := int(float64(len(samples)-1) * a.cfg.Percentile)
+	timeout := time.Duration(float64(samples[index]) * a.cfg.Factor)
+	return min(max(timeout, a.cfg.Min), a.cfg.Max)
+}
+
+func (a *adaptiveTimeouts) observe(route string, latency time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	w := a.window(route)
+	if len(w.samples) < a.cfg.Window {
+		w.samples = append(w.samples, latency)
+		return
+	}
+	w.samples[w.next] = latency
+	w.next = (w.next + 1) % a.cfg.Window
+}
+
+// executeOnceAdaptive runs executeOnce under the route's adaptive timeout,
+// which covers the attempt up to the response headers but not reading the
+// body.
+func (c *HTTPClient) executeOnceAdaptive(ctx context.Context, url, method string, body []byte, header http.Header) (*Response, error) {
+	if c.adaptive == nil {
+		return c.executeOnceShortcut(ctx, url, method, body, header)
+	}
+	route := c.adaptive.route(ctx, method, url)
+	timeout := c.adaptive.timeout(route)
+	attemptCtx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(timeout, cancel)
+	start := time.Now()
+	resp, err := c.executeOnceShortcut(attemptCtx, url, method, body, header)
+	if !timer.Stop() && ctx.Err() == nil {
+		if resp != nil && resp.body != nil {
+			resp.body.Close()
+		}
+		cancel()
+		return nil, fmt.Errorf("%w after %v", ErrAttemptTimeout, timeout)
+	}
+	if err != nil || resp.body == nil {
+		cancel()
+		return resp, err
+	}
+	c.adaptive.observe(route, time.Since(start))
+	resp.body = &cancelOnCloseBody{ReadCloser: resp.body, cancel: cancel}
+	return resp, nil
+}
@@ -0,0 +1,94 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveTimeouts(t *testing.T) {
+	a := newAdaptiveTimeouts(AdaptiveTimeout{Percentile: 0.9, Factor: 3, Min: 5 * time.Millisecond, Max: time.Second, Window: 10, MinSamples: 5})
+
+	if got := a.timeout("GET api/users"); got != time.Second {
+		t.Errorf("timeout() without samples = %v, want Max", got)
+	}
+	for i := 1; i <= 10; i++ {
+		a.observe("GET api/users", time.Duration(i)*10*time.Millisecond)
+	}
+	// p90 of 10ms..100ms is 90ms
+	if got := a.timeout("GET api/users"); got != 270*time.Millisecond {
+		t.Errorf("timeout() = %v, want 270ms", got)
+	}
+
+	// Old samples fall out of the window
+	for range 10 {
+		a.observe("GET api/users", time.Millisecond)
+	}
+	if got := a.timeout("GET api/users"); got != 5*time.Millisecond {
+		t.Errorf("timeout() = %v, want Min", got)
+	}
+	if got := a.timeout("GET api/orders"); got != time.Second {
+		t.Errorf("timeout() of another route = %v, want Max", got)
+	}
+}
+
+func TestClientBuilder_WithAdaptiveTimeout(t *testing.T) {
+	var stall atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if stall.CompareAndSwap(true, false) {
+			select {
+			case <-time.After(2 * time.Second):
+			case <-r.Context().Done():
+			}
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	t.Run("slow attempt is retried", func(t *testing.T) {
+		cli := NewClientBuilder().
+			WithRetryConfig(fastRetryConfig(2)).
+			WithAdaptiveTimeout(AdaptiveTimeout{Min: 50 * time.Millisecond, Window: 10, MinSamples: 5}).
+			Build()
+
+		for range 5 {
+			resp, err := cli.Get(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("warm-up Get() error = %v", err)
+			}
+			resp.Bytes()
+		}
+
+		stall.Store(true)
+		start := time.Now()
+		resp, err := cli.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Get() took %v, want the stalled attempt abandoned early", elapsed)
+		}
+		if resp.RetryAttempts() != 1 {
+			t.Errorf("RetryAttempts() = %d, want 1", resp.RetryAttempts())
+		}
+		if data, _ := resp.Bytes(); string(data) != "ok" {
+			t.Errorf("body = %q, want ok", data)
+		}
+	})
+
+	t.Run("timeout error", func(t *testing.T) {
+		cli := NewClientBuilder().
+			WithAdaptiveTimeout(AdaptiveTimeout{Max: 50 * time.Millisecond}).
+			Build()
+
+		stall.Store(true)
+		_, err := cli.Get(context.Background(), server.URL)
+		if !errors.Is(err, ErrAttemptTimeout) || !IsTimeoutError(err) {
+			t.Errorf("Get() error = %v, want ErrAttemptTimeout", err)
+		}
+	})
+}
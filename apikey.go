@@ -0,0 +1,143 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// APIKeyLocation says where an API key is sent.
+type APIKeyLocation int
+
+const (
+	// InHeader sends the key as a request header.
+	InHeader APIKeyLocation = iota
+	// InQuery sends the key as a URL query parameter.
+	InQuery
+)
+
+func (l APIKeyLocation) String() string {
+	if l == InQuery {
+		return "query"
+	}
+	return "header"
+}
+
+// APIKeySource supplies API keys. It is consulted on every attempt, so a
+// rotated key is picked up without rebuilding the client.
+type APIKeySource interface {
+	APIKey(ctx context.Context) (string, error)
+}
+
+// APIKeyFunc adapts a function to an APIKeySource.
+type APIKeyFunc func(ctx context.Context) (string, error)
+
+func (f APIKeyFunc) APIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+type staticAPIKey string
+
+func (k staticAPIKey) APIKey(context.Context) (string, error) {
+	return string(k), nil
+}
+
+type apiKeyConfig struct {
+	name   string
+	in     APIKeyLocation
+	source APIKeySource
+}
+
+// Secrets names the headers and query parameters of a request that carry
+// credentials, so anything that logs or records requests can mask them.
+type Secrets struct {
+	Headers     []string
+	QueryParams []string
+}
+
+type secretsKey struct{}
+
+// SecretsFromContext returns the secrets the client marked on the context of
+// an outgoing request, e.g. for a RoundTripperWrapper that dumps requests.
+func SecretsFromContext(ctx context.Context) Secrets {
+	secrets, _ := ctx.Value(secretsKey{}).(Secrets)
+	return secrets
+}
+
+// withSecrets marks the API key on ctx.
+func (k *apiKeyConfig) withSecrets(ctx context.Context) context.Context {
+	secrets := SecretsFromContext(ctx)
+	if k.in == InQuery {
+		secrets.QueryParams = append(secrets.QueryParams[:len(secrets.QueryParams):len(secrets.QueryParams)], k.name)
+	} else {
+		secrets.Headers = append(secrets.Headers[:len(secrets.Headers):len(secrets.Headers)], k.name)
+	}
+	return context.WithValue(ctx, secretsKey{}, secrets)
+}
+
+// apply adds the current key to req unless the request already carries one.
+func (k *apiKeyConfig) apply(ctx context.Context, req *http.Request) error {
+	key, err := k.source.APIKey(ctx)
+	if err != nil {
+		return fmt.Errorf("api key source error: %v", err)
+	}
+	if k.in == InHeader {
+		if req.Header.Get(k.name) == "" {
+			req.Header.Set(k.name, key)
+		}
+		return nil
+	}
+	if req.URL.Query().Has(k.name) {
+		return nil
+	}
+	param := url.QueryEscape(k.name) + "=" + url.QueryEscape(key)
+	if req.URL.RawQuery == "" {
+		req.URL.RawQuery = param
+	} else {
+		req.URL.RawQuery += "&" + param
+	}
+	return nil
+}
+
+// redact masks the key in the URL of a transport error, which would
+// otherwise end up in logs.
+func (k *apiKeyConfig) redact(err error) error {
+	var urlErr *url.Error
+	if k.in == InQuery && errors.As(err, &urlErr) {
+		urlErr.URL = RedactQuery(urlErr.URL, k.name)
+	}
+	return err
+}
+
+// RedactedValue replaces secrets masked by RedactQuery.
+const RedactedValue = "REDACTED"
+
+// RedactQuery replaces the values of the query parameters params in rawURL
+// with RedactedValue.
+func RedactQuery(rawURL string, params ...string) string {
+	base, query, ok := strings.Cut(rawURL, "?")
+	if !ok {
+		return rawURL
+	}
+	query, fragment, hasFragment := strings.Cut(query, "#")
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		name, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		for _, param := range params {
+			if name == param {
+				pairs[i] = url.QueryEscape(name) + "=" + RedactedValue
+			}
+		}
+	}
+	redacted := base + "?" + strings.Join(pairs, "&")
+	if hasFragment {
+		redacted += "#" + fragment
+	}
+	return redacted
+}
@@ -0,0 +1,96 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClientBuilder_WithAPIKey(t *testing.T) {
+	var gotHeader, gotQuery string
+	var secrets Secrets
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Api-Key")
+		gotQuery = r.URL.RawQuery
+	}))
+	defer server.Close()
+	spy := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			secrets = SecretsFromContext(req.Context())
+			return next.RoundTrip(req)
+		})
+	}
+
+	t.Run("header", func(t *testing.T) {
+		cli := NewClientBuilder().WithAPIKey("X-Api-Key", "k1", InHeader).WithRoundTripperWrapper(spy).Build()
+		if _, err := cli.Get(context.Background(), server.URL); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if gotHeader != "k1" {
+			t.Errorf("X-Api-Key = %q, want k1", gotHeader)
+		}
+		if len(secrets.Headers) != 1 || secrets.Headers[0] != "X-Api-Key" {
+			t.Errorf("secrets = %+v, want the header marked", secrets)
+		}
+		if policy := cli.Policy(); policy.APIKey != "X-Api-Key in header" || strings.Contains(policy.String(), "k1") {
+			t.Errorf("Policy().APIKey = %q, want location without the key", policy.APIKey)
+		}
+	})
+
+	t.Run("query with rotation", func(t *testing.T) {
+		var version atomic.Int32
+		source := APIKeyFunc(func(ctx context.Context) (string, error) {
+			if version.Load() == 0 {
+				return "old key", nil
+			}
+			return "new-key", nil
+		})
+		cli := NewClientBuilder().WithAPIKeySource("api_key", InQuery, source).WithRoundTripperWrapper(spy).Build()
+
+		if _, err := cli.Get(context.Background(), server.URL+"/items?page=2"); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if gotQuery != "page=2&api_key=old+key" {
+			t.Errorf("query = %q, want the key appended", gotQuery)
+		}
+		version.Store(1)
+		cli.Get(context.Background(), server.URL)
+		if gotQuery != "api_key=new-key" {
+			t.Errorf("query = %q, want the rotated key", gotQuery)
+		}
+		if len(secrets.QueryParams) != 1 || secrets.QueryParams[0] != "api_key" {
+			t.Errorf("secrets = %+v, want the query parameter marked", secrets)
+		}
+	})
+
+	t.Run("masked in errors", func(t *testing.T) {
+		cli := NewClientBuilder().WithAPIKey("api_key", "s3cr3t", InQuery).Build()
+		_, err := cli.Get(context.Background(), "http://127.0.0.1:1/items?api_key=s3cr3t&page=1")
+		if err == nil {
+			t.Fatal("Get() error = nil, want connection error")
+		}
+		if strings.Contains(err.Error(), "s3cr3t") || !strings.Contains(err.Error(), "api_key=REDACTED") {
+			t.Errorf("error = %v, want the key masked", err)
+		}
+	})
+}
+
+func TestRedactQuery(t *testing.T) {
+	testCases := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://x.test/a?key=1&b=2", want: "https://x.test/a?key=REDACTED&b=2"},
+		{url: "https://x.test/a?b=2&key=1#frag", want: "https://x.test/a?b=2&key=REDACTED#frag"},
+		{url: "https://x.test/a?keys=1", want: "https://x.test/a?keys=1"},
+		{url: "https://x.test/a", want: "https://x.test/a"},
+	}
+	for _, tc := range testCases {
+		if got := RedactQuery(tc.url, "key"); got != tc.want {
+			t.Errorf("RedactQuery(%q) = %q, want %q", tc.url, got, tc.want)
+		}
+	}
+}
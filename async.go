@@ -0,0 +1,58 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+)
+
+// AsyncDoer starts requests in the background, so callers can fan out and
+// select on completion without managing goroutines.
+//
+//	user := client.GetAsync(ctx, "/users/1")
+//	orders := client.GetAsync(ctx, "/users/1/orders")
+//	select {
+//	case <-user.Done():
+//	case <-time.After(time.Second):
+//	}
+type AsyncDoer interface {
+	GetAsync(ctx context.Context, url string) *Future
+	DoAsync(ctx context.Context, req *Request) *Future
+}
+
+// Future is the outcome of a request started with GetAsync or DoAsync.
+// Cancelling the context the request was started with cancels it.
+type Future struct {
+	done chan struct{}
+	resp *Response
+	err  error
+}
+
+// startFuture executes req with doer in the background.
+func startFuture(ctx context.Context, doer Doer, req *Request) *Future {
+	f := &Future{done: make(chan struct{})}
+	go func() {
+		defer close(f.done)
+		f.resp, f.err = doer.Do(ctx, req)
+	}()
+	return f
+}
+
+// Done is closed once the request has finished.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Get waits for the request to finish and returns its outcome. The caller
+// must close the response body.
+func (f *Future) Get() (*Response, error) {
+	<-f.done
+	return f.resp, f.err
+}
+
+func (c *HTTPClient) GetAsync(ctx context.Context, url string) *Future {
+	return startFuture(ctx, c, NewRequest(http.MethodGet, url))
+}
+
+func (c *HTTPClient) DoAsync(ctx context.Context, req *Request) *Future {
+	return startFuture(ctx, c, req)
+}
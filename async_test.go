@@ -0,0 +1,56 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_Async(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+	defer close(release)
+	cli := NewClientBuilder().WithBaseURL(server.URL).WithRetryConfig(nil).Build()
+
+	t.Run("futures complete independently", func(t *testing.T) {
+		slow := cli.GetAsync(context.TODO(), "/slow")
+		fast := cli.DoAsync(context.TODO(), NewRequest(http.MethodGet, "/fast"))
+		select {
+		case <-fast.Done():
+		case <-slow.Done():
+			t.Fatal("Expected the slow request to still be running")
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the fast request")
+		}
+		resp, err := fast.Get()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		body, _ := resp.Bytes()
+		if string(body) != "/fast" {
+			t.Errorf("Expected /fast, got %q", body)
+		}
+	})
+
+	t.Run("cancelling the context cancels the request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		future := cli.GetAsync(ctx, "/slow")
+		cancel()
+		if _, err := future.Get(); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
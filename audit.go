@@ -0,0 +1,194 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditRecord explains everything the client did for one logical request:
+// the policies it consulted, every attempt including followed redirects,
+// and the final outcome.
+type AuditRecord struct {
+	RequestID  string         `json:"request_id,omitempty"`
+	Method     string         `json:"method"`
+	URL        string         `json:"url"`
+	Policies   []string       `json:"policies"`
+	Attempts   []AuditAttempt `json:"attempts"`
+	Outcome    string         `json:"outcome"`
+	StatusCode int            `json:"status_code,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	StartedAt  time.Time      `json:"started_at"`
+	Duration   time.Duration  `json:"duration"`
+}
+
+// AuditAttempt is a single attempt of a logical request.
+type AuditAttempt struct {
+	Number     int             `json:"number"`
+	URL        string          `json:"url"`
+	StartedAt  time.Time       `json:"started_at"`
+	Duration   time.Duration   `json:"duration"`
+	StatusCode int             `json:"status_code,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Redirects  []AuditRedirect `json:"redirects,omitempty"`
+	Retried    bool            `json:"retried"`
+}
+
+// AuditRedirect is a redirect followed during an attempt.
+type AuditRedirect struct {
+	StatusCode int    `json:"status_code"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+}
+
+// Audit outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeError   = "error"
+)
+
+// JSON returns the record encoded as JSON.
+func (a *AuditRecord) JSON() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+// auditConfig enables audit records, optionally delivering each finished one
+// to sink.
+type auditConfig struct {
+	sink func(*AuditRecord)
+}
+
+// newAudit starts the audit record of a logical request, or returns nil when
+// auditing is disabled. All auditRecord methods are nil-safe.
+func (c *HTTPClient) newAudit(ctx context.Context, request *Request, url, requestID string, retry *RetryConfig) *auditRecord {
+	if c.audit == nil {
+		return nil
+	}
+	record := &AuditRecord{
+		RequestID: requestID,
+		Method:    request.method,
+		URL:       url,
+		Policies:  c.policies(ctx, request, retry),
+		StartedAt: time.Now(),
+	}
+	return &auditRecord{record: record, sink: c.audit.sink}
+}
+
+// policies describes the configuration consulted for request.
+func (c *HTTPClient) policies(ctx context.Context, request *Request, retry *RetryConfig) []string {
+	var policies []string
+	if timeout, ok := timeoutOverride(ctx); ok {
+		policies = append(policies, fmt.Sprintf("timeout override: %v", timeout))
+	}
+	switch {
+	case noRetry(ctx):
+		policies = append(policies, "retries disabled by context")
+	case request.retryOverride && retry == nil:
+		policies = append(policies, "retries disabled by request")
+	case request.retryOverride:
+		policies = append(policies, fmt.Sprintf("request retry policy: up to %d retries", retry.maxRetries))
+	case retry != nil:
+		policies = append(policies, fmt.Sprintf("client retry policy: up to %d retries", retry.maxRetries))
+	default:
+		policies = append(policies, "no retries")
+	}
+	if c.baseURLs != nil {
+		if c.baseURLs.affinity != nil {
+			policies = append(policies, "load balancing with affinity key")
+		} else {
+			policies = append(policies, "round-robin load balancing")
+		}
+		if c.failover != FailoverNone {
+			policies = append(policies, fmt.Sprintf("failover across %d endpoints", len(c.baseURLs.endpoints)))
+		}
+	}
+	if c.discovery != nil {
+		policies = append(policies, fmt.Sprintf("service discovery: %T", c.discovery.balancer))
+	}
+	if c.dns != nil && c.dns.ttl > 0 {
+		policies = append(policies, fmt.Sprintf("dns cache ttl: %v", c.dns.ttl))
+	}
+	if c.cache != nil {
+		policies = append(policies, fmt.Sprintf("response cache ttl: %v", c.cache.store.ttl))
+	}
+	if c.limiter != nil {
+		policies = append(policies, fmt.Sprintf("concurrency limit: %d", cap(c.limiter.slots)))
+	}
+	if c.maxRequestBytes > 0 {
+		policies = append(policies, fmt.Sprintf("max request bytes: %d", c.maxRequestBytes))
+	}
+	if len(c.validators) > 0 {
+		policies = append(policies, fmt.Sprintf("validators: %d", len(c.validators)))
+	}
+	if c.signer != nil {
+		policies = append(policies, fmt.Sprintf("signer: %T", c.signer))
+	}
+	return policies
+}
+
+type auditRecord struct {
+	record *AuditRecord
+	sink   func(*AuditRecord)
+}
+
+func (a *auditRecord) attempt(number int, url string, start time.Time, resp *Response, err error) {
+	if a == nil {
+		return
+	}
+	attempt := AuditAttempt{
+		Number:    number,
+		URL:       url,
+		StartedAt: start,
+		Duration:  time.Since(start),
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	if resp != nil {
+		attempt.StatusCode = resp.statusCode
+		attempt.Redirects = redirectsOf(resp)
+	}
+	a.record.Attempts = append(a.record.Attempts, attempt)
+}
+
+// retried marks the latest attempt as followed by a retry.
+func (a *auditRecord) retried() {
+	if a == nil || len(a.record.Attempts) == 0 {
+		return
+	}
+	a.record.Attempts[len(a.record.Attempts)-1].Retried = true
+}
+
+func (a *auditRecord) finish(resp *Response, err error) {
+	if a == nil {
+		return
+	}
+	a.record.Duration = time.Since(a.record.StartedAt)
+	a.record.Outcome = AuditOutcomeSuccess
+	if err != nil {
+		a.record.Outcome = AuditOutcomeError
+		a.record.Error = err.Error()
+	}
+	if resp != nil {
+		a.record.StatusCode = resp.statusCode
+		resp.audit = a.record
+	}
+	if a.sink != nil {
+		a.sink(a.record)
+	}
+}
+
+// redirectsOf reconstructs the redirects followed to obtain resp from the
+// chain net/http links through Request.Response.
+func redirectsOf(resp *Response) []AuditRedirect {
+	var redirects []AuditRedirect
+	for req := resp.request; req != nil && req.Response != nil; req = req.Response.Request {
+		redirect := AuditRedirect{StatusCode: req.Response.StatusCode, To: req.URL.String()}
+		if req.Response.Request != nil {
+			redirect.From = req.Response.Request.URL.String()
+		}
+		redirects = append([]AuditRedirect{redirect}, redirects...)
+	}
+	return redirects
+}
@@ -0,0 +1,106 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_WithAuditLog(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/new":
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	var sunk []*AuditRecord
+	client := NewClientBuilder().
+		WithBaseURL(server.URL).
+		WithRetryConfig(fastRetryConfig(2)).
+		WithAuditLog(func(r *AuditRecord) { sunk = append(sunk, r) }).
+		Build()
+
+	resp, err := client.Get(context.Background(), "/old")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	record := resp.Audit()
+	if record == nil {
+		t.Fatal("Expected an audit record on the response")
+	}
+	if len(sunk) != 1 || sunk[0] != record {
+		t.Errorf("Expected the record to be delivered to the sink once, got %d", len(sunk))
+	}
+	if record.Outcome != AuditOutcomeSuccess || record.StatusCode != http.StatusOK {
+		t.Errorf("Expected successful outcome with 200, got %s/%d", record.Outcome, record.StatusCode)
+	}
+	if len(record.Attempts) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(record.Attempts))
+	}
+	first, second := record.Attempts[0], record.Attempts[1]
+	if first.Number != 1 || first.StatusCode != http.StatusServiceUnavailable || !first.Retried {
+		t.Errorf("Unexpected first attempt: %+v", first)
+	}
+	if second.Retried || second.StatusCode != http.StatusOK {
+		t.Errorf("Unexpected second attempt: %+v", second)
+	}
+	if len(second.Redirects) != 1 || second.Redirects[0].StatusCode != http.StatusMovedPermanently ||
+		second.Redirects[0].From != server.URL+"/old" || second.Redirects[0].To != server.URL+"/new" {
+		t.Errorf("Unexpected redirects: %+v", second.Redirects)
+	}
+	if len(record.Policies) == 0 || record.Policies[0] != "client retry policy: up to 2 retries" {
+		t.Errorf("Unexpected policies: %v", record.Policies)
+	}
+
+	data, err := record.JSON()
+	if err != nil {
+		t.Fatalf("Failed to encode record: %v", err)
+	}
+	var decoded AuditRecord
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to decode record: %v", err)
+	}
+	if len(decoded.Attempts) != 2 {
+		t.Errorf("Expected JSON round trip to keep 2 attempts, got %d", len(decoded.Attempts))
+	}
+}
+
+func TestClient_WithAuditLog_Failure(t *testing.T) {
+	var record *AuditRecord
+	client := NewClientBuilder().
+		WithAuditLog(func(r *AuditRecord) { record = r }).
+		Build()
+	client.SimulateOutage("*", OutageConnectionRefused)
+
+	if _, err := client.Get(context.Background(), "http://unreachable.example/"); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if record == nil || record.Outcome != AuditOutcomeError || record.Error == "" {
+		t.Errorf("Expected a failed record with an error, got %+v", record)
+	}
+}
+
+func TestClient_Audit_DisabledByDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	resp, err := NewClientBuilder().Build().Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Audit() != nil {
+		t.Error("Expected no audit record without WithAuditLog")
+	}
+}
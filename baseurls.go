@@ -0,0 +1,47 @@
+package reqwest
+
+import (
+	"hash/fnv"
+	"sync/atomic"
+)
+
+// baseURLPool spreads logical requests across the endpoints configured with
+// WithBaseURLs. Requests with an affinity key always go to the same endpoint
+// (rendezvous hashing, so adding or removing an endpoint only moves the keys
+// that mapped to it); the rest are distributed round-robin.
+type baseURLPool struct {
+	endpoints []string
+	affinity  func(*Request) string
+	next      atomic.Uint64
+}
+
+func newBaseURLPool(endpoints []string, affinity func(*Request) string) *baseURLPool {
+	return &baseURLPool{endpoints: endpoints, affinity: affinity}
+}
+
+// pick returns the index of the endpoint for request.
+func (b *baseURLPool) pick(request *Request) int {
+	if b.affinity != nil {
+		if key := b.affinity(request); key != "" {
+			return rendezvous(key, b.endpoints)
+		}
+	}
+	n := b.next.Add(1) - 1
+	return int(n % uint64(len(b.endpoints)))
+}
+
+// rendezvous returns the index of the endpoint with the highest hash score
+// for key.
+func rendezvous(key string, endpoints []string) int {
+	best, bestScore := 0, uint64(0)
+	for i, endpoint := range endpoints {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(endpoint))
+		if score := h.Sum64(); i == 0 || score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,85 @@
+package reqwest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newNamedServer(name string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(name))
+	}))
+}
+
+func servedBy(t *testing.T, resp *Response, err error) string {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body().Close()
+	name, err := io.ReadAll(resp.Body())
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+	return string(name)
+}
+
+func TestClient_WithBaseURLs(t *testing.T) {
+	a, b, c := newNamedServer("a"), newNamedServer("b"), newNamedServer("c")
+	defer a.Close()
+	defer b.Close()
+	defer c.Close()
+
+	t.Run("round-robin without affinity", func(t *testing.T) {
+		client := NewClientBuilder().WithBaseURLs(a.URL, b.URL, c.URL).Build()
+		var order []string
+		for i := 0; i < 4; i++ {
+			resp, err := client.Get(context.Background(), "/")
+			order = append(order, servedBy(t, resp, err))
+		}
+		if fmt.Sprint(order) != "[a b c a]" {
+			t.Errorf("Expected round-robin order [a b c a], got %v", order)
+		}
+	})
+
+	t.Run("affinity key is sticky", func(t *testing.T) {
+		client := NewClientBuilder().
+			WithBaseURLs(a.URL, b.URL, c.URL).
+			WithAffinityKey(func(r *Request) string { return r.Header().Get("X-User") }).
+			Build()
+
+		seen := map[string]map[string]bool{}
+		for i := 0; i < 10; i++ {
+			user := fmt.Sprintf("user-%d", i%5)
+			req := NewRequest(http.MethodGet, "/").WithHeader("X-User", user)
+			resp, err := client.Do(context.Background(), req)
+			if seen[user] == nil {
+				seen[user] = map[string]bool{}
+			}
+			seen[user][servedBy(t, resp, err)] = true
+		}
+		for user, backends := range seen {
+			if len(backends) != 1 {
+				t.Errorf("Expected %s to stick to one backend, got %v", user, backends)
+			}
+		}
+	})
+}
+
+func TestRendezvous_StableWhenEndpointRemoved(t *testing.T) {
+	endpoints := []string{"http://a", "http://b", "http://c", "http://d"}
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("session-%d", i)
+		before := endpoints[rendezvous(key, endpoints)]
+		if before == "http://d" {
+			continue
+		}
+		if after := endpoints[rendezvous(key, endpoints[:3])]; after != before {
+			t.Errorf("Key %s moved from %s to %s after removing an unrelated endpoint", key, before, after)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"reflect"
+	"strings"
+)
+
+// Bind implements an API client declared as a struct of function fields, each
+// tagged with the method and path it calls:
+//
+//	type UserAPI struct {
+//		Get    func(ctx context.Context, id int) (User, error)                `reqwest:"GET /users/{id}"`
+//		List   func(ctx context.Context, page int, q *string) ([]User, error) `reqwest:"GET /users" query:"page,q"`
+//		Create func(ctx context.Context, user NewUser) (User, error)          `reqwest:"POST /users"`
+//		Delete func(ctx context.Context, id int) error                        `reqwest:"DELETE /users/{id}"`
+//	}
+//
+//	var users UserAPI
+//	err := reqwest.Bind(client, &users)
+//
+// Go can't implement interfaces at run time, hence fields instead of methods.
+// After the context, arguments fill the path placeholders in order, then the
+// query parameters named by the query tag, where nil pointers are left out;
+// one more argument is sent as the JSON body. A final ...RequestOption is
+// passed on. Functions return an error, optionally preceded by a value
+// decoded from JSON or by the *Response itself, which the caller must close.
+// Except for the latter, a non-2xx response fails with an *HTTPError.
+//
+// Bind checks every tagged field and fails without binding any of them if one
+// is invalid.
+func Bind(doer Doer, service any) error {
+	ptr := reflect.ValueOf(service)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("failed to bind service: want a non-nil pointer to a struct, got %T", service)
+	}
+	target := ptr.Elem()
+	typ := target.Type()
+	bound := make(map[int]reflect.Value)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag, ok := field.Tag.Lookup("reqwest")
+		if !ok {
+			continue
+		}
+		endpoint, err := newBoundEndpoint(field, tag)
+		if err != nil {
+			return fmt.Errorf("failed to bind %s.%s: %v", typ.Name(), field.Name, err)
+		}
+		bound[i] = reflect.MakeFunc(field.Type, func(args []reflect.Value) []reflect.Value {
+			return endpoint.call(doer, args)
+		})
+	}
+	for i, fn := range bound {
+		target.Field(i).Set(fn)
+	}
+	return nil
+}
+
+var (
+	contextType       = reflect.TypeFor[context.Context]()
+	errorType         = reflect.TypeFor[error]()
+	responseType      = reflect.TypeFor[*Response]()
+	requestOptionType = reflect.TypeFor[[]RequestOption]()
+)
+
+// boundEndpoint is the request a function field of a bound service sends.
+type boundEndpoint struct {
+	method  string
+	path    string
+	params  []string
+	query   []string
+	body    bool
+	options bool
+	result  reflect.Type // nil when the function returns only an error
+}
+
+func newBoundEndpoint(field reflect.StructField, tag string) (*boundEndpoint, error) {
+	if !field.IsExported() {
+		return nil, errors.New("field is not exported")
+	}
+	fn := field.Type
+	if fn.Kind() != reflect.Func {
+		return nil, fmt.Errorf("field is a %s, not a function", fn)
+	}
+	method, path, ok := strings.Cut(strings.TrimSpace(tag), " ")
+	path = strings.TrimSpace(path)
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("tag %q is not of the form \"METHOD /path\"", tag)
+	}
+	e := &boundEndpoint{method: method, path: path}
+	for rest := path; ; {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated placeholder in %q", path)
+		}
+		e.params = append(e.params, rest[start+1:start+end])
+		rest = rest[start+end+1:]
+	}
+	if query := field.Tag.Get("query"); query != "" {
+		e.query = strings.Split(query, ",")
+	}
+
+	if fn.NumIn() == 0 || fn.In(0) != contextType {
+		return nil, errors.New("first parameter must be a context.Context")
+	}
+	args := fn.NumIn() - 1
+	if fn.IsVariadic() {
+		if fn.In(fn.NumIn()-1) != requestOptionType {
+			return nil, errors.New("only ...RequestOption may be variadic")
+		}
+		e.options = true
+		args--
+	}
+	scalars := len(e.params) + len(e.query)
+	switch args {
+	case scalars:
+	case scalars + 1:
+		e.body = true
+	default:
+		return nil, fmt.Errorf("has %d arguments after the context for %d placeholders and %d query parameters", args, len(e.params), len(e.query))
+	}
+	for i := 0; i < scalars; i++ {
+		arg := fn.In(i + 1)
+		if i >= len(e.params) && arg.Kind() == reflect.Pointer {
+			arg = arg.Elem()
+		}
+		if !isScalarKind(arg.Kind()) {
+			return nil, fmt.Errorf("parameter %d is a %s, not a string, number or bool", i+2, fn.In(i+1))
+		}
+	}
+
+	switch {
+	case fn.NumOut() == 1 && fn.Out(0) == errorType:
+	case fn.NumOut() == 2 && fn.Out(1) == errorType:
+		e.result = fn.Out(0)
+	default:
+		return nil, errors.New("must return error or (T, error)")
+	}
+	return e, nil
+}
+
+func isScalarKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func (e *boundEndpoint) call(doer Doer, args []reflect.Value) []reflect.Value {
+	ctx, _ := args[0].Interface().(context.Context)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	resp, err := e.send(ctx, doer, args[1:])
+	if e.result == nil {
+		return []reflect.Value{errorValue(err)}
+	}
+	result := reflect.New(e.result)
+	switch {
+	case err != nil:
+	case e.result == responseType:
+		result.Elem().Set(reflect.ValueOf(resp))
+	case !resp.IsSuccess():
+		err = httpError(resp)
+	case resp.StatusCode() == http.StatusNoContent:
+		resp.Body().Close()
+	default:
+		err = resp.JSON(result.Interface())
+	}
+	return []reflect.Value{result.Elem(), errorValue(err)}
+}
+
+func (e *boundEndpoint) send(ctx context.Context, doer Doer, args []reflect.Value) (*Response, error) {
+	path := e.path
+	for i, name := range e.params {
+		path = strings.Replace(path, "{"+name+"}", neturl.PathEscape(fmt.Sprint(args[i].Interface())), 1)
+	}
+	req := NewRequest(e.method, path)
+	for i, name := range e.query {
+		arg := args[len(e.params)+i]
+		if arg.Kind() == reflect.Pointer {
+			if arg.IsNil() {
+				continue
+			}
+			arg = arg.Elem()
+		}
+		req.WithQuery(name, fmt.Sprint(arg.Interface()))
+	}
+	if e.body {
+		data, err := JSONCodec{}.Marshal(args[len(e.params)+len(e.query)].Interface())
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %v", err)
+		}
+		req.WithBody(data).WithHeader("Content-Type", JSONCodec{}.ContentType())
+	}
+	if e.result != nil && e.result != responseType {
+		req.ExpectJSON()
+	}
+	if e.options {
+		opts, _ := args[len(args)-1].Interface().([]RequestOption)
+		req.with(opts)
+	}
+
+	resp, err := doer.Do(ctx, req)
+	if err != nil || e.result != nil {
+		return resp, err
+	}
+	// Only the error is returned, so the status is all that's left to check
+	if !resp.IsSuccess() {
+		return nil, httpError(resp)
+	}
+	resp.Body().Close()
+	return nil, nil
+}
+
+func errorValue(err error) reflect.Value {
+	value := reflect.New(errorType).Elem()
+	if err != nil {
+		value.Set(reflect.ValueOf(err))
+	}
+	return value
+}
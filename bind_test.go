@@ -0,0 +1,141 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type boundUserAPI struct {
+	Get    func(ctx context.Context, id int) (typedUser, error)                                   `reqwest:"GET /users/{id}"`
+	List   func(ctx context.Context, team string, page int, q *string) ([]typedUser, error)       `reqwest:"GET /teams/{team}/users" query:"page,q"`
+	Create func(ctx context.Context, user typedNewUser, opts ...RequestOption) (typedUser, error) `reqwest:"POST /users"`
+	Delete func(ctx context.Context, id int) error                                                `reqwest:"DELETE /users/{id}"`
+	Raw    func(ctx context.Context, id int) (*Response, error)                                   `reqwest:"GET /users/{id}"`
+	Helper func() string
+}
+
+func TestBind(t *testing.T) {
+	var gotURL, gotTenant string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURL = r.URL.RequestURI()
+		gotTenant = r.Header.Get("X-Tenant")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/users/1":
+			w.Write([]byte(`{"id":1,"name":"Ada"}`))
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/teams/"):
+			w.Write([]byte(`[{"id":1,"name":"Ada"},{"id":2,"name":"Grace"}]`))
+		case r.Method == http.MethodPost:
+			var in typedNewUser
+			json.NewDecoder(r.Body).Decode(&in)
+			json.NewEncoder(w).Encode(typedUser{ID: 3, Name: in.Name})
+		case r.Method == http.MethodDelete && r.URL.Path == "/users/1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	var api boundUserAPI
+	if err := Bind(NewClientBuilder().WithBaseURL(server.URL).Build(), &api); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if api.Helper != nil {
+		t.Error("Bind() set an untagged field")
+	}
+
+	t.Run("path parameters", func(t *testing.T) {
+		user, err := api.Get(context.TODO(), 1)
+		if err != nil || user.Name != "Ada" {
+			t.Errorf("Get() = %+v, %v", user, err)
+		}
+	})
+
+	t.Run("query parameters", func(t *testing.T) {
+		q := "a&b"
+		users, err := api.List(context.TODO(), "core team", 2, &q)
+		if err != nil || len(users) != 2 {
+			t.Fatalf("List() = %+v, %v", users, err)
+		}
+		if gotURL != "/teams/core%20team/users?page=2&q=a%26b" {
+			t.Errorf("Unexpected request URI %q", gotURL)
+		}
+		if _, err := api.List(context.TODO(), "core", 1, nil); err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if gotURL != "/teams/core/users?page=1" {
+			t.Errorf("Expected the nil parameter to be left out, got %q", gotURL)
+		}
+	})
+
+	t.Run("body and options", func(t *testing.T) {
+		user, err := api.Create(context.TODO(), typedNewUser{Name: "Linus"}, RequestHeader("X-Tenant", "acme"))
+		if err != nil || user != (typedUser{ID: 3, Name: "Linus"}) {
+			t.Errorf("Create() = %+v, %v", user, err)
+		}
+		if gotTenant != "acme" {
+			t.Errorf("Expected the option to apply, got X-Tenant %q", gotTenant)
+		}
+	})
+
+	t.Run("error only", func(t *testing.T) {
+		if err := api.Delete(context.TODO(), 1); err != nil {
+			t.Errorf("Delete() error = %v", err)
+		}
+		var httpErr *HTTPError
+		if err := api.Delete(context.TODO(), 2); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected an HTTPError for 404, got %v", err)
+		}
+	})
+
+	t.Run("raw response", func(t *testing.T) {
+		resp, err := api.Raw(context.TODO(), 9)
+		if err != nil {
+			t.Fatalf("Raw() error = %v", err)
+		}
+		resp.Body().Close()
+		if resp.StatusCode() != http.StatusNotFound {
+			t.Errorf("Expected the 404 response, got %d", resp.StatusCode())
+		}
+	})
+}
+
+func TestBind_Invalid(t *testing.T) {
+	cli := NewClientBuilder().Build()
+	tests := []struct {
+		name    string
+		service any
+		want    string
+	}{
+		{"not a pointer", boundUserAPI{}, "non-nil pointer to a struct"},
+		{"bad tag", &struct {
+			F func(context.Context) error `reqwest:"/users"`
+		}{}, "not of the form"},
+		{"missing context", &struct {
+			F func(int) error `reqwest:"GET /users/{id}"`
+		}{}, "context.Context"},
+		{"argument count", &struct {
+			F func(context.Context) error `reqwest:"GET /users/{id}"`
+		}{}, "1 placeholders"},
+		{"non-scalar path parameter", &struct {
+			F func(context.Context, []int) error `reqwest:"GET /users/{id}"`
+		}{}, "not a string, number or bool"},
+		{"results", &struct {
+			F func(context.Context) (int, int) `reqwest:"GET /users"`
+		}{}, "must return"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Bind(cli, tt.service)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Bind() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package reqwest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	// ErrBodyTooLarge is returned when reading a response body past the limit
+	// set with WithMaxResponseBytes.
+	ErrBodyTooLarge = errors.New("response body exceeds size limit")
+	// ErrTruncatedBody is returned when a response body ends before its
+	// Content-Length, e.g. because the connection was cut mid-stream. It also
+	// matches io.ErrUnexpectedEOF, and is retried with WithBodyReadRetries.
+	ErrTruncatedBody = errors.New("response body truncated")
+)
+
+// limitedBody fails reads once more than limit bytes have been consumed,
+// instead of silently truncating like io.LimitReader.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func newLimitedBody(body io.ReadCloser, limit int64) *limitedBody {
+	return &limitedBody{ReadCloser: body, remaining: limit, limit: limit}
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, b.tooLarge()
+	}
+	// Read one byte past the limit so an exact-size body still succeeds
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), b.tooLarge()
+	}
+	return n, err
+}
+
+func (b *limitedBody) tooLarge() error {
+	return fmt.Errorf("%w: limit is %d bytes", ErrBodyTooLarge, b.limit)
+}
+
+// lengthCheckedBody fails the read that ends the body early with
+// ErrTruncatedBody, rather than leaving it to the transport: custom ones may
+// return io.EOF and the partial data as if it was complete.
+type lengthCheckedBody struct {
+	io.ReadCloser
+	read   int64
+	length int64
+}
+
+// checkLength wraps the body of resp if it declares its length.
+func checkLength(resp *http.Response) {
+	if resp.ContentLength > 0 && bodyAllowed(resp) {
+		resp.Body = &lengthCheckedBody{ReadCloser: resp.Body, length: resp.ContentLength}
+	}
+}
+
+func (b *lengthCheckedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read < b.length && (err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF)) {
+		return n, fmt.Errorf("%w: received %d of %d bytes: %w", ErrTruncatedBody, b.read, b.length, io.ErrUnexpectedEOF)
+	}
+	return n, err
+}
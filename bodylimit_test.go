@@ -0,0 +1,121 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_MaxResponseBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"reqwest"}`))
+	}))
+	defer server.Close()
+
+	t.Run("body within limit", func(t *testing.T) {
+		client := NewClientBuilder().WithMaxResponseBytes(18).Build()
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var out struct{ Name string }
+		if err := resp.JSON(&out); err != nil {
+			t.Fatalf("Unexpected decode error: %v", err)
+		}
+		if out.Name != "reqwest" {
+			t.Errorf("Expected name reqwest, got %q", out.Name)
+		}
+	})
+
+	t.Run("body over limit", func(t *testing.T) {
+		client := NewClientBuilder().WithMaxResponseBytes(10).Build()
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var out map[string]any
+		if err := resp.JSON(&out); !errors.Is(err, ErrBodyTooLarge) {
+			t.Fatalf("Expected ErrBodyTooLarge, got %v", err)
+		}
+	})
+}
+
+func TestLimitedBody(t *testing.T) {
+	body := newLimitedBody(io.NopCloser(strings.NewReader("0123456789")), 4)
+	data, err := io.ReadAll(body)
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("Expected ErrBodyTooLarge, got %v", err)
+	}
+	if string(data) != "0123" {
+		t.Errorf("Expected only the first 4 bytes, got %q", data)
+	}
+	if _, err := body.Read(make([]byte, 1)); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("Expected later reads to keep failing, got %v", err)
+	}
+}
+
+func TestClient_TruncatedBody(t *testing.T) {
+	const body = `{"name":"reqwest"}`
+
+	t.Run("connection cut mid-body", func(t *testing.T) {
+		server, _ := newTruncatingServer(t, 1, body)
+		defer server.Close()
+
+		cli := NewClientBuilder().Build()
+		resp, err := cli.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		_, err = resp.Bytes()
+		if !errors.Is(err, ErrTruncatedBody) || !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Fatalf("Expected ErrTruncatedBody, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "received 9 of 100 bytes") {
+			t.Errorf("Expected the byte counts in %q", err)
+		}
+	})
+
+	t.Run("transport ending the body early", func(t *testing.T) {
+		cli := NewClientBuilder().
+			WithTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode:    http.StatusOK,
+					Header:        make(http.Header),
+					Body:          io.NopCloser(strings.NewReader("partial")),
+					ContentLength: 100,
+					Request:       req,
+				}, nil
+			})).
+			Build()
+		resp, err := cli.Get(context.Background(), "http://example.com")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := resp.Bytes(); !errors.Is(err, ErrTruncatedBody) {
+			t.Fatalf("Expected ErrTruncatedBody, got %v", err)
+		}
+	})
+
+	t.Run("retried with body read retries", func(t *testing.T) {
+		server, calls := newTruncatingServer(t, 1, body)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithBodyReadRetries(1).Build()
+		resp, err := cli.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		data, err := resp.Bytes()
+		if err != nil || string(data) != body {
+			t.Fatalf("Expected the full body, got %q and %v", data, err)
+		}
+		if got := atomic.LoadInt32(calls); got != 2 {
+			t.Errorf("Expected 2 calls, got %d", got)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package reqwest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// bodyRetry re-executes the logical request behind a response whose body
+// failed mid-read.
+type bodyRetry struct {
+	remaining int
+	refetch   func() (*Response, error)
+}
+
+// withBodyRetry lets helpers that consume the whole body of resp re-execute
+// request when reading fails, if the client enables it and the method is
+// idempotent.
+func (c *HTTPClient) withBodyRetry(ctx context.Context, request *Request, resp *Response) {
+	if c.bodyReadRetries <= 0 || resp == nil || !idempotentMethod(request.method) {
+		return
+	}
+	resp.bodyRetry = &bodyRetry{
+		remaining: c.bodyReadRetries,
+		refetch:   func() (*Response, error) { return c.execute(ctx, request) },
+	}
+}
+
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// retryableReadErr reports whether a body read error may succeed on a fresh
+// request, unlike exceeding the size limit or byte budget, a checksum
+// mismatch, or a cancelled context.
+func retryableReadErr(err error) bool {
+	return !errors.Is(err, ErrBodyTooLarge) &&
+		!errors.Is(err, ErrChecksumMismatch) &&
+		!errors.Is(err, ErrByteBudgetExceeded) &&
+		!errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded)
+}
+
+// readBody reads and closes the body. When reading fails mid-body and body
+// read retries apply, the request is re-executed and r takes over the fresh
+// response.
+func (r *Response) readBody() ([]byte, error) {
+	if r.buffered {
+		return bytes.Clone(r.buffer), nil
+	}
+	data, err := io.ReadAll(r.body)
+	r.body.Close()
+	for err != nil && r.bodyRetry != nil && r.bodyRetry.remaining > 0 && retryableReadErr(err) {
+		r.bodyRetry.remaining--
+		next, fetchErr := r.bodyRetry.refetch()
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		r.adopt(next)
+		data, err = io.ReadAll(r.body)
+		r.body.Close()
+	}
+	return data, err
+}
+
+// adopt replaces r's wire-level state with that of a re-executed request.
+func (r *Response) adopt(next *Response) {
+	r.statusCode = next.statusCode
+	r.proto = next.proto
+	r.header = next.header
+	r.request = next.request
+	r.body = next.body
+	r.retryAttempts += next.retryAttempts + 1
+	r.totalDuration += next.totalDuration
+	r.requestID = next.requestID
+	r.traceID = next.traceID
+	r.attemptTraces = append(r.attemptTraces, next.attemptTraces...)
+	r.apiVersion = next.apiVersion
+}
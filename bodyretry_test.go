@@ -0,0 +1,105 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newTruncatingServer cuts the connection mid-body for the first failures
+// requests, then serves body in full.
+func newTruncatingServer(t *testing.T, failures int32, body string) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) > failures {
+			w.Write([]byte(body))
+			return
+		}
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("Failed to hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 100\r\n\r\n" + body[:len(body)/2])
+		buf.Flush()
+	}))
+	return server, &calls
+}
+
+func TestClient_WithBodyReadRetries(t *testing.T) {
+	const body = `{"name":"reqwest"}`
+
+	t.Run("GET is re-executed", func(t *testing.T) {
+		server, calls := newTruncatingServer(t, 1, body)
+		defer server.Close()
+
+		client := NewClientBuilder().WithBodyReadRetries(2).Build()
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var out struct{ Name string }
+		if err := resp.JSON(&out); err != nil {
+			t.Fatalf("Unexpected decode error: %v", err)
+		}
+		if out.Name != "reqwest" || atomic.LoadInt32(calls) != 2 {
+			t.Errorf("Expected decoded body after 2 calls, got %q after %d", out.Name, atomic.LoadInt32(calls))
+		}
+		if resp.RetryAttempts() != 1 {
+			t.Errorf("Expected the re-execution to count as a retry, got %d", resp.RetryAttempts())
+		}
+	})
+
+	t.Run("gives up after n re-executions", func(t *testing.T) {
+		server, calls := newTruncatingServer(t, 10, body)
+		defer server.Close()
+
+		client := NewClientBuilder().WithBodyReadRetries(2).Build()
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := resp.Bytes(); err == nil {
+			t.Fatal("Expected a read error")
+		}
+		if got := atomic.LoadInt32(calls); got != 3 {
+			t.Errorf("Expected 3 calls, got %d", got)
+		}
+	})
+
+	t.Run("POST is not re-executed", func(t *testing.T) {
+		server, calls := newTruncatingServer(t, 1, body)
+		defer server.Close()
+
+		client := NewClientBuilder().WithBodyReadRetries(2).Build()
+		resp, err := client.Post(context.Background(), server.URL, []byte("{}"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := resp.Bytes(); err == nil {
+			t.Fatal("Expected a read error")
+		}
+		if got := atomic.LoadInt32(calls); got != 1 {
+			t.Errorf("Expected 1 call, got %d", got)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		server, calls := newTruncatingServer(t, 1, body)
+		defer server.Close()
+
+		resp, err := NewClientBuilder().Build().Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := resp.Bytes(); err == nil {
+			t.Fatal("Expected a read error")
+		}
+		if got := atomic.LoadInt32(calls); got != 1 {
+			t.Errorf("Expected 1 call, got %d", got)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the host while its circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// circuitState is the persisted state of one host's circuit.
+type circuitState struct {
+	Failures int       `json:"failures"`
+	OpenedAt time.Time `json:"opened_at,omitempty"`
+}
+
+// circuitBreaker stops sending to a host after threshold consecutive
+// failures, for cooldown. After the cooldown attempts are let through again
+// (half-open) and the first success closes the circuit. Store errors fail
+// open, so an unavailable store never blocks traffic.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	store     StateStore
+	clock     Clock
+	mu        sync.Mutex
+	onClose   func()
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration, store StateStore, clock Clock) *circuitBreaker {
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, store: store, clock: orSystemClock(clock)}
+}
+
+func circuitKey(host string) string {
+	return "reqwest:circuit:" + host
+}
+
+func (b *circuitBreaker) load(ctx context.Context, host string) circuitState {
+	var state circuitState
+	if data, err := b.store.Load(ctx, circuitKey(host)); err == nil && data != nil {
+		_ = json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+func (b *circuitBreaker) save(ctx context.Context, host string, state circuitState) {
+	data, _ := json.Marshal(state)
+	// Keep state around long enough to outlive an open circuit
+	_ = b.store.Store(ctx, circuitKey(host), data, 2*b.cooldown+time.Minute)
+}
+
+// update changes the thresholds of a live breaker.
+func (b *circuitBreaker) update(threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.threshold, b.cooldown = threshold, cooldown
+}
+
+func (b *circuitBreaker) settings() (threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.threshold, b.cooldown
+}
+
+// allow fails with ErrCircuitOpen while host's circuit is open.
+func (b *circuitBreaker) allow(ctx context.Context, host string) error {
+	state := b.load(ctx, host)
+	if state.OpenedAt.IsZero() {
+		return nil
+	}
+	_, cooldown := b.settings()
+	if remaining := cooldown - b.clock.Now().Sub(state.OpenedAt); remaining > 0 {
+		return fmt.Errorf("%w for %s, retry in %v", ErrCircuitOpen, host, remaining.Round(time.Millisecond))
+	}
+	return nil
+}
+
+// record updates host's circuit with the outcome of an attempt.
+func (b *circuitBreaker) record(ctx context.Context, host string, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state := b.load(ctx, host)
+	if success {
+		if state.Failures == 0 && state.OpenedAt.IsZero() {
+			return
+		}
+		b.save(ctx, host, circuitState{})
+		if !state.OpenedAt.IsZero() && b.onClose != nil {
+			b.onClose()
+		}
+		return
+	}
+	state.Failures++
+	if state.Failures >= b.threshold {
+		// Reopening after a failed half-open probe restarts the cooldown
+		state.OpenedAt = b.clock.Now()
+	}
+	b.save(ctx, host, state)
+}
@@ -0,0 +1,94 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_WithCircuitBreaker(t *testing.T) {
+	var healthy atomic.Bool
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientBuilder().WithCircuitBreaker(2, 50*time.Millisecond).Build()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get(ctx, server.URL); err != nil {
+			t.Fatalf("Unexpected error before the circuit opens: %v", err)
+		}
+	}
+	if _, err := client.Get(ctx, server.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Errorf("Expected the open circuit to short-circuit, got %d hits", got)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	healthy.Store(true)
+	resp, err := client.Get(ctx, server.URL)
+	if err != nil || resp.StatusCode() != http.StatusOK {
+		t.Fatalf("Expected the half-open probe to succeed, got %v", err)
+	}
+	if _, err := client.Get(ctx, server.URL); err != nil {
+		t.Errorf("Expected the circuit to be closed after a success, got %v", err)
+	}
+}
+
+func TestClient_SharedCircuitState(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	store := NewMemoryStateStore()
+	replica1 := NewClientBuilder().WithCircuitBreaker(1, time.Minute).WithStateStore(store).Build()
+	replica2 := NewClientBuilder().WithCircuitBreaker(1, time.Minute).WithStateStore(store).Build()
+
+	if _, err := replica1.Get(context.Background(), server.URL); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := replica2.Get(context.Background(), server.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected replica2 to see the circuit opened by replica1, got %v", err)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Load(context.Context, string) ([]byte, error) {
+	return nil, errors.New("store unavailable")
+}
+
+func (failingStore) Store(context.Context, string, []byte, time.Duration) error {
+	return errors.New("store unavailable")
+}
+
+func TestClient_StateStoreFailsOpen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClientBuilder().
+		WithCircuitBreaker(1, time.Minute).
+		WithRateLimit(1, 1).
+		WithStateStore(failingStore{}).
+		Build()
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get(context.Background(), server.URL); err != nil {
+			t.Fatalf("Expected an unavailable store not to block requests, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package reqwest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Buffer reads the whole body into memory and closes the stream, so the body
+// can be read any number of times afterwards, e.g. peeked at by a Handler
+// that logs it and then decoded by the caller. Buffering an already buffered
+// response does nothing.
+func (r *Response) Buffer() error {
+	if r.buffered {
+		return nil
+	}
+	data, err := r.readBody()
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	r.buffer = data
+	r.buffered = true
+	r.body = io.NopCloser(bytes.NewReader(data))
+	return nil
+}
+
+// bufferResponse buffers resp when the client was built with
+// WithBufferedResponses.
+func (c *HTTPClient) bufferResponse(resp *Response, err error) (*Response, error) {
+	if !c.bufferResponses || err != nil || resp == nil {
+		return resp, err
+	}
+	if err := resp.Buffer(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,65 @@
+package reqwest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponse_Buffer(t *testing.T) {
+	resp := NewResponse(http.StatusOK, nil, []byte(`{"name":"ada"}`))
+	if err := resp.Buffer(); err != nil {
+		t.Fatalf("Buffer() error = %v", err)
+	}
+	if err := resp.Buffer(); err != nil {
+		t.Fatalf("second Buffer() error = %v", err)
+	}
+
+	for i := range 2 {
+		data, err := resp.Bytes()
+		if err != nil || string(data) != `{"name":"ada"}` {
+			t.Errorf("Bytes() #%d = %q, %v", i+1, data, err)
+		}
+	}
+	data, _ := io.ReadAll(resp.Body())
+	if string(data) != `{"name":"ada"}` {
+		t.Errorf("Body() read %q after Bytes()", data)
+	}
+	var v struct{ Name string }
+	if err := resp.JSON(&v); err != nil || v.Name != "ada" {
+		t.Errorf("JSON() = %+v, %v", v, err)
+	}
+}
+
+func TestWithBufferedResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"ada"}`))
+	}))
+	defer server.Close()
+
+	var logged string
+	logBody := func(ctx context.Context, req *http.Request, next Next) (*Response, error) {
+		resp, err := next(ctx, req)
+		if err == nil {
+			data, _ := resp.Bytes()
+			logged = string(data)
+		}
+		return resp, err
+	}
+	cli := NewClientBuilder().WithBufferedResponses().WithHandler(logBody).Build()
+
+	resp, err := cli.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if logged != `{"name":"ada"}` {
+		t.Errorf("handler logged %q", logged)
+	}
+	var v struct{ Name string }
+	if err := resp.JSON(&v); err != nil || v.Name != "ada" {
+		t.Errorf("JSON() after the handler read the body = %+v, %v", v, err)
+	}
+}
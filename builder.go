@@ -1,14 +1,94 @@
 package reqwest
 
 import (
+	"context"
+	"crypto/tls"
+	"maps"
+	"net"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"slices"
 	"strings"
+	"time"
 )
 
 type ClientBuilder struct {
-	baseURL     string
-	middlewares []Middleware
-	retryConfig *RetryConfig
+	baseURL          string
+	middlewares      []Middleware
+	handlers         []Handler
+	attemptHandlers  []Handler
+	retryConfig      *RetryConfig
+	onRetry          func(ctx context.Context, attempt int, resp *Response, err error, nextDelay time.Duration)
+	decode           decodeConfig
+	roundTripper     http.RoundTripper
+	transportOptions []transportOption
+	preflightTTL     time.Duration
+	cacheTTL         time.Duration
+	cacheKey         CacheKeyFunc
+	readYourWrites   bool
+	cacheRelated     func(*Request) []string
+	signer           Signer
+	verifier         ResponseVerifier
+	requestID        *requestIDConfig
+	idempotency      bool
+	traceContext     bool
+	maxConcurrent    int
+	selfCheckPath    string
+	maxResponseBytes int64
+	versioning       versionConfig
+	maxRequestBytes  int64
+	maxHeaderCount   int
+	maxHeaderBytes   int
+	validators       []Validator
+	endpoints        []string
+	affinity         func(*Request) string
+	failover         FailoverStrategy
+	audit            *auditConfig
+	wrappers         []RoundTripperWrapper
+	tokenSource      TokenSource
+	apiKey           *apiKeyConfig
+	contentDecoders  map[string]ContentDecoder
+	onCompression    func(CompressionStats)
+	bodyReadRetries  int
+	prefetchBytes    int64
+	breakerThreshold int
+	breakerCooldown  time.Duration
+	rateLimit        rateLimitConfig
+	stateStore       StateStore
+	discovery        *discovery
+	connLimits       connLimits
+	dnsTTL           time.Duration
+	dnsResolver      *net.Resolver
+	clock            Clock
+	fallback         func(req *Request, err error) (*Response, error)
+	faults           *FaultInjector
+	coalesce         bool
+	staleRetry       bool
+	errorOnNon2xx    bool
+	strictURLs       bool
+	errorDecoder     func(*Response) error
+	bufferResponses  bool
+	leaks            *leakConfig
+	allowedHosts     []string
+	blockedCIDRs     []netip.Prefix
+	authenticator    Authenticator
+	redirectTTL      time.Duration
+	adaptiveTimeout  *AdaptiveTimeout
+	outbox           *Outbox
+	sharedOutbox     *outbox
+	offlineQueue     *OfflineQueue
+	connStats        bool
+	dualStack        *DualStack
+	pins             []string
+	wsReadLimit      int64
+	customDialer     bool
+	timeout          time.Duration
+	envDefaults      bool
+	defaultHeader    http.Header
+	shared           *sharedTransport
+	frozen           bool
 }
 
 func NewClientBuilder() *ClientBuilder {
@@ -18,34 +98,1045 @@ func NewClientBuilder() *ClientBuilder {
 }
 
 func (cb *ClientBuilder) WithBaseURL(url string) *ClientBuilder {
+	cb = cb.mutable()
 	cb.baseURL = strings.TrimRight(url, "/")
 	return cb
 }
 
+// WithBaseURLs load balances requests with relative URLs across several
+// equivalent backends, round-robin unless an affinity key is configured.
+// Trailing slashes are trimmed as with WithBaseURL.
+func (cb *ClientBuilder) WithBaseURLs(urls ...string) *ClientBuilder {
+	cb = cb.mutable()
+	cb.endpoints = make([]string, 0, len(urls))
+	for _, url := range urls {
+		cb.endpoints = append(cb.endpoints, strings.TrimRight(url, "/"))
+	}
+	return cb
+}
+
+// WithStrictURLs validates request URLs before anything is sent, failing
+// malformed ones with a *URLError, and normalizes them: dot segments and
+// duplicate slashes are removed from the path and unsafe characters are
+// percent-encoded.
+func (cb *ClientBuilder) WithStrictURLs() *ClientBuilder {
+	cb = cb.mutable()
+	cb.strictURLs = true
+	return cb
+}
+
+// WithAffinityKey routes requests that share a non-empty key, such as a user
+// or session ID, consistently to the same base URL configured with
+// WithBaseURLs. All attempts of a logical request use the same endpoint.
+func (cb *ClientBuilder) WithAffinityKey(key func(*Request) string) *ClientBuilder {
+	cb = cb.mutable()
+	cb.affinity = key
+	return cb
+}
+
+// WithServiceDiscovery treats the host of each request URL as a logical
+// service name: every attempt asks resolver for the service's endpoints and
+// balancer picks one, e.g. http://payments/charges goes to one of the
+// endpoints of "payments". A nil balancer uses RoundRobinBalancer.
+func (cb *ClientBuilder) WithServiceDiscovery(resolver Resolver, balancer Balancer) *ClientBuilder {
+	cb = cb.mutable()
+	if balancer == nil {
+		balancer = RoundRobinBalancer()
+	}
+	cb.discovery = &discovery{resolver: resolver, balancer: balancer}
+	return cb
+}
+
+// WithFailover makes retries move to another base URL configured with
+// WithBaseURLs, skipping endpoints whose circuit breaker is open, so a failing
+// replica doesn't consume every attempt.
+func (cb *ClientBuilder) WithFailover(strategy FailoverStrategy) *ClientBuilder {
+	cb = cb.mutable()
+	cb.failover = strategy
+	return cb
+}
+
 func (cb *ClientBuilder) WithMiddleware(middleware Middleware) *ClientBuilder {
+	cb = cb.mutable()
 	cb.middlewares = append(cb.middlewares, middleware)
 	return cb
 }
 
+// WithHandler wraps every logical request, once regardless of retries, with
+// handler. Unlike a Middleware, a Handler can answer without sending the
+// request, or inspect and replace the response.
+func (cb *ClientBuilder) WithHandler(handler Handler) *ClientBuilder {
+	cb = cb.mutable()
+	cb.handlers = append(cb.handlers, handler)
+	return cb
+}
+
+// WithAttemptHandler is like WithHandler but wraps every attempt, retries
+// included, after the retry policy has chosen the URL.
+func (cb *ClientBuilder) WithAttemptHandler(handler Handler) *ClientBuilder {
+	cb = cb.mutable()
+	cb.attemptHandlers = append(cb.attemptHandlers, handler)
+	return cb
+}
+
+// WithSigner signs every attempt after middleware has run, with access to
+// the final body bytes.
+func (cb *ClientBuilder) WithSigner(signer Signer) *ClientBuilder {
+	cb = cb.mutable()
+	cb.signer = signer
+	return cb
+}
+
+// WithAuthenticator answers 401 challenges with authenticator, e.g.
+// NewDigestAuth, by repeating the request once with the Authorization it
+// returns. The challenges are remembered per host, so later requests there
+// are authenticated before they are sent.
+func (cb *ClientBuilder) WithAuthenticator(authenticator Authenticator) *ClientBuilder {
+	cb = cb.mutable()
+	cb.authenticator = authenticator
+	return cb
+}
+
+// WithResponseVerifier rejects responses that fail verifier with a
+// *SignatureError. The body is read in full to be verified, so verified
+// responses are never streamed.
+func (cb *ClientBuilder) WithResponseVerifier(verifier ResponseVerifier) *ClientBuilder {
+	cb = cb.mutable()
+	cb.verifier = verifier
+	return cb
+}
+
+// WithRequestID stamps every logical request with an ID in headerName. The
+// ID is reused across retry attempts and exposed via Response.RequestID for
+// correlation with server logs. A nil generator uses NewUUID and an empty
+// header name uses X-Request-ID.
+func (cb *ClientBuilder) WithRequestID(generator func() string, headerName string) *ClientBuilder {
+	cb = cb.mutable()
+	if generator == nil {
+		generator = NewUUID
+	}
+	if headerName == "" {
+		headerName = DefaultRequestIDHeader
+	}
+	cb.requestID = &requestIDConfig{generator: generator, header: headerName}
+	return cb
+}
+
+// WithTraceContext sends a W3C traceparent header with every attempt, all
+// attempts of a logical request sharing one trace ID exposed via
+// Response.TraceID. A valid traceparent already on the request is continued.
+func (cb *ClientBuilder) WithTraceContext() *ClientBuilder {
+	cb = cb.mutable()
+	cb.traceContext = true
+	return cb
+}
+
+// WithIdempotencyKeys attaches a generated Idempotency-Key to every POST and
+// PATCH that doesn't carry one, reusing it across retry attempts and outbox
+// replays so retried mutations are not applied twice.
+func (cb *ClientBuilder) WithIdempotencyKeys() *ClientBuilder {
+	cb = cb.mutable()
+	cb.idempotency = true
+	return cb
+}
+
+// WithTimeout bounds every call to d, covering all retry attempts and reading
+// the response body, unless the context carries a ContextWithTimeoutOverride.
+func (cb *ClientBuilder) WithTimeout(d time.Duration) *ClientBuilder {
+	cb = cb.mutable()
+	cb.timeout = d
+	return cb
+}
+
+// WithEnvDefaults takes settings the builder leaves unset from the
+// environment when the client is built: REQWEST_BASE_URL, REQWEST_TIMEOUT,
+// REQWEST_MAX_RETRIES, REQWEST_MAX_CONCURRENT_REQUESTS and the proxy
+// variables HTTP_PROXY, HTTPS_PROXY and NO_PROXY. Explicit builder settings
+// take precedence over the environment, which takes precedence over the
+// defaults. Invalid values are ignored; the client's Policy reports what was
+// resolved.
+func (cb *ClientBuilder) WithEnvDefaults() *ClientBuilder {
+	cb = cb.mutable()
+	cb.envDefaults = true
+	return cb
+}
+
+// WithMaxConcurrentRequests limits how many attempts may be in flight at
+// once. Callers queue for a slot; when the expected wait exceeds the context
+// deadline the call fails immediately with ErrWouldExceedDeadline.
+func (cb *ClientBuilder) WithMaxConcurrentRequests(limit int) *ClientBuilder {
+	cb = cb.mutable()
+	cb.maxConcurrent = limit
+	return cb
+}
+
+// WithSelfCheckPath sets the path SelfCheck probes, e.g. an authenticated
+// health endpoint. It defaults to "/".
+func (cb *ClientBuilder) WithSelfCheckPath(path string) *ClientBuilder {
+	cb = cb.mutable()
+	cb.selfCheckPath = path
+	return cb
+}
+
+// WithMaxResponseBytes caps how much of a response body may be read. Reads
+// past n bytes, including those made by Response.JSON, fail with
+// ErrBodyTooLarge so an oversized payload can't exhaust memory.
+func (cb *ClientBuilder) WithMaxResponseBytes(n int64) *ClientBuilder {
+	cb = cb.mutable()
+	cb.maxResponseBytes = n
+	return cb
+}
+
+// WithMediaTypeVersions negotiates vendor media-type versions, sending e.g.
+// "Accept: application/vnd.foo.v2+json, application/vnd.foo.v1+json;q=0.9"
+// for vendor "foo" and versions "v2", "v1" (most preferred first). The
+// version the server answered with is available from Response.APIVersion.
+// Requests that set their own Accept header are left alone.
+func (cb *ClientBuilder) WithMediaTypeVersions(vendor string, versions ...string) *ClientBuilder {
+	cb = cb.mutable()
+	cb.versioning.vendor = vendor
+	cb.versioning.versions = append([]string(nil), versions...)
+	return cb
+}
+
+// WithOnRetry registers a hook called each time the client decides to retry,
+// e.g. to log or count retries. attempt is the 1-based number of the attempt
+// that failed, with its response (closed once the hook returns) or error,
+// and nextDelay the backoff before the next attempt.
+func (cb *ClientBuilder) WithOnRetry(hook func(attempt int, resp *Response, err error, nextDelay time.Duration)) *ClientBuilder {
+	if hook == nil {
+		return cb.WithOnRetryContext(nil)
+	}
+	return cb.WithOnRetryContext(func(_ context.Context, attempt int, resp *Response, err error, nextDelay time.Duration) {
+		hook(attempt, resp, err, nextDelay)
+	})
+}
+
+// WithOnRetryContext is like WithOnRetry but also passes the context of the
+// call, which carries values attached with Request.WithValue.
+func (cb *ClientBuilder) WithOnRetryContext(hook func(ctx context.Context, attempt int, resp *Response, err error, nextDelay time.Duration)) *ClientBuilder {
+	cb = cb.mutable()
+	cb.onRetry = hook
+	return cb
+}
+
+// WithOnVersionDowngrade registers a hook called whenever a response is
+// served with a version other than the most preferred one.
+func (cb *ClientBuilder) WithOnVersionDowngrade(hook func(VersionDowngrade)) *ClientBuilder {
+	cb = cb.mutable()
+	cb.versioning.onDowngrade = hook
+	return cb
+}
+
+// WithMaxRequestBytes rejects requests whose body is larger than n bytes
+// with ErrRequestTooLarge, without sending them.
+func (cb *ClientBuilder) WithMaxRequestBytes(n int64) *ClientBuilder {
+	cb = cb.mutable()
+	cb.maxRequestBytes = n
+	return cb
+}
+
+// WithHeaderLimits rejects requests with more than maxCount header lines or
+// more than maxBytes of headers, measured after middleware and signing, with
+// ErrHeadersTooLarge. Zero disables a limit.
+func (cb *ClientBuilder) WithHeaderLimits(maxCount, maxBytes int) *ClientBuilder {
+	cb = cb.mutable()
+	cb.maxHeaderCount = maxCount
+	cb.maxHeaderBytes = maxBytes
+	return cb
+}
+
+// WithValidator adds a Validator run on every attempt just before it is
+// sent. Validators run in the order they were added.
+func (cb *ClientBuilder) WithValidator(validator Validator) *ClientBuilder {
+	cb = cb.mutable()
+	cb.validators = append(cb.validators, validator)
+	return cb
+}
+
+// WithAuditLog records an AuditRecord for every logical request, available
+// from Response.Audit. A non-nil sink also receives every finished record,
+// including those of requests that failed without a response.
+func (cb *ClientBuilder) WithAuditLog(sink func(*AuditRecord)) *ClientBuilder {
+	cb = cb.mutable()
+	cb.audit = &auditConfig{sink: sink}
+	return cb
+}
+
+// WithBodyReadRetries lets Response.Bytes, JSON and Decode transparently
+// re-execute idempotent requests up to n times when the body fails mid-read,
+// e.g. on a connection reset after a 200. Re-executions use the full policy
+// stack, including regular retries.
+func (cb *ClientBuilder) WithBodyReadRetries(n int) *ClientBuilder {
+	cb = cb.mutable()
+	cb.bodyReadRetries = n
+	return cb
+}
+
+// WithPrefetchBody reads response bodies with a Content-Length of at most
+// maxBytes before returning, releasing the connection for reuse and letting
+// read failures of idempotent requests be retried like transport errors.
+// Larger bodies and bodies of unknown length keep streaming.
+func (cb *ClientBuilder) WithPrefetchBody(maxBytes int64) *ClientBuilder {
+	cb = cb.mutable()
+	cb.prefetchBytes = maxBytes
+	return cb
+}
+
+// WithCircuitBreaker stops sending requests to a host for cooldown after
+// failureThreshold consecutive failures (transport errors or 5xx), failing
+// them fast with ErrCircuitOpen instead.
+func (cb *ClientBuilder) WithCircuitBreaker(failureThreshold int, cooldown time.Duration) *ClientBuilder {
+	cb = cb.mutable()
+	cb.breakerThreshold = failureThreshold
+	cb.breakerCooldown = cooldown
+	return cb
+}
+
+// WithRateLimit limits attempts to requestsPerSecond on average with bursts
+// of up to burst, delaying attempts until the budget allows them.
+func (cb *ClientBuilder) WithRateLimit(requestsPerSecond float64, burst int) *ClientBuilder {
+	cb = cb.mutable()
+	cb.rateLimit.rate = requestsPerSecond
+	cb.rateLimit.burst = burst
+	return cb
+}
+
+// WithRateLimitWarmup starts the rate limit at initialFraction of its rate
+// and burst, ramping linearly to the full rate over d. The ramp restarts when
+// a circuit breaker closes, so a fleet of fresh or recovering clients doesn't
+// stampede the upstream.
+func (cb *ClientBuilder) WithRateLimitWarmup(d time.Duration, initialFraction float64) *ClientBuilder {
+	cb = cb.mutable()
+	cb.rateLimit.warmup = d
+	cb.rateLimit.warmFraction = initialFraction
+	return cb
+}
+
+// WithRateLimitSmoothing spreads a full burst evenly over window instead of
+// allowing it all at once.
+func (cb *ClientBuilder) WithRateLimitSmoothing(window time.Duration) *ClientBuilder {
+	cb = cb.mutable()
+	cb.rateLimit.smoothingSpan = window
+	return cb
+}
+
+// WithStateStore keeps circuit breaker and rate limit state in store instead
+// of in process memory, so replicas sharing the store share upstream health
+// and one rate budget.
+func (cb *ClientBuilder) WithStateStore(store StateStore) *ClientBuilder {
+	cb = cb.mutable()
+	cb.stateStore = store
+	return cb
+}
+
+// WithFallback serves a response from fallback when a request ultimately
+// fails, after retries or because its circuit is open, so features can
+// degrade to cached, stubbed or default data instead of erroring. fallback
+// may return an error to fail the request after all. It is not consulted for
+// cancelled requests, or for error statuses returned by the server.
+func (cb *ClientBuilder) WithFallback(fallback func(req *Request, err error) (*Response, error)) *ClientBuilder {
+	cb = cb.mutable()
+	cb.fallback = fallback
+	return cb
+}
+
+// WithRequestCoalescing makes identical concurrent GETs (same URL and
+// headers) share a single upstream call. Each caller receives its own copy of
+// the fully read response. Requests with per-call options, such as their own
+// retries, timeout or byte budget, are not coalesced.
+func (cb *ClientBuilder) WithRequestCoalescing() *ClientBuilder {
+	cb = cb.mutable()
+	cb.coalesce = true
+	return cb
+}
+
+// WithAdaptiveTimeout bounds each attempt by a timeout learned from the
+// route's recent latencies, so a slow attempt is abandoned and retried long
+// before the overall request timeout. It covers the time to the response
+// headers; the request timeout still bounds the whole call.
+func (cb *ClientBuilder) WithAdaptiveTimeout(cfg AdaptiveTimeout) *ClientBuilder {
+	cb = cb.mutable()
+	cb.adaptiveTimeout = &cfg
+	return cb
+}
+
+// WithStaleEndpointRetry smooths over DNS cutovers: when a host that has
+// answered before fails with NXDOMAIN or a refused connection, its cached
+// lookups and idle connections are dropped and the attempt is repeated once,
+// immediately, without counting against the retry policy.
+func (cb *ClientBuilder) WithStaleEndpointRetry() *ClientBuilder {
+	cb = cb.mutable()
+	cb.staleRetry = true
+	return cb
+}
+
+// WithBufferedResponses reads every response body into memory before the
+// request returns, so handlers and callers can read it as often as they need.
+// Streamed bodies are read to the end first, so leave it off for streams.
+func (cb *ClientBuilder) WithBufferedResponses() *ClientBuilder {
+	cb = cb.mutable()
+	cb.bufferResponses = true
+	return cb
+}
+
+// WithBodyLeakDetection reports response bodies that are never closed to
+// onLeak and closes them, so their connections return to the pool: bodies
+// garbage collected while open, and, if idleTimeout is positive, bodies left
+// unread for idleTimeout. Each report carries the stack that made the
+// request. Tracking costs a stack capture per attempt.
+func (cb *ClientBuilder) WithBodyLeakDetection(idleTimeout time.Duration, onLeak func(BodyLeak)) *ClientBuilder {
+	cb = cb.mutable()
+	cb.leaks = &leakConfig{idleTimeout: idleTimeout, onLeak: onLeak}
+	return cb
+}
+
+// WithErrorOnNon2xx makes requests whose final response, after retries, is
+// not 2xx fail with an *HTTPError instead of returning the response.
+func (cb *ClientBuilder) WithErrorOnNon2xx() *ClientBuilder {
+	cb = cb.mutable()
+	cb.errorOnNon2xx = true
+	return cb
+}
+
+// WithErrorDecoder decodes responses that are not 2xx, e.g. an API's error
+// envelope, with decoder. When decoder returns an error the request fails
+// with an *HTTPError wrapping it, and it is retried only if the error is
+// Retryable; when it returns nil the response is handled as usual.
+func (cb *ClientBuilder) WithErrorDecoder(decoder func(*Response) error) *ClientBuilder {
+	cb = cb.mutable()
+	cb.errorDecoder = decoder
+	return cb
+}
+
+// WithFaultInjector randomly delays or fails attempts with injector, so
+// retries and circuit breaking can be exercised in resilience tests.
+func (cb *ClientBuilder) WithFaultInjector(injector *FaultInjector) *ClientBuilder {
+	cb = cb.mutable()
+	cb.faults = injector
+	return cb
+}
+
+// WithClock makes the client tell time with clock instead of SystemClock,
+// e.g. a fake clock that lets tests advance through cache expiry, circuit
+// cooldowns and retry backoff instantly. See Clock for what it covers.
+func (cb *ClientBuilder) WithClock(clock Clock) *ClientBuilder {
+	cb = cb.mutable()
+	cb.clock = clock
+	return cb
+}
+
 func (cb *ClientBuilder) WithRetryConfig(config *RetryConfig) *ClientBuilder {
+	cb = cb.mutable()
 	cb.retryConfig = config
 	return cb
 }
 
 func (cb *ClientBuilder) WithRetries() *ClientBuilder {
+	cb = cb.mutable()
 	cb.retryConfig = NewRetryConfigBuilder().Build()
 	return cb
 }
 
-func (cb *ClientBuilder) Build() Client {
-	c := &client{
-		httpClient:  http.DefaultClient,
-		middlewares: make([]Middleware, len(cb.middlewares)),
-		retryConfig: cb.retryConfig,
+// WithNumberMode sets how JSON numbers are decoded by Response.JSON. Use
+// NumberJSON for APIs where float64 rounding is unacceptable, such as money.
+func (cb *ClientBuilder) WithNumberMode(mode NumberMode) *ClientBuilder {
+	cb = cb.mutable()
+	cb.decode.numberMode = mode
+	return cb
+}
+
+// WithStrictDecoding makes Response.JSON reject payloads containing fields
+// that have no destination in the target value.
+func (cb *ClientBuilder) WithStrictDecoding() *ClientBuilder {
+	cb = cb.mutable()
+	cb.decode.strict = true
+	return cb
+}
+
+// WithOnUnknownField registers a hook called with the path of every field in
+// a decoded payload that the target value does not declare. Without strict
+// decoding this reports contract drift as warnings while decoding succeeds.
+func (cb *ClientBuilder) WithOnUnknownField(hook func(field string)) *ClientBuilder {
+	cb = cb.mutable()
+	cb.decode.onUnknownField = hook
+	return cb
+}
+
+// WithDriftMonitor compares the shape of every JSON body decoded with
+// Response.JSON against the monitor's captured baselines.
+func (cb *ClientBuilder) WithDriftMonitor(monitor *DriftMonitor) *ClientBuilder {
+	cb = cb.mutable()
+	cb.decode.drift = monitor
+	return cb
+}
+
+// WithCodec registers codec for its content type, used by Response.Decode.
+// JSON and XML codecs are built in; registering a codec for their content
+// types replaces them.
+func (cb *ClientBuilder) WithCodec(codec Codec) *ClientBuilder {
+	cb = cb.mutable()
+	if cb.decode.codecs == nil {
+		cb.decode.codecs = make(map[string]Codec)
+	}
+	cb.decode.codecs[codec.ContentType()] = codec
+	return cb
+}
+
+// WithPreflightCache caches successful OPTIONS responses per host and path
+// for ttl, so capability probes don't double request volume.
+func (cb *ClientBuilder) WithPreflightCache(ttl time.Duration) *ClientBuilder {
+	cb = cb.mutable()
+	cb.preflightTTL = ttl
+	return cb
+}
+
+// WithResponseCache serves repeated GET requests from memory for ttl. Only
+// 200 OK responses without Cache-Control: no-store are cached; send
+// Cache-Control: no-cache to force a refresh.
+func (cb *ClientBuilder) WithResponseCache(ttl time.Duration) *ClientBuilder {
+	cb = cb.mutable()
+	cb.cacheTTL = ttl
+	return cb
+}
+
+// WithOutbox saves requests that still fail after their retries to
+// cfg.Store, and replays them from a background worker with its own backoff
+// until they are delivered, so fire-and-forget requests such as webhooks
+// survive outages and restarts. A saved request fails with an error wrapping
+// ErrQueued. Replays may deliver a request twice, e.g. when a response is
+// lost, so receivers should deduplicate, e.g. by an idempotency key header:
+// keys from WithIdempotencyKeys are saved with the request and replayed as is.
+// Clients derived from this one share its outbox and worker, which Close on
+// this client stops.
+func (cb *ClientBuilder) WithOutbox(cfg Outbox) *ClientBuilder {
+	cb = cb.mutable()
+	cb.outbox = &cfg
+	cb.sharedOutbox = nil
+	return cb
+}
+
+// WithOfflineQueue holds requests back while the network is down, for CLI
+// tools and edge agents with intermittent connectivity. A request failing
+// because the network is down, after its retries, takes the client offline;
+// until a probe finds the network back, that request and new ones wait in a
+// bounded queue and are then sent again. A request that waits longer than
+// the queue's TTL fails with ErrOffline, one that finds the queue full with
+// ErrOfflineQueueFull.
+func (cb *ClientBuilder) WithOfflineQueue(cfg OfflineQueue) *ClientBuilder {
+	cb = cb.mutable()
+	cb.offlineQueue = &cfg
+	return cb
+}
+
+// WithRedirectCache remembers permanent redirects (301 and 308) for ttl, so
+// later requests for a moved URL go straight to its new location. A 301 is
+// only replayed for GET and HEAD, which it can't change the method of. Use
+// FlushRedirects on the client to drop entries before they expire.
+func (cb *ClientBuilder) WithRedirectCache(ttl time.Duration) *ClientBuilder {
+	cb = cb.mutable()
+	cb.redirectTTL = ttl
+	return cb
+}
+
+// WithCacheKey customizes how the response cache keys requests, e.g. with
+// VaryCacheKey to separate responses per language or tenant.
+func (cb *ClientBuilder) WithCacheKey(key CacheKeyFunc) *ClientBuilder {
+	cb = cb.mutable()
+	cb.cacheKey = key
+	return cb
+}
+
+// WithReadYourWrites makes successful mutations (any 2xx response to a method
+// other than GET, HEAD or OPTIONS) invalidate cached responses for the
+// mutated URL, so later GETs see the write. related may return further URLs
+// to invalidate, e.g. the collection a created item belongs to; it may be
+// nil.
+func (cb *ClientBuilder) WithReadYourWrites(related func(req *Request) []string) *ClientBuilder {
+	cb = cb.mutable()
+	cb.readYourWrites = true
+	cb.cacheRelated = related
+	return cb
+}
+
+// WithTransport replaces the underlying http.RoundTripper. This is the hook
+// for transports the core does not ship, such as an HTTP/3 (QUIC) round
+// tripper. Transport settings from other builder options are applied on top
+// when the round tripper is an *http.Transport.
+func (cb *ClientBuilder) WithTransport(roundTripper http.RoundTripper) *ClientBuilder {
+	cb = cb.mutable()
+	cb.roundTripper = roundTripper
+	cb.shared = nil
+	return cb
+}
+
+// WithRoundTripperWrapper wraps the transport, e.g. with a tracing or
+// metrics round tripper. Wrappers are applied in order, so the last one added
+// is outermost.
+func (cb *ClientBuilder) WithRoundTripperWrapper(wrapper RoundTripperWrapper) *ClientBuilder {
+	cb = cb.mutable()
+	cb.wrappers = append(cb.wrappers, wrapper)
+	cb.shared = nil
+	return cb
+}
+
+// WithDefaultHeader sends name: value with every request that doesn't set
+// name itself. Use SetDefaultHeader to change it on the built client.
+func (cb *ClientBuilder) WithDefaultHeader(name, value string) *ClientBuilder {
+	cb = cb.mutable()
+	if cb.defaultHeader == nil {
+		cb.defaultHeader = make(http.Header)
+	}
+	cb.defaultHeader.Set(name, value)
+	return cb
+}
+
+// WithTokenSource sends "Authorization: Bearer <token>" with a token from
+// source on every attempt.
+func (cb *ClientBuilder) WithTokenSource(source TokenSource) *ClientBuilder {
+	cb = cb.mutable()
+	cb.tokenSource = source
+	return cb
+}
+
+// WithAPIKey sends value as the API key name, in a header or the query
+// string. The key is masked in transport errors and marked on the request
+// context (see SecretsFromContext) so recorders and dumps can mask it too.
+func (cb *ClientBuilder) WithAPIKey(name, value string, in APIKeyLocation) *ClientBuilder {
+	return cb.WithAPIKeySource(name, in, staticAPIKey(value))
+}
+
+// WithAPIKeySource is like WithAPIKey but asks source for the key on every
+// attempt, so keys can be rotated while the client is in use.
+func (cb *ClientBuilder) WithAPIKeySource(name string, in APIKeyLocation, source APIKeySource) *ClientBuilder {
+	cb = cb.mutable()
+	cb.apiKey = &apiKeyConfig{name: name, in: in, source: source}
+	return cb
+}
+
+// WithContentDecoder registers a decoder for responses with the given
+// Content-Encoding and advertises it in Accept-Encoding.
+func (cb *ClientBuilder) WithContentDecoder(encoding string, decoder ContentDecoder) *ClientBuilder {
+	cb = cb.mutable()
+	if cb.contentDecoders == nil {
+		cb.contentDecoders = make(map[string]ContentDecoder)
+	}
+	cb.contentDecoders[strings.ToLower(encoding)] = decoder
+	return cb
+}
+
+// WithCompressionMetrics reports the negotiated encoding and the wire and
+// decoded sizes of every response body once it is closed, e.g. to quantify
+// bandwidth savings or find upstreams ignoring Accept-Encoding. gzip is then
+// decoded by the client instead of the transport so wire bytes can be seen.
+func (cb *ClientBuilder) WithCompressionMetrics(report func(CompressionStats)) *ClientBuilder {
+	cb = cb.mutable()
+	cb.onCompression = report
+	return cb
+}
+
+// WithHTTPVersion restricts the protocol versions used by the transport.
+func (cb *ClientBuilder) WithHTTPVersion(version HTTPVersion) *ClientBuilder {
+	cb = cb.mutable()
+	cb.transportOptions = append(cb.transportOptions, withHTTPVersion(version))
+	cb.shared = nil
+	return cb
+}
+
+// WithDialContext replaces how the transport opens connections.
+func (cb *ClientBuilder) WithDialContext(dial DialContextFunc) *ClientBuilder {
+	cb = cb.mutable()
+	cb.transportOptions = append(cb.transportOptions, withDialContext(dial))
+	cb.customDialer = true
+	cb.shared = nil
+	return cb
+}
+
+// WithTLSConfig sets the TLS configuration of the transport, e.g. to trust a
+// private CA or present a client certificate.
+func (cb *ClientBuilder) WithTLSConfig(config *tls.Config) *ClientBuilder {
+	cb = cb.mutable()
+	cb.transportOptions = append(cb.transportOptions, withTLSConfig(config))
+	cb.shared = nil
+	return cb
+}
+
+// WithProxy routes requests through the proxy returned by proxy, as
+// http.Transport.Proxy does. Use http.ProxyURL for a fixed proxy.
+func (cb *ClientBuilder) WithProxy(proxy func(*http.Request) (*url.URL, error)) *ClientBuilder {
+	cb = cb.mutable()
+	cb.transportOptions = append(cb.transportOptions, withProxy(proxy))
+	cb.shared = nil
+	return cb
+}
+
+// WithBlockedCIDRs refuses to connect to addresses in prefixes, e.g.
+// DefaultBlockedCIDRs when requesting user-supplied URLs. Addresses are
+// checked before connecting, after DNS resolution and for redirects too, and
+// the targets of proxied requests before they are sent to the proxy. Hosts
+// listed with WithAllowedHosts are exempt.
+func (cb *ClientBuilder) WithBlockedCIDRs(prefixes ...netip.Prefix) *ClientBuilder {
+	cb = cb.mutable()
+	for _, prefix := range prefixes {
+		cb.blockedCIDRs = append(cb.blockedCIDRs, prefix.Masked())
+	}
+	cb.shared = nil
+	return cb
+}
+
+// WithAllowedHosts exempts hosts from WithBlockedCIDRs, e.g. an internal API
+// or the proxy. Hosts are names, "*.example.com" wildcards matching any
+// subdomain, IP addresses or CIDR prefixes.
+func (cb *ClientBuilder) WithAllowedHosts(hosts ...string) *ClientBuilder {
+	cb = cb.mutable()
+	cb.allowedHosts = append(cb.allowedHosts, hosts...)
+	cb.shared = nil
+	return cb
+}
+
+// WithTransportTimeouts sets the non-zero timeouts of timeouts on the
+// transport, and the connection lifetime as WithMaxConnectionLifetime does.
+func (cb *ClientBuilder) WithTransportTimeouts(timeouts Timeouts) *ClientBuilder {
+	cb = cb.mutable()
+	cb.transportOptions = append(cb.transportOptions, withTimeouts(timeouts))
+	if timeouts.ConnectionLifetime > 0 {
+		cb.connLimits.lifetime = timeouts.ConnectionLifetime
+	}
+	cb.shared = nil
+	return cb
+}
+
+// WithMaxConnectionLifetime retires pooled connections once they are older
+// than d, so traffic moves when upstream load balancers only rebalance new
+// connections. It applies to HTTP/1 connections of the default transport.
+func (cb *ClientBuilder) WithMaxConnectionLifetime(d time.Duration) *ClientBuilder {
+	cb = cb.mutable()
+	cb.connLimits.lifetime = d
+	cb.shared = nil
+	return cb
+}
+
+// WithMaxRequestsPerConnection retires pooled connections after they have
+// served n requests. It applies to HTTP/1 connections of the default
+// transport.
+func (cb *ClientBuilder) WithMaxRequestsPerConnection(n int) *ClientBuilder {
+	cb = cb.mutable()
+	cb.connLimits.maxRequests = n
+	cb.shared = nil
+	return cb
+}
+
+// WithConnectionStats instruments the transport so Stats reports open, idle
+// and busy connections and how often connections are reused, per dialed
+// address. Connection counts need the default transport or an
+// *http.Transport. Derived clients share the pool and its stats.
+func (cb *ClientBuilder) WithConnectionStats() *ClientBuilder {
+	cb = cb.mutable()
+	cb.connStats = true
+	cb.shared = nil
+	return cb
+}
+
+// WithDualStack controls how connections to hosts with both IPv4 and IPv6
+// addresses are dialed: which family goes first, how long it has before the
+// other one is raced against it, or that the families are tried one after
+// the other. Networks that break one family otherwise pay the fallback delay
+// on every new connection. Host names are then resolved by the client, through
+// the DNS cache if there is one. Like WithDNSCache, it has no effect with
+// WithDialContext or WithUnixSocket.
+func (cb *ClientBuilder) WithDualStack(cfg DualStack) *ClientBuilder {
+	cb = cb.mutable()
+	cb.dualStack = &cfg
+	cb.shared = nil
+	return cb
+}
+
+// WithCertificatePinning accepts a TLS connection only if a certificate of
+// the server's chain matches one of pins, each "sha256/" and the base64
+// SHA-256 hash of the certificate's public key (see CertificatePin) or of the
+// whole certificate. It applies on top of the usual verification; a mismatch
+// fails the handshake with a *CertificatePinError, which is not retried.
+func (cb *ClientBuilder) WithCertificatePinning(pins ...string) *ClientBuilder {
+	cb = cb.mutable()
+	cb.pins = append(cb.pins, pins...)
+	cb.shared = nil
+	return cb
+}
+
+// WithWebSocketReadLimit caps the size of a message read from connections
+// opened with Dial, DefaultWebSocketReadLimit by default. A larger message
+// fails Conn.ReadMessage with ErrWebSocketProtocol and closes the connection
+// before its payload is buffered.
+func (cb *ClientBuilder) WithWebSocketReadLimit(n int64) *ClientBuilder {
+	cb = cb.mutable()
+	cb.wsReadLimit = n
+	return cb
+}
+
+// WithDNSCache caches host name lookups for ttl, so high-QPS clients don't
+// query the resolver for every new connection. Use DNSCacheStats and
+// InvalidateDNS on the client to observe and reset the cache. It has no
+// effect with WithDialContext or WithUnixSocket, whose dialer gets the host
+// names as they are.
+func (cb *ClientBuilder) WithDNSCache(ttl time.Duration) *ClientBuilder {
+	cb = cb.mutable()
+	cb.dnsTTL = ttl
+	cb.shared = nil
+	return cb
+}
+
+// WithDNSResolver resolves host names with resolver instead of the system
+// resolver, e.g. one from NewDNSResolver that pins specific DNS servers. Like
+// WithDNSCache, it has no effect with WithDialContext or WithUnixSocket.
+func (cb *ClientBuilder) WithDNSResolver(resolver *net.Resolver) *ClientBuilder {
+	cb = cb.mutable()
+	cb.dnsResolver = resolver
+	cb.shared = nil
+	return cb
+}
+
+// WithUnixSocket sends every request over the unix domain socket at path.
+// URLs keep their normal form, e.g. http://localhost/v1.43/containers/json;
+// only the path and query are meaningful to the daemon.
+func (cb *ClientBuilder) WithUnixSocket(path string) *ClientBuilder {
+	return cb.WithDialContext(unixSocketDialer(path))
+}
+
+// Freeze makes the builder a read-only template that is safe to share across
+// packages and goroutines: every With* call on a frozen builder applies to a
+// fresh snapshot and returns it, leaving the template unchanged.
+func (cb *ClientBuilder) Freeze() *ClientBuilder {
+	cb.frozen = true
+	return cb
+}
+
+// Snapshot returns an independent, unfrozen deep copy of the builder.
+func (cb *ClientBuilder) Snapshot() *ClientBuilder {
+	snapshot := *cb
+	snapshot.frozen = false
+	snapshot.middlewares = append(make([]Middleware, 0, len(cb.middlewares)), cb.middlewares...)
+	snapshot.retryConfig = cb.retryConfig.clone()
+	snapshot.decode = cb.decode.clone()
+	snapshot.transportOptions = slices.Clone(cb.transportOptions)
+	snapshot.versioning.versions = slices.Clone(cb.versioning.versions)
+	snapshot.validators = slices.Clone(cb.validators)
+	snapshot.handlers = slices.Clone(cb.handlers)
+	snapshot.attemptHandlers = slices.Clone(cb.attemptHandlers)
+	snapshot.endpoints = slices.Clone(cb.endpoints)
+	snapshot.wrappers = slices.Clone(cb.wrappers)
+	snapshot.contentDecoders = maps.Clone(cb.contentDecoders)
+	snapshot.defaultHeader = cb.defaultHeader.Clone()
+	snapshot.allowedHosts = slices.Clone(cb.allowedHosts)
+	snapshot.blockedCIDRs = slices.Clone(cb.blockedCIDRs)
+	snapshot.pins = slices.Clone(cb.pins)
+	return &snapshot
+}
+
+// mutable returns the builder to apply a With* call to.
+func (cb *ClientBuilder) mutable() *ClientBuilder {
+	if cb.frozen {
+		return cb.Snapshot()
+	}
+	return cb
+}
+
+func (cb *ClientBuilder) buildHTTPClient(dns *dnsCache, stats *connStats) *http.Client {
+	options, wrappers := slices.Clone(cb.transportOptions), cb.wrappers
+	if len(cb.pins) > 0 {
+		options = append(options, pinTransportOption(slices.Clone(cb.pins)))
+	}
+	guard := cb.hostGuard()
+	if guard != nil {
+		// The default transport's dialer is replaced by one doing the check
+		options = append(options, guard.dialerOption(cb.roundTripper == nil && !cb.customDialer))
+	}
+	if cb.dualStack != nil && !cb.customDialer {
+		options = append(options, cb.dualStack.withDefaults().transportOption(dns))
+	} else if dns != nil {
+		options = append(options, dns.transportOption())
+	}
+	if guard != nil {
+		options = append(options, guard.transportOption())
+	}
+	if cb.connLimits.enabled() {
+		options = append(options, cb.connLimits.transportOption())
+		wrappers = append([]RoundTripperWrapper{cb.connLimits.wrapper()}, wrappers...)
+	}
+	if stats != nil {
+		options = append(options, stats.transportOption())
+		wrappers = append([]RoundTripperWrapper{stats.wrapper()}, wrappers...)
+	}
+	return buildHTTPClient(cb.roundTripper, options, wrappers)
+}
+
+// hostGuard returns the SSRF guard configured on the builder, or nil.
+func (cb *ClientBuilder) hostGuard() *hostGuard {
+	if len(cb.blockedCIDRs) == 0 {
+		return nil
+	}
+	return newHostGuard(cb.allowedHosts, cb.blockedCIDRs)
+}
+
+// Build returns a client that is independent of the builder: later changes
+// to the builder, or to the RetryConfig passed to it, don't affect the client.
+func (cb *ClientBuilder) Build() *HTTPClient {
+	var env *EnvConfig
+	if cb.envDefaults {
+		resolved := loadEnvConfig(os.LookupEnv)
+		env = &resolved
+		cb = cb.withEnvDefaults(resolved)
+	}
+	clock := orSystemClock(cb.clock)
+	transport := cb.shared
+	if transport == nil {
+		var dns *dnsCache
+		// A custom dialer, e.g. for a unix socket, may get names that are
+		// not in DNS, so it resolves them itself
+		if (cb.dnsTTL > 0 || cb.dnsResolver != nil) && !cb.customDialer {
+			dns = newDNSCache(cb.dnsTTL, cb.dnsResolver, clock)
+		}
+		var stats *connStats
+		if cb.connStats {
+			stats = newConnStats()
+		}
+		transport = &sharedTransport{
+			httpClient: cb.buildHTTPClient(dns, stats),
+			dns:        dns,
+			stats:      stats,
+			timeouts:   transportTimeouts(cb.roundTripper, cb.transportOptions, cb.connLimits),
+		}
+	}
+	c := &HTTPClient{
+		httpClient:       transport.httpClient,
+		dns:              transport.dns,
+		stats:            transport.stats,
+		clock:            clock,
+		fallback:         cb.fallback,
+		errorOnNon2xx:    cb.errorOnNon2xx,
+		strictURLs:       cb.strictURLs,
+		errorDecoder:     cb.errorDecoder,
+		bufferResponses:  cb.bufferResponses,
+		leaks:            cb.leaks,
+		hostGuard:        cb.hostGuard(),
+		timeout:          cb.timeout,
+		env:              env,
+		faults:           cb.faults,
+		timeouts:         transport.timeouts,
+		middlewares:      make([]Middleware, len(cb.middlewares)),
+		retryConfig:      cb.retryConfig.clone(),
+		onRetry:          cb.onRetry,
+		decode:           cb.decode.clone(),
+		signer:           cb.signer,
+		verifier:         cb.verifier,
+		requestID:        cb.requestID,
+		idempotency:      cb.idempotency,
+		traceContext:     cb.traceContext,
+		selfCheckPath:    cb.selfCheckPath,
+		maxResponseBytes: cb.maxResponseBytes,
+		versioning:       cb.versioning.clone(),
+		maxRequestBytes:  cb.maxRequestBytes,
+		maxHeaderCount:   cb.maxHeaderCount,
+		maxHeaderBytes:   cb.maxHeaderBytes,
+		validators:       append([]Validator(nil), cb.validators...),
+		handlers:         slices.Clone(cb.handlers),
+		attemptHandlers:  slices.Clone(cb.attemptHandlers),
+		audit:            cb.audit,
+		tokenSource:      cb.tokenSource,
+		apiKey:           cb.apiKey,
+		contentDecoders:  maps.Clone(cb.contentDecoders),
+		onCompression:    cb.onCompression,
+		bodyReadRetries:  cb.bodyReadRetries,
+		discovery:        cb.discovery,
+		prefetchBytes:    cb.prefetchBytes,
+	}
+	if c.onCompression != nil && c.contentDecoders["gzip"] == nil {
+		// Decode gzip here rather than in the transport to see wire bytes
+		if c.contentDecoders == nil {
+			c.contentDecoders = make(map[string]ContentDecoder)
+		}
+		c.contentDecoders["gzip"] = gzipDecoder
 	}
 	if cb.baseURL != "" {
 		c.baseURL = cb.baseURL
 	}
+	if len(cb.endpoints) > 0 {
+		c.baseURLs = newBaseURLPool(append([]string(nil), cb.endpoints...), cb.affinity)
+		c.failover = cb.failover
+		if c.baseURL == "" {
+			c.baseURL = cb.endpoints[0]
+		}
+	}
+	store := cb.stateStore
+	if store == nil {
+		memory := NewMemoryStateStore()
+		memory.clock = clock
+		store = memory
+	}
+	if cb.breakerThreshold > 0 {
+		c.breaker = newCircuitBreaker(cb.breakerThreshold, cb.breakerCooldown, store, clock)
+	}
+	if cb.rateLimit.rate > 0 {
+		c.rateLimiter = newRateLimiter(cb.rateLimit, store, clock)
+		if c.breaker != nil && cb.rateLimit.warmup > 0 {
+			c.breaker.onClose = c.rateLimiter.restartWarmup
+		}
+	}
+	if cb.maxConcurrent > 0 {
+		c.limiter = newConcurrencyLimiter(cb.maxConcurrent)
+	}
+	if cb.cacheTTL > 0 {
+		c.cache = &responseCache{
+			store:          newTTLCache(cb.cacheTTL, clock),
+			key:            cb.cacheKey,
+			readYourWrites: cb.readYourWrites,
+			related:        cb.cacheRelated,
+		}
+		if c.cache.key == nil {
+			c.cache.key = DefaultCacheKey
+		}
+	}
+	if cb.coalesce {
+		c.coalescer = newCoalescer()
+	}
+	if cb.staleRetry {
+		c.staleEndpoints = newStaleEndpoints()
+	}
+	if cb.adaptiveTimeout != nil {
+		c.adaptive = newAdaptiveTimeouts(*cb.adaptiveTimeout)
+	}
+	if cb.redirectTTL > 0 {
+		c.redirects = newRedirectCache(cb.redirectTTL, clock)
+	}
+	if cb.authenticator != nil {
+		c.authenticator = cb.authenticator
+		c.challenges = newChallengeCache()
+	}
+	if cb.preflightTTL > 0 {
+		c.preflight = newTTLCache(cb.preflightTTL, clock)
+	}
+	if cb.dualStack != nil && !cb.customDialer {
+		dualStack := cb.dualStack.withDefaults()
+		c.dualStack = &dualStack
+	}
+	c.pins = slices.Clone(cb.pins)
+	c.wsReadLimit = cb.wsReadLimit
+	if c.wsReadLimit <= 0 {
+		c.wsReadLimit = DefaultWebSocketReadLimit
+	}
+	if cb.offlineQueue != nil {
+		c.offline = newOfflineQueue(*cb.offlineQueue, clock)
+	}
+	switch {
+	case cb.sharedOutbox != nil:
+		c.outbox = cb.sharedOutbox
+	case cb.outbox != nil && cb.outbox.Store != nil:
+		c.outbox = &outbox{cfg: cb.outbox.withDefaults()}
+		c.startOutbox()
+	}
 	copy(c.middlewares, cb.middlewares)
+	c.defaults.store(cb.defaultHeader)
+	c.builder = cb.Snapshot()
+	c.builder.shared = transport
+	c.builder.sharedOutbox = c.outbox
 	return c
 }
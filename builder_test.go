@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -111,8 +112,8 @@ func TestClientBuilder_Build(t *testing.T) {
 		client2 := builder.Build()
 
 		// Both clients should have the same configuration
-		impl1 := client1.(*client)
-		impl2 := client2.(*client)
+		impl1 := client1
+		impl2 := client2
 
 		if impl1.baseURL != impl2.baseURL {
 			t.Error("Multiple Build() calls should produce clients with same configuration")
@@ -320,9 +321,10 @@ func TestClientBuilder_WithRetryConfig(t *testing.T) {
 		}
 
 		cli := builder.Build()
-		clientImpl := cli.(*client)
+		clientImpl := cli
 
-		if clientImpl.retryConfig != retryConfig {
+		if clientImpl.retryConfig.maxRetries != 5 ||
+			!slices.Equal(clientImpl.retryConfig.retryableStatusCodes, []int{500, 503}) {
 			t.Error("Build() did not pass retry config to client correctly")
 		}
 	})
@@ -335,7 +337,7 @@ func TestClientBuilder_WithRetryConfig(t *testing.T) {
 		}
 
 		cli := builder.Build()
-		clientImpl := cli.(*client)
+		clientImpl := cli
 
 		if clientImpl.retryConfig != nil {
 			t.Error("Build() should pass nil retry config to client")
@@ -367,3 +369,56 @@ func TestClientBuilder_WithRetryConfig(t *testing.T) {
 		}
 	})
 }
+
+func TestClientBuilder_CopyOnBuild(t *testing.T) {
+	t.Run("later builder changes don't affect built clients", func(t *testing.T) {
+		retryConfig := NewRetryConfigBuilder().WithRetryableStatusCodes([]int{503}).Build()
+		builder := NewClientBuilder().WithRetryConfig(retryConfig)
+		built := builder.Build()
+
+		retryConfig.retryableStatusCodes[0] = 500
+		builder.WithMiddleware(func(*http.Request) error { return nil })
+
+		if built.retryConfig.retryableStatusCodes[0] != 503 {
+			t.Error("Mutating the retry config leaked into a built client")
+		}
+		if len(built.middlewares) != 0 {
+			t.Error("Adding middleware leaked into a built client")
+		}
+	})
+
+	t.Run("frozen template is never mutated", func(t *testing.T) {
+		template := NewClientBuilder().
+			WithBaseURL("https://api.example.com").
+			WithHTTPVersion(HTTPVersion1).
+			Freeze()
+
+		derived := template.WithBaseURL("https://other.example.com").WithDialContext(nil)
+
+		if derived == template {
+			t.Fatal("Expected With* on a frozen builder to return a snapshot")
+		}
+		if template.baseURL != "https://api.example.com" || len(template.transportOptions) != 1 {
+			t.Errorf("Frozen template was mutated: %q, %d transport options",
+				template.baseURL, len(template.transportOptions))
+		}
+		if derived.baseURL != "https://other.example.com" || len(derived.transportOptions) != 2 {
+			t.Errorf("Snapshot missing changes: %q, %d transport options",
+				derived.baseURL, len(derived.transportOptions))
+		}
+		if derived.frozen {
+			t.Error("Snapshot of a frozen builder should be mutable")
+		}
+	})
+
+	t.Run("snapshot is independent", func(t *testing.T) {
+		original := NewClientBuilder().WithMiddleware(func(*http.Request) error { return nil })
+		snapshot := original.Snapshot()
+		snapshot.WithMiddleware(func(*http.Request) error { return nil })
+
+		if len(original.middlewares) != 1 || len(snapshot.middlewares) != 2 {
+			t.Errorf("Expected 1 and 2 middlewares, got %d and %d",
+				len(original.middlewares), len(snapshot.middlewares))
+		}
+	})
+}
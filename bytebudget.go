@@ -0,0 +1,113 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync/atomic"
+)
+
+// ErrByteBudgetExceeded is returned once a request or group has received more
+// response body bytes than the budget set with WithByteBudget.
+var ErrByteBudgetExceeded = errors.New("byte budget exceeded")
+
+// byteBudget counts the response body bytes received by a logical operation,
+// across retries, body read retries and, for groups, concurrent requests.
+type byteBudget struct {
+	limit int64
+	used  atomic.Int64
+	owner *Request
+}
+
+func (b *byteBudget) exceeded() error {
+	if used := b.used.Load(); used > b.limit {
+		return fmt.Errorf("%w: received %d of %d bytes", ErrByteBudgetExceeded, used, b.limit)
+	}
+	return nil
+}
+
+type byteBudgetsKey struct{}
+
+// withByteBudget returns a context whose requests also charge budget. Budgets
+// nest, so a request's own budget applies on top of its group's.
+func withByteBudget(ctx context.Context, budget *byteBudget) context.Context {
+	budgets := append(slices.Clone(byteBudgets(ctx)), budget)
+	return context.WithValue(ctx, byteBudgetsKey{}, budgets)
+}
+
+func byteBudgets(ctx context.Context) []*byteBudget {
+	budgets, _ := ctx.Value(byteBudgetsKey{}).([]*byteBudget)
+	return budgets
+}
+
+// withRequestBudget adds request's budget to ctx, unless ctx already carries
+// it because the request is being re-executed after a body read failure.
+func withRequestBudget(ctx context.Context, request *Request) context.Context {
+	if request.byteBudget <= 0 {
+		return ctx
+	}
+	for _, budget := range byteBudgets(ctx) {
+		if budget.owner == request {
+			return ctx
+		}
+	}
+	return withByteBudget(ctx, &byteBudget{limit: request.byteBudget, owner: request})
+}
+
+// checkByteBudgets fails an attempt up front when a budget it would charge is
+// already spent.
+func checkByteBudgets(ctx context.Context) error {
+	for _, budget := range byteBudgets(ctx) {
+		if err := budget.exceeded(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// budgetedBody charges the bytes read from the wire to budgets and fails
+// reads once any of them is exceeded.
+type budgetedBody struct {
+	io.ReadCloser
+	budgets []*byteBudget
+}
+
+func (b *budgetedBody) Read(p []byte) (int, error) {
+	if err := b.exceeded(); err != nil {
+		return 0, err
+	}
+	n, err := b.ReadCloser.Read(p)
+	for _, budget := range b.budgets {
+		budget.used.Add(int64(n))
+	}
+	if exceeded := b.exceeded(); exceeded != nil {
+		return n, exceeded
+	}
+	return n, err
+}
+
+func (b *budgetedBody) exceeded() error {
+	for _, budget := range b.budgets {
+		if err := budget.exceeded(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithByteBudget aborts the request once its response bodies, counted on the
+// wire and across all retries, exceed n bytes. Useful when fetching untrusted
+// URLs or on metered links.
+func (r *Request) WithByteBudget(n int64) *Request {
+	r.byteBudget = n
+	return r
+}
+
+// WithByteBudget aborts the group's requests once their response bodies
+// together exceed n bytes on the wire. Configure it before starting requests.
+func (g *Group) WithByteBudget(n int64) *Group {
+	g.ctx = withByteBudget(g.ctx, &byteBudget{limit: n})
+	return g
+}
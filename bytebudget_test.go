@@ -0,0 +1,92 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestByteBudget(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(strings.Repeat("x", 60)))
+	}))
+	defer server.Close()
+	client := NewClientBuilder().WithBaseURL(server.URL).Build()
+
+	t.Run("request within budget", func(t *testing.T) {
+		resp, err := client.Do(context.Background(), NewRequest(http.MethodGet, "/").WithByteBudget(60))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if body, err := resp.Bytes(); err != nil || len(body) != 60 {
+			t.Errorf("Expected the full body, got %d bytes, err %v", len(body), err)
+		}
+	})
+
+	t.Run("request over budget", func(t *testing.T) {
+		resp, err := client.Do(context.Background(), NewRequest(http.MethodGet, "/").WithByteBudget(10))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := resp.Bytes(); !errors.Is(err, ErrByteBudgetExceeded) {
+			t.Errorf("Expected ErrByteBudgetExceeded, got %v", err)
+		}
+	})
+
+	t.Run("group shares the budget", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		g := client.Group(context.Background()).WithLimit(1).WithByteBudget(100)
+		read := func(resp *Response) error {
+			_, err := io.ReadAll(resp.Body())
+			return err
+		}
+		for range 3 {
+			g.Go(NewRequest(http.MethodGet, "/"), read)
+		}
+		if err := g.Wait(); !errors.Is(err, ErrByteBudgetExceeded) {
+			t.Fatalf("Expected ErrByteBudgetExceeded, got %v", err)
+		}
+		if got := atomic.LoadInt32(&calls); got > 2 {
+			t.Errorf("Expected no request after the budget was spent, got %d calls", got)
+		}
+	})
+
+	t.Run("spent budget fails before sending", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		budget := &byteBudget{limit: 10}
+		budget.used.Store(11)
+		_, err := client.Get(withByteBudget(context.Background(), budget), "/")
+		if !errors.Is(err, ErrByteBudgetExceeded) {
+			t.Errorf("Expected ErrByteBudgetExceeded, got %v", err)
+		}
+		if got := atomic.LoadInt32(&calls); got != 0 {
+			t.Errorf("Expected no call, got %d", got)
+		}
+	})
+}
+
+func TestByteBudget_BodyReadRetries(t *testing.T) {
+	const body = `{"name":"reqwest"}`
+	server, calls := newTruncatingServer(t, 1, body)
+	defer server.Close()
+
+	// The truncated first body and the full second one add up past 20 bytes
+	client := NewClientBuilder().WithBodyReadRetries(2).Build()
+	resp, err := client.Do(context.Background(), NewRequest(http.MethodGet, server.URL).WithByteBudget(20))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := resp.Bytes(); !errors.Is(err, ErrByteBudgetExceeded) {
+		t.Errorf("Expected ErrByteBudgetExceeded, got %v", err)
+	}
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("Expected 2 calls, got %d", got)
+	}
+}
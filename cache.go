@@ -0,0 +1,209 @@
+package reqwest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheKeyFunc derives the response cache key of a GET request. The request
+// carries the absolute URL; ctx is the caller's context, e.g. to key
+// responses per tenant. Requests with the same key share a cached response.
+type CacheKeyFunc func(ctx context.Context, req *Request) string
+
+// DefaultCacheKey keys responses by method and URL.
+func DefaultCacheKey(_ context.Context, req *Request) string {
+	return req.method + " " + req.url
+}
+
+// VaryCacheKey keys responses by method and URL like DefaultCacheKey, plus
+// the values of headers (e.g. Accept-Language or a tenant header), ignoring
+// volatile query parameters such as cache busters or timestamps.
+func VaryCacheKey(headers []string, ignoreParams []string) CacheKeyFunc {
+	return func(_ context.Context, req *Request) string {
+		var key strings.Builder
+		key.WriteString(req.method + " " + withoutQueryParams(req.url, ignoreParams))
+		for _, name := range headers {
+			fmt.Fprintf(&key, "\n%s: %s", http.CanonicalHeaderKey(name), strings.Join(req.header.Values(name), ", "))
+		}
+		return key.String()
+	}
+}
+
+// withoutQueryParams removes params from rawURL and sorts the remaining
+// query so parameter order doesn't split the cache.
+func withoutQueryParams(rawURL string, params []string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.RawQuery == "" {
+		return rawURL
+	}
+	query := parsed.Query()
+	for _, param := range params {
+		query.Del(param)
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+// cachedResponse is a fully read response that can be replayed. resource
+// identifies the URL it was fetched from for invalidation.
+type cachedResponse struct {
+	resource   string
+	statusCode int
+	proto      string
+	header     http.Header
+	body       []byte
+	expiresAt  time.Time
+}
+
+func (e cachedResponse) response() *Response {
+	return &Response{
+		statusCode: e.statusCode,
+		proto:      e.proto,
+		header:     e.header.Clone(),
+		body:       io.NopCloser(bytes.NewReader(e.body)),
+		fromCache:  true,
+	}
+}
+
+// ttlCache holds cached responses for a fixed TTL. Expired entries are
+// dropped on access and swept at most once per TTL.
+type ttlCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]cachedResponse
+	lastSweep time.Time
+	clock     Clock
+}
+
+func newTTLCache(ttl time.Duration, clock Clock) *ttlCache {
+	clock = orSystemClock(clock)
+	return &ttlCache{
+		ttl:       ttl,
+		entries:   make(map[string]cachedResponse),
+		lastSweep: clock.Now(),
+		clock:     clock,
+	}
+}
+
+func (t *ttlCache) get(key string) (cachedResponse, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.entries[key]
+	if !ok {
+		return cachedResponse{}, false
+	}
+	if t.clock.Now().After(entry.expiresAt) {
+		delete(t.entries, key)
+		return cachedResponse{}, false
+	}
+	return entry, true
+}
+
+func (t *ttlCache) put(key string, entry cachedResponse) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.clock.Now()
+	if now.Sub(t.lastSweep) >= t.ttl {
+		for k, e := range t.entries {
+			if now.After(e.expiresAt) {
+				delete(t.entries, k)
+			}
+		}
+		t.lastSweep = now
+	}
+	entry.expiresAt = now.Add(t.ttl)
+	t.entries[key] = entry
+}
+
+// invalidate drops every entry whose resource is in resources.
+func (t *ttlCache) invalidate(resources ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, entry := range t.entries {
+		if slices.Contains(resources, entry.resource) {
+			delete(t.entries, key)
+		}
+	}
+}
+
+// responseCache serves repeated GET requests from memory. With readYourWrites
+// set, successful mutations invalidate the mutated resource and the URLs
+// returned by related.
+type responseCache struct {
+	store          *ttlCache
+	key            CacheKeyFunc
+	readYourWrites bool
+	related        func(*Request) []string
+}
+
+// resourceOf identifies a resource by scheme, host and path, so every cached
+// variant of it (query, headers) is invalidated together.
+func resourceOf(rawURL string) string {
+	return preflightKey(rawURL)
+}
+
+// cacheable reports whether a response may be stored: only 200 OK responses
+// without Cache-Control: no-store.
+func cacheable(resp *Response) bool {
+	if resp.statusCode != http.StatusOK {
+		return false
+	}
+	return !slices.ContainsFunc(strings.Split(resp.header.Get("Cache-Control"), ","), func(directive string) bool {
+		return strings.EqualFold(strings.TrimSpace(directive), "no-store")
+	})
+}
+
+// executeCached answers GET requests from the response cache, and stores
+// cacheable responses. Cache-Control: no-cache on the request skips the
+// lookup but still refreshes the entry.
+func (c *HTTPClient) executeCached(ctx context.Context, request *Request) (*Response, error) {
+	key := c.cache.key(ctx, request.withURL(c.buildURL(request.url)))
+	if !strings.Contains(strings.ToLower(request.header.Get("Cache-Control")), "no-cache") {
+		if entry, ok := c.cache.store.get(key); ok {
+			return entry.response(), nil
+		}
+	}
+
+	resp, err := c.executeUncached(ctx, request)
+	if err != nil || !cacheable(resp) {
+		return resp, err
+	}
+	body, err := resp.readBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+	resp.body = io.NopCloser(bytes.NewReader(body))
+	c.cache.store.put(key, cachedResponse{
+		resource:   resourceOf(c.buildURL(request.url)),
+		statusCode: resp.statusCode,
+		proto:      resp.proto,
+		header:     resp.header.Clone(),
+		body:       body,
+	})
+	return resp, nil
+}
+
+// executeMutation executes a non-GET request and, for read-your-writes,
+// invalidates what a successful mutation made stale.
+func (c *HTTPClient) executeMutation(ctx context.Context, request *Request) (*Response, error) {
+	resp, err := c.executeUncached(ctx, request)
+	if err != nil || resp.statusCode < http.StatusOK || resp.statusCode >= http.StatusMultipleChoices {
+		return resp, err
+	}
+	resources := []string{resourceOf(c.buildURL(request.url))}
+	if c.cache.related != nil {
+		for _, url := range c.cache.related(request) {
+			resources = append(resources, resourceOf(c.buildURL(url)))
+		}
+	}
+	c.cache.store.invalidate(resources...)
+	return resp, nil
+}
@@ -0,0 +1,255 @@
+package reqwest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTenantServer answers with the request's X-Tenant header and counts
+// the requests that reach it.
+func newTenantServer(handler http.HandlerFunc) (*httptest.Server, *int32) {
+	var hits int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if handler != nil {
+			handler(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("tenant=" + r.Header.Get("X-Tenant")))
+	})), &hits
+}
+
+func getBody(t *testing.T, cli Client, req *Request) (string, bool) {
+	t.Helper()
+	resp, err := cli.Do(context.TODO(), req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body().Close()
+	body, err := io.ReadAll(resp.Body())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return string(body), resp.FromCache()
+}
+
+func TestClientBuilder_WithResponseCache(t *testing.T) {
+	t.Run("repeated GETs are served from the cache", func(t *testing.T) {
+		server, hits := newTenantServer(nil)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithBaseURL(server.URL).WithResponseCache(time.Minute).Build()
+		if _, cached := getBody(t, cli, NewRequest(http.MethodGet, "/users")); cached {
+			t.Error("Expected the first response to come from the server")
+		}
+		body, cached := getBody(t, cli, NewRequest(http.MethodGet, "/users"))
+		if !cached || body != "tenant=" {
+			t.Errorf("Expected a cached replay, got %q (cached %v)", body, cached)
+		}
+		if got := atomic.LoadInt32(hits); got != 1 {
+			t.Errorf("Expected 1 request to reach the server, got %d", got)
+		}
+	})
+
+	t.Run("uncacheable responses and methods", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			method  string
+			handler http.HandlerFunc
+		}{
+			{"POST", http.MethodPost, nil},
+			{"non-200", http.MethodGet, func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusAccepted) }},
+			{"no-store", http.MethodGet, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "private, no-store")
+			}},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				server, hits := newTenantServer(tc.handler)
+				defer server.Close()
+
+				cli := NewClientBuilder().WithResponseCache(time.Minute).Build()
+				for i := 0; i < 2; i++ {
+					getBody(t, cli, NewRequest(tc.method, server.URL))
+				}
+				if got := atomic.LoadInt32(hits); got != 2 {
+					t.Errorf("Expected every request to reach the server, got %d", got)
+				}
+			})
+		}
+	})
+
+	t.Run("no-cache refreshes the entry", func(t *testing.T) {
+		server, hits := newTenantServer(nil)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithResponseCache(time.Minute).Build()
+		getBody(t, cli, NewRequest(http.MethodGet, server.URL))
+		if _, cached := getBody(t, cli, NewRequest(http.MethodGet, server.URL).WithHeader("Cache-Control", "no-cache")); cached {
+			t.Error("Expected no-cache to bypass the cache")
+		}
+		getBody(t, cli, NewRequest(http.MethodGet, server.URL))
+		if got := atomic.LoadInt32(hits); got != 2 {
+			t.Errorf("Expected 2 requests to reach the server, got %d", got)
+		}
+	})
+}
+
+func TestClientBuilder_WithCacheKey(t *testing.T) {
+	t.Run("per-tenant responses with volatile params ignored", func(t *testing.T) {
+		server, hits := newTenantServer(nil)
+		defer server.Close()
+
+		cli := NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithResponseCache(time.Minute).
+			WithCacheKey(VaryCacheKey([]string{"X-Tenant"}, []string{"_ts"})).
+			Build()
+		tenant := func(name, url string) *Request {
+			return NewRequest(http.MethodGet, url).WithHeader("X-Tenant", name)
+		}
+
+		if body, _ := getBody(t, cli, tenant("acme", "/report?_ts=1")); body != "tenant=acme" {
+			t.Fatalf("Unexpected body %q", body)
+		}
+		if body, _ := getBody(t, cli, tenant("globex", "/report?_ts=2")); body != "tenant=globex" {
+			t.Errorf("Expected globex not to see acme's response, got %q", body)
+		}
+		if body, cached := getBody(t, cli, tenant("acme", "/report?_ts=3")); !cached || body != "tenant=acme" {
+			t.Errorf("Expected acme's cached response despite a new _ts, got %q (cached %v)", body, cached)
+		}
+		if got := atomic.LoadInt32(hits); got != 2 {
+			t.Errorf("Expected 2 requests to reach the server, got %d", got)
+		}
+	})
+
+	t.Run("context-derived keys", func(t *testing.T) {
+		type tenantKey struct{}
+		server, hits := newTenantServer(nil)
+		defer server.Close()
+
+		cli := NewClientBuilder().
+			WithResponseCache(time.Minute).
+			WithCacheKey(func(ctx context.Context, req *Request) string {
+				tenant, _ := ctx.Value(tenantKey{}).(string)
+				return tenant + " " + DefaultCacheKey(ctx, req)
+			}).
+			Build()
+		for _, tenant := range []string{"acme", "globex", "acme"} {
+			ctx := context.WithValue(context.TODO(), tenantKey{}, tenant)
+			resp, err := cli.Get(ctx, server.URL)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body().Close()
+		}
+		if got := atomic.LoadInt32(hits); got != 2 {
+			t.Errorf("Expected one request per tenant, got %d", got)
+		}
+	})
+}
+
+func TestVaryCacheKey(t *testing.T) {
+	key := VaryCacheKey([]string{"accept-language"}, []string{"nonce"})
+	a := NewRequest(http.MethodGet, "https://api.example.com/items?b=2&a=1&nonce=x").WithHeader("Accept-Language", "de")
+	b := NewRequest(http.MethodGet, "https://api.example.com/items?a=1&b=2").WithHeader("Accept-Language", "de")
+	c := NewRequest(http.MethodGet, "https://api.example.com/items?a=1&b=2").WithHeader("Accept-Language", "fr")
+
+	if key(context.TODO(), a) != key(context.TODO(), b) {
+		t.Errorf("Expected equal keys, got %q and %q", key(context.TODO(), a), key(context.TODO(), b))
+	}
+	if key(context.TODO(), b) == key(context.TODO(), c) {
+		t.Errorf("Expected languages to get separate keys, got %q", key(context.TODO(), b))
+	}
+}
+
+func TestClientBuilder_WithReadYourWrites(t *testing.T) {
+	newStore := func() (*httptest.Server, *int32) {
+		var version int32
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				if r.URL.Query().Get("fail") != "" {
+					w.WriteHeader(http.StatusConflict)
+					return
+				}
+				atomic.AddInt32(&version, 1)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			_, _ = fmt.Fprintf(w, "%s v%d", r.URL.Path, atomic.LoadInt32(&version))
+		})), &version
+	}
+	get := func(t *testing.T, cli Client, url string) string {
+		t.Helper()
+		body, _ := getBody(t, cli, NewRequest(http.MethodGet, url))
+		return body
+	}
+
+	t.Run("mutation invalidates every variant of the resource", func(t *testing.T) {
+		server, _ := newStore()
+		defer server.Close()
+
+		cli := NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithResponseCache(time.Minute).
+			WithReadYourWrites(nil).
+			Build()
+		get(t, cli, "/users/1")
+		get(t, cli, "/users/1?fields=name")
+		get(t, cli, "/users/2")
+
+		resp, err := cli.Do(context.TODO(), NewRequest(http.MethodPut, "/users/1"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		if got := get(t, cli, "/users/1"); got != "/users/1 v1" {
+			t.Errorf("Expected the write to be visible, got %q", got)
+		}
+		if got := get(t, cli, "/users/1?fields=name"); got != "/users/1 v1" {
+			t.Errorf("Expected query variants to be invalidated, got %q", got)
+		}
+		if got := get(t, cli, "/users/2"); got != "/users/2 v0" {
+			t.Errorf("Expected unrelated resources to stay cached, got %q", got)
+		}
+	})
+
+	t.Run("related resources and failed mutations", func(t *testing.T) {
+		server, _ := newStore()
+		defer server.Close()
+
+		cli := NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithResponseCache(time.Minute).
+			WithReadYourWrites(func(req *Request) []string {
+				return []string{"/users"}
+			}).
+			Build()
+		get(t, cli, "/users")
+
+		resp, err := cli.Post(context.TODO(), "/users/3?fail=1", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+		if got := get(t, cli, "/users"); got != "/users v0" {
+			t.Errorf("Expected a failed mutation to keep the cache, got %q", got)
+		}
+
+		resp, err = cli.Post(context.TODO(), "/users/3", nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+		if got := get(t, cli, "/users"); got != "/users v1" {
+			t.Errorf("Expected the related collection to be invalidated, got %q", got)
+		}
+	})
+}
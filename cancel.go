@@ -0,0 +1,103 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// ErrForceCancelled is returned by requests and body reads interrupted by
+// CancelAll, wrapping the underlying error.
+var ErrForceCancelled = errors.New("request cancelled by client")
+
+// Canceller interrupts everything a client is doing, e.g. during shutdown or
+// after credentials were revoked.
+type Canceller interface {
+	CancelAll(reason string)
+}
+
+type cancelEntry struct {
+	cancel func(error)
+}
+
+// cancelSet tracks what CancelAll interrupts. Its zero value is empty.
+type cancelSet struct {
+	mu      sync.Mutex
+	entries map[*cancelEntry]struct{}
+}
+
+// register adds cancel to the set until the returned release is called.
+func (s *cancelSet) register(cancel func(error)) (release func()) {
+	entry := &cancelEntry{cancel: cancel}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = make(map[*cancelEntry]struct{})
+	}
+	s.entries[entry] = struct{}{}
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.entries, entry)
+	}
+}
+
+// track derives a context that CancelAll cancels. release must be called
+// once the call, including reading its body, is done.
+func (s *cancelSet) track(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	unregister := s.register(cancel)
+	return ctx, func() {
+		unregister()
+		cancel(context.Canceled)
+	}
+}
+
+func (s *cancelSet) cancelAll(cause error) {
+	s.mu.Lock()
+	entries := s.entries
+	s.entries = nil
+	s.mu.Unlock()
+	for entry := range entries {
+		entry.cancel(cause)
+	}
+}
+
+// forceCancelled adds the CancelAll reason to err if ctx was cancelled by it.
+func forceCancelled(ctx context.Context, err error) error {
+	cause := context.Cause(ctx)
+	if err == nil || !errors.Is(cause, ErrForceCancelled) || errors.Is(err, ErrForceCancelled) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", cause, err)
+}
+
+// CancelAll cancels every in-flight request, including backoff and queue
+// waits and unread response bodies, and closes open streaming connections.
+// Requests started afterwards run normally.
+func (c *HTTPClient) CancelAll(reason string) {
+	c.cancels.cancelAll(fmt.Errorf("%w: %s", ErrForceCancelled, reason))
+}
+
+// trackedBody keeps a tracked call alive while its body is read and reports
+// reads interrupted by CancelAll as such.
+type trackedBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	release func()
+}
+
+func (b *trackedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		err = forceCancelled(b.ctx, err)
+	}
+	return n, err
+}
+
+func (b *trackedBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,149 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// cancelSoon calls CancelAll once waiting has had time to start.
+func cancelSoon(cli *HTTPClient, reason string) {
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cli.CancelAll(reason)
+	}()
+}
+
+func expectForceCancelled(t *testing.T, err error, start time.Time) {
+	t.Helper()
+	if !errors.Is(err, ErrForceCancelled) {
+		t.Fatalf("Expected ErrForceCancelled, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "shutdown") {
+		t.Errorf("Expected the reason in the error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected prompt cancellation, took %v", elapsed)
+	}
+}
+
+func TestClient_CancelAll(t *testing.T) {
+	t.Run("backoff wait", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().
+			WithRetryConfig(NewRetryConfigBuilder().
+				WithMaxRetries(3).
+				WithBackoffStrategy(NewFixedBackoffBuilder().WithDelay(time.Minute).Build()).
+				Build()).
+			Build()
+
+		start := time.Now()
+		cancelSoon(cli, "shutdown")
+		_, err := cli.Get(context.TODO(), server.URL)
+		expectForceCancelled(t, err, start)
+	})
+
+	t.Run("queue wait and in-flight attempt", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().WithMaxConcurrentRequests(1).Build()
+
+		start := time.Now()
+		errs := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				_, err := cli.Get(context.TODO(), server.URL)
+				errs <- err
+			}()
+		}
+		cancelSoon(cli, "shutdown")
+		for i := 0; i < 2; i++ {
+			expectForceCancelled(t, <-errs, start)
+		}
+	})
+
+	t.Run("response body read", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("partial"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().Build()
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer resp.Body().Close()
+
+		start := time.Now()
+		cancelSoon(cli, "shutdown")
+		_, err = io.ReadAll(resp.Body())
+		expectForceCancelled(t, err, start)
+	})
+
+	t.Run("websocket read", func(t *testing.T) {
+		server := newEchoWebSocketServer(t, nil)
+		defer server.Close()
+
+		cli := NewClientBuilder().Build()
+		conn, err := cli.Dial(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer conn.Close()
+
+		done := make(chan error)
+		go func() {
+			_, _, err := conn.ReadMessage()
+			done <- err
+		}()
+		cli.CancelAll("shutdown")
+		select {
+		case err := <-done:
+			if err == nil {
+				t.Error("Expected the read to fail")
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected CancelAll to unblock the read")
+		}
+	})
+
+	t.Run("later requests are unaffected", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().Build()
+		cli.CancelAll("credentials revoked")
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+		if atomic.LoadInt32(&calls) != 1 {
+			t.Error("Expected the request to reach the server")
+		}
+	})
+}
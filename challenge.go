@@ -0,0 +1,277 @@
+package reqwest
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Challenge is an authentication challenge from a WWW-Authenticate header,
+// e.g. Digest with its realm and nonce, or Bearer with the RFC 9728
+// resource_metadata URL of an OAuth-protected resource. Parameter names are
+// lower case.
+type Challenge struct {
+	Scheme string
+	Params map[string]string
+}
+
+// Authenticator answers authentication challenges. The client remembers the
+// challenges each host answered 401 with and calls Authenticate before every
+// further request to it, so requests are authorized up front instead of
+// after another 401.
+type Authenticator interface {
+	// Authenticate returns the Authorization header for req, or "" if none of
+	// challenges is one it can answer.
+	Authenticate(req *http.Request, challenges []Challenge) (string, error)
+}
+
+// challengeCache holds the latest challenges per host.
+type challengeCache struct {
+	mu     sync.Mutex
+	byHost map[string][]Challenge
+}
+
+func newChallengeCache() *challengeCache {
+	return &challengeCache{byHost: make(map[string][]Challenge)}
+}
+
+func (c *challengeCache) get(host string) []Challenge {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.byHost[host]
+}
+
+func (c *challengeCache) put(host string, challenges []Challenge) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.byHost[host] = challenges
+}
+
+// executeOnceShortcut runs one attempt, skipping round trips the client
+// learned to avoid: it goes straight to the target of a cached permanent
+// redirect, and answers an authentication challenge with a single repeat of
+// the request, after which later requests are pre-authenticated.
+func (c *HTTPClient) executeOnceShortcut(ctx context.Context, url, method string, body []byte, header http.Header) (*Response, error) {
+	target := url
+	if c.redirects != nil {
+		target = c.redirects.resolve(method, url)
+	}
+	resp, err := c.executeOnce(ctx, target, method, body, header)
+	if c.redirects != nil {
+		if err != nil && target != url {
+			c.redirects.forget(url)
+		} else if err == nil {
+			c.redirects.observe(resp)
+		}
+	}
+	if err != nil || c.challenges == nil || resp.statusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenges := parseChallenges(resp.header)
+	if len(challenges) == 0 || header.Get("Authorization") != "" {
+		return resp, nil
+	}
+	c.challenges.put(hostOf(target), challenges)
+	resp.body.Close()
+	return c.executeOnce(ctx, target, method, body, header)
+}
+
+// authenticate authorizes req for the challenges last seen from its host.
+// An Authorization header set by the caller, middleware or a TokenSource is
+// kept.
+func (c *HTTPClient) authenticate(req *http.Request) error {
+	if req.Header.Get("Authorization") != "" {
+		return nil
+	}
+	challenges := c.challenges.get(req.URL.Host)
+	if len(challenges) == 0 {
+		return nil
+	}
+	authorization, err := c.authenticator.Authenticate(req, challenges)
+	if err != nil {
+		return fmt.Errorf("authenticator error: %v", err)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return nil
+}
+
+// parseChallenges parses the WWW-Authenticate headers of a response (RFC
+// 9110, section 11.6.1). Challenges carrying a token68 instead of parameters
+// are returned without parameters.
+func parseChallenges(header http.Header) []Challenge {
+	var challenges []Challenge
+	for _, value := range header.Values("WWW-Authenticate") {
+		p := &challengeParser{s: value}
+		for {
+			p.skip(", \t")
+			scheme := p.token()
+			if scheme == "" {
+				break
+			}
+			challenge := Challenge{Scheme: scheme, Params: make(map[string]string)}
+			for p.param(challenge.Params) {
+			}
+			challenges = append(challenges, challenge)
+		}
+	}
+	return challenges
+}
+
+type challengeParser struct {
+	s   string
+	pos int
+}
+
+func (p *challengeParser) skip(chars string) {
+	for p.pos < len(p.s) && strings.IndexByte(chars, p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *challengeParser) token() string {
+	start := p.pos
+	for p.pos < len(p.s) && !strings.ContainsRune(" \t,=\"", rune(p.s[p.pos])) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// param reads one auth-param, or a token68, of the current challenge into
+// params. It reports false, leaving the input where the next challenge
+// starts, once the challenge has no more parameters.
+func (p *challengeParser) param(params map[string]string) bool {
+	start := p.pos
+	p.skip(", \t")
+	name := p.token()
+	p.skip(" \t")
+	if name == "" || p.pos >= len(p.s) || p.s[p.pos] != '=' {
+		// A bare token is the scheme of the next challenge
+		p.pos = start
+		return false
+	}
+	p.pos++
+	if rest := p.s[p.pos:]; len(params) == 0 && (rest == "" || rest[0] == '=' || rest[0] == ',') {
+		// A token68 like "Negotiate YII=" ends in padding
+		p.skip("=")
+		return false
+	}
+	p.skip(" \t")
+	params[strings.ToLower(name)] = p.value()
+	return true
+}
+
+func (p *challengeParser) value() string {
+	if p.pos >= len(p.s) || p.s[p.pos] != '"' {
+		return p.token()
+	}
+	var value strings.Builder
+	for p.pos++; p.pos < len(p.s); p.pos++ {
+		switch c := p.s[p.pos]; c {
+		case '"':
+			p.pos++
+			return value.String()
+		case '\\':
+			if p.pos+1 < len(p.s) {
+				p.pos++
+				value.WriteByte(p.s[p.pos])
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return value.String()
+}
+
+// DigestAuth answers Digest challenges (RFC 7616) with the MD5 or SHA-256
+// algorithm and "auth" quality of protection.
+type DigestAuth struct {
+	username string
+	password string
+	mu       sync.Mutex
+	counts   map[string]int
+}
+
+// NewDigestAuth returns an Authenticator for HTTP Digest authentication.
+func NewDigestAuth(username, password string) *DigestAuth {
+	return &DigestAuth{username: username, password: password, counts: make(map[string]int)}
+}
+
+func (d *DigestAuth) Authenticate(req *http.Request, challenges []Challenge) (string, error) {
+	for _, challenge := range challenges {
+		if !strings.EqualFold(challenge.Scheme, "Digest") {
+			continue
+		}
+		newHash := digestHash(challenge.Params["algorithm"])
+		if newHash == nil {
+			continue
+		}
+		return d.authorization(req, challenge.Params, newHash), nil
+	}
+	return "", nil
+}
+
+func (d *DigestAuth) authorization(req *http.Request, params map[string]string, newHash func() hash.Hash) string {
+	h := func(s string) string {
+		sum := newHash()
+		sum.Write([]byte(s))
+		return hex.EncodeToString(sum.Sum(nil))
+	}
+	nonce := params["nonce"]
+	uri := req.URL.RequestURI()
+	ha1 := h(d.username + ":" + params["realm"] + ":" + d.password)
+	ha2 := h(req.Method + ":" + uri)
+
+	fields := []string{
+		fmt.Sprintf("username=%q", d.username),
+		fmt.Sprintf("realm=%q", params["realm"]),
+		fmt.Sprintf("nonce=%q", nonce),
+		fmt.Sprintf("uri=%q", uri),
+	}
+	if algorithm := params["algorithm"]; algorithm != "" {
+		fields = append(fields, "algorithm="+algorithm)
+	}
+	if slices.Contains(strings.Split(strings.ReplaceAll(params["qop"], " ", ""), ","), "auth") {
+		nc := fmt.Sprintf("%08x", d.nextCount(nonce))
+		cnonce := randomHex(16)
+		response := h(ha1 + ":" + nonce + ":" + nc + ":" + cnonce + ":auth:" + ha2)
+		fields = append(fields, "qop=auth", "nc="+nc, fmt.Sprintf("cnonce=%q", cnonce), fmt.Sprintf("response=%q", response))
+	} else {
+		fields = append(fields, fmt.Sprintf("response=%q", h(ha1+":"+nonce+":"+ha2)))
+	}
+	if opaque, ok := params["opaque"]; ok {
+		fields = append(fields, fmt.Sprintf("opaque=%q", opaque))
+	}
+	return "Digest " + strings.Join(fields, ", ")
+}
+
+// nextCount returns how many requests, including this one, were
+// authenticated with nonce. Servers reject a repeated count as a replay.
+func (d *DigestAuth) nextCount(nonce string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.counts[nonce]; !ok && len(d.counts) >= 64 {
+		// Nonces are replaced over time; the old counts are of no further use
+		clear(d.counts)
+	}
+	d.counts[nonce]++
+	return d.counts[nonce]
+}
+
+func digestHash(algorithm string) func() hash.Hash {
+	switch strings.ToUpper(algorithm) {
+	case "", "MD5":
+		return md5.New
+	case "SHA-256":
+		return sha256.New
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package reqwest
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestParseChallenges(t *testing.T) {
+	testCases := []struct {
+		name   string
+		values []string
+		want   []Challenge
+	}{
+		{
+			name:   "digest",
+			values: []string{`Digest realm="api@example.com", qop="auth, auth-int", nonce="7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v", opaque="FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS"`},
+			want: []Challenge{{Scheme: "Digest", Params: map[string]string{
+				"realm":  "api@example.com",
+				"qop":    "auth, auth-int",
+				"nonce":  "7ypf/xlj9XXwfDPEoM4URrv/xwf94BcCAzFZH4GiTo0v",
+				"opaque": "FQhe/qaU925kfnzjCev0ciny7QMkPqMAFRtzCUYo5tdS",
+			}}},
+		},
+		{
+			name:   "several challenges in one header",
+			values: []string{`Bearer resource_metadata="https://api.example.com/.well-known/oauth-protected-resource", Basic realm="legacy"`},
+			want: []Challenge{
+				{Scheme: "Bearer", Params: map[string]string{"resource_metadata": "https://api.example.com/.well-known/oauth-protected-resource"}},
+				{Scheme: "Basic", Params: map[string]string{"realm": "legacy"}},
+			},
+		},
+		{
+			name:   "token68 and escapes",
+			values: []string{`Negotiate YII=`, `Basic Realm="say \"hi\""`},
+			want: []Challenge{
+				{Scheme: "Negotiate", Params: map[string]string{}},
+				{Scheme: "Basic", Params: map[string]string{"realm": `say "hi"`}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{"Www-Authenticate": tc.values}
+			if got := parseChallenges(header); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseChallenges() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+// digestServer requires Digest authentication without qop, rotating its
+// nonce whenever rotate is called.
+type digestServer struct {
+	mu         sync.Mutex
+	nonce      string
+	challenges int
+}
+
+func (s *digestServer) rotate(nonce string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nonce = nonce
+}
+
+func (s *digestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h := func(v string) string {
+		sum := md5.Sum([]byte(v))
+		return hex.EncodeToString(sum[:])
+	}
+	response := h(h("alice:test:secret") + ":" + s.nonce + ":" + h(r.Method+":"+r.URL.RequestURI()))
+	params := parseChallenges(http.Header{"Www-Authenticate": {r.Header.Get("Authorization")}})
+	if len(params) == 1 && params[0].Params["response"] == response && params[0].Params["opaque"] == "o" {
+		w.Write([]byte("ok"))
+		return
+	}
+	s.challenges++
+	w.Header().Set("WWW-Authenticate", `Digest realm="test", nonce="`+s.nonce+`", opaque="o"`)
+	w.WriteHeader(http.StatusUnauthorized)
+}
+
+func TestWithAuthenticator(t *testing.T) {
+	server := &digestServer{nonce: "n1"}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	cli := NewClientBuilder().WithAuthenticator(NewDigestAuth("alice", "secret")).Build()
+	get := func() {
+		t.Helper()
+		resp, err := cli.Get(context.Background(), ts.URL+"/items?page=2")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if resp.StatusCode() != http.StatusOK {
+			t.Fatalf("StatusCode() = %d, want 200", resp.StatusCode())
+		}
+	}
+
+	get()
+	get()
+	if server.challenges != 1 {
+		t.Errorf("challenges = %d, want 1 before the second request is pre-authenticated", server.challenges)
+	}
+
+	// A new nonce costs one more challenge
+	server.rotate("n2")
+	get()
+	get()
+	if server.challenges != 2 {
+		t.Errorf("challenges = %d after nonce rotation, want 2", server.challenges)
+	}
+}
+
+func TestDigestAuth_QOP(t *testing.T) {
+	auth := NewDigestAuth("Mufasa", "Circle of Life")
+	req := httptest.NewRequest(http.MethodGet, "http://www.example.com/dir/index.html", nil)
+	challenges := []Challenge{{Scheme: "Digest", Params: map[string]string{
+		"realm": "testrealm@host.com", "qop": "auth,auth-int", "nonce": "dcd98b7102dd2f0e8b11d0f600bfb0c093", "algorithm": "SHA-256",
+	}}}
+
+	first, err := auth.Authenticate(req, challenges)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	second, _ := auth.Authenticate(req, challenges)
+	got := parseChallenges(http.Header{"Www-Authenticate": {first, second}})
+	if len(got) != 2 {
+		t.Fatalf("Authorization headers = %q, %q", first, second)
+	}
+	if got[0].Params["qop"] != "auth" || got[0].Params["algorithm"] != "SHA-256" {
+		t.Errorf("Authorization = %q, want qop=auth and algorithm=SHA-256", first)
+	}
+	if got[0].Params["nc"] != "00000001" || got[1].Params["nc"] != "00000002" {
+		t.Errorf("nonce counts = %s, %s, want 00000001, 00000002", got[0].Params["nc"], got[1].Params["nc"])
+	}
+	if len(got[0].Params["response"]) != 64 {
+		t.Errorf("response = %q, want a SHA-256 digest", got[0].Params["response"])
+	}
+}
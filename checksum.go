@@ -0,0 +1,189 @@
+package reqwest
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned when reading a response body to the end
+// yields a different hash than the expected one.
+var ErrChecksumMismatch = errors.New("response body checksum mismatch")
+
+// ChecksumAlgorithm names a hash for Request.WithChecksum.
+type ChecksumAlgorithm string
+
+const (
+	ChecksumMD5    ChecksumAlgorithm = "md5"
+	ChecksumSHA1   ChecksumAlgorithm = "sha1"
+	ChecksumSHA256 ChecksumAlgorithm = "sha256"
+	ChecksumSHA512 ChecksumAlgorithm = "sha512"
+	ChecksumCRC32  ChecksumAlgorithm = "crc32"
+	ChecksumCRC32C ChecksumAlgorithm = "crc32c"
+)
+
+func (a ChecksumAlgorithm) newHash() (hash.Hash, bool) {
+	switch a {
+	case ChecksumMD5:
+		return md5.New(), true
+	case ChecksumSHA1:
+		return sha1.New(), true
+	case ChecksumSHA256:
+		return sha256.New(), true
+	case ChecksumSHA512:
+		return sha512.New(), true
+	case ChecksumCRC32:
+		return crc32.NewIEEE(), true
+	case ChecksumCRC32C:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli)), true
+	}
+	return nil, false
+}
+
+// checksumHeaders are the response headers WithServerChecksum reads, the
+// strongest first.
+var checksumHeaders = []struct {
+	name string
+	algo ChecksumAlgorithm
+}{
+	{"X-Amz-Checksum-Sha256", ChecksumSHA256},
+	{"X-Amz-Checksum-Sha1", ChecksumSHA1},
+	{"X-Amz-Checksum-Crc32c", ChecksumCRC32C},
+	{"X-Amz-Checksum-Crc32", ChecksumCRC32},
+	{"Content-MD5", ChecksumMD5},
+}
+
+// checksum is the body hash a request expects. fromHeader takes it from the
+// response instead.
+type checksum struct {
+	algo       ChecksumAlgorithm
+	expected   string
+	fromHeader bool
+}
+
+// WithChecksum verifies that the response body hashes to expected with algo,
+// e.g. for artifact and package downloads. expected is hex or base64, as
+// published next to most artifacts. Reading the body to the end then fails
+// with ErrChecksumMismatch if it differs. Only successful, complete
+// responses are checked: not 206 Partial Content or HEAD ones.
+func (r *Request) WithChecksum(algo ChecksumAlgorithm, expected string) *Request {
+	r.checksum = &checksum{algo: algo, expected: expected}
+	return r
+}
+
+// WithServerChecksum verifies the response body against the checksum the
+// server sent along, the first of the x-amz-checksum-sha256, -sha1, -crc32c
+// and -crc32 and Content-MD5 headers, like WithChecksum. Responses without
+// one, or whose Content-Encoding was decoded, are not checked.
+func (r *Request) WithServerChecksum() *Request {
+	r.checksum = &checksum{fromHeader: true}
+	return r
+}
+
+// RequestChecksum verifies the response body, like Request.WithChecksum.
+func RequestChecksum(algo ChecksumAlgorithm, expected string) RequestOption {
+	return func(r *Request) { r.WithChecksum(algo, expected) }
+}
+
+// RequestServerChecksum verifies the response body, like
+// Request.WithServerChecksum.
+func RequestServerChecksum() RequestOption {
+	return func(r *Request) { r.WithServerChecksum() }
+}
+
+// check rejects an unknown algorithm or malformed expected value before the
+// request is sent.
+func (c *checksum) check() error {
+	if c == nil || c.fromHeader {
+		return nil
+	}
+	if _, ok := c.algo.newHash(); !ok {
+		return fmt.Errorf("failed to verify checksum: unsupported algorithm %q", c.algo)
+	}
+	if _, err := c.sum(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// sum decodes the expected hash, as hex if it has the hash's length in hex
+// and as base64 otherwise.
+func (c *checksum) sum() ([]byte, error) {
+	h, _ := c.algo.newHash()
+	if len(c.expected) == 2*h.Size() {
+		if sum, err := hex.DecodeString(c.expected); err == nil {
+			return sum, nil
+		}
+	}
+	sum, err := base64.StdEncoding.DecodeString(c.expected)
+	if err != nil || len(sum) != h.Size() {
+		return nil, fmt.Errorf("failed to verify checksum: %q is not a hex or base64 %s hash", c.expected, c.algo)
+	}
+	return sum, nil
+}
+
+// wrap makes resp's body verify the checksum as it is read, if it applies
+// to resp.
+func (c *checksum) wrap(resp *Response) {
+	if c == nil || !resp.IsSuccess() || resp.statusCode == http.StatusPartialContent ||
+		resp.request != nil && resp.request.Method == http.MethodHead {
+		return
+	}
+	expected := *c
+	if c.fromHeader {
+		if resp.decoded {
+			return
+		}
+		found := false
+		for _, header := range checksumHeaders {
+			// Composite checksums of multipart uploads ("...-3") don't cover
+			// the body as a whole
+			if value := resp.header.Get(header.name); value != "" && !strings.Contains(value, "-") {
+				expected, found = checksum{algo: header.algo, expected: value}, true
+				break
+			}
+		}
+		if !found {
+			return
+		}
+	}
+	sum, err := expected.sum()
+	h, _ := expected.algo.newHash()
+	resp.body = &checksumBody{ReadCloser: resp.body, hash: h, algo: expected.algo, expected: sum, err: err}
+}
+
+// checksumBody hashes the body as it is read and, at the end, fails the last
+// read if the hash is not the expected one.
+type checksumBody struct {
+	io.ReadCloser
+	hash     hash.Hash
+	algo     ChecksumAlgorithm
+	expected []byte
+	err      error // a malformed expected value from the server
+}
+
+func (b *checksumBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.hash.Write(p[:n])
+	if err != io.EOF {
+		return n, err
+	}
+	if b.err != nil {
+		return n, b.err
+	}
+	if got := b.hash.Sum(nil); string(got) != string(b.expected) {
+		return n, fmt.Errorf("%w: %s is %s, expected %s", ErrChecksumMismatch, b.algo,
+			hex.EncodeToString(got), hex.EncodeToString(b.expected))
+	}
+	return n, err
+}
@@ -0,0 +1,118 @@
+package reqwest
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequest_WithChecksum(t *testing.T) {
+	body := "artifact contents"
+	sum := sha256.Sum256([]byte(body))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		io.WriteString(w, body)
+	}))
+	defer server.Close()
+	cli := NewClientBuilder().WithBaseURL(server.URL).Build()
+
+	for name, expected := range map[string]string{
+		"hex":    hex.EncodeToString(sum[:]),
+		"base64": base64.StdEncoding.EncodeToString(sum[:]),
+	} {
+		t.Run("matching "+name, func(t *testing.T) {
+			resp, err := cli.Get(context.TODO(), "/", RequestChecksum(ChecksumSHA256, expected))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			data, err := io.ReadAll(resp.Body())
+			if err != nil || string(data) != body {
+				t.Errorf("Expected %q, got %q and %v", body, data, err)
+			}
+		})
+	}
+
+	t.Run("mismatch", func(t *testing.T) {
+		other := sha256.Sum256([]byte("something else"))
+		resp, err := cli.Get(context.TODO(), "/", RequestChecksum(ChecksumSHA256, hex.EncodeToString(other[:])))
+		if err != nil {
+			t.Fatalf("Expected no error before reading, got %v", err)
+		}
+		if _, err := resp.Bytes(); !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("Expected ErrChecksumMismatch, got %v", err)
+		}
+	})
+
+	t.Run("error responses are not checked", func(t *testing.T) {
+		resp, err := cli.Get(context.TODO(), "/missing", RequestChecksum(ChecksumSHA256, strings.Repeat("0", 64)))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := resp.Bytes(); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("invalid checksum is rejected before sending", func(t *testing.T) {
+		for _, opt := range []RequestOption{
+			RequestChecksum("sha3", strings.Repeat("0", 64)),
+			RequestChecksum(ChecksumSHA256, "not a hash"),
+		} {
+			if _, err := cli.Get(context.TODO(), "/", opt); err == nil {
+				t.Error("Expected an error")
+			}
+		}
+	})
+}
+
+func TestRequest_WithServerChecksum(t *testing.T) {
+	body := "package contents"
+	md5Sum := md5.Sum([]byte(body))
+	crc := crc32.Checksum([]byte(body), crc32.MakeTable(crc32.Castagnoli))
+	crcSum := []byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)}
+
+	tests := []struct {
+		name    string
+		header  string
+		value   string
+		wantErr bool
+	}{
+		{"content md5", "Content-MD5", base64.StdEncoding.EncodeToString(md5Sum[:]), false},
+		{"amz crc32c", "x-amz-checksum-crc32c", base64.StdEncoding.EncodeToString(crcSum), false},
+		{"wrong amz crc32c", "x-amz-checksum-crc32c", base64.StdEncoding.EncodeToString([]byte{1, 2, 3, 4}), true},
+		{"composite is skipped", "x-amz-checksum-crc32c", "AAAAAA==-3", false},
+		{"no header", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.header != "" {
+					w.Header().Set(tt.header, tt.value)
+				}
+				io.WriteString(w, body)
+			}))
+			defer server.Close()
+
+			cli := NewClientBuilder().Build()
+			resp, err := cli.Get(context.TODO(), server.URL, RequestServerChecksum())
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			_, err = resp.Bytes()
+			if got := errors.Is(err, ErrChecksumMismatch); got != tt.wantErr {
+				t.Errorf("Expected mismatch %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -3,10 +3,13 @@ package reqwest
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,66 +17,360 @@ const (
 	DefaultClientTimeout = 10 * time.Second
 )
 
+// Getter performs GET requests.
+type Getter interface {
+	Get(ctx context.Context, url string, opts ...RequestOption) (*Response, error)
+}
+
+// Poster performs POST requests.
+type Poster interface {
+	Post(ctx context.Context, url string, body []byte, opts ...RequestOption) (*Response, error)
+}
+
+// Prober performs OPTIONS capability probes.
+type Prober interface {
+	Options(ctx context.Context, url string) (*Response, error)
+}
+
+// Doer executes arbitrary requests. It is the one interface frameworks need
+// to accept any client, decorator or fake.
+type Doer interface {
+	Do(ctx context.Context, req *Request) (*Response, error)
+}
+
+// DoerFunc adapts a function to a Doer, e.g. to stub one in tests or wrap
+// one with extra behavior.
+type DoerFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// Do calls f(ctx, req).
+func (f DoerFunc) Do(ctx context.Context, req *Request) (*Response, error) {
+	return f(ctx, req)
+}
+
+// Streamer opens bidirectional streaming connections.
+type Streamer interface {
+	Dial(ctx context.Context, url string) (*Conn, error)
+}
+
+// Client is what code sending requests needs from a reqwest client.
+// Consumers should depend on the smallest interface they need, e.g. a
+// Getter, so their mocks stay small. Administrative capabilities, such as
+// PolicyReporter or Reconfigurer, are separate interfaces that *HTTPClient
+// also satisfies.
 type Client interface {
-	Get(ctx context.Context, url string) (*Response, error)
-	Post(ctx context.Context, url string, body []byte) (*Response, error)
+	Getter
+	Poster
+	Prober
+	Doer
+	Streamer
 }
 
-type client struct {
-	baseURL     string
-	httpClient  *http.Client
-	middlewares []Middleware
-	retryConfig *RetryConfig
+// Extension points. Integrations with heavy dependencies (OpenTelemetry,
+// Prometheus, OAuth2, HTTP/3, brotli/zstd) live outside the core module and
+// plug in through these interfaces, together with Signer, MetricsCollector
+// and WithTransport, so the core stays dependency-free. The reqwestcompress
+// subpackage is a reference ContentDecoder implementation.
+
+// RoundTripperWrapper wraps the client's transport, e.g. to trace or measure
+// every attempt. Wrappers see requests after signing and validation.
+type RoundTripperWrapper func(http.RoundTripper) http.RoundTripper
+
+// TokenSource supplies bearer tokens, e.g. from an OAuth2 flow. It is
+// consulted on every attempt, after middleware and before signing, so
+// refreshed tokens are picked up by retries.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
 }
 
-func (c *client) Get(ctx context.Context, url string) (*Response, error) {
-	return c.execute(ctx, url, http.MethodGet, nil)
+// ContentDecoder decodes a response body compressed with a Content-Encoding
+// the standard library doesn't handle, such as "br" or "zstd".
+type ContentDecoder func(body io.ReadCloser) (io.ReadCloser, error)
+
+// HTTPClient is the client built by a ClientBuilder. Besides Client, it
+// satisfies SelfChecker, OutageSimulator, Grouper, DNSCacher,
+// InflightReporter, Canceller, PolicyReporter, Inspector, HeaderSetter,
+// AsyncDoer, Deriver, Reconfigurer, Scheduler, OutboxReplayer,
+// StatsReporter and RedirectCacher.
+type HTTPClient struct {
+	baseURL          string
+	httpClient       *http.Client
+	middlewares      []Middleware
+	retryConfig      *RetryConfig
+	onRetry          func(ctx context.Context, attempt int, resp *Response, err error, nextDelay time.Duration)
+	decode           decodeConfig
+	preflight        *ttlCache
+	cache            *responseCache
+	signer           Signer
+	verifier         ResponseVerifier
+	requestID        *requestIDConfig
+	idempotency      bool
+	traceContext     bool
+	limiter          *concurrencyLimiter
+	selfCheckPath    string
+	maxResponseBytes int64
+	versioning       versionConfig
+	maxRequestBytes  int64
+	maxHeaderCount   int
+	maxHeaderBytes   int
+	validators       []Validator
+	baseURLs         *baseURLPool
+	failover         FailoverStrategy
+	outages          outageSimulator
+	inflight         inflightSet
+	cancels          cancelSet
+	audit            *auditConfig
+	tokenSource      TokenSource
+	apiKey           *apiKeyConfig
+	contentDecoders  map[string]ContentDecoder
+	onCompression    func(CompressionStats)
+	bodyReadRetries  int
+	prefetchBytes    int64
+	breaker          *circuitBreaker
+	rateLimiter      *rateLimiter
+	discovery        *discovery
+	dns              *dnsCache
+	clock            Clock
+	fallback         func(req *Request, err error) (*Response, error)
+	errorOnNon2xx    bool
+	strictURLs       bool
+	errorDecoder     func(*Response) error
+	bufferResponses  bool
+	leaks            *leakConfig
+	hostGuard        *hostGuard
+	faults           *FaultInjector
+	timeouts         Timeouts
+	coalescer        *coalescer
+	defaults         defaultHeaders
+	builder          *ClientBuilder
+	configMu         sync.RWMutex
+	timeout          time.Duration
+	env              *EnvConfig
+	staleEndpoints   *staleEndpoints
+	schedules        scheduleSet
+	handlers         []Handler
+	attemptHandlers  []Handler
+	adaptive         *adaptiveTimeouts
+	redirects        *redirectCache
+	authenticator    Authenticator
+	challenges       *challengeCache
+	outbox           *outbox
+	offline          *offlineQueue
+	stats            *connStats
+	dualStack        *DualStack
+	pins             []string
+	wsReadLimit      int64
 }
 
-func (c *client) Post(ctx context.Context, url string, body []byte) (*Response, error) {
-	return c.execute(ctx, url, http.MethodPost, bytes.NewBuffer(body))
+func (c *HTTPClient) Get(ctx context.Context, url string, opts ...RequestOption) (*Response, error) {
+	return c.execute(ctx, NewRequest(http.MethodGet, url).with(opts))
 }
 
-func (c *client) execute(
-	ctx context.Context,
-	url,
-	method string,
-	body io.Reader) (*Response, error) {
+func (c *HTTPClient) Post(ctx context.Context, url string, body []byte, opts ...RequestOption) (*Response, error) {
+	return c.execute(ctx, NewRequest(http.MethodPost, url).WithBody(body).with(opts))
+}
+
+// Do executes req with the client's full policy stack (middleware, signing,
+// retries).
+func (c *HTTPClient) Do(ctx context.Context, req *Request) (*Response, error) {
+	return c.execute(ctx, req)
+}
+
+func (c *HTTPClient) execute(ctx context.Context, request *Request) (*Response, error) {
+	if c.idempotency && needsIdempotencyKey(request.method, request.header) {
+		// One key for every attempt of the call, and for its outbox replays
+		request = request.Clone().WithIdempotencyKey(NewUUID())
+	}
+	ctx = withRoute(request.withValues(ctx), request)
+	resp, err := c.sendOnline(ctx, request, func(ctx context.Context, request *Request) (*Response, error) {
+		return c.handleRequest(ctx, request, func(ctx context.Context, request *Request) (*Response, error) {
+			return c.executeMemoized(ctx, request, func(ctx context.Context, request *Request) (*Response, error) {
+				return c.bufferResponse(c.executeCoalesced(ctx, request, c.dispatch))
+			})
+		})
+	})
+	if err != nil && c.fallback != nil {
+		resp, err = c.bufferResponse(c.degrade(ctx, request, err))
+	}
+	if c.outbox != nil {
+		resp, err = c.queue(ctx, request, resp, err)
+	}
+	if err == nil && (resp.decodedErr != nil || c.errorOnNon2xx && !resp.IsSuccess()) {
+		return nil, httpError(resp)
+	}
+	if resp != nil {
+		resp.expect = request.expect
+	}
+	return resp, err
+}
+
+func (c *HTTPClient) dispatch(ctx context.Context, request *Request) (*Response, error) {
+	if c.cache != nil {
+		switch request.method {
+		case http.MethodGet:
+			return c.executeCached(ctx, request)
+		case http.MethodHead, http.MethodOptions:
+		default:
+			if c.cache.readYourWrites {
+				return c.executeMutation(ctx, request)
+			}
+		}
+	}
+	return c.executeUncached(ctx, request)
+}
+
+func (c *HTTPClient) executeUncached(ctx context.Context, request *Request) (*Response, error) {
+	if err := request.checksum.check(); err != nil {
+		return nil, err
+	}
+	ctx = withRequestBudget(ctx, request)
+	tracked, release := c.cancels.track(ctx)
+	resp, err := c.executeWithTimeout(tracked, request)
+	if resp == nil || resp.body == nil {
+		release()
+	} else {
+		resp.body = &trackedBody{ReadCloser: resp.body, ctx: tracked, release: release}
+	}
+	if err == nil {
+		c.withBodyRetry(ctx, request, resp)
+		request.checksum.wrap(resp)
+	}
+	return resp, forceCancelled(tracked, err)
+}
+
+func (c *HTTPClient) executeWithTimeout(ctx context.Context, request *Request) (*Response, error) {
+	timeout, ok := timeoutOverride(ctx)
+	if !ok && request.timeout > 0 {
+		timeout, ok = request.timeout, true
+	}
+	if !ok {
+		timeout = c.timeout
+	}
+	if timeout <= 0 {
+		return c.executeWithRetries(ctx, request)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	resp, err := c.executeWithRetries(ctx, request)
+	if resp == nil || resp.body == nil {
+		cancel()
+		return resp, err
+	}
+	// The deadline also covers reading the body, so release it on Close
+	resp.body = &cancelOnCloseBody{ReadCloser: resp.body, cancel: cancel}
+	return resp, err
+}
+
+func (c *HTTPClient) executeWithRetries(ctx context.Context, request *Request) (resp *Response, err error) {
+	if err := c.checkRequestSize(request.body); err != nil {
+		return nil, err
+	}
+
 	startTime := time.Now()
 	var lastErr error
-	var resp *Response
 
-	// Cache body content for retries
-	var bodyBytes []byte
-	if body != nil {
-		var err error
-		bodyBytes, err = io.ReadAll(body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read request body: %v", err)
+	header := request.header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	var requestID string
+	if c.requestID != nil {
+		// An ID set by the caller, e.g. propagated from an inbound request,
+		// is kept so both sides log the same one
+		requestID = header.Get(c.requestID.header)
+		if requestID == "" {
+			requestID = c.requestID.generator()
+			header.Set(c.requestID.header, requestID)
+		}
+	}
+	var traceID string
+	if c.traceContext {
+		traceID = traceIDOf(header.Get(TraceparentHeader))
+		if traceID == "" {
+			traceID = randomHex(16)
+		}
+	}
+	if c.idempotency && needsIdempotencyKey(request.method, header) {
+		header.Set(IdempotencyKeyHeader, NewUUID())
+	}
+	if c.versioning.enabled() && header.Get("Accept") == "" {
+		header.Set("Accept", c.versioning.accept())
+	}
+	if request.expect != nil && header.Get("Accept") == "" {
+		header.Set("Accept", request.expect.ContentType())
+	}
+
+	urls := c.attemptURLs(ctx, request)
+	if c.strictURLs {
+		for i, url := range urls {
+			normalized, err := normalizeURL(url)
+			if err != nil {
+				return nil, err
+			}
+			urls[i] = normalized
 		}
 	}
+	retry := c.retryConfigFor(ctx, request)
 	maxAttempts := 1
-	if c.retryConfig != nil {
-		maxAttempts = c.retryConfig.maxRetries + 1
+	if retry != nil {
+		maxAttempts = retry.maxRetries + 1
 	}
 
+	var traces []AttemptTrace
+	audit := c.newAudit(ctx, request, urls[0], requestID, retry)
+	defer func() { audit.finish(resp, err) }()
+	inflight := c.inflight.add(request, requestID)
+	defer c.inflight.remove(inflight)
+
+	var delay time.Duration
 	for attempt := 0; attempt < maxAttempts; attempt++ {
 		if err := contextCancelled(ctx); err != nil {
 			return nil, err
 		}
-		if err := c.applyBackoff(ctx, attempt); err != nil {
+		if err := sleep(ctx, c.clock, delay); err != nil {
 			return nil, err
 		}
-		resp, lastErr = c.executeOnce(ctx, url, method, bodyReaderFromByteSlice(bodyBytes))
+		url := urls[attempt%len(urls)]
+		inflight.attempt.Store(int32(attempt + 1))
+		if traceID != "" {
+			header.Set(TraceparentHeader, newTraceparent(traceID))
+		}
+		attemptStart := time.Now()
+		attemptCtx, tracer := traceAttempt(ctx, attempt+1)
+		resp, lastErr = c.executeAttempt(attemptCtx, request, url, header)
+		traces = append(traces, tracer.finish())
+		audit.attempt(attempt+1, url, attemptStart, resp, lastErr)
 		// If successful and no retry needed, return immediately
-		if lastErr == nil && !c.shouldRetry(resp) {
+		if resp != nil {
+			resp.requestID = requestID
+			resp.traceID = traceID
+			resp.route = request.Route()
+			resp.attemptTraces = traces
+		}
+		retryResponse := retry.shouldRetryResponse(resp)
+		if lastErr == nil && c.errorDecoder != nil && !resp.IsSuccess() {
+			// A decoded error, rather than the status, decides whether to retry
+			if resp.decodedErr = c.decodeError(resp); resp.decodedErr != nil {
+				retryResponse = retry != nil && isRetryable(resp.decodedErr)
+			}
+		}
+		if lastErr == nil && !retryResponse {
 			resp.retryAttempts = attempt
 			resp.totalDuration = time.Since(startTime)
 			return resp, nil
 		}
 
 		// Check if we should retry this error/response
-		if attempt < maxAttempts-1 && (c.shouldRetryError(lastErr) || c.shouldRetry(resp)) {
+		if attempt < maxAttempts-1 && (retry.shouldRetryAttempt(request.method, header, lastErr) ||
+			retry.shouldRetryPrefetch(request.method, lastErr) || retryResponse ||
+			(len(urls) > 1 && errors.Is(lastErr, ErrCircuitOpen))) {
+			delay = retry.delayAfter(attempt+1, resp, lastErr)
+			if c.onRetry != nil {
+				c.onRetry(ctx, attempt+1, resp, lastErr, delay)
+			}
+			if resp != nil {
+				resp.body.Close()
+			}
+			audit.retried()
 			continue
 		}
 
@@ -91,78 +388,268 @@ func (c *client) execute(
 	return nil, lastErr
 }
 
-func (c *client) executeOnce(ctx context.Context, url, method string, body io.Reader) (*Response, error) {
-	fullURL := c.buildURL(url)
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to make http request: %v", err)
+// executeAttempt runs one attempt through the circuit breaker and rate
+// limiter, holding a concurrency slot if the client limits concurrency.
+func (c *HTTPClient) executeAttempt(ctx context.Context, request *Request, url string, header http.Header) (*Response, error) {
+	if c.discovery != nil {
+		routed, done, err := c.discovery.route(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("service discovery error: %v", err)
+		}
+		defer done()
+		url = routed
+	}
+	host := hostOf(url)
+	if c.breaker != nil {
+		if err := c.breaker.allow(ctx, host); err != nil {
+			return nil, err
+		}
+	}
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.wait(ctx); err != nil {
+			return nil, err
+		}
 	}
+	if c.limiter != nil {
+		release, err := c.limiter.acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+	resp, err := c.handleAttempt(ctx, url, request.method, request.body, header, c.executeOnceAdaptive)
+	if c.staleEndpoints != nil && c.staleEndpoints.observe(host, err) {
+		// A blue/green cutover left us with an outdated address: re-resolve
+		// and try once more before the failure counts as an attempt
+		c.forgetEndpoint(host)
+		resp, err = c.handleAttempt(ctx, url, request.method, request.body, header, c.executeOnceAdaptive)
+		c.staleEndpoints.observe(host, err)
+	}
+	if c.breaker != nil && ctx.Err() == nil {
+		c.breaker.record(ctx, host, err == nil && resp.statusCode < http.StatusInternalServerError)
+	}
+	return resp, err
+}
+
+// prepare adds the client's headers and credentials to req and checks it
+// before it is sent: default headers, middleware, the token source, API key
+// and authenticator, then the signer, header limits, validators and host
+// guard.
+func (c *HTTPClient) prepare(ctx context.Context, req *http.Request, body []byte) error {
+	c.defaults.apply(req)
 	for _, middleware := range c.middlewares {
 		if err := middleware(req); err != nil {
-			return nil, fmt.Errorf("middleware error: %v", err)
+			return fmt.Errorf("middleware error: %v", err)
+		}
+	}
+	if c.tokenSource != nil {
+		token, err := c.tokenSource.Token(ctx)
+		if err != nil {
+			return fmt.Errorf("token source error: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if c.apiKey != nil {
+		if err := c.apiKey.apply(ctx, req); err != nil {
+			return err
+		}
+	}
+	if c.authenticator != nil {
+		if err := c.authenticate(req); err != nil {
+			return err
+		}
+	}
+	// An upgraded connection has no body to decode
+	if len(c.contentDecoders) > 0 && req.Header.Get("Accept-Encoding") == "" && req.Header.Get("Upgrade") == "" {
+		req.Header.Set("Accept-Encoding", c.acceptEncoding())
+	}
+	if c.signer != nil {
+		if err := c.signer.Sign(req, body); err != nil {
+			return fmt.Errorf("signer error: %v", err)
 		}
 	}
+	if err := c.checkHeaders(req); err != nil {
+		return err
+	}
+	if err := c.validate(req); err != nil {
+		return err
+	}
+	if c.hostGuard != nil {
+		if err := c.hostGuard.check(req.URL.Host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *HTTPClient) executeOnce(
+	ctx context.Context,
+	url,
+	method string,
+	body []byte,
+	header http.Header) (*Response, error) {
+	if c.apiKey != nil {
+		ctx = c.apiKey.withSecrets(ctx)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReaderFromByteSlice(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to make http request: %v", err)
+	}
+	for name, values := range header {
+		req.Header[name] = append([]string(nil), values...)
+	}
+	if err := c.prepare(ctx, req, body); err != nil {
+		return nil, err
+	}
+	if err := checkByteBudgets(ctx); err != nil {
+		return nil, err
+	}
+	if mode := c.outageFor(req); mode != OutageNone {
+		return c.simulateOutage(req, mode)
+	}
+	if resp, injected, err := c.injectFault(req); injected {
+		return resp, err
+	}
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to do http request: %v", err)
+		if c.apiKey != nil {
+			err = c.apiKey.redact(err)
+		}
+		return nil, fmt.Errorf("failed to do http request: %w", err)
+	}
+	if resp.StatusCode == http.StatusRequestHeaderFieldsTooLarge {
+		return nil, headerFieldsRejected(resp)
+	}
+	checkLength(resp)
+	if budgets := byteBudgets(ctx); len(budgets) > 0 {
+		resp.Body = &budgetedBody{ReadCloser: resp.Body, budgets: budgets}
 	}
 
-	return fromHTTPResponse(resp), nil
-}
-
-func (c *client) shouldRetry(resp *Response) bool {
-	if c.retryConfig == nil || resp == nil {
-		return false
+	if err := c.decodeContent(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
 	}
 
-	statusCode := resp.StatusCode()
-	for _, code := range c.retryConfig.retryableStatusCodes {
-		if statusCode == code {
-			return true
+	response := fromHTTPResponse(resp)
+	response.decode = c.decode
+	response.decoded = resp.Uncompressed
+	if c.leaks != nil {
+		response.body = c.trackLeaks(ctx, req, response.body)
+	}
+	if c.versioning.enabled() {
+		c.versioning.observe(response, method, url)
+	}
+	if c.maxResponseBytes > 0 {
+		response.body = newLimitedBody(response.body, c.maxResponseBytes)
+	}
+	if c.prefetchBytes > 0 {
+		if err := prefetch(response, resp.ContentLength, c.prefetchBytes); err != nil {
+			return nil, err
 		}
 	}
-
-	return false
+	if c.verifier != nil {
+		if err := c.verifyResponse(resp, response); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
 }
 
-func (c *client) shouldRetryError(err error) bool {
-	if c.retryConfig == nil || err == nil {
-		return false
+// retryConfigFor resolves the retry policy for one call: the context can
+// disable retries, then the request can override the client default.
+func (c *HTTPClient) retryConfigFor(ctx context.Context, request *Request) *RetryConfig {
+	if noRetry(ctx) {
+		return nil
 	}
-
-	errStr := strings.ToLower(err.Error())
-	for retryableErr := range c.retryConfig.retryableError {
-		if strings.Contains(errStr, retryableErr) {
-			return true
-		}
+	if request.retryOverride {
+		return request.retryConfig
 	}
+	return c.currentRetryConfig()
+}
 
-	return false
+func (c *HTTPClient) shouldRetry(resp *Response) bool {
+	return c.currentRetryConfig().shouldRetryResponse(resp)
 }
 
-func (c *client) buildURL(url string) string {
-	if c.baseURL == "" {
+func (c *HTTPClient) shouldRetryError(err error) bool {
+	return c.currentRetryConfig().shouldRetryError(err)
+}
+
+func (c *HTTPClient) buildURL(url string) string {
+	return joinURL(c.baseURL, url)
+}
+
+// hostOf returns the host of an absolute URL, or "" if it has none.
+func hostOf(rawURL string) string {
+	parsed, err := neturl.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
+
+// joinURL resolves url against baseURL. Relative paths, with or without a
+// leading slash, are appended to the base path rather than replacing it, and
+// the base's query parameters are added to the request's, which win when both
+// set the same one. The base's fragment is dropped; absolute URLs are used
+// as-is.
+func joinURL(baseURL, url string) string {
+	if baseURL == "" {
 		return url
 	}
 
-	if strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") {
+	// Schemes are case-insensitive, so "HTTPS://host/x" is absolute too
+	if parsed, err := neturl.Parse(url); err == nil && parsed.IsAbs() {
 		return url
 	}
 
-	return c.baseURL + "/" + strings.TrimLeft(url, "/")
+	base, err := neturl.Parse(baseURL)
+	ref, refErr := neturl.Parse(strings.TrimLeft(url, "/"))
+	if err != nil || refErr != nil || ref.Scheme != "" || ref.Host != "" {
+		// Leave the error to NewRequest, which reports it with the full URL
+		return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(url, "/")
+	}
+	// Only a bare query or fragment applies to the base path itself
+	if !strings.HasPrefix(url, "?") && !strings.HasPrefix(url, "#") && !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+		if base.RawPath != "" {
+			base.RawPath += "/"
+		}
+	}
+	query := mergeQuery(base.RawQuery, ref.RawQuery)
+	base.RawQuery, base.Fragment, base.RawFragment = "", "", ""
+	resolved := base.ResolveReference(ref)
+	resolved.RawQuery = query
+	resolved.ForceQuery = ref.ForceQuery && query == ""
+	return resolved.String()
 }
 
-func (c *client) applyBackoff(ctx context.Context, attempt int) error {
-	// Apply backoff delay for retry attempts (skip on first attempt)
-	if attempt > 0 && c.retryConfig != nil {
-		delay := c.retryConfig.backoffStrategy.Delay(attempt)
-		select {
-		case <-time.After(delay):
-		case <-ctx.Done():
-			return ctx.Err()
+// mergeQuery appends to a request's raw query the parameters of the base
+// query that it doesn't set itself, keeping both in their original order and
+// encoding.
+func mergeQuery(baseQuery, query string) string {
+	if baseQuery == "" {
+		return query
+	}
+	if query == "" {
+		return baseQuery
+	}
+	own, _ := neturl.ParseQuery(query)
+	merged := query
+	for _, param := range strings.Split(baseQuery, "&") {
+		key, _, _ := strings.Cut(param, "=")
+		if name, err := neturl.QueryUnescape(key); err == nil && own.Has(name) {
+			continue
+		}
+		if param != "" {
+			merged += "&" + param
 		}
 	}
-	return nil
+	return merged
+}
+
+func (c *HTTPClient) applyBackoff(ctx context.Context, attempt int) error {
+	return c.currentRetryConfig().wait(ctx, c.clock, attempt)
 }
 
 func contextCancelled(ctx context.Context) error {
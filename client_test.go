@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -163,15 +166,51 @@ func TestClient_BuildURL(t *testing.T) {
 			url:      "http://other.example.com/data",
 			expected: "http://other.example.com/data",
 		},
+		{
+			name:     "Base URL ignored for upper-case scheme",
+			baseURL:  "https://api.example.com",
+			url:      "HTTPS://other.example.com/data",
+			expected: "HTTPS://other.example.com/data",
+		},
+		{
+			name:     "Base URL with path",
+			baseURL:  "https://api.example.com/api/v2/",
+			url:      "/users/7",
+			expected: "https://api.example.com/api/v2/users/7",
+		},
+		{
+			name:     "Base URL with query",
+			baseURL:  "https://api.example.com/api?key=x",
+			url:      "/users",
+			expected: "https://api.example.com/api/users?key=x",
+		},
+		{
+			name:     "Base and request queries merged",
+			baseURL:  "https://api.example.com/api?key=x&lang=en",
+			url:      "/users?page=2&lang=fr",
+			expected: "https://api.example.com/api/users?page=2&lang=fr&key=x",
+		},
+		{
+			name:     "Base URL fragment dropped",
+			baseURL:  "https://api.example.com/api#docs",
+			url:      "users#top",
+			expected: "https://api.example.com/api/users#top",
+		},
+		{
+			name:     "Request with only a query",
+			baseURL:  "https://api.example.com/search?key=x",
+			url:      "?q=go",
+			expected: "https://api.example.com/search?q=go&key=x",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			var c *client
+			var c *HTTPClient
 			if tt.baseURL != "" {
-				c = NewClientBuilder().WithBaseURL(tt.baseURL).Build().(*client)
+				c = NewClientBuilder().WithBaseURL(tt.baseURL).Build()
 			} else {
-				c = NewClientBuilder().Build().(*client)
+				c = NewClientBuilder().Build()
 			}
 
 			result := c.buildURL(tt.url)
@@ -890,7 +929,7 @@ func TestClient_ApplyBackoff(t *testing.T) {
 				Build()).
 			Build()
 
-		client := &client{retryConfig: retryConfig}
+		client := &HTTPClient{retryConfig: retryConfig}
 		ctx := context.Background()
 
 		start := time.Now()
@@ -914,7 +953,7 @@ func TestClient_ApplyBackoff(t *testing.T) {
 				Build()).
 			Build()
 
-		client := &client{retryConfig: retryConfig}
+		client := &HTTPClient{retryConfig: retryConfig}
 		ctx := context.Background()
 
 		start := time.Now()
@@ -937,7 +976,7 @@ func TestClient_ApplyBackoff(t *testing.T) {
 				Build()).
 			Build()
 
-		client := &client{retryConfig: retryConfig}
+		client := &HTTPClient{retryConfig: retryConfig}
 		ctx, cancel := context.WithCancel(context.Background())
 
 		// Cancel after short delay
@@ -964,7 +1003,7 @@ func TestClient_ApplyBackoff(t *testing.T) {
 	})
 
 	t.Run("no delay without retry config", func(t *testing.T) {
-		client := &client{retryConfig: nil}
+		client := &HTTPClient{retryConfig: nil}
 		ctx := context.Background()
 
 		start := time.Now()
@@ -986,7 +1025,7 @@ func TestClient_ShouldRetry(t *testing.T) {
 		WithRetryableStatusCodes([]int{500, 502, 503}).
 		Build()
 
-	cli := &client{retryConfig: retryConfig}
+	cli := &HTTPClient{retryConfig: retryConfig}
 
 	t.Run("retries on configured status codes", func(t *testing.T) {
 		testCases := []struct {
@@ -1021,7 +1060,7 @@ func TestClient_ShouldRetry(t *testing.T) {
 	})
 
 	t.Run("returns false without retry config", func(t *testing.T) {
-		clientNoRetry := &client{retryConfig: nil}
+		clientNoRetry := &HTTPClient{retryConfig: nil}
 		resp := &Response{statusCode: 500}
 
 		result := clientNoRetry.shouldRetry(resp)
@@ -1034,18 +1073,18 @@ func TestClient_ShouldRetry(t *testing.T) {
 func TestClient_ShouldRetryError(t *testing.T) {
 	t.Run("retries on default retryable errors", func(t *testing.T) {
 		retryConfig := NewRetryConfigBuilder().Build() // Uses default retryable errors
-		cli := &client{retryConfig: retryConfig}
+		cli := &HTTPClient{retryConfig: retryConfig}
 
 		testCases := []struct {
 			name        string
 			err         error
 			shouldRetry bool
 		}{
-			{"connection refused error", fmt.Errorf("dial tcp: connection refused"), true},
-			{"timeout error", fmt.Errorf("context deadline exceeded: timeout"), true},
-			{"temporary failure error", fmt.Errorf("temporary failure in name resolution"), true},
-			{"no such host error", fmt.Errorf("dial tcp: lookup example.com: no such host"), true},
-			{"case insensitive matching", fmt.Errorf("TIMEOUT occurred"), true},
+			{"connection refused error", fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED), true},
+			{"timeout error", fmt.Errorf("read: %w", os.ErrDeadlineExceeded), true},
+			{"temporary failure error", &net.DNSError{Err: "temporary failure in name resolution", IsTemporary: true}, true},
+			{"no such host error", &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}, true},
+			{"error strings are not matched", fmt.Errorf("dial tcp: connection refused"), false},
 			{"non-retryable error", fmt.Errorf("invalid request format"), false},
 			{"empty error message", fmt.Errorf(""), false},
 		}
@@ -1068,7 +1107,7 @@ func TestClient_ShouldRetryError(t *testing.T) {
 		retryConfig := NewRetryConfigBuilder().
 			WithRetryableErrors(customRetryableErrors).
 			Build()
-		cli := &client{retryConfig: retryConfig}
+		cli := &HTTPClient{retryConfig: retryConfig}
 
 		testCases := []struct {
 			name        string
@@ -1094,7 +1133,7 @@ func TestClient_ShouldRetryError(t *testing.T) {
 
 	t.Run("returns false for nil error", func(t *testing.T) {
 		retryConfig := NewRetryConfigBuilder().Build()
-		cli := &client{retryConfig: retryConfig}
+		cli := &HTTPClient{retryConfig: retryConfig}
 
 		result := cli.shouldRetryError(nil)
 		if result {
@@ -1103,7 +1142,7 @@ func TestClient_ShouldRetryError(t *testing.T) {
 	})
 
 	t.Run("returns false without retry config", func(t *testing.T) {
-		cli := &client{retryConfig: nil}
+		cli := &HTTPClient{retryConfig: nil}
 		err := fmt.Errorf("connection refused")
 
 		result := cli.shouldRetryError(err)
@@ -1113,8 +1152,8 @@ func TestClient_ShouldRetryError(t *testing.T) {
 	})
 
 	t.Run("partial string matching", func(t *testing.T) {
-		retryConfig := NewRetryConfigBuilder().Build()
-		cli := &client{retryConfig: retryConfig}
+		retryConfig := NewRetryConfigBuilder().WithRetryableErrors(DefaultRetryableErrors).Build()
+		cli := &HTTPClient{retryConfig: retryConfig}
 
 		testCases := []struct {
 			name        string
@@ -1124,6 +1163,7 @@ func TestClient_ShouldRetryError(t *testing.T) {
 			{"timeout in middle of message", fmt.Errorf("request failed due to timeout while connecting"), true},
 			{"connection refused with details", fmt.Errorf("failed to connect: connection refused by server"), true},
 			{"no such host with context", fmt.Errorf("DNS lookup failed: no such host found"), true},
+			{"case insensitive matching", fmt.Errorf("TIMEOUT occurred"), true},
 		}
 
 		for _, tc := range testCases {
@@ -0,0 +1,34 @@
+package reqwest
+
+import "time"
+
+// Clock tells time for response, DNS, redirect and preflight cache expiry,
+// circuit breaker cooldowns, rate limiting, Retry-After dates, the in-memory
+// state store, retry backoff, injected fault delays, offline and outbox
+// replays, scheduled jobs, paginator spacing and BodyLeak.OpenedAt. Tests can
+// substitute a fake clock, such as reqwesttest.FakeClock, to step through them
+// without waiting. Everything else uses real time: context deadlines, measured
+// durations, adaptive timeouts, concurrency limit waits and the body leak idle
+// timeout.
+type Clock interface {
+	Now() time.Time
+	// After waits for d to elapse, like time.After.
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// SystemClock is the real clock, used unless WithClock sets another.
+var SystemClock Clock = systemClock{}
+
+// orSystemClock returns clock, or SystemClock if it is nil, so components
+// created without one keep working.
+func orSystemClock(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+	return clock
+}
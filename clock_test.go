@@ -0,0 +1,145 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// manualClock only moves when advanced.
+type manualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []manualWaiter
+}
+
+type manualWaiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newManualClock(now time.Time) *manualClock {
+	return &manualClock{now: now}
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.waiters = append(c.waiters, manualWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *manualClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.at.After(c.now) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- c.now
+	}
+	c.waiters = pending
+}
+
+func (c *manualClock) waiting() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+func TestClientBuilder_WithClock(t *testing.T) {
+	var calls, failures int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.AddInt32(&failures, -1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	get := func(cli Client) error {
+		resp, err := cli.Get(context.Background(), server.URL)
+		if err == nil {
+			resp.Body().Close()
+		}
+		return err
+	}
+
+	t.Run("cache expiry", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		clock := newManualClock(time.Now())
+		cli := NewClientBuilder().WithClock(clock).WithResponseCache(time.Minute).Build()
+		for range 2 {
+			if err := get(cli); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		clock.advance(time.Minute + time.Second)
+		if err := get(cli); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := atomic.LoadInt32(&calls); got != 2 {
+			t.Errorf("Expected the entry to expire once the clock passed the TTL, got %d calls", got)
+		}
+	})
+
+	t.Run("circuit breaker cooldown", func(t *testing.T) {
+		atomic.StoreInt32(&failures, 1)
+		clock := newManualClock(time.Now())
+		cli := NewClientBuilder().WithClock(clock).WithCircuitBreaker(1, time.Hour).Build()
+		if err := get(cli); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := get(cli); !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+		}
+		clock.advance(time.Hour)
+		if err := get(cli); err != nil {
+			t.Errorf("Expected the half-open circuit to let the request through, got %v", err)
+		}
+	})
+
+	t.Run("retry backoff", func(t *testing.T) {
+		atomic.StoreInt32(&failures, 1)
+		clock := newManualClock(time.Now())
+		cli := NewClientBuilder().
+			WithClock(clock).
+			WithRetryConfig(NewRetryConfigBuilder().
+				WithBackoffStrategy(NewFixedBackoffBuilder().WithDelay(time.Hour).Build()).
+				Build()).
+			Build()
+		done := make(chan error, 1)
+		go func() { done <- get(cli) }()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for clock.waiting() == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("Expected the retry to wait on the clock")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		clock.advance(time.Hour)
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the retry to run once the clock advanced")
+		}
+	})
+}
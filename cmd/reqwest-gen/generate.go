@@ -0,0 +1,685 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const reqwestImport = "github.com/rbhujang/reqwest"
+
+// generator turns an OpenAPI document into the Go source of a client.
+type generator struct {
+	doc     *document
+	imports map[string]bool
+	names   map[string]bool
+	pending []namedSchema
+	methods map[string]bool
+	scalars map[string]bool // declared types with a string, number or bool underlying type
+}
+
+// namedSchema is a schema to be declared as a Go type, with the comment to
+// give it if the schema has no description.
+type namedSchema struct {
+	name   string
+	schema *schema
+	doc    string
+}
+
+// generate returns the formatted source of package pkg with a client for doc.
+// source names the document in the generated header.
+func generate(doc *document, pkg, source string) ([]byte, error) {
+	g := &generator{
+		doc:     doc,
+		imports: map[string]bool{"context": true, "net/http": true, reqwestImport: true},
+		names:   map[string]bool{"Client": true, "NewClient": true, "ServerURL": true},
+		methods: make(map[string]bool),
+		scalars: make(map[string]bool),
+	}
+	for _, name := range slices.Sorted(maps.Keys(doc.Components.Schemas)) {
+		if g.names[exported(name)] {
+			return nil, fmt.Errorf("schema %q: name %s is already used", name, exported(name))
+		}
+		g.names[exported(name)] = true
+		if s := doc.Components.Schemas[name]; s.Ref == "" && len(s.Properties) == 0 && len(s.AllOf) == 0 {
+			switch s.Type {
+			case "string", "integer", "number", "boolean":
+				g.scalars[exported(name)] = true
+			}
+		}
+		g.pending = append(g.pending, namedSchema{
+			name:   exported(name),
+			schema: doc.Components.Schemas[name],
+			doc:    fmt.Sprintf("%s is the %s schema.", exported(name), name),
+		})
+	}
+
+	var body bytes.Buffer
+	g.client(&body)
+	for _, path := range slices.Sorted(maps.Keys(doc.Paths)) {
+		item := doc.Paths[path]
+		for _, entry := range item.operations() {
+			if err := g.operation(&body, path, entry.method, item, entry.op); err != nil {
+				return nil, fmt.Errorf("%s %s: %v", entry.method, path, err)
+			}
+		}
+	}
+	// Declaring a type can queue the inline types of its fields
+	for len(g.pending) > 0 {
+		next := g.pending[0]
+		g.pending = g.pending[1:]
+		if err := g.declare(&body, next); err != nil {
+			return nil, fmt.Errorf("schema %s: %v", next.name, err)
+		}
+	}
+
+	var file bytes.Buffer
+	fmt.Fprintf(&file, "// Code generated by reqwest-gen from %s. DO NOT EDIT.\n\npackage %s\n\nimport (\n", source, pkg)
+	for _, path := range slices.Sorted(maps.Keys(g.imports)) {
+		if path == reqwestImport {
+			continue
+		}
+		fmt.Fprintf(&file, "\t%q\n", path)
+	}
+	fmt.Fprintf(&file, "\n\t%q\n)\n", reqwestImport)
+	file.Write(body.Bytes())
+	formatted, err := format.Source(file.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %v", err)
+	}
+	return formatted, nil
+}
+
+func (g *generator) client(w *bytes.Buffer) {
+	title := g.doc.Info.Title
+	if title == "" {
+		title = "the"
+	}
+	if len(g.doc.Servers) > 0 {
+		fmt.Fprintf(w, "\n// ServerURL is the first server the API document lists, for WithBaseURL.\nconst ServerURL = %q\n", g.doc.Servers[0].URL)
+	}
+	fmt.Fprintf(w, `
+// Client calls the %s API through a reqwest client, with its retries,
+// middleware and other policies.
+type Client struct {
+	doer reqwest.Doer
+}
+
+// NewClient returns a Client sending its requests through doer, usually a
+// reqwest.Client built with the API's base URL.
+func NewClient(doer reqwest.Doer) *Client {
+	return &Client{doer: doer}
+}
+`, title)
+}
+
+var httpMethods = map[string]string{
+	"GET": "http.MethodGet", "PUT": "http.MethodPut", "POST": "http.MethodPost", "DELETE": "http.MethodDelete",
+	"OPTIONS": "http.MethodOptions", "HEAD": "http.MethodHead", "PATCH": "http.MethodPatch",
+}
+
+// reservedArgs are the names generated methods use for their own variables.
+var reservedArgs = map[string]bool{
+	"c": true, "ctx": true, "params": true, "body": true, "opts": true, "opt": true,
+	"req": true, "data": true, "err": true, "zero": true, "v": true,
+}
+
+func (g *generator) operation(w *bytes.Buffer, path, method string, item *pathItem, op *operation) error {
+	name := exported(op.OperationID)
+	if op.OperationID == "" {
+		name = operationName(method, path)
+	}
+	if g.methods[name] {
+		return fmt.Errorf("duplicate operation name %s", name)
+	}
+	g.methods[name] = true
+
+	params, err := g.parameters(item, op)
+	if err != nil {
+		return err
+	}
+	var pathParams, otherParams []*parameter
+	for _, p := range params {
+		switch p.In {
+		case "path":
+			pathParams = append(pathParams, p)
+		case "query", "header":
+			otherParams = append(otherParams, p)
+		}
+	}
+
+	// Path parameters become arguments, in the order the path uses them
+	var args []string
+	pathExpr, err := g.pathExpr(path, pathParams, &args)
+	if err != nil {
+		return err
+	}
+	var paramsType string
+	if len(otherParams) > 0 {
+		paramsType = g.uniqueName(name + "Params")
+		args = append(args, "params "+paramsType)
+	}
+
+	var bodyType, contentType string
+	reqBody, err := g.doc.requestBody(op.RequestBody)
+	if err != nil {
+		return err
+	}
+	if reqBody != nil {
+		if s, ok := jsonContent(reqBody.Content); ok {
+			if bodyType, err = g.goType(s, name+"Request"); err != nil {
+				return fmt.Errorf("request body: %v", err)
+			}
+			g.imports["encoding/json"] = true
+		} else if len(reqBody.Content) > 0 {
+			bodyType, contentType = "[]byte", slices.Sorted(maps.Keys(reqBody.Content))[0]
+		}
+		if bodyType != "" {
+			args = append(args, "body "+bodyType)
+		}
+	}
+
+	resultType, err := g.resultType(name, op)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n// %s calls %s %s.\n", name, method, path)
+	if doc := strings.TrimSpace(op.Summary + "\n\n" + op.Description); doc != "" {
+		fmt.Fprintf(w, "//\n")
+		writeDoc(w, "", doc)
+	}
+	if op.Deprecated {
+		fmt.Fprintf(w, "//\n// Deprecated: the API marks this operation as deprecated.\n")
+	}
+	results := "error"
+	if resultType != "" {
+		results = "(" + resultType + ", error)"
+	}
+	fmt.Fprintf(w, "func (c *Client) %s(%s) %s {\n", name, strings.Join(append(append([]string{"ctx context.Context"}, args...), "opts ...reqwest.RequestOption"), ", "), results)
+	fmt.Fprintf(w, "\treq := reqwest.NewRequest(%s, %s)\n", httpMethods[method], pathExpr)
+	for _, p := range otherParams {
+		if err := g.setParameter(w, p); err != nil {
+			return err
+		}
+	}
+	switch {
+	case bodyType == "[]byte":
+		fmt.Fprintf(w, "\treq.WithBody(body).WithHeader(\"Content-Type\", %q)\n", contentType)
+	case bodyType != "":
+		fmt.Fprintf(w, "\tdata, err := json.Marshal(body)\n\tif err != nil {\n")
+		if resultType != "" {
+			fmt.Fprintf(w, "\t\tvar zero %s\n\t\treturn zero, fmt.Errorf(\"failed to encode request body: %%v\", err)\n", resultType)
+		} else {
+			fmt.Fprintf(w, "\t\treturn fmt.Errorf(\"failed to encode request body: %%v\", err)\n")
+		}
+		fmt.Fprintf(w, "\t}\n\treq.WithBody(data).WithHeader(\"Content-Type\", \"application/json\")\n")
+		g.imports["fmt"] = true
+	}
+	fmt.Fprintf(w, "\tfor _, opt := range opts {\n\t\topt(req)\n\t}\n")
+	if resultType != "" {
+		fmt.Fprintf(w, "\treturn reqwest.DoJSON[%s](ctx, c.doer, req)\n}\n", resultType)
+	} else {
+		fmt.Fprintf(w, "\treturn reqwest.Send(ctx, c.doer, req)\n}\n")
+	}
+
+	if paramsType != "" {
+		if err := g.paramsStruct(w, paramsType, name, otherParams); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parameters merges the parameters of a path item and of one of its
+// operations, which override those with the same name and location.
+func (g *generator) parameters(item *pathItem, op *operation) ([]*parameter, error) {
+	var params []*parameter
+	index := make(map[string]int)
+	for _, p := range append(slices.Clone(item.Parameters), op.Parameters...) {
+		resolved, err := g.doc.parameter(p)
+		if err != nil {
+			return nil, err
+		}
+		key := resolved.In + " " + resolved.Name
+		if i, ok := index[key]; ok {
+			params[i] = resolved
+			continue
+		}
+		index[key] = len(params)
+		params = append(params, resolved)
+	}
+	return params, nil
+}
+
+// pathExpr returns a Go expression building path with its placeholders
+// filled from arguments, which it appends to args.
+func (g *generator) pathExpr(path string, params []*parameter, args *[]string) (string, error) {
+	var parts []string
+	used := make(map[string]bool)
+	for rest := path; rest != ""; {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			parts = append(parts, strconv.Quote(rest))
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated placeholder in %q", path)
+		}
+		if start > 0 {
+			parts = append(parts, strconv.Quote(rest[:start]))
+		}
+		name := rest[start+1 : start+end]
+		rest = rest[start+end+1:]
+
+		i := slices.IndexFunc(params, func(p *parameter) bool { return p.Name == name })
+		if i < 0 {
+			return "", fmt.Errorf("path parameter %q is not declared", name)
+		}
+		typ, err := g.goType(params[i].Schema, "")
+		if err != nil {
+			return "", fmt.Errorf("path parameter %q: %v", name, err)
+		}
+		if !g.isScalar(typ) {
+			return "", fmt.Errorf("path parameter %q has unsupported type %s", name, typ)
+		}
+		arg := argName(name)
+		if !used[arg] {
+			used[arg] = true
+			*args = append(*args, arg+" "+typ)
+		}
+		parts = append(parts, "url.PathEscape("+g.formatValue(arg, typ)+")")
+		g.imports["net/url"] = true
+	}
+	return strings.Join(parts, " + "), nil
+}
+
+// setParameter writes the statements that add a query or header parameter
+// from the params argument to req.
+func (g *generator) setParameter(w *bytes.Buffer, p *parameter) error {
+	typ, err := g.goType(p.Schema, "")
+	if err != nil {
+		return fmt.Errorf("parameter %q: %v", p.Name, err)
+	}
+	field := "params." + exported(p.Name)
+	set := "req.WithQuery(%q, %s)\n"
+	if p.In == "header" {
+		set = "req.Header().Add(%q, %s)\n"
+	}
+	switch {
+	case strings.HasPrefix(typ, "[]") && g.isScalar(typ[2:]):
+		fmt.Fprintf(w, "\tfor _, v := range %s {\n\t\t"+set+"\t}\n", field, p.Name, g.formatValue("v", typ[2:]))
+	case !g.isScalar(typ):
+		return fmt.Errorf("parameter %q has unsupported type %s", p.Name, typ)
+	case p.Required:
+		fmt.Fprintf(w, "\t"+set, p.Name, g.formatValue(field, typ))
+	default:
+		fmt.Fprintf(w, "\tif %s != nil {\n\t\t"+set+"\t}\n", field, p.Name, g.formatValue("*"+field, typ))
+	}
+	return nil
+}
+
+func (g *generator) paramsStruct(w *bytes.Buffer, typeName, method string, params []*parameter) error {
+	fmt.Fprintf(w, "\n// %s holds the query and header parameters of %s.\ntype %s struct {\n", typeName, method, typeName)
+	for _, p := range params {
+		typ, err := g.goType(p.Schema, "")
+		if err != nil {
+			return fmt.Errorf("parameter %q: %v", p.Name, err)
+		}
+		if !p.Required && !strings.HasPrefix(typ, "[]") {
+			typ = "*" + typ
+		}
+		desc := p.Description
+		if desc == "" {
+			desc = fmt.Sprintf("%s is the %q %s parameter.", exported(p.Name), p.Name, p.In)
+		}
+		writeDoc(w, "\t", desc)
+		fmt.Fprintf(w, "\t%s %s\n", exported(p.Name), typ)
+	}
+	fmt.Fprintf(w, "}\n")
+	return nil
+}
+
+// resultType returns the Go type of the JSON body of the operation's first
+// success response, or "" if it has none.
+func (g *generator) resultType(name string, op *operation) (string, error) {
+	for _, code := range slices.Sorted(maps.Keys(op.Responses)) {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		resp, err := g.doc.response(op.Responses[code])
+		if err != nil {
+			return "", err
+		}
+		s, ok := jsonContent(resp.Content)
+		if !ok || s == nil {
+			return "", nil
+		}
+		typ, err := g.goType(s, name+"Response")
+		if err != nil {
+			return "", fmt.Errorf("response %s: %v", code, err)
+		}
+		return typ, nil
+	}
+	return "", nil
+}
+
+// goType returns the Go type for s, queueing a declaration named after hint
+// for inline objects.
+func (g *generator) goType(s *schema, hint string) (string, error) {
+	if s == nil {
+		return "any", nil
+	}
+	if s.Ref != "" {
+		name, err := refName(s.Ref, "schemas")
+		if err != nil {
+			return "", err
+		}
+		if g.doc.Components.Schemas[name] == nil {
+			return "", fmt.Errorf("unknown schema %q", s.Ref)
+		}
+		return exported(name), nil
+	}
+	switch {
+	case len(s.AllOf) == 1:
+		return g.goType(s.AllOf[0], hint)
+	case len(s.AllOf) > 1:
+		return g.inline(hint, s)
+	case len(s.OneOf) > 0 || len(s.AnyOf) > 0:
+		g.imports["encoding/json"] = true
+		return "json.RawMessage", nil
+	}
+	switch s.Type {
+	case "string":
+		if s.Format == "date-time" {
+			g.imports["time"] = true
+			return "time.Time", nil
+		}
+		return "string", nil
+	case "integer":
+		if s.Format == "int32" {
+			return "int32", nil
+		}
+		return "int64", nil
+	case "number":
+		if s.Format == "float" {
+			return "float32", nil
+		}
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		item, err := g.goType(s.Items, hint+"Item")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	case "object", "":
+		if len(s.Properties) > 0 {
+			return g.inline(hint, s)
+		}
+		if extra := s.additional(); extra != nil {
+			value, err := g.goType(extra, hint+"Value")
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + value, nil
+		}
+		if s.Type == "object" {
+			return "map[string]any", nil
+		}
+		return "any", nil
+	}
+	return "", fmt.Errorf("unsupported schema type %q", s.Type)
+}
+
+func (g *generator) inline(hint string, s *schema) (string, error) {
+	if hint == "" {
+		return "", fmt.Errorf("inline objects are not supported here")
+	}
+	name := g.uniqueName(hint)
+	g.pending = append(g.pending, namedSchema{name: name, schema: s, doc: name + " is an inline schema of the API document."})
+	return name, nil
+}
+
+func (g *generator) uniqueName(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+// declare writes the declaration of a named schema.
+func (g *generator) declare(w *bytes.Buffer, named namedSchema) error {
+	name, s := named.name, named.schema
+	fmt.Fprintln(w)
+	if s.Description != "" {
+		writeDoc(w, "", s.Description)
+	} else {
+		writeDoc(w, "", named.doc)
+	}
+	switch {
+	case s.Ref != "":
+		target, err := g.goType(s, name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "type %s = %s\n", name, target)
+	case len(s.AllOf) > 1 || len(s.Properties) > 0:
+		return g.declareStruct(w, name, s)
+	case s.Type == "string" && len(s.Enum) > 0:
+		fmt.Fprintf(w, "type %s string\n\nconst (\n", name)
+		for _, value := range s.Enum {
+			str, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("enum value %v is not a string", value)
+			}
+			fmt.Fprintf(w, "\t%s %s = %q\n", g.uniqueName(name+exported(str)), name, str)
+		}
+		fmt.Fprintf(w, ")\n")
+	default:
+		typ, err := g.goType(s, name+"Item")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "type %s %s\n", name, typ)
+	}
+	return nil
+}
+
+// declareStruct writes a struct for an object schema. The schemas of allOf
+// referencing components are embedded, so their fields are inlined in JSON.
+func (g *generator) declareStruct(w *bytes.Buffer, name string, s *schema) error {
+	var embedded []string
+	properties := maps.Clone(s.Properties)
+	if properties == nil {
+		properties = make(map[string]*schema)
+	}
+	required := slices.Clone(s.Required)
+	for _, part := range s.AllOf {
+		if part.Ref != "" {
+			typ, err := g.goType(part, "")
+			if err != nil {
+				return err
+			}
+			embedded = append(embedded, typ)
+			continue
+		}
+		maps.Copy(properties, part.Properties)
+		required = append(required, part.Required...)
+	}
+
+	fmt.Fprintf(w, "type %s struct {\n", name)
+	for _, typ := range embedded {
+		fmt.Fprintf(w, "\t%s\n", typ)
+	}
+	fields := make(map[string]bool)
+	for _, property := range slices.Sorted(maps.Keys(properties)) {
+		prop := properties[property]
+		field := exported(property)
+		for i := 2; fields[field]; i++ {
+			field = exported(property) + strconv.Itoa(i)
+		}
+		fields[field] = true
+		typ, err := g.goType(prop, name+field)
+		if err != nil {
+			return fmt.Errorf("property %q: %v", property, err)
+		}
+		tag := property
+		optional := !slices.Contains(required, property)
+		if optional {
+			tag += ",omitempty"
+		}
+		if (optional || prop.Nullable) && !isReference(typ) {
+			typ = "*" + typ
+		}
+		writeDoc(w, "\t", prop.Description)
+		fmt.Fprintf(w, "\t%s %s `json:%q`\n", field, typ, tag)
+	}
+	fmt.Fprintf(w, "}\n")
+	return nil
+}
+
+// formatValue returns an expression formatting the value of expr, of type
+// typ, as a string.
+func (g *generator) formatValue(expr, typ string) string {
+	switch typ {
+	case "string":
+		return expr
+	case "time.Time":
+		return expr + ".Format(time.RFC3339)"
+	}
+	g.imports["fmt"] = true
+	return "fmt.Sprint(" + expr + ")"
+}
+
+// isScalar reports whether a parameter of type typ can be sent as text.
+func (g *generator) isScalar(typ string) bool {
+	switch typ {
+	case "string", "bool", "int32", "int64", "float32", "float64", "time.Time":
+		return true
+	}
+	return g.scalars[typ]
+}
+
+// isReference reports whether a zero value of typ already means "absent".
+func isReference(typ string) bool {
+	return strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[") || typ == "any" || typ == "json.RawMessage"
+}
+
+func writeDoc(w *bytes.Buffer, indent, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimRight(line, " \t"); line == "" {
+			fmt.Fprintf(w, "%s//\n", indent)
+		} else {
+			fmt.Fprintf(w, "%s// %s\n", indent, line)
+		}
+	}
+}
+
+// initialisms are written in upper case in Go identifiers.
+var initialisms = map[string]bool{
+	"api": true, "html": true, "http": true, "https": true, "id": true, "ip": true, "json": true,
+	"sql": true, "tls": true, "ttl": true, "uid": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// words splits an OpenAPI name such as "pet_id", "petId" or "X-Request-ID"
+// into words.
+func words(name string) []string {
+	var words []string
+	var current []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if len(current) > 0 {
+				words = append(words, string(current))
+			}
+			current = nil
+			continue
+		case unicode.IsUpper(r) && len(current) > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower {
+				words = append(words, string(current))
+				current = nil
+			}
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+	return words
+}
+
+// exported returns name as an exported Go identifier.
+func exported(name string) string {
+	var b strings.Builder
+	for _, word := range words(name) {
+		if lower := strings.ToLower(word); initialisms[lower] {
+			b.WriteString(strings.ToUpper(word))
+		} else {
+			runes := []rune(word)
+			b.WriteString(string(unicode.ToUpper(runes[0])) + string(runes[1:]))
+		}
+	}
+	id := b.String()
+	if id == "" {
+		return "X"
+	}
+	if unicode.IsDigit(rune(id[0])) {
+		return "N" + id
+	}
+	return id
+}
+
+// argName returns name as an argument name that doesn't clash with keywords
+// or the variables of generated methods.
+func argName(name string) string {
+	parts := words(name)
+	if len(parts) == 0 {
+		return "arg"
+	}
+	id := strings.ToLower(parts[0])
+	for _, word := range parts[1:] {
+		id += exported(word)
+	}
+	if unicode.IsDigit(rune(id[0])) {
+		id = "n" + id
+	}
+	if token.IsKeyword(id) || reservedArgs[id] {
+		id += "Arg"
+	}
+	return id
+}
+
+// operationName names an operation without an operationId after its method
+// and path, e.g. GetPetsByPetID for GET /pets/{petId}.
+func operationName(method, path string) string {
+	name := exported(strings.ToLower(method))
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+		if param, ok := strings.CutPrefix(segment, "{"); ok {
+			name += "By" + exported(strings.TrimSuffix(param, "}"))
+		} else {
+			name += exported(segment)
+		}
+	}
+	return name
+}
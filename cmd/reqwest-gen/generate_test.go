@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func TestGenerate(t *testing.T) {
+	data, err := os.ReadFile("testdata/petstore.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := parseDocument(data)
+	if err != nil {
+		t.Fatalf("parseDocument() error = %v", err)
+	}
+	got, err := generate(doc, "petstore", "petstore.json")
+	if err != nil {
+		t.Fatalf("generate() error = %v", err)
+	}
+	if *update {
+		if err := os.WriteFile("testdata/petstore.golden", got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile("testdata/petstore.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated code differs from testdata/petstore.golden; run go test -update to accept it:\n%s", got)
+	}
+}
+
+// TestGenerate_Client builds the generated client in a scratch module and
+// runs it against a fake server.
+func TestGenerate_Client(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a scratch module")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := os.ReadFile("testdata/petstore.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/petstore\n\ngo 1.24\n\nrequire github.com/rbhujang/reqwest v0.0.0\n\n" +
+			"replace github.com/rbhujang/reqwest => " + root + "\n",
+		"petstore_gen.go": string(generated),
+		"petstore_test.go": `package petstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rbhujang/reqwest"
+)
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method + " " + r.URL.RequestURI() {
+		case "GET /v1/pets?limit=2&status=sold&tags=a&tags=b":
+			if r.Header.Get("X-Request-ID") != "r1" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(` + "`" + `{"items":[{"id":1,"name":"Rex","status":"sold","owner":{"name":"Ada"}}]}` + "`" + `))
+		case "POST /v1/pets":
+			var pet NewPet
+			json.NewDecoder(r.Body).Decode(&pet)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(Pet{NewPet: pet, ID: 7})
+		case "PUT /v1/pets/7/photo":
+			body, _ := io.ReadAll(r.Body)
+			if r.Header.Get("Content-Type") != "image/png" || string(body) != "png" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(` + "`" + `{"message":"no such pet"}` + "`" + `))
+		}
+	}))
+	defer server.Close()
+
+	api := NewClient(reqwest.NewClientBuilder().WithBaseURL(server.URL + "/v1").Build())
+	ctx := context.Background()
+
+	limit, status := int32(2), PetStatusSold
+	requestID := "r1"
+	page, err := api.ListPets(ctx, ListPetsParams{Limit: &limit, Status: &status, Tags: []string{"a", "b"}, XRequestID: &requestID})
+	if err != nil {
+		t.Fatalf("ListPets() error = %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].Name != "Rex" || page.Items[0].Owner.Name == nil {
+		t.Errorf("ListPets() = %+v", page)
+	}
+
+	pet, err := api.CreatePet(ctx, NewPet{Name: "Fido"})
+	if err != nil || pet.ID != 7 || pet.Name != "Fido" {
+		t.Errorf("CreatePet() = %+v, %v", pet, err)
+	}
+
+	if err := api.UploadPhoto(ctx, 7, []byte("png")); err != nil {
+		t.Errorf("UploadPhoto() error = %v", err)
+	}
+
+	var httpErr *reqwest.HTTPError
+	if _, err := api.GetPet(ctx, 404); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("GetPet() error = %v, want an HTTPError", err)
+	}
+}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command("go", "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go test in the generated module failed: %v\n%s", err, out)
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want string
+	}{
+		{"not OpenAPI 3", `{"swagger": "2.0"}`, "unsupported OpenAPI version"},
+		{"undeclared path parameter", `{"openapi": "3.0.0", "paths": {"/pets/{id}": {"get": {"responses": {}}}}}`,
+			`path parameter "id" is not declared`},
+		{"unknown schema", `{"openapi": "3.0.0", "paths": {"/pets": {"get": {"responses": {"200": {"description": "",
+			"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}}}}}}}`, `unknown schema`},
+		{"duplicate operation", `{"openapi": "3.0.0", "paths": {"/a": {"get": {"operationId": "x", "responses": {}}},
+			"/b": {"get": {"operationId": "x", "responses": {}}}}}`, "duplicate operation name X"},
+		{"object query parameter", `{"openapi": "3.0.0", "paths": {"/pets": {"get": {"parameters": [{"name": "f", "in": "query",
+			"schema": {"type": "object"}}], "responses": {}}}}}`, "unsupported type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parseDocument([]byte(tt.doc))
+			if err == nil {
+				_, err = generate(doc, "api", "api.json")
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+	tests := []struct {
+		name, exported, arg string
+	}{
+		{"petId", "PetID", "petID"},
+		{"pet_id", "PetID", "petID"},
+		{"X-Request-ID", "XRequestID", "xRequestID"},
+		{"HTMLPage", "HTMLPage", "htmlPage"},
+		{"2fa", "N2fa", "n2fa"},
+		{"type", "Type", "typeArg"},
+		{"body", "Body", "bodyArg"},
+	}
+	for _, tt := range tests {
+		if got := exported(tt.name); got != tt.exported {
+			t.Errorf("exported(%q) = %q, want %q", tt.name, got, tt.exported)
+		}
+		if got := argName(tt.name); got != tt.arg {
+			t.Errorf("argName(%q) = %q, want %q", tt.name, got, tt.arg)
+		}
+	}
+}
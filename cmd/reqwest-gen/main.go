@@ -0,0 +1,60 @@
+// Command reqwest-gen generates a typed Go client built on reqwest from an
+// OpenAPI 3 document in JSON:
+//
+//	reqwest-gen -spec openapi.json -package petstore -o petstore_gen.go
+//
+// or from a go:generate directive:
+//
+//	//go:generate go run github.com/rbhujang/reqwest/cmd/reqwest-gen -spec openapi.json -package petstore -o petstore_gen.go
+//
+// The generated Client has a method per operation, taking path parameters as
+// arguments, query and header parameters as a struct, and the request body
+// as its model type, and returning the decoded response model. Requests go
+// through the reqwest.Doer passed to NewClient, so the retries, middleware
+// and other policies of the client apply.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	spec := flag.String("spec", "", "OpenAPI 3 document to read, in JSON")
+	pkg := flag.String("package", "api", "package name of the generated code")
+	out := flag.String("o", "", "file to write; standard output if empty")
+	flag.Parse()
+	if *spec == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*spec, *pkg, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "reqwest-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(spec, pkg, out string) error {
+	data, err := os.ReadFile(spec)
+	if err != nil {
+		return fmt.Errorf("failed to read OpenAPI document: %v", err)
+	}
+	doc, err := parseDocument(data)
+	if err != nil {
+		return err
+	}
+	source, err := generate(doc, pkg, filepath.Base(spec))
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+	if err := os.WriteFile(out, source, 0o644); err != nil {
+		return fmt.Errorf("failed to write generated code: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// document is the part of an OpenAPI 3 document the generator reads.
+type document struct {
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Servers []struct {
+		URL string `json:"url"`
+	} `json:"servers"`
+	Paths      map[string]*pathItem `json:"paths"`
+	Components struct {
+		Schemas       map[string]*schema      `json:"schemas"`
+		Parameters    map[string]*parameter   `json:"parameters"`
+		RequestBodies map[string]*requestBody `json:"requestBodies"`
+		Responses     map[string]*response    `json:"responses"`
+	} `json:"components"`
+}
+
+type pathItem struct {
+	Parameters []*parameter `json:"parameters"`
+	Get        *operation   `json:"get"`
+	Put        *operation   `json:"put"`
+	Post       *operation   `json:"post"`
+	Delete     *operation   `json:"delete"`
+	Options    *operation   `json:"options"`
+	Head       *operation   `json:"head"`
+	Patch      *operation   `json:"patch"`
+}
+
+// methodOperation is an operation of a path item and its HTTP method.
+type methodOperation struct {
+	method string
+	op     *operation
+}
+
+// operations returns the operations of the path item in a fixed order.
+func (p *pathItem) operations() []methodOperation {
+	all := []methodOperation{
+		{"GET", p.Get}, {"PUT", p.Put}, {"POST", p.Post}, {"DELETE", p.Delete},
+		{"OPTIONS", p.Options}, {"HEAD", p.Head}, {"PATCH", p.Patch},
+	}
+	ops := all[:0]
+	for _, entry := range all {
+		if entry.op != nil {
+			ops = append(ops, entry)
+		}
+	}
+	return ops
+}
+
+type operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary"`
+	Description string               `json:"description"`
+	Deprecated  bool                 `json:"deprecated"`
+	Parameters  []*parameter         `json:"parameters"`
+	RequestBody *requestBody         `json:"requestBody"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type parameter struct {
+	Ref         string  `json:"$ref"`
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description"`
+	Required    bool    `json:"required"`
+	Schema      *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Ref      string               `json:"$ref"`
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type response struct {
+	Ref         string               `json:"$ref"`
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 schemaType         `json:"type"`
+	Format               string             `json:"format"`
+	Description          string             `json:"description"`
+	Nullable             bool               `json:"nullable"`
+	Enum                 []any              `json:"enum"`
+	Items                *schema            `json:"items"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	AllOf                []*schema          `json:"allOf"`
+	OneOf                []*schema          `json:"oneOf"`
+	AnyOf                []*schema          `json:"anyOf"`
+}
+
+// schemaType is the type of a schema, which OpenAPI 3.1 allows to be a list
+// such as ["string", "null"].
+type schemaType string
+
+func (t *schemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaType(single)
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings")
+	}
+	for _, name := range list {
+		if name != "null" {
+			*t = schemaType(name)
+			return nil
+		}
+	}
+	return nil
+}
+
+// additional returns the schema of additionalProperties, or nil if it isn't a
+// schema.
+func (s *schema) additional() *schema {
+	var extra schema
+	if len(s.AdditionalProperties) == 0 || json.Unmarshal(s.AdditionalProperties, &extra) != nil {
+		return nil
+	}
+	return &extra
+}
+
+func (s *schema) requires(property string) bool {
+	for _, name := range s.Required {
+		if name == property {
+			return true
+		}
+	}
+	return false
+}
+
+func parseDocument(data []byte) (*document, error) {
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q, want 3.x", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// refName returns the component name a local $ref points to in section,
+// e.g. "Pet" for "#/components/schemas/Pet".
+func refName(ref, section string) (string, error) {
+	name, ok := strings.CutPrefix(ref, "#/components/"+section+"/")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("unsupported $ref %q", ref)
+	}
+	return name, nil
+}
+
+func (d *document) parameter(p *parameter) (*parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, err := refName(p.Ref, "parameters")
+	if err != nil {
+		return nil, err
+	}
+	if resolved := d.Components.Parameters[name]; resolved != nil {
+		return resolved, nil
+	}
+	return nil, fmt.Errorf("unknown parameter %q", p.Ref)
+}
+
+func (d *document) requestBody(b *requestBody) (*requestBody, error) {
+	if b == nil || b.Ref == "" {
+		return b, nil
+	}
+	name, err := refName(b.Ref, "requestBodies")
+	if err != nil {
+		return nil, err
+	}
+	if resolved := d.Components.RequestBodies[name]; resolved != nil {
+		return resolved, nil
+	}
+	return nil, fmt.Errorf("unknown request body %q", b.Ref)
+}
+
+func (d *document) response(r *response) (*response, error) {
+	if r.Ref == "" {
+		return r, nil
+	}
+	name, err := refName(r.Ref, "responses")
+	if err != nil {
+		return nil, err
+	}
+	if resolved := d.Components.Responses[name]; resolved != nil {
+		return resolved, nil
+	}
+	return nil, fmt.Errorf("unknown response %q", r.Ref)
+}
+
+// jsonContent returns the schema of the JSON media type of content, if any.
+func jsonContent(content map[string]mediaType) (*schema, bool) {
+	for _, contentType := range slices.Sorted(maps.Keys(content)) {
+		base, _, _ := strings.Cut(contentType, ";")
+		if base == "application/json" || strings.HasSuffix(base, "+json") {
+			return content[contentType].Schema, true
+		}
+	}
+	return nil, false
+}
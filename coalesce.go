@@ -0,0 +1,139 @@
+package reqwest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// coalescer shares one upstream call between identical concurrent GETs.
+type coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+// coalescedCall is an upstream call in flight. It runs detached from any one
+// caller's context and is cancelled once every caller has given up.
+type coalescedCall struct {
+	done    chan struct{}
+	waiters int
+	cancel  context.CancelFunc
+	resp    *Response
+	body    []byte
+	err     error
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{calls: make(map[string]*coalescedCall)}
+}
+
+// coalesceKey identifies requests that can share a response: same method,
+// URL and headers.
+func coalesceKey(request *Request, url string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", request.method, url)
+	names := make([]string, 0, len(request.header))
+	for name := range request.header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Fprintf(h, "%s: %q\n", name, request.header[name])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// do executes request through fetch, or waits for an identical call already
+// in flight. Every caller gets its own copy of the fully read response.
+func (co *coalescer) do(ctx context.Context, key string, fetch func(context.Context) (*Response, error)) (*Response, error) {
+	co.mu.Lock()
+	call, shared := co.calls[key]
+	if !shared {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &coalescedCall{done: make(chan struct{}), cancel: cancel}
+		co.calls[key] = call
+		go co.run(callCtx, key, call, fetch)
+	}
+	call.waiters++
+	co.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		co.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			co.forget(key, call)
+		}
+		co.mu.Unlock()
+		return nil, ctx.Err()
+	}
+	if call.err != nil {
+		return nil, call.err
+	}
+	resp := call.resp.withBody(call.body)
+	resp.coalesced = shared
+	return resp, nil
+}
+
+func (co *coalescer) run(ctx context.Context, key string, call *coalescedCall, fetch func(context.Context) (*Response, error)) {
+	defer call.cancel()
+	call.resp, call.err = fetch(ctx)
+	if call.err == nil && call.resp.body != nil {
+		call.body, call.err = call.resp.readBody()
+		if call.err != nil {
+			call.err = fmt.Errorf("failed to read response body: %w", call.err)
+		}
+	}
+	co.mu.Lock()
+	co.forget(key, call)
+	co.mu.Unlock()
+	close(call.done)
+}
+
+// forget lets later requests for key start a new call. co.mu must be held.
+func (co *coalescer) forget(key string, call *coalescedCall) {
+	if co.calls[key] == call {
+		delete(co.calls, key)
+	}
+}
+
+// executeCoalesced shares the response of GETs with identical concurrent
+// ones when the client coalesces requests.
+func (c *HTTPClient) executeCoalesced(ctx context.Context, request *Request, next func(context.Context, *Request) (*Response, error)) (*Response, error) {
+	if c.coalescer == nil || request.method != http.MethodGet || !coalescable(ctx, request) {
+		return next(ctx, request)
+	}
+	key := coalesceKey(request, c.buildURL(request.url))
+	return c.coalescer.do(ctx, key, func(ctx context.Context) (*Response, error) {
+		return next(ctx, request)
+	})
+}
+
+// coalescable reports whether request may share a call. The call runs with
+// the options and context values of its first caller, so requests with their
+// own retry policy, timeout, byte budget, checksum or values run alone.
+func coalescable(ctx context.Context, request *Request) bool {
+	if request.retryOverride || request.timeout > 0 || request.byteBudget > 0 ||
+		request.checksum != nil || len(request.values) > 0 {
+		return false
+	}
+	_, overridden := timeoutOverride(ctx)
+	return !overridden && !noRetry(ctx) && len(byteBudgets(ctx)) == 0
+}
+
+// withBody returns a copy of r that reads body.
+func (r *Response) withBody(body []byte) *Response {
+	clone := *r
+	clone.header = r.header.Clone()
+	clone.body = io.NopCloser(bytes.NewReader(body))
+	clone.bodyRetry = nil
+	return &clone
+}
@@ -0,0 +1,157 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientBuilder_WithRequestCoalescing(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.URL.Path == "/slow" {
+			<-release
+		}
+		w.Header().Set("X-Path", r.URL.Path)
+		w.Write([]byte("payload"))
+	}))
+	defer server.Close()
+	cli := NewClientBuilder().WithBaseURL(server.URL).WithRequestCoalescing().Build()
+
+	t.Run("identical GETs share one call", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		const callers = 5
+		var wg sync.WaitGroup
+		var coalesced int32
+		errs := make(chan error, callers)
+		for range callers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := cli.Get(context.Background(), "/slow")
+				if err != nil {
+					errs <- err
+					return
+				}
+				body, err := resp.Bytes()
+				if err != nil || string(body) != "payload" || resp.Header().Get("X-Path") != "/slow" {
+					errs <- errors.New("unexpected response " + string(body))
+				}
+				if resp.Coalesced() {
+					atomic.AddInt32(&coalesced, 1)
+				}
+			}()
+		}
+		waitForCallers(t, cli.coalescer, callers)
+		close(release)
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Error(err)
+		}
+		if got := atomic.LoadInt32(&hits); got != 1 {
+			t.Errorf("Expected 1 upstream call, got %d", got)
+		}
+		if got := atomic.LoadInt32(&coalesced); got != callers-1 {
+			t.Errorf("Expected %d coalesced responses, got %d", callers-1, got)
+		}
+	})
+
+	t.Run("different headers are not shared", func(t *testing.T) {
+		a := coalesceKey(NewRequest(http.MethodGet, "/x").WithHeader("Accept", "text/plain"), "/x")
+		b := coalesceKey(NewRequest(http.MethodGet, "/x").WithHeader("Accept", "application/json"), "/x")
+		if a == b || a != coalesceKey(NewRequest(http.MethodGet, "/x").WithHeader("Accept", "text/plain"), "/x") {
+			t.Error("Expected keys to depend on headers only")
+		}
+	})
+
+	t.Run("requests with their own options run alone", func(t *testing.T) {
+		get := func() *Request { return NewRequest(http.MethodGet, "/x") }
+		ctx := context.Background()
+		testCases := []struct {
+			name    string
+			ctx     context.Context
+			request *Request
+			want    bool
+		}{
+			{"plain", ctx, get(), true},
+			{"retry override", ctx, get().WithoutRetries(), false},
+			{"timeout", ctx, get().WithTimeout(time.Second), false},
+			{"byte budget", ctx, get().WithByteBudget(1024), false},
+			{"checksum", ctx, get().WithServerChecksum(), false},
+			{"values", ctx, get().WithValue("tenant", "a"), false},
+			{"no retry context", ContextWithNoRetry(ctx), get(), false},
+			{"timeout context", ContextWithTimeoutOverride(ctx, time.Second), get(), false},
+			{"group byte budget", withByteBudget(ctx, &byteBudget{limit: 1024}), get(), false},
+		}
+		for _, tc := range testCases {
+			if got := coalescable(tc.ctx, tc.request); got != tc.want {
+				t.Errorf("%s: coalescable = %v, want %v", tc.name, got, tc.want)
+			}
+		}
+	})
+
+	t.Run("a caller giving up does not fail the others", func(t *testing.T) {
+		co := newCoalescer()
+		started := make(chan struct{})
+		finish := make(chan struct{})
+		fetch := func(ctx context.Context) (*Response, error) {
+			close(started)
+			select {
+			case <-finish:
+				return NewResponse(http.StatusOK, nil, []byte("ok")), nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan error, 1)
+		go func() {
+			_, err := co.do(ctx, "k", fetch)
+			first <- err
+		}()
+		<-started
+		second := make(chan *Response, 1)
+		go func() {
+			resp, _ := co.do(context.Background(), "k", fetch)
+			second <- resp
+		}()
+		waitForCallers(t, co, 2)
+		cancel()
+		if err := <-first; !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the cancelled caller to fail, got %v", err)
+		}
+		close(finish)
+		if resp := <-second; resp == nil {
+			t.Error("Expected the remaining caller to get the response")
+		}
+	})
+}
+
+// waitForCallers waits until n callers wait on co's only call.
+func waitForCallers(t *testing.T, co *coalescer, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		co.mu.Lock()
+		waiting := 0
+		for _, call := range co.calls {
+			waiting = call.waiters
+		}
+		co.mu.Unlock()
+		if waiting >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d waiting callers, got %d", n, waiting)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
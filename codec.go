@@ -0,0 +1,120 @@
+package reqwest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// ErrUnexpectedContentType is returned when decoding a response whose
+// Content-Type doesn't match the type the request declared with Expect.
+var ErrUnexpectedContentType = errors.New("unexpected content type")
+
+// Codec marshals and unmarshals one media type, e.g. "application/msgpack".
+type Codec interface {
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONCodec handles application/json and any "+json" media type. When
+// Response.Decode selects it, the client's JSON decoding settings apply.
+type JSONCodec struct{}
+
+func (JSONCodec) ContentType() string                { return "application/json" }
+func (JSONCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (JSONCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// XMLCodec handles application/xml, text/xml and any "+xml" media type.
+type XMLCodec struct{}
+
+func (XMLCodec) ContentType() string                { return "application/xml" }
+func (XMLCodec) Marshal(v any) ([]byte, error)      { return xml.Marshal(v) }
+func (XMLCodec) Unmarshal(data []byte, v any) error { return xml.Unmarshal(data, v) }
+
+var defaultCodecs = map[string]Codec{
+	"application/json": JSONCodec{},
+	"application/xml":  XMLCodec{},
+}
+
+// codecFor finds the codec for a Content-Type header value. Structured syntax
+// suffixes ("application/vnd.foo.v2+json") and text/ aliases fall back to the
+// codec of the base application/ type. A missing Content-Type is treated as
+// JSON.
+func (cfg decodeConfig) codecFor(contentType string) (Codec, error) {
+	if contentType == "" {
+		return cfg.lookupCodec("application/json"), nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type %q: %v", contentType, err)
+	}
+	candidates := []string{mediaType}
+	if i := strings.LastIndexByte(mediaType, '+'); i >= 0 {
+		candidates = append(candidates, "application/"+mediaType[i+1:])
+	}
+	if subtype, ok := strings.CutPrefix(mediaType, "text/"); ok {
+		candidates = append(candidates, "application/"+subtype)
+	}
+	for _, candidate := range candidates {
+		if codec := cfg.lookupCodec(candidate); codec != nil {
+			return codec, nil
+		}
+	}
+	return nil, fmt.Errorf("no codec registered for content type %q", mediaType)
+}
+
+func (cfg decodeConfig) lookupCodec(mediaType string) Codec {
+	if codec, ok := cfg.codecs[mediaType]; ok {
+		return codec
+	}
+	return defaultCodecs[mediaType]
+}
+
+// Decode reads and closes the body, decoding it with the codec registered
+// for the response Content-Type. JSON bodies are decoded like JSON, honouring
+// opts.
+func (r *Response) Decode(v any, opts ...DecodeOption) error {
+	if err := r.checkContentType(); err != nil {
+		return err
+	}
+	codec, err := r.decode.codecFor(r.header.Get("Content-Type"))
+	if err != nil {
+		r.body.Close()
+		return fmt.Errorf("failed to decode response body: %v", err)
+	}
+	if _, ok := codec.(JSONCodec); ok {
+		return r.JSON(v, opts...)
+	}
+	data, err := r.readBody()
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if err := codec.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s response body: %v", codec.ContentType(), err)
+	}
+	return nil
+}
+
+// checkContentType closes the body and fails if the response isn't of the
+// media type the request expected. A missing or generic text/plain
+// Content-Type is accepted, since many servers don't label what they send;
+// the check is for answers such as an HTML error page from a proxy.
+func (r *Response) checkContentType() error {
+	contentType := r.header.Get("Content-Type")
+	if r.expect == nil || contentType == "" {
+		return nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "text/plain" {
+		return nil
+	}
+	codec, err := r.decode.codecFor(contentType)
+	if err == nil && codec.ContentType() == r.expect.ContentType() {
+		return nil
+	}
+	r.body.Close()
+	return fmt.Errorf("%w: got %q, want %s", ErrUnexpectedContentType, contentType, r.expect.ContentType())
+}
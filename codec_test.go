@@ -0,0 +1,202 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// csvCodec is a toy proprietary format: one comma-separated record.
+type csvCodec struct{}
+
+func (csvCodec) ContentType() string { return "text/csv" }
+
+func (csvCodec) Marshal(v any) ([]byte, error) {
+	return []byte(strings.Join(v.([]string), ",")), nil
+}
+
+func (csvCodec) Unmarshal(data []byte, v any) error {
+	*v.(*[]string) = strings.Split(string(data), ",")
+	return nil
+}
+
+func serveContent(contentType, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contentType == "" {
+			// A nil value stops net/http from sniffing a content type
+			w.Header()["Content-Type"] = nil
+		} else {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.Write([]byte(body))
+	}))
+}
+
+func TestResponse_Decode(t *testing.T) {
+	type user struct {
+		Name string `json:"name" xml:"name"`
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"json", "application/json; charset=utf-8", `{"name":"ada"}`},
+		{"vendor json", "application/vnd.foo.v2+json", `{"name":"ada"}`},
+		{"missing content type", "", `{"name":"ada"}`},
+		{"xml", "application/xml", `<user><name>ada</name></user>`},
+		{"text xml", "text/xml", `<user><name>ada</name></user>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := serveContent(tt.contentType, tt.body)
+			defer server.Close()
+
+			resp, err := NewClientBuilder().Build().Get(context.Background(), server.URL)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var out user
+			if err := resp.Decode(&out); err != nil {
+				t.Fatalf("Unexpected decode error: %v", err)
+			}
+			if out.Name != "ada" {
+				t.Errorf("Expected name ada, got %q", out.Name)
+			}
+		})
+	}
+
+	t.Run("custom codec", func(t *testing.T) {
+		server := serveContent("text/csv", "a,b,c")
+		defer server.Close()
+
+		resp, err := NewClientBuilder().WithCodec(csvCodec{}).Build().Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var out []string
+		if err := resp.Decode(&out); err != nil {
+			t.Fatalf("Unexpected decode error: %v", err)
+		}
+		if len(out) != 3 || out[2] != "c" {
+			t.Errorf("Expected [a b c], got %v", out)
+		}
+	})
+
+	t.Run("unknown content type", func(t *testing.T) {
+		server := serveContent("application/msgpack", "\x81")
+		defer server.Close()
+
+		resp, err := NewClientBuilder().Build().Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var out any
+		if err := resp.Decode(&out); err == nil || !strings.Contains(err.Error(), "no codec") {
+			t.Errorf("Expected missing codec error, got %v", err)
+		}
+	})
+
+	t.Run("json honours decode options", func(t *testing.T) {
+		server := serveContent("application/json", `{"amount":0.1}`)
+		defer server.Close()
+
+		resp, err := NewClientBuilder().Build().Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var out map[string]any
+		if err := resp.Decode(&out, DecodeNumbersAs(NumberJSON)); err != nil {
+			t.Fatalf("Unexpected decode error: %v", err)
+		}
+		if _, ok := out["amount"].(json.Number); !ok {
+			t.Errorf("Expected json.Number, got %T", out["amount"])
+		}
+	})
+}
+
+func TestRequest_Expect(t *testing.T) {
+	var accept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		switch r.URL.Path {
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html>502 Bad Gateway</html>"))
+		case "/csv":
+			w.Header().Set("Content-Type", "text/csv")
+			w.Write([]byte("a,b"))
+		default:
+			w.Header().Set("Content-Type", "application/vnd.example.v2+json")
+			w.Write([]byte(`{"ok":true}`))
+		}
+	}))
+	defer server.Close()
+	cli := NewClientBuilder().WithBaseURL(server.URL).WithCodec(csvCodec{}).Build()
+
+	t.Run("sets Accept", func(t *testing.T) {
+		resp, err := cli.Do(context.Background(), NewRequest(http.MethodGet, "/json").ExpectJSON())
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		if accept != "application/json" {
+			t.Errorf("Accept = %q, want application/json", accept)
+		}
+		var body struct{ OK bool }
+		if err := resp.JSON(&body); err != nil || !body.OK {
+			t.Errorf("JSON() = %+v, %v; want a +json body to match", body, err)
+		}
+	})
+
+	t.Run("keeps explicit Accept", func(t *testing.T) {
+		req := NewRequest(http.MethodGet, "/json").WithHeader("Accept", "application/vnd.example.v2+json").ExpectJSON()
+		if _, err := cli.Do(context.Background(), req); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		if accept != "application/vnd.example.v2+json" {
+			t.Errorf("Accept = %q, want the explicit value", accept)
+		}
+	})
+
+	t.Run("unexpected type", func(t *testing.T) {
+		resp, err := cli.Do(context.Background(), NewRequest(http.MethodGet, "/html").ExpectJSON())
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		var body map[string]any
+		if err := resp.JSON(&body); !errors.Is(err, ErrUnexpectedContentType) {
+			t.Errorf("JSON() error = %v, want ErrUnexpectedContentType", err)
+		}
+	})
+
+	t.Run("custom codec", func(t *testing.T) {
+		resp, err := cli.Do(context.Background(), NewRequest(http.MethodGet, "/csv").Expect(csvCodec{}))
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		if accept != "text/csv" {
+			t.Errorf("Accept = %q, want text/csv", accept)
+		}
+		var record []string
+		if err := resp.Decode(&record); err != nil || len(record) != 2 {
+			t.Errorf("Decode() = %v, %v", record, err)
+		}
+	})
+
+	t.Run("GoJSON", func(t *testing.T) {
+		g := cli.Group(context.Background())
+		result := GoJSON[map[string]any](g, NewRequest(http.MethodGet, "/html"))
+		g.Wait()
+		if _, err := result.Get(); !errors.Is(err, ErrUnexpectedContentType) {
+			t.Errorf("Get() error = %v, want ErrUnexpectedContentType", err)
+		}
+		if accept != "application/json" {
+			t.Errorf("Accept = %q, want application/json", accept)
+		}
+	})
+}
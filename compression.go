@@ -0,0 +1,110 @@
+package reqwest
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// CompressionStats describes how a response body was compressed on the
+// wire. It is reported once the body is closed.
+type CompressionStats struct {
+	Method string
+	URL    string
+	// AcceptEncoding is what the client offered; ContentEncoding is what the
+	// server used, empty for an uncompressed body.
+	AcceptEncoding  string
+	ContentEncoding string
+	// WireBytes and DecodedBytes count the body bytes read before and after
+	// decoding.
+	WireBytes    int64
+	DecodedBytes int64
+}
+
+// Ratio returns DecodedBytes / WireBytes, e.g. 4 when compression saved
+// three quarters of the bandwidth, or 0 if no bytes were read.
+func (s CompressionStats) Ratio() float64 {
+	if s.WireBytes == 0 {
+		return 0
+	}
+	return float64(s.DecodedBytes) / float64(s.WireBytes)
+}
+
+// IgnoredAcceptEncoding reports whether the server sent an uncompressed body
+// although the client offered compression.
+func (s CompressionStats) IgnoredAcceptEncoding() bool {
+	return s.AcceptEncoding != "" && s.ContentEncoding == ""
+}
+
+func gzipDecoder(body io.ReadCloser) (io.ReadCloser, error) {
+	return gzip.NewReader(body)
+}
+
+// compressionMeter counts a response body's bytes on both sides of the
+// content decoder.
+type compressionMeter struct {
+	mu     sync.Mutex
+	stats  CompressionStats
+	report func(CompressionStats)
+	once   sync.Once
+}
+
+func newCompressionMeter(resp *http.Response, encoding string, report func(CompressionStats)) *compressionMeter {
+	return &compressionMeter{
+		stats: CompressionStats{
+			Method:          resp.Request.Method,
+			URL:             resp.Request.URL.String(),
+			AcceptEncoding:  resp.Request.Header.Get("Accept-Encoding"),
+			ContentEncoding: encoding,
+		},
+		report: report,
+	}
+}
+
+func (m *compressionMeter) add(n *int64, bytes int) {
+	m.mu.Lock()
+	*n += int64(bytes)
+	m.mu.Unlock()
+}
+
+// wire counts the raw body.
+func (m *compressionMeter) wire(body io.ReadCloser) io.ReadCloser {
+	return &countingBody{ReadCloser: body, count: func(n int) { m.add(&m.stats.WireBytes, n) }}
+}
+
+// decoded counts the decoded body and reports when it is closed.
+func (m *compressionMeter) decoded(body io.ReadCloser) io.ReadCloser {
+	return &countingBody{
+		ReadCloser: body,
+		count:      func(n int) { m.add(&m.stats.DecodedBytes, n) },
+		closed: func() {
+			m.once.Do(func() {
+				m.mu.Lock()
+				stats := m.stats
+				m.mu.Unlock()
+				m.report(stats)
+			})
+		},
+	}
+}
+
+type countingBody struct {
+	io.ReadCloser
+	count  func(int)
+	closed func()
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.count(n)
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.closed != nil {
+		b.closed()
+	}
+	return err
+}
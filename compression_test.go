@@ -0,0 +1,80 @@
+package reqwest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientBuilder_WithCompressionMetrics(t *testing.T) {
+	payload := strings.Repeat("reqwest compresses well. ", 400)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/plain" || !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			_, _ = w.Write([]byte(payload))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		if r.Method == http.MethodHead {
+			return
+		}
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		_, _ = gz.Write([]byte(payload))
+		gz.Close()
+		_, _ = w.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	var reported []CompressionStats
+	cli := NewClientBuilder().
+		WithBaseURL(server.URL).
+		WithCompressionMetrics(func(stats CompressionStats) { reported = append(reported, stats) }).
+		Build()
+	fetch := func(t *testing.T, method, url string) (string, CompressionStats) {
+		t.Helper()
+		reported = nil
+		resp, err := cli.Do(context.TODO(), NewRequest(method, url))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		body, err := resp.Bytes()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(reported) != 1 {
+			t.Fatalf("Expected one report, got %d", len(reported))
+		}
+		return string(body), reported[0]
+	}
+
+	t.Run("gzip ratio", func(t *testing.T) {
+		body, stats := fetch(t, http.MethodGet, "/data")
+		if body != payload {
+			t.Fatalf("Expected the decoded payload, got %d bytes", len(body))
+		}
+		if stats.ContentEncoding != "gzip" || stats.AcceptEncoding != "gzip" || stats.Method != http.MethodGet {
+			t.Errorf("Unexpected negotiation: %+v", stats)
+		}
+		if stats.DecodedBytes != int64(len(payload)) || stats.WireBytes >= stats.DecodedBytes || stats.Ratio() <= 1 {
+			t.Errorf("Expected compressed wire bytes, got %+v (ratio %.2f)", stats, stats.Ratio())
+		}
+	})
+
+	t.Run("upstream ignoring Accept-Encoding", func(t *testing.T) {
+		_, stats := fetch(t, http.MethodGet, "/plain")
+		if !stats.IgnoredAcceptEncoding() || stats.Ratio() != 1 {
+			t.Errorf("Expected an uncompressed response to be flagged, got %+v", stats)
+		}
+	})
+
+	t.Run("HEAD with Content-Encoding", func(t *testing.T) {
+		_, stats := fetch(t, http.MethodHead, "/data")
+		if stats.WireBytes != 0 || stats.Ratio() != 0 {
+			t.Errorf("Expected no body bytes, got %+v", stats)
+		}
+	})
+}
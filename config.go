@@ -0,0 +1,176 @@
+package reqwest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Config declares client settings, e.g. decoded from a JSON or YAML file, so
+// services can configure clients without long builder chains:
+//
+//	var cfg reqwest.Config
+//	if err := json.Unmarshal(data, &cfg); err != nil { ... }
+//	builder, err := reqwest.FromConfig(cfg)
+//	if err != nil { ... }
+//	client := builder.Build()
+//
+// Zero values leave the builder defaults in place.
+type Config struct {
+	BaseURL  string            `json:"base_url,omitempty" yaml:"base_url,omitempty"`
+	Timeouts TimeoutSettings   `json:"timeouts,omitzero" yaml:"timeouts,omitempty"`
+	Retry    *RetrySettings    `json:"retry,omitempty" yaml:"retry,omitempty"`
+	TLS      *TLSSettings      `json:"tls,omitempty" yaml:"tls,omitempty"`
+	Proxy    string            `json:"proxy,omitempty" yaml:"proxy,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+}
+
+// TimeoutSettings is the timeouts part of a Config. Request bounds every call
+// as WithTimeout does; the others are transport timeouts.
+type TimeoutSettings struct {
+	Request            Duration `json:"request,omitempty" yaml:"request,omitempty"`
+	TLSHandshake       Duration `json:"tls_handshake,omitempty" yaml:"tls_handshake,omitempty"`
+	ResponseHeader     Duration `json:"response_header,omitempty" yaml:"response_header,omitempty"`
+	IdleConnection     Duration `json:"idle_connection,omitempty" yaml:"idle_connection,omitempty"`
+	ConnectionLifetime Duration `json:"connection_lifetime,omitempty" yaml:"connection_lifetime,omitempty"`
+}
+
+// RetrySettings is the retry part of a Config. It enables retries with
+// exponential backoff; zero fields use the defaults of the retry and backoff
+// builders.
+type RetrySettings struct {
+	MaxRetries  int      `json:"max_retries,omitempty" yaml:"max_retries,omitempty"`
+	StatusCodes []int    `json:"status_codes,omitempty" yaml:"status_codes,omitempty"`
+	BaseDelay   Duration `json:"base_delay,omitempty" yaml:"base_delay,omitempty"`
+	MaxDelay    Duration `json:"max_delay,omitempty" yaml:"max_delay,omitempty"`
+	Multiplier  float64  `json:"multiplier,omitempty" yaml:"multiplier,omitempty"`
+	Jitter      bool     `json:"jitter,omitempty" yaml:"jitter,omitempty"`
+}
+
+// TLSSettings is the TLS part of a Config. Files are PEM encoded.
+type TLSSettings struct {
+	CAFile             string `json:"ca_file,omitempty" yaml:"ca_file,omitempty"`
+	CertFile           string `json:"cert_file,omitempty" yaml:"cert_file,omitempty"`
+	KeyFile            string `json:"key_file,omitempty" yaml:"key_file,omitempty"`
+	ServerName         string `json:"server_name,omitempty" yaml:"server_name,omitempty"`
+	MinVersion         string `json:"min_version,omitempty" yaml:"min_version,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty"`
+}
+
+// Duration is a time.Duration written as a string such as "1.5s" in JSON and
+// YAML.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// FromConfig returns a builder configured from cfg. Further options can be
+// chained before calling Build.
+func FromConfig(cfg Config) (*ClientBuilder, error) {
+	cb := NewClientBuilder()
+	if cfg.BaseURL != "" {
+		cb = cb.WithBaseURL(cfg.BaseURL)
+	}
+	if cfg.Timeouts.Request > 0 {
+		cb = cb.WithTimeout(time.Duration(cfg.Timeouts.Request))
+	}
+	if timeouts := cfg.Timeouts.transport(); timeouts != (Timeouts{}) {
+		cb = cb.WithTransportTimeouts(timeouts)
+	}
+	if cfg.Retry != nil {
+		cb = cb.WithRetryConfig(cfg.Retry.build())
+	}
+	if cfg.TLS != nil {
+		config, err := cfg.TLS.build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure tls: %v", err)
+		}
+		cb = cb.WithTLSConfig(config)
+	}
+	if cfg.Proxy != "" {
+		proxy, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy url: %v", err)
+		}
+		cb = cb.WithProxy(http.ProxyURL(proxy))
+	}
+	for name, value := range cfg.Headers {
+		cb = cb.WithDefaultHeader(name, value)
+	}
+	return cb, nil
+}
+
+func (t TimeoutSettings) transport() Timeouts {
+	return Timeouts{
+		TLSHandshake:       time.Duration(t.TLSHandshake),
+		ResponseHeader:     time.Duration(t.ResponseHeader),
+		IdleConnection:     time.Duration(t.IdleConnection),
+		ConnectionLifetime: time.Duration(t.ConnectionLifetime),
+	}
+}
+
+func (r *RetrySettings) build() *RetryConfig {
+	retry := NewRetryConfigBuilder().WithBackoffStrategy(NewExponentialBackoffBuilder().
+		WithBaseDelay(time.Duration(r.BaseDelay)).
+		WithMaxDelay(time.Duration(r.MaxDelay)).
+		WithMultiplier(r.Multiplier).
+		WithJitter(r.Jitter).
+		Build())
+	if r.MaxRetries > 0 {
+		retry = retry.WithMaxRetries(r.MaxRetries)
+	}
+	if len(r.StatusCodes) > 0 {
+		retry = retry.WithRetryableStatusCodes(r.StatusCodes)
+	}
+	return retry.Build()
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func (t *TLSSettings) build() (*tls.Config, error) {
+	config := &tls.Config{ServerName: t.ServerName, InsecureSkipVerify: t.InsecureSkipVerify}
+	if t.MinVersion != "" {
+		version, ok := tlsVersions[t.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown min_version %q", t.MinVersion)
+		}
+		config.MinVersion = version
+	}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", t.CAFile)
+		}
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
@@ -0,0 +1,117 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromConfig(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		var cfg Config
+		err := json.Unmarshal([]byte(`{
+			"base_url": "https://api.example.com/",
+			"timeouts": {"request": "2.5s", "response_header": "1s", "connection_lifetime": "5m"},
+			"retry": {"max_retries": 4, "status_codes": [503], "base_delay": "50ms"},
+			"headers": {"X-Team": "payments"}
+		}`), &cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		builder, err := FromConfig(cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		cli := builder.Build()
+		if got := cli.BaseURL(); got != "https://api.example.com" {
+			t.Errorf("Expected the base URL, got %q", got)
+		}
+		if got := cli.Policy().Timeout; got != 2500*time.Millisecond {
+			t.Errorf("Expected a 2.5s timeout, got %v", got)
+		}
+		if got := cli.Timeouts(); got.ResponseHeader != time.Second || got.ConnectionLifetime != 5*time.Minute {
+			t.Errorf("Expected the transport timeouts, got %+v", got)
+		}
+		want := "up to 4 retries on [503], exponential backoff from 50ms ×2 up to 10s"
+		if got := cli.RetryPolicy().String(); got != want {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+		if got := cli.defaults.snapshot().Get("X-Team"); got != "payments" {
+			t.Errorf("Expected the default header, got %q", got)
+		}
+	})
+
+	t.Run("request timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer server.Close()
+		builder, _ := FromConfig(Config{Timeouts: TimeoutSettings{Request: Duration(20 * time.Millisecond)}})
+		_, err := builder.Build().Get(context.Background(), server.URL)
+		if err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+			t.Errorf("Expected the timeout to apply, got %v", err)
+		}
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		var proxied string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = r.URL.String()
+		}))
+		defer proxy.Close()
+		builder, err := FromConfig(Config{Proxy: proxy.URL})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := builder.Build().Get(context.Background(), "http://upstream.invalid/x"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if proxied != "http://upstream.invalid/x" {
+			t.Errorf("Expected the request to go through the proxy, got %q", proxied)
+		}
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Config.ErrorLog = log.New(io.Discard, "", 0) // the rejected handshake is expected
+		server.StartTLS()
+		defer server.Close()
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600)
+
+		builder, err := FromConfig(Config{TLS: &TLSSettings{CAFile: caFile, MinVersion: "1.2"}})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := builder.Build().Get(context.Background(), server.URL); err != nil {
+			t.Errorf("Expected the CA file to be trusted, got %v", err)
+		}
+		if _, err := NewClientBuilder().Build().Get(context.Background(), server.URL); err == nil {
+			t.Error("Expected the default client to reject the test certificate")
+		}
+	})
+
+	t.Run("invalid settings", func(t *testing.T) {
+		for name, cfg := range map[string]Config{
+			"tls version": {TLS: &TLSSettings{MinVersion: "2.0"}},
+			"ca file":     {TLS: &TLSSettings{CAFile: filepath.Join(t.TempDir(), "missing.pem")}},
+			"proxy":       {Proxy: "://nope"},
+		} {
+			if _, err := FromConfig(cfg); err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+		}
+	})
+}
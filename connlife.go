@@ -0,0 +1,100 @@
+package reqwest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+)
+
+// connLimits retires pooled HTTP/1 connections that are older than lifetime
+// or have served maxRequests requests. Upstream load balancers that only
+// rebalance new connections then see traffic move.
+type connLimits struct {
+	lifetime    time.Duration
+	maxRequests int
+}
+
+func (l connLimits) enabled() bool {
+	return l.lifetime > 0 || l.maxRequests > 0
+}
+
+// transportOption wraps the dialer so connections can be told apart and
+// aged. It is applied after all other options so it wraps a custom dialer.
+func (l connLimits) transportOption() transportOption {
+	return func(t *http.Transport) {
+		dial := t.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+		}
+		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dial(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return &limitedConn{Conn: conn, created: time.Now(), limits: l}, nil
+		}
+	}
+}
+
+// wrapper counts requests per connection and marks the request that
+// exhausts a connection with Close, so the transport drops the connection once
+// the response is read instead of returning it to the pool.
+func (l connLimits) wrapper() RoundTripperWrapper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// The copy made by WithContext is ours to mark; GotConn runs
+			// before the transport writes the request.
+			var traced *http.Request
+			trace := &httptrace.ClientTrace{
+				GotConn: func(info httptrace.GotConnInfo) {
+					if conn := asLimitedConn(info.Conn); conn != nil && conn.use() {
+						traced.Close = true
+					}
+				},
+			}
+			traced = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+			return next.RoundTrip(traced)
+		})
+	}
+}
+
+type limitedConn struct {
+	net.Conn
+	created  time.Time
+	requests atomic.Int64
+	limits   connLimits
+}
+
+// use records a request on the connection and reports whether it is the last
+// one the connection may serve.
+func (c *limitedConn) use() bool {
+	requests := c.requests.Add(1)
+	if c.limits.maxRequests > 0 && requests >= int64(c.limits.maxRequests) {
+		return true
+	}
+	return c.limits.lifetime > 0 && time.Since(c.created) >= c.limits.lifetime
+}
+
+// asLimitedConn finds the limitedConn under conn, looking through TLS.
+func asLimitedConn(conn net.Conn) *limitedConn {
+	for conn != nil {
+		if limited, ok := conn.(*limitedConn); ok {
+			return limited
+		}
+		wrapper, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			return nil
+		}
+		conn = wrapper.NetConn()
+	}
+	return nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,111 @@
+package reqwest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newConnCountingServer() (*httptest.Server, *int32) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	return server, &conns
+}
+
+func TestClientBuilder_WithMaxRequestsPerConnection(t *testing.T) {
+	t.Run("recycles after n requests", func(t *testing.T) {
+		server, conns := newConnCountingServer()
+		defer server.Close()
+
+		cli := NewClientBuilder().WithMaxRequestsPerConnection(2).Build()
+		for i := 0; i < 6; i++ {
+			resp, err := cli.Get(context.TODO(), server.URL)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body().Close()
+		}
+
+		if got := atomic.LoadInt32(conns); got != 3 {
+			t.Errorf("Expected 3 connections, got %d", got)
+		}
+	})
+
+	t.Run("non-idempotent requests are not replayed", func(t *testing.T) {
+		var posts int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&posts, 1)
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().WithMaxRequestsPerConnection(1).Build()
+		for i := 0; i < 3; i++ {
+			resp, err := cli.Post(context.TODO(), server.URL, []byte(`{}`))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body().Close()
+		}
+
+		if got := atomic.LoadInt32(&posts); got != 3 {
+			t.Errorf("Expected 3 posts, got %d", got)
+		}
+	})
+
+	t.Run("wraps a custom dialer", func(t *testing.T) {
+		server, conns := newConnCountingServer()
+		defer server.Close()
+
+		var dials int32
+		dialer := &net.Dialer{}
+		cli := NewClientBuilder().
+			WithMaxRequestsPerConnection(1).
+			WithDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+				atomic.AddInt32(&dials, 1)
+				return dialer.DialContext(ctx, network, addr)
+			}).
+			Build()
+		for i := 0; i < 2; i++ {
+			resp, err := cli.Get(context.TODO(), server.URL)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body().Close()
+		}
+
+		if atomic.LoadInt32(&dials) != 2 || atomic.LoadInt32(conns) != 2 {
+			t.Errorf("Expected 2 dials and connections, got %d and %d", dials, *conns)
+		}
+	})
+}
+
+func TestClientBuilder_WithMaxConnectionLifetime(t *testing.T) {
+	server, conns := newConnCountingServer()
+	defer server.Close()
+
+	cli := NewClientBuilder().WithMaxConnectionLifetime(20 * time.Millisecond).Build()
+	for i := 0; i < 4; i++ {
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+		time.Sleep(30 * time.Millisecond)
+	}
+
+	if got := atomic.LoadInt32(conns); got < 2 {
+		t.Errorf("Expected aged connections to be replaced, got %d connection(s)", got)
+	}
+}
@@ -0,0 +1,47 @@
+package reqwest
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+type timeoutOverrideKey struct{}
+
+type noRetryKey struct{}
+
+// ContextWithTimeoutOverride returns a context that bounds every reqwest call
+// made with it to timeout, covering all retry attempts and reading the
+// response body. Frameworks that only thread contexts through their layers can
+// use it to tune specific operations.
+func ContextWithTimeoutOverride(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutOverrideKey{}, timeout)
+}
+
+// ContextWithNoRetry returns a context that disables retries for reqwest
+// calls made with it, regardless of the client's retry configuration.
+func ContextWithNoRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+func timeoutOverride(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(timeoutOverrideKey{}).(time.Duration)
+	return timeout, ok && timeout > 0
+}
+
+func noRetry(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noRetryKey{}).(bool)
+	return disabled
+}
+
+// cancelOnCloseBody releases a context derived for a single call once the
+// caller is done with the response body.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,100 @@
+package reqwest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestContextWithNoRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cli := NewClientBuilder().WithRetryConfig(fastRetryConfig(3)).Build()
+
+	resp, err := cli.Post(ContextWithNoRetry(context.Background()), server.URL, []byte("charge"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body().Close()
+
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("Expected a single attempt, got %d", calls)
+	}
+	if resp.StatusCode() != http.StatusServiceUnavailable || resp.RetryAttempts() != 0 {
+		t.Errorf("Expected 503 without retries, got %d after %d retries", resp.StatusCode(), resp.RetryAttempts())
+	}
+
+	// The same client still retries for contexts without the override
+	resp, err = cli.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body().Close()
+	if atomic.LoadInt32(&calls) != 5 {
+		t.Errorf("Expected 4 more attempts with retries enabled, got %d total", calls)
+	}
+}
+
+func TestContextWithTimeoutOverride(t *testing.T) {
+	t.Run("bounds the call", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		ctx := ContextWithTimeoutOverride(context.Background(), 50*time.Millisecond)
+		start := time.Now()
+		_, err := NewClientBuilder().Build().Get(ctx, server.URL)
+
+		if err == nil || !strings.Contains(err.Error(), "context deadline exceeded") {
+			t.Errorf("Expected timeout error, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("Expected the override to cut the call short, took %v", elapsed)
+		}
+	})
+
+	t.Run("body stays readable until closed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("payload"))
+		}))
+		defer server.Close()
+
+		ctx := ContextWithTimeoutOverride(context.Background(), time.Second)
+		resp, err := NewClientBuilder().Build().Get(ctx, server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		body, err := io.ReadAll(resp.Body())
+		if err != nil || string(body) != "payload" {
+			t.Errorf("Expected readable body, got %q (%v)", body, err)
+		}
+		if err := resp.Body().Close(); err != nil {
+			t.Errorf("Unexpected close error: %v", err)
+		}
+	})
+
+	t.Run("ignored when not positive", func(t *testing.T) {
+		if _, ok := timeoutOverride(ContextWithTimeoutOverride(context.Background(), 0)); ok {
+			t.Error("Expected zero timeout override to be ignored")
+		}
+		if _, ok := timeoutOverride(context.Background()); ok {
+			t.Error("Expected no override on a plain context")
+		}
+	})
+}
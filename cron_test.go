@@ -0,0 +1,49 @@
+package reqwest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// Wednesday
+	from := time.Date(2025, time.January, 15, 10, 7, 30, 0, time.UTC)
+
+	testCases := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: "* * * * *", want: time.Date(2025, time.January, 15, 10, 8, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", want: time.Date(2025, time.January, 15, 10, 15, 0, 0, time.UTC)},
+		{spec: "0 9-17 * * 1-5", want: time.Date(2025, time.January, 15, 11, 0, 0, 0, time.UTC)},
+		{spec: "30 2 * * *", want: time.Date(2025, time.January, 16, 2, 30, 0, 0, time.UTC)},
+		{spec: "0 0 * * 7", want: time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 1,15 * 5", want: time.Date(2025, time.January, 17, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 29 2 *", want: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{spec: "@hourly", want: time.Date(2025, time.January, 15, 11, 0, 0, 0, time.UTC)},
+		{spec: "@monthly", want: time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "@every 90s", want: from.Add(90 * time.Second)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			schedule, err := parseCron(tc.spec)
+			if err != nil {
+				t.Fatalf("parseCron() error = %v", err)
+			}
+			if got := schedule.next(from); !got.Equal(tc.want) {
+				t.Errorf("next() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "@every", "@every -1s", "@fortnightly"} {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := parseCron(spec); err == nil {
+				t.Errorf("parseCron(%q) error = nil, want error", spec)
+			}
+		})
+	}
+}
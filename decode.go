@@ -0,0 +1,213 @@
+package reqwest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// NumberMode controls how JSON numbers are decoded into interface{} values.
+type NumberMode int
+
+const (
+	// NumberFloat64 decodes numbers as float64, matching encoding/json defaults.
+	NumberFloat64 NumberMode = iota
+	// NumberJSON decodes numbers as json.Number, preserving their exact text so
+	// they can be converted with BigFloat or BigRat without precision loss.
+	NumberJSON
+)
+
+type decodeConfig struct {
+	numberMode     NumberMode
+	strict         bool
+	onUnknownField func(field string)
+	drift          *DriftMonitor
+	codecs         map[string]Codec
+}
+
+// DecodeOption adjusts decoding for a single call, overriding client defaults.
+type DecodeOption func(*decodeConfig)
+
+// DecodeNumbersAs overrides the client number mode for one decode call.
+func DecodeNumbersAs(mode NumberMode) DecodeOption {
+	return func(cfg *decodeConfig) {
+		cfg.numberMode = mode
+	}
+}
+
+// DecodeStrict overrides the client strict decoding setting for one call.
+func DecodeStrict(strict bool) DecodeOption {
+	return func(cfg *decodeConfig) {
+		cfg.strict = strict
+	}
+}
+
+// clone copies cfg so later builder changes don't affect built clients.
+func (cfg decodeConfig) clone() decodeConfig {
+	cfg.codecs = maps.Clone(cfg.codecs)
+	return cfg
+}
+
+func (cfg decodeConfig) with(opts []DecodeOption) decodeConfig {
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+func decodeJSON(data []byte, v any, cfg decodeConfig) error {
+	if cfg.onUnknownField != nil {
+		for _, field := range unknownFields(data, reflect.TypeOf(v)) {
+			cfg.onUnknownField(field)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if cfg.numberMode == NumberJSON {
+		decoder.UseNumber()
+	}
+	if cfg.strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("failed to decode json: %v", err)
+	}
+	return nil
+}
+
+// BigFloat converts a json.Number into a big.Float with enough precision to
+// represent every digit of the original text.
+func BigFloat(n json.Number) (*big.Float, error) {
+	f, _, err := big.ParseFloat(n.String(), 10, uint(len(n.String()))*4+64, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q: %v", n, err)
+	}
+	return f, nil
+}
+
+// BigRat converts a json.Number into an exact rational, which is the safest
+// representation for monetary amounts.
+func BigRat(n json.Number) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(n.String())
+	if !ok {
+		return nil, fmt.Errorf("invalid number %q", n)
+	}
+	return r, nil
+}
+
+// unknownFields lists the dotted paths of object keys in data that have no
+// destination in t. Array elements are reported with a "[]" suffix.
+func unknownFields(data []byte, t reflect.Type) []string {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+	var fields []string
+	collectUnknownFields(doc, t, "", &fields)
+	sort.Strings(fields)
+	return fields
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func collectUnknownFields(doc any, t reflect.Type, path string, fields *[]string) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := doc.(map[string]any)
+		if !ok {
+			return
+		}
+		known := structJSONFields(t)
+		for key, value := range object {
+			fieldType, ok := lookupJSONField(known, key)
+			if !ok {
+				*fields = append(*fields, joinFieldPath(path, key))
+				continue
+			}
+			collectUnknownFields(value, fieldType, joinFieldPath(path, key), fields)
+		}
+	case reflect.Map:
+		object, ok := doc.(map[string]any)
+		if !ok {
+			return
+		}
+		for key, value := range object {
+			collectUnknownFields(value, t.Elem(), joinFieldPath(path, key), fields)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := doc.([]any)
+		if !ok {
+			return
+		}
+		for _, item := range items {
+			collectUnknownFields(item, t.Elem(), path+"[]", fields)
+		}
+	}
+}
+
+func structJSONFields(t reflect.Type) map[string]reflect.Type {
+	known := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for k, v := range structJSONFields(embedded) {
+					if _, exists := known[k]; !exists {
+						known[k] = v
+					}
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		known[name] = field.Type
+	}
+	return known
+}
+
+// lookupJSONField mirrors encoding/json, preferring an exact key match and
+// falling back to a case-insensitive one.
+func lookupJSONField(known map[string]reflect.Type, key string) (reflect.Type, bool) {
+	if t, ok := known[key]; ok {
+		return t, true
+	}
+	for name, t := range known {
+		if strings.EqualFold(name, key) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,236 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResponse_JSON(t *testing.T) {
+	payload := `{"amount": 12345678901234567.89, "name": "invoice"}`
+
+	t.Run("float64 by default", func(t *testing.T) {
+		resp := &Response{body: io.NopCloser(strings.NewReader(payload))}
+
+		var out map[string]any
+		if err := resp.JSON(&out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if _, ok := out["amount"].(float64); !ok {
+			t.Errorf("Expected float64 amount, got %T", out["amount"])
+		}
+	})
+
+	t.Run("json.Number per call", func(t *testing.T) {
+		resp := &Response{body: io.NopCloser(strings.NewReader(payload))}
+
+		var out map[string]any
+		if err := resp.JSON(&out, DecodeNumbersAs(NumberJSON)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		n, ok := out["amount"].(json.Number)
+		if !ok {
+			t.Fatalf("Expected json.Number amount, got %T", out["amount"])
+		}
+		if n.String() != "12345678901234567.89" {
+			t.Errorf("Expected exact digits to be preserved, got %s", n)
+		}
+	})
+
+	t.Run("json.Number per client", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(payload))
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().WithNumberMode(NumberJSON).Build()
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var out map[string]any
+		if err := resp.JSON(&out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, ok := out["amount"].(json.Number); !ok {
+			t.Errorf("Expected json.Number amount, got %T", out["amount"])
+		}
+
+		// Per-call options override the client default
+		resp, err = cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := resp.JSON(&out, DecodeNumbersAs(NumberFloat64)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, ok := out["amount"].(float64); !ok {
+			t.Errorf("Expected float64 amount, got %T", out["amount"])
+		}
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		resp := &Response{body: io.NopCloser(strings.NewReader("{"))}
+
+		var out map[string]any
+		err := resp.JSON(&out)
+		if err == nil || !strings.Contains(err.Error(), "failed to decode json") {
+			t.Errorf("Expected decode error, got %v", err)
+		}
+	})
+}
+
+func TestBigConversions(t *testing.T) {
+	t.Run("BigRat is exact", func(t *testing.T) {
+		r, err := BigRat(json.Number("0.1"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if r.Cmp(big.NewRat(1, 10)) != 0 {
+			t.Errorf("Expected 1/10, got %s", r)
+		}
+	})
+
+	t.Run("BigFloat keeps all digits", func(t *testing.T) {
+		f, err := BigFloat(json.Number("12345678901234567.89"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := f.Text('f', 2); got != "12345678901234567.89" {
+			t.Errorf("Expected 12345678901234567.89, got %s", got)
+		}
+	})
+
+	t.Run("invalid numbers", func(t *testing.T) {
+		if _, err := BigRat(json.Number("abc")); err == nil {
+			t.Error("Expected error for invalid rational")
+		}
+		if _, err := BigFloat(json.Number("abc")); err == nil {
+			t.Error("Expected error for invalid float")
+		}
+	})
+}
+
+type testAddress struct {
+	City string `json:"city"`
+}
+
+type testEmbedded struct {
+	ID int `json:"id"`
+}
+
+type testUser struct {
+	testEmbedded
+	Name      string        `json:"name"`
+	Addresses []testAddress `json:"addresses"`
+	Ignored   string        `json:"-"`
+	Raw       json.RawMessage
+}
+
+func TestStrictDecoding(t *testing.T) {
+	payload := `{"id": 1, "NAME": "a", "nickname": "b", "addresses": [{"city": "x", "zip": "1"}], "raw": {"any": 1}}`
+
+	t.Run("lenient decoding ignores unknown fields", func(t *testing.T) {
+		resp := &Response{body: io.NopCloser(strings.NewReader(payload))}
+
+		var user testUser
+		if err := resp.JSON(&user); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if user.ID != 1 || user.Name != "a" {
+			t.Errorf("Unexpected decoded user: %+v", user)
+		}
+	})
+
+	t.Run("strict decoding rejects unknown fields", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(payload))
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().WithStrictDecoding().Build()
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var user testUser
+		err = resp.JSON(&user)
+		if err == nil || !strings.Contains(err.Error(), "unknown field") {
+			t.Errorf("Expected unknown field error, got %v", err)
+		}
+	})
+
+	t.Run("per call override disables strict mode", func(t *testing.T) {
+		resp := &Response{
+			body:   io.NopCloser(strings.NewReader(payload)),
+			decode: decodeConfig{strict: true},
+		}
+
+		var user testUser
+		if err := resp.JSON(&user, DecodeStrict(false)); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("hook reports unknown fields as warnings", func(t *testing.T) {
+		var reported []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(payload))
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().
+			WithOnUnknownField(func(field string) {
+				reported = append(reported, field)
+			}).
+			Build()
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var user testUser
+		if err := resp.JSON(&user); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := []string{"addresses[].zip", "nickname"}
+		if strings.Join(reported, ",") != strings.Join(expected, ",") {
+			t.Errorf("Expected unknown fields %v, got %v", expected, reported)
+		}
+	})
+}
+
+func TestUnknownFields(t *testing.T) {
+	testCases := []struct {
+		name     string
+		data     string
+		target   any
+		expected []string
+	}{
+		{"no unknown fields", `{"city": "x"}`, &testAddress{}, nil},
+		{"map of structs", `{"home": {"city": "x", "zip": 1}}`, &map[string]testAddress{}, []string{"home.zip"}},
+		{"interface target", `{"a": 1}`, new(any), nil},
+		{"invalid json", `{`, &testAddress{}, nil},
+		{"type mismatch", `[1, 2]`, &testAddress{}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fields := unknownFields([]byte(tc.data), reflect.TypeOf(tc.target))
+			if strings.Join(fields, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("Expected %v, got %v", tc.expected, fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// MetricsCollector receives one observation per logical request.
+type MetricsCollector interface {
+	ObserveRequest(metrics RequestMetrics)
+}
+
+// RequestMetrics describes the outcome of a logical request, including all
+// retry attempts.
+type RequestMetrics struct {
+	Method string
+	URL    string
+	// Route is the path template of the request, or its normalized URL,
+	// for use as a low-cardinality label.
+	Route         string
+	StatusCode    int
+	RetryAttempts int
+	Duration      time.Duration
+	Err           error
+	ctx           context.Context
+}
+
+// Value returns the value for key attached to the request with
+// Request.WithValue or carried by the call's context, or nil.
+func (m RequestMetrics) Value(key any) any {
+	if m.ctx == nil {
+		return nil
+	}
+	return m.ctx.Value(key)
+}
+
+// retryingClient adds retries around any Client implementation. Methods it
+// does not override, such as Dial, are passed through unchanged.
+type retryingClient struct {
+	Client
+	config *RetryConfig
+}
+
+// WithRetriesAround wraps an existing Client, including user implementations
+// and mocks, with reqwest's retry policy. A nil config uses the defaults.
+// The result only has the Client methods: those of *HTTPClient such as
+// GetAsync, Group or Schedule would send requests past the wrapper.
+func WithRetriesAround(next Client, config *RetryConfig) Client {
+	if config == nil {
+		config = NewRetryConfigBuilder().Build()
+	}
+	return &retryingClient{Client: next, config: config}
+}
+
+func (r *retryingClient) Get(ctx context.Context, url string, opts ...RequestOption) (*Response, error) {
+	return r.retry(ctx, http.MethodGet, nil, func() (*Response, error) { return r.Client.Get(ctx, url, opts...) })
+}
+
+func (r *retryingClient) Post(ctx context.Context, url string, body []byte, opts ...RequestOption) (*Response, error) {
+	header := NewRequest(http.MethodPost, url).with(opts).header
+	return r.retry(ctx, http.MethodPost, header, func() (*Response, error) { return r.Client.Post(ctx, url, body, opts...) })
+}
+
+func (r *retryingClient) Options(ctx context.Context, url string) (*Response, error) {
+	return r.retry(ctx, http.MethodOptions, nil, func() (*Response, error) { return r.Client.Options(ctx, url) })
+}
+
+func (r *retryingClient) Do(ctx context.Context, req *Request) (*Response, error) {
+	return r.retry(ctx, req.method, req.header, func() (*Response, error) { return r.Client.Do(ctx, req) })
+}
+
+func (r *retryingClient) retry(ctx context.Context, method string, header http.Header, send func() (*Response, error)) (*Response, error) {
+	var resp *Response
+	var err error
+	for attempt := 0; attempt <= r.config.maxRetries; attempt++ {
+		if attempt > 0 {
+			if resp != nil && resp.body != nil {
+				resp.body.Close()
+			}
+			select {
+			case <-time.After(r.config.delayAfter(attempt, resp, err)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		resp, err = send()
+		retryable := (err != nil && r.config.shouldRetryAttempt(method, header, err)) ||
+			(err == nil && resp != nil && r.config.retryableStatus(resp.statusCode))
+		if !retryable || attempt == r.config.maxRetries {
+			if resp != nil {
+				resp.retryAttempts += attempt
+			}
+			return resp, err
+		}
+	}
+	return resp, err
+}
+
+// metricsClient reports every logical request to a MetricsCollector.
+type metricsClient struct {
+	Client
+	collector MetricsCollector
+}
+
+// WithMetricsAround wraps an existing Client so that every Get, Post,
+// Options and Do call is reported to collector. Like WithRetriesAround, the
+// result only has the Client methods.
+func WithMetricsAround(next Client, collector MetricsCollector) Client {
+	return &metricsClient{Client: next, collector: collector}
+}
+
+func (m *metricsClient) Get(ctx context.Context, url string, opts ...RequestOption) (*Response, error) {
+	return m.observe(ctx, http.MethodGet, url, NormalizeRoute(url), func() (*Response, error) { return m.Client.Get(ctx, url, opts...) })
+}
+
+func (m *metricsClient) Post(ctx context.Context, url string, body []byte, opts ...RequestOption) (*Response, error) {
+	return m.observe(ctx, http.MethodPost, url, NormalizeRoute(url), func() (*Response, error) { return m.Client.Post(ctx, url, body, opts...) })
+}
+
+func (m *metricsClient) Options(ctx context.Context, url string) (*Response, error) {
+	return m.observe(ctx, http.MethodOptions, url, NormalizeRoute(url), func() (*Response, error) { return m.Client.Options(ctx, url) })
+}
+
+func (m *metricsClient) Do(ctx context.Context, req *Request) (*Response, error) {
+	return m.observe(req.withValues(ctx), req.method, req.url, req.Route(), func() (*Response, error) { return m.Client.Do(ctx, req) })
+}
+
+func (m *metricsClient) observe(ctx context.Context, method, url, route string, send func() (*Response, error)) (*Response, error) {
+	start := time.Now()
+	resp, err := send()
+	metrics := RequestMetrics{
+		Method:   method,
+		URL:      url,
+		Route:    route,
+		Duration: time.Since(start),
+		Err:      err,
+		ctx:      ctx,
+	}
+	if resp != nil {
+		metrics.StatusCode = resp.statusCode
+		metrics.RetryAttempts = resp.retryAttempts
+	}
+	m.collector.ObserveRequest(metrics)
+	return resp, err
+}
@@ -0,0 +1,221 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// stubClient is a hand-written Client returning scripted results, standing in
+// for user implementations and mocks.
+type stubClient struct {
+	Client
+	results []stubResult
+	calls   []string
+}
+
+type stubResult struct {
+	statusCode int
+	err        error
+}
+
+func (s *stubClient) next(method, url string) (*Response, error) {
+	s.calls = append(s.calls, method+" "+url)
+	result := s.results[0]
+	if len(s.results) > 1 {
+		s.results = s.results[1:]
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+	return &Response{statusCode: result.statusCode, body: io.NopCloser(strings.NewReader(""))}, nil
+}
+
+func (s *stubClient) Get(_ context.Context, url string, _ ...RequestOption) (*Response, error) {
+	return s.next(http.MethodGet, url)
+}
+
+func (s *stubClient) Post(_ context.Context, url string, _ []byte, _ ...RequestOption) (*Response, error) {
+	return s.next(http.MethodPost, url)
+}
+
+func (s *stubClient) Options(_ context.Context, url string) (*Response, error) {
+	return s.next(http.MethodOptions, url)
+}
+
+func (s *stubClient) Do(_ context.Context, req *Request) (*Response, error) {
+	return s.next(req.method, req.url)
+}
+
+func fastRetryConfig(maxRetries int) *RetryConfig {
+	return NewRetryConfigBuilder().
+		WithMaxRetries(maxRetries).
+		WithBackoffStrategy(NewFixedBackoffBuilder().WithDelay(time.Millisecond).Build()).
+		Build()
+}
+
+func TestWithRetriesAround(t *testing.T) {
+	t.Run("retries status codes and errors", func(t *testing.T) {
+		stub := &stubClient{results: []stubResult{
+			{statusCode: http.StatusServiceUnavailable},
+			{err: fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED)},
+			{statusCode: http.StatusOK},
+		}}
+		cli := WithRetriesAround(stub, fastRetryConfig(3))
+
+		resp, err := cli.Get(context.TODO(), "/items")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.StatusCode() != http.StatusOK {
+			t.Errorf("Expected 200, got %d", resp.StatusCode())
+		}
+		if resp.RetryAttempts() != 2 {
+			t.Errorf("Expected 2 retry attempts, got %d", resp.RetryAttempts())
+		}
+		if len(stub.calls) != 3 {
+			t.Errorf("Expected 3 calls, got %v", stub.calls)
+		}
+	})
+
+	t.Run("returns final result when exhausted", func(t *testing.T) {
+		stub := &stubClient{results: []stubResult{{statusCode: http.StatusBadGateway}}}
+		cli := WithRetriesAround(stub, fastRetryConfig(2))
+
+		resp, err := cli.Post(context.TODO(), "/items", []byte("x"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.StatusCode() != http.StatusBadGateway || resp.RetryAttempts() != 2 {
+			t.Errorf("Expected 502 after 2 retries, got %d after %d", resp.StatusCode(), resp.RetryAttempts())
+		}
+		if len(stub.calls) != 3 {
+			t.Errorf("Expected 3 calls, got %d", len(stub.calls))
+		}
+	})
+
+	t.Run("non-retryable errors return immediately", func(t *testing.T) {
+		stub := &stubClient{results: []stubResult{{err: errors.New("invalid request")}}}
+		cli := WithRetriesAround(stub, nil)
+
+		_, err := cli.Options(context.TODO(), "/items")
+		if err == nil || len(stub.calls) != 1 {
+			t.Errorf("Expected one failing call, got %d calls and err %v", len(stub.calls), err)
+		}
+	})
+
+	t.Run("dropped connections are retried only if safe", func(t *testing.T) {
+		eof := stubResult{err: fmt.Errorf("failed to do http request: %w", io.EOF)}
+		stub := &stubClient{results: []stubResult{eof, {statusCode: http.StatusOK}}}
+		cli := WithRetriesAround(stub, fastRetryConfig(2))
+		if _, err := cli.Post(context.TODO(), "/items", []byte("x")); err == nil || len(stub.calls) != 1 {
+			t.Errorf("Expected one failing POST, got %d calls and err %v", len(stub.calls), err)
+		}
+
+		stub = &stubClient{results: []stubResult{eof, {statusCode: http.StatusOK}}}
+		cli = WithRetriesAround(stub, fastRetryConfig(2))
+		if _, err := cli.Post(context.TODO(), "/items", []byte("x"), RequestHeader(IdempotencyKeyHeader, "k")); err != nil || len(stub.calls) != 2 {
+			t.Errorf("Expected the keyed POST to be retried, got %d calls and err %v", len(stub.calls), err)
+		}
+	})
+
+	t.Run("context cancellation stops backoff", func(t *testing.T) {
+		stub := &stubClient{results: []stubResult{{statusCode: http.StatusServiceUnavailable}}}
+		config := NewRetryConfigBuilder().
+			WithBackoffStrategy(NewFixedBackoffBuilder().WithDelay(time.Second).Build()).
+			Build()
+		cli := WithRetriesAround(stub, config)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := cli.Get(ctx, "/items")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded, got %v", err)
+		}
+	})
+
+	t.Run("wraps the built-in client", func(t *testing.T) {
+		cli := WithRetriesAround(NewClientBuilder().Build(), nil)
+		if _, ok := cli.(*retryingClient).Client.(*HTTPClient); !ok {
+			t.Error("Expected built-in client to be wrapped")
+		}
+	})
+
+	t.Run("hides methods that would bypass the wrapper", func(t *testing.T) {
+		for _, cli := range []Client{
+			WithRetriesAround(NewClientBuilder().Build(), nil),
+			WithMetricsAround(NewClientBuilder().Build(), &recordingCollector{}),
+		} {
+			if _, ok := cli.(AsyncDoer); ok {
+				t.Errorf("Expected %T not to be an AsyncDoer", cli)
+			}
+			if _, ok := cli.(Grouper); ok {
+				t.Errorf("Expected %T not to be a Grouper", cli)
+			}
+			if _, ok := cli.(Scheduler); ok {
+				t.Errorf("Expected %T not to be a Scheduler", cli)
+			}
+		}
+	})
+}
+
+type recordingCollector struct {
+	observed []RequestMetrics
+}
+
+func (r *recordingCollector) ObserveRequest(m RequestMetrics) {
+	r.observed = append(r.observed, m)
+}
+
+func TestWithMetricsAround(t *testing.T) {
+	stub := &stubClient{results: []stubResult{
+		{statusCode: http.StatusCreated},
+		{err: errors.New("boom")},
+		{statusCode: http.StatusNoContent},
+	}}
+	collector := &recordingCollector{}
+	cli := WithMetricsAround(stub, collector)
+
+	_, _ = cli.Post(context.TODO(), "/a", nil)
+	_, _ = cli.Get(context.TODO(), "/b")
+	_, _ = cli.Options(context.TODO(), "/c")
+
+	if len(collector.observed) != 3 {
+		t.Fatalf("Expected 3 observations, got %d", len(collector.observed))
+	}
+
+	first := collector.observed[0]
+	if first.Method != http.MethodPost || first.URL != "/a" || first.StatusCode != http.StatusCreated {
+		t.Errorf("Unexpected first observation: %+v", first)
+	}
+	if collector.observed[1].Err == nil || collector.observed[1].StatusCode != 0 {
+		t.Errorf("Expected error observation, got %+v", collector.observed[1])
+	}
+	if collector.observed[2].Method != http.MethodOptions {
+		t.Errorf("Expected OPTIONS observation, got %+v", collector.observed[2])
+	}
+}
+
+func TestDecorators_Compose(t *testing.T) {
+	stub := &stubClient{results: []stubResult{
+		{statusCode: http.StatusInternalServerError},
+		{statusCode: http.StatusOK},
+	}}
+	collector := &recordingCollector{}
+	cli := WithMetricsAround(WithRetriesAround(stub, fastRetryConfig(3)), collector)
+
+	if _, err := cli.Get(context.TODO(), "/x"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(collector.observed) != 1 || collector.observed[0].RetryAttempts != 1 {
+		t.Errorf("Expected one logical request with 1 retry, got %+v", collector.observed)
+	}
+}
@@ -0,0 +1,84 @@
+package reqwest
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// HeaderSetter swaps headers sent with every request on a built client, e.g.
+// to rotate credentials of a long-lived client without rebuilding it. It is
+// safe to call while requests are running; attempts started afterwards use
+// the new values.
+type HeaderSetter interface {
+	// SetDefaultHeader sends name: value with every request that doesn't set
+	// name itself. An empty value stops sending it.
+	SetDefaultHeader(name, value string)
+	// SetBearerToken sends "Authorization: Bearer <token>". An empty token
+	// stops sending it. A TokenSource, if configured, takes precedence.
+	SetBearerToken(token string)
+}
+
+// defaultHeaders holds the headers set with HeaderSetter. Writers replace the
+// whole header under mu so attempts read a consistent snapshot without
+// locking. Its zero value is empty.
+type defaultHeaders struct {
+	mu     sync.Mutex
+	header atomic.Pointer[http.Header]
+}
+
+func (d *defaultHeaders) set(name, value string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	header := d.snapshot()
+	if header == nil {
+		header = make(http.Header)
+	}
+	if value == "" {
+		header.Del(name)
+	} else {
+		header.Set(name, value)
+	}
+	d.header.Store(&header)
+}
+
+// store replaces the default headers with a copy of header.
+func (d *defaultHeaders) store(header http.Header) {
+	header = header.Clone()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.header.Store(&header)
+}
+
+// snapshot returns a copy of the current default headers.
+func (d *defaultHeaders) snapshot() http.Header {
+	if header := d.header.Load(); header != nil {
+		return header.Clone()
+	}
+	return nil
+}
+
+// apply adds the default headers req doesn't set itself.
+func (d *defaultHeaders) apply(req *http.Request) {
+	header := d.header.Load()
+	if header == nil {
+		return
+	}
+	for name, values := range *header {
+		if _, ok := req.Header[name]; !ok {
+			req.Header[name] = append([]string(nil), values...)
+		}
+	}
+}
+
+func (c *HTTPClient) SetDefaultHeader(name, value string) {
+	c.defaults.set(name, value)
+}
+
+func (c *HTTPClient) SetBearerToken(token string) {
+	if token == "" {
+		c.defaults.set("Authorization", "")
+		return
+	}
+	c.defaults.set("Authorization", "Bearer "+token)
+}
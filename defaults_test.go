@@ -0,0 +1,78 @@
+package reqwest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestClient_HeaderSetter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Authorization", r.Header.Get("Authorization"))
+		w.Header().Set("X-Tenant", r.Header.Get("X-Tenant"))
+	}))
+	defer server.Close()
+	cli := NewClientBuilder().WithBaseURL(server.URL).Build()
+	get := func(req *Request) http.Header {
+		t.Helper()
+		resp, err := cli.Do(context.TODO(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+		return resp.Header()
+	}
+
+	t.Run("headers are sent once set", func(t *testing.T) {
+		cli.SetBearerToken("first")
+		cli.SetDefaultHeader("X-Tenant", "acme")
+		header := get(NewRequest(http.MethodGet, "/"))
+		if got := header.Get("X-Authorization"); got != "Bearer first" {
+			t.Errorf("Expected Bearer first, got %q", got)
+		}
+		if got := header.Get("X-Tenant"); got != "acme" {
+			t.Errorf("Expected acme, got %q", got)
+		}
+	})
+
+	t.Run("request headers win", func(t *testing.T) {
+		header := get(NewRequest(http.MethodGet, "/").WithHeader("X-Tenant", "other"))
+		if got := header.Get("X-Tenant"); got != "other" {
+			t.Errorf("Expected other, got %q", got)
+		}
+	})
+
+	t.Run("rotation and removal", func(t *testing.T) {
+		cli.SetBearerToken("second")
+		cli.SetDefaultHeader("X-Tenant", "")
+		header := get(NewRequest(http.MethodGet, "/"))
+		if got := header.Get("X-Authorization"); got != "Bearer second" {
+			t.Errorf("Expected Bearer second, got %q", got)
+		}
+		if got := header.Get("X-Tenant"); got != "" {
+			t.Errorf("Expected no tenant, got %q", got)
+		}
+	})
+
+	t.Run("concurrent rotation", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := range 10 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				cli.SetBearerToken(fmt.Sprintf("token-%d", i))
+			}()
+			go func() {
+				defer wg.Done()
+				resp, err := cli.Get(context.TODO(), "/")
+				if err == nil {
+					resp.Body().Close()
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,40 @@
+package reqwest
+
+import "net/http"
+
+// Deriver derives clients from a built one.
+type Deriver interface {
+	// Derive returns a builder preset with the client's configuration and
+	// current default headers. Clients it builds share the client's
+	// transport, so deriving a client per tenant or base URL doesn't
+	// duplicate connection pools. Changing transport settings on the builder,
+	// such as WithTransport or WithDNSCache, builds a separate transport
+	// instead.
+	//
+	//	tenant := client.Derive().
+	//		WithBaseURL("https://acme.example.com").
+	//		WithDefaultHeader("X-Tenant", "acme").
+	//		Build()
+	Derive() *ClientBuilder
+}
+
+// sharedTransport is the part of a client that derived clients reuse.
+type sharedTransport struct {
+	httpClient *http.Client
+	dns        *dnsCache
+	stats      *connStats
+	timeouts   Timeouts
+}
+
+func (c *HTTPClient) Derive() *ClientBuilder {
+	cb := NewClientBuilder()
+	if c.builder != nil {
+		c.configMu.RLock()
+		cb = c.builder.Snapshot()
+		c.configMu.RUnlock()
+	} else if c.httpClient != nil {
+		cb.shared = &sharedTransport{httpClient: c.httpClient, dns: c.dns, stats: c.stats, timeouts: c.timeouts}
+	}
+	cb.defaultHeader = c.defaults.snapshot()
+	return cb
+}
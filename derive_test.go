@@ -0,0 +1,73 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_Derive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Seen-Tenant", r.Header.Get("X-Tenant"))
+		w.Header().Set("X-Seen-Path", r.URL.Path)
+	}))
+	defer server.Close()
+	parent := NewClientBuilder().
+		WithBaseURL(server.URL+"/parent").
+		WithRetryConfig(NewRetryConfigBuilder().WithMaxRetries(7).Build()).
+		WithMaxConnectionLifetime(time.Minute).
+		WithDefaultHeader("X-Tenant", "parent").
+		Build()
+	get := func(cli Client) http.Header {
+		t.Helper()
+		resp, err := cli.Get(context.TODO(), "/items")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+		return resp.Header()
+	}
+
+	t.Run("derived clients share the transport", func(t *testing.T) {
+		child := parent.Derive().
+			WithBaseURL(server.URL+"/child").
+			WithDefaultHeader("X-Tenant", "child").
+			Build()
+		if child.httpClient != parent.httpClient {
+			t.Error("Expected the derived client to share the parent's http.Client")
+		}
+		header := get(child)
+		if header.Get("X-Seen-Path") != "/child/items" || header.Get("X-Seen-Tenant") != "child" {
+			t.Errorf("Expected the child's base URL and header, got %v", header)
+		}
+		if got := child.RetryPolicy().maxRetries; got != 7 {
+			t.Errorf("Expected the parent's retry policy, got %d retries", got)
+		}
+		header = get(parent)
+		if header.Get("X-Seen-Path") != "/parent/items" || header.Get("X-Seen-Tenant") != "parent" {
+			t.Errorf("Expected the parent to be unchanged, got %v", header)
+		}
+	})
+
+	t.Run("runtime default headers are inherited", func(t *testing.T) {
+		parent.SetDefaultHeader("X-Tenant", "rotated")
+		defer parent.SetDefaultHeader("X-Tenant", "parent")
+		child := parent.Derive().Build()
+		if got := get(child).Get("X-Seen-Tenant"); got != "rotated" {
+			t.Errorf("Expected rotated, got %q", got)
+		}
+	})
+
+	t.Run("transport changes build a separate transport", func(t *testing.T) {
+		child := parent.Derive().WithTransport(http.DefaultTransport).Build()
+		if child.httpClient == parent.httpClient {
+			t.Error("Expected a separate http.Client")
+		}
+		grandchild := child.Derive().Build()
+		if grandchild.httpClient != child.httpClient {
+			t.Error("Expected the grandchild to share the child's http.Client")
+		}
+	})
+}
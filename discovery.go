@@ -0,0 +1,159 @@
+package reqwest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Resolver returns the endpoints (base URLs such as "http://10.0.0.7:8080")
+// currently serving a logical service name. It returns no endpoints and no
+// error for names it doesn't manage, which are then dialled as-is.
+type Resolver interface {
+	Resolve(ctx context.Context, service string) ([]string, error)
+}
+
+// Balancer picks one of the resolved endpoints for an attempt. done is called
+// when the attempt finishes, which load-aware balancers use to track pending
+// requests.
+type Balancer interface {
+	Pick(endpoints []string) (endpoint string, done func())
+}
+
+// StaticResolver maps service names to fixed endpoint lists.
+type StaticResolver map[string][]string
+
+func (r StaticResolver) Resolve(_ context.Context, service string) ([]string, error) {
+	return r[service], nil
+}
+
+// SRVResolver resolves service names with DNS SRV records, so a request to
+// scheme://_http._tcp.api.internal/path targets one of the record targets.
+// Names that don't look like SRV names ("_service._proto.name") pass through.
+type SRVResolver struct {
+	// Scheme of the resolved endpoints, "http" by default.
+	Scheme string
+	// Resolver performs the lookups; nil uses net.DefaultResolver.
+	Resolver *net.Resolver
+}
+
+func (r SRVResolver) Resolve(ctx context.Context, service string) ([]string, error) {
+	if !strings.HasPrefix(service, "_") {
+		return nil, nil
+	}
+	resolver := r.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	_, records, err := resolver.LookupSRV(ctx, "", "", service)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %v", service, err)
+	}
+	scheme := r.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	// Records come sorted by priority and shuffled by weight
+	endpoints := make([]string, 0, len(records))
+	for _, record := range records {
+		host := strings.TrimSuffix(record.Target, ".")
+		endpoints = append(endpoints, scheme+"://"+net.JoinHostPort(host, strconv.Itoa(int(record.Port))))
+	}
+	return endpoints, nil
+}
+
+// RoundRobinBalancer cycles through the endpoints.
+func RoundRobinBalancer() Balancer {
+	return &roundRobinBalancer{}
+}
+
+type roundRobinBalancer struct {
+	next atomic.Uint64
+}
+
+func (b *roundRobinBalancer) Pick(endpoints []string) (string, func()) {
+	n := b.next.Add(1) - 1
+	return endpoints[n%uint64(len(endpoints))], func() {}
+}
+
+// RandomBalancer picks an endpoint uniformly at random.
+func RandomBalancer() Balancer {
+	return randomBalancer{}
+}
+
+type randomBalancer struct{}
+
+func (randomBalancer) Pick(endpoints []string) (string, func()) {
+	return endpoints[rand.Intn(len(endpoints))], func() {}
+}
+
+// LeastPendingBalancer picks the endpoint with the fewest attempts in flight
+// from this client, preferring earlier endpoints on ties.
+func LeastPendingBalancer() Balancer {
+	return &leastPendingBalancer{pending: make(map[string]int)}
+}
+
+type leastPendingBalancer struct {
+	mu      sync.Mutex
+	pending map[string]int
+}
+
+func (b *leastPendingBalancer) Pick(endpoints []string) (string, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	best := endpoints[0]
+	for _, endpoint := range endpoints[1:] {
+		if b.pending[endpoint] < b.pending[best] {
+			best = endpoint
+		}
+	}
+	b.pending[best]++
+	return best, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.pending[best]--; b.pending[best] <= 0 {
+			delete(b.pending, best)
+		}
+	}
+}
+
+// discovery resolves the host of request URLs as a logical service name.
+type discovery struct {
+	resolver Resolver
+	balancer Balancer
+}
+
+// route rewrites rawURL to target an endpoint of the service named by its
+// host. URLs whose host the resolver doesn't manage are returned unchanged.
+func (d *discovery) route(ctx context.Context, rawURL string) (string, func(), error) {
+	parsed, err := neturl.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return rawURL, func() {}, nil
+	}
+	endpoints, err := d.resolver.Resolve(ctx, parsed.Hostname())
+	if err != nil {
+		return "", nil, err
+	}
+	if len(endpoints) == 0 {
+		return rawURL, func() {}, nil
+	}
+	endpoint, done := d.balancer.Pick(endpoints)
+	target, err := neturl.Parse(endpoint)
+	if err != nil {
+		done()
+		return "", nil, fmt.Errorf("invalid endpoint %q: %v", endpoint, err)
+	}
+	parsed.Scheme = target.Scheme
+	parsed.Host = target.Host
+	if prefix := strings.TrimRight(target.Path, "/"); prefix != "" {
+		parsed.Path = prefix + parsed.Path
+		parsed.RawPath = ""
+	}
+	return parsed.String(), done, nil
+}
@@ -0,0 +1,84 @@
+package reqwest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestClient_WithServiceDiscovery(t *testing.T) {
+	a, b := newNamedServer("a"), newNamedServer("b")
+	defer a.Close()
+	defer b.Close()
+
+	client := NewClientBuilder().
+		WithServiceDiscovery(StaticResolver{"payments": {a.URL, b.URL}}, RoundRobinBalancer()).
+		Build()
+
+	var served []string
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(context.Background(), "http://payments/charges")
+		served = append(served, servedBy(t, resp, err))
+	}
+	if fmt.Sprint(served) != "[a b a]" {
+		t.Errorf("Expected round-robin across endpoints, got %v", served)
+	}
+
+	t.Run("unmanaged hosts pass through", func(t *testing.T) {
+		resp, err := client.Get(context.Background(), a.URL)
+		if got := servedBy(t, resp, err); got != "a" {
+			t.Errorf("Expected the request to reach a, got %q", got)
+		}
+	})
+}
+
+func TestDiscovery_Route(t *testing.T) {
+	d := &discovery{
+		resolver: StaticResolver{"api": {"https://10.0.0.1:8443/prefix/"}},
+		balancer: RoundRobinBalancer(),
+	}
+	got, done, err := d.route(context.Background(), "http://api/v1/users?id=1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	done()
+	if got != "https://10.0.0.1:8443/prefix/v1/users?id=1" {
+		t.Errorf("route() = %q", got)
+	}
+}
+
+func TestLeastPendingBalancer(t *testing.T) {
+	balancer := LeastPendingBalancer()
+	endpoints := []string{"a", "b"}
+
+	first, doneFirst := balancer.Pick(endpoints)
+	second, doneSecond := balancer.Pick(endpoints)
+	if first != "a" || second != "b" {
+		t.Fatalf("Expected a then b, got %s then %s", first, second)
+	}
+	doneFirst()
+	if next, done := balancer.Pick(endpoints); next != "a" {
+		t.Errorf("Expected the endpoint with no pending requests, got %s", next)
+	} else {
+		done()
+	}
+	doneSecond()
+}
+
+func TestRandomBalancer(t *testing.T) {
+	endpoints := []string{"a", "b", "c"}
+	for i := 0; i < 20; i++ {
+		endpoint, done := RandomBalancer().Pick(endpoints)
+		done()
+		if endpoint != "a" && endpoint != "b" && endpoint != "c" {
+			t.Fatalf("Unexpected endpoint %q", endpoint)
+		}
+	}
+}
+
+func TestSRVResolver_PassesThroughPlainNames(t *testing.T) {
+	endpoints, err := SRVResolver{}.Resolve(context.Background(), "localhost")
+	if err != nil || endpoints != nil {
+		t.Errorf("Expected plain names to pass through, got %v (%v)", endpoints, err)
+	}
+}
@@ -0,0 +1,247 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DNSCacher exposes the client's DNS cache. Without WithDNSCache the stats
+// stay zero and invalidation is a no-op.
+type DNSCacher interface {
+	DNSCacheStats() DNSCacheStats
+	// InvalidateDNS drops cached lookups for hosts, or all of them when no
+	// host is given, e.g. after a planned failover.
+	InvalidateDNS(hosts ...string)
+}
+
+// DNSCacheStats reports how effective the DNS cache is.
+type DNSCacheStats struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Entries int    `json:"entries"`
+}
+
+// NewDNSResolver returns a resolver that sends every lookup to servers
+// ("host:port"), in order, instead of the system configuration. Use it with
+// WithDNSResolver to pin lookups to specific DNS servers.
+func NewDNSResolver(servers ...string) *net.Resolver {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var errs []error
+			for _, server := range servers {
+				conn, err := dialer.DialContext(ctx, network, server)
+				if err == nil {
+					return conn, nil
+				}
+				errs = append(errs, err)
+			}
+			if len(errs) == 0 {
+				return nil, errors.New("no DNS servers configured")
+			}
+			return nil, errors.Join(errs...)
+		},
+	}
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+// dnsLookup is a lookup in flight; concurrent dials for the same host wait
+// for it instead of querying the resolver again.
+type dnsLookup struct {
+	done  chan struct{}
+	addrs []string
+	err   error
+}
+
+// dnsCache resolves host names for the transport's dialer, caching results
+// for ttl. A zero ttl disables caching but still uses resolver.
+type dnsCache struct {
+	ttl      time.Duration
+	resolver *net.Resolver
+	clock    Clock
+	hits     atomic.Uint64
+	misses   atomic.Uint64
+
+	mu       sync.Mutex
+	entries  map[string]dnsEntry
+	inflight map[string]*dnsLookup
+}
+
+func newDNSCache(ttl time.Duration, resolver *net.Resolver, clock Clock) *dnsCache {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &dnsCache{
+		ttl:      ttl,
+		resolver: resolver,
+		clock:    orSystemClock(clock),
+		entries:  make(map[string]dnsEntry),
+		inflight: make(map[string]*dnsLookup),
+	}
+}
+
+// transportOption makes the transport dial resolved addresses, wrapping any
+// dialer configured with WithDialContext.
+func (d *dnsCache) transportOption() transportOption {
+	return func(t *http.Transport) {
+		dial := t.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+		}
+		t.DialContext = d.dial(dial)
+	}
+}
+
+// dial resolves addr and tries each address in turn, like net.Dialer does.
+func (d *dnsCache) dial(next DialContextFunc) DialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return next(ctx, network, addr)
+		}
+		addrs, err := resolveTraced(ctx, host, d.lookup)
+		if err != nil {
+			return nil, err
+		}
+		return dialSerial(ctx, next, network, addrs, port)
+	}
+}
+
+// resolveTraced looks host up, reporting the lookup to the request's
+// ClientTrace: dialing an IP skips the transport's own lookup hooks.
+func resolveTraced(ctx context.Context, host string, lookup func(context.Context, string) ([]string, error)) ([]string, error) {
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace != nil && trace.DNSStart != nil {
+		trace.DNSStart(httptrace.DNSStartInfo{Host: host})
+	}
+	addrs, err := lookup(ctx, host)
+	if trace != nil && trace.DNSDone != nil {
+		trace.DNSDone(httptrace.DNSDoneInfo{Addrs: ipAddrs(addrs), Err: err})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	return addrs, nil
+}
+
+// dialSerial tries each address in turn.
+func dialSerial(ctx context.Context, next DialContextFunc, network string, addrs []string, port string) (net.Conn, error) {
+	var errs []error
+	for _, ip := range addrs {
+		conn, err := next(ctx, network, net.JoinHostPort(ip, port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, errors.Join(errs...)
+}
+
+func ipAddrs(addrs []string) []net.IPAddr {
+	ips := make([]net.IPAddr, 0, len(addrs))
+	for _, addr := range addrs {
+		if ip := net.ParseIP(addr); ip != nil {
+			ips = append(ips, net.IPAddr{IP: ip})
+		}
+	}
+	return ips
+}
+
+func (d *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	if d.ttl <= 0 {
+		return d.resolver.LookupHost(ctx, host)
+	}
+
+	d.mu.Lock()
+	if entry, ok := d.entries[host]; ok && d.clock.Now().Before(entry.expires) {
+		d.mu.Unlock()
+		d.hits.Add(1)
+		return entry.addrs, nil
+	}
+	d.misses.Add(1)
+	call, ok := d.inflight[host]
+	if !ok {
+		call = &dnsLookup{done: make(chan struct{})}
+		d.inflight[host] = call
+		go d.resolve(host, call)
+	}
+	d.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.addrs, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resolve runs a shared lookup detached from any one caller's context, so a
+// cancelled request does not fail the others waiting on it.
+func (d *dnsCache) resolve(host string, call *dnsLookup) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	call.addrs, call.err = d.resolver.LookupHost(ctx, host)
+
+	d.mu.Lock()
+	delete(d.inflight, host)
+	if call.err == nil {
+		d.entries[host] = dnsEntry{addrs: call.addrs, expires: d.clock.Now().Add(d.ttl)}
+	}
+	d.mu.Unlock()
+	close(call.done)
+}
+
+func (d *dnsCache) stats() DNSCacheStats {
+	d.mu.Lock()
+	entries := len(d.entries)
+	d.mu.Unlock()
+	return DNSCacheStats{Hits: d.hits.Load(), Misses: d.misses.Load(), Entries: entries}
+}
+
+func (d *dnsCache) invalidate(hosts ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(hosts) == 0 {
+		clear(d.entries)
+		return
+	}
+	for _, host := range hosts {
+		delete(d.entries, host)
+	}
+}
+
+func (c *HTTPClient) DNSCacheStats() DNSCacheStats {
+	if c.dns == nil {
+		return DNSCacheStats{}
+	}
+	return c.dns.stats()
+}
+
+func (c *HTTPClient) InvalidateDNS(hosts ...string) {
+	if c.dns != nil {
+		c.dns.invalidate(hosts...)
+	}
+}
+
+// netResolver returns the resolver the client's dialer uses.
+func (c *HTTPClient) netResolver() *net.Resolver {
+	if c.dns == nil {
+		return net.DefaultResolver
+	}
+	return c.dns.resolver
+}
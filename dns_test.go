@@ -0,0 +1,151 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientBuilder_WithDNSCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	url := "http://localhost:" + port
+
+	// One request per connection, so every request dials and resolves.
+	cli := NewClientBuilder().
+		WithDNSCache(time.Minute).
+		WithMaxRequestsPerConnection(1).
+		Build()
+	get := func() {
+		t.Helper()
+		resp, err := cli.Get(context.TODO(), url)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+	}
+
+	t.Run("lookups are cached", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			get()
+		}
+		want := DNSCacheStats{Hits: 2, Misses: 1, Entries: 1}
+		if got := cli.DNSCacheStats(); got != want {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("invalidation forces a fresh lookup", func(t *testing.T) {
+		cli.InvalidateDNS("localhost")
+		if got := cli.DNSCacheStats().Entries; got != 0 {
+			t.Fatalf("Expected empty cache, got %d entries", got)
+		}
+		get()
+		if got := cli.DNSCacheStats().Misses; got != 2 {
+			t.Errorf("Expected 2 misses, got %d", got)
+		}
+	})
+
+	t.Run("custom dialers get the host name", func(t *testing.T) {
+		var dialed string
+		cli := NewClientBuilder().
+			WithDNSCache(time.Minute).
+			WithDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+				dialed = addr
+				return new(net.Dialer).DialContext(ctx, network, server.Listener.Addr().String())
+			}).
+			Build()
+		resp, err := cli.Get(context.TODO(), "http://service.invalid/health")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+		if dialed != "service.invalid:80" {
+			t.Errorf("Expected the dialer to get service.invalid:80, got %q", dialed)
+		}
+	})
+
+	t.Run("disabled cache reports nothing", func(t *testing.T) {
+		cli := NewClientBuilder().Build()
+		cli.InvalidateDNS()
+		if got := cli.DNSCacheStats(); got != (DNSCacheStats{}) {
+			t.Errorf("Expected zero stats, got %+v", got)
+		}
+	})
+}
+
+func TestClientBuilder_WithDNSResolver(t *testing.T) {
+	var queries int32
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			atomic.AddInt32(&queries, 1)
+			return nil, errors.New("dns server unreachable")
+		},
+	}
+	cli := NewClientBuilder().
+		WithDNSResolver(resolver).
+		WithRetryConfig(nil).
+		Build()
+
+	_, err := cli.Get(context.TODO(), "http://service.invalid/health")
+	if err == nil || !strings.Contains(err.Error(), "failed to resolve service.invalid") {
+		t.Fatalf("Expected resolution error, got %v", err)
+	}
+	if atomic.LoadInt32(&queries) == 0 {
+		t.Error("Expected the custom resolver to be queried")
+	}
+}
+
+func TestDNSCache_SharesConcurrentLookups(t *testing.T) {
+	// lookup resolves from callers goroutines against a resolver that holds
+	// every query until all callers are waiting, and counts the queries.
+	lookup := func(callers int) (int32, DNSCacheStats) {
+		var queries int32
+		release := make(chan struct{})
+		cache := newDNSCache(time.Minute, &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+				atomic.AddInt32(&queries, 1)
+				<-release
+				return nil, errors.New("dns server unreachable")
+			},
+		}, nil)
+
+		errs := make(chan error)
+		for i := 0; i < callers; i++ {
+			go func() {
+				_, err := cache.lookup(context.TODO(), "service.invalid")
+				errs <- err
+			}()
+		}
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		for i := 0; i < callers; i++ {
+			if err := <-errs; err == nil {
+				t.Error("Expected lookup error")
+			}
+		}
+		return atomic.LoadInt32(&queries), cache.stats()
+	}
+
+	// The Go resolver may send several queries per lookup (A and AAAA, one
+	// per nameserver), so compare against a single caller.
+	single, _ := lookup(1)
+	shared, stats := lookup(5)
+	if shared != single {
+		t.Errorf("Expected %d queries for one shared lookup, got %d", single, shared)
+	}
+	if stats.Misses != 5 || stats.Entries != 0 {
+		t.Errorf("Expected 5 misses and no cached failure, got %+v", stats)
+	}
+}
@@ -0,0 +1,372 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DownloadFile downloads url to path through doer. The body is written to
+// path+".part" and renamed to path once complete; a .part file left by an
+// interrupted download is resumed with a Range request if the server
+// supports it. The ETag or Last-Modified of the response is kept in
+// path+".part.validator" and sent as If-Range, so a file that changed in the
+// meantime is downloaded again in full. A .part file without one is
+// discarded. It returns the size of the downloaded file.
+func DownloadFile(ctx context.Context, doer Doer, url, path string) (int64, error) {
+	size, _, err := downloadOnce(ctx, doer, url, path)
+	return size, err
+}
+
+// downloadError is a download that failed while transferring the body, so a
+// new attempt can resume it.
+type downloadError struct {
+	err error
+}
+
+func (e *downloadError) Error() string { return e.err.Error() }
+func (e *downloadError) Unwrap() error { return e.err }
+
+func downloadOnce(ctx context.Context, doer Doer, url, path string) (size int64, resumed bool, err error) {
+	part := path + ".part"
+	var offset int64
+	validator, _ := os.ReadFile(part + ".validator")
+	if info, err := os.Stat(part); err == nil && len(validator) > 0 {
+		offset = info.Size()
+	}
+	req := NewRequest(http.MethodGet, url)
+	if offset > 0 {
+		req.WithHeader("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.WithHeader("If-Range", string(validator))
+	}
+	resp, err := doer.Do(ctx, req)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body().Close()
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	switch status := resp.StatusCode(); {
+	case status == http.StatusPartialContent && offset > 0:
+		if start, _, ok := contentRange(resp.Header()); !ok || start != offset {
+			return 0, false, fmt.Errorf("failed to download %s: resumed at %q instead of byte %d",
+				url, resp.Header().Get("Content-Range"), offset)
+		}
+		flags, resumed = os.O_WRONLY|os.O_APPEND, true
+	case status == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The .part file may already hold the whole body.
+		if _, total, ok := contentRange(resp.Header()); !ok || total != offset {
+			os.Remove(part)
+			return 0, false, &downloadError{fmt.Errorf("failed to resume %s: partial file discarded", url)}
+		}
+		if err := os.Rename(part, path); err != nil {
+			return 0, true, fmt.Errorf("failed to save %s: %v", path, err)
+		}
+		os.Remove(part + ".validator")
+		return offset, true, nil
+	case status < http.StatusOK || status >= http.StatusMultipleChoices:
+		return 0, false, fmt.Errorf("failed to download %s: unexpected status %d", url, status)
+	}
+
+	if !resumed {
+		if err := saveValidator(part, resp.Header()); err != nil {
+			return 0, false, err
+		}
+	}
+	file, err := os.OpenFile(part, flags, 0o644)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to open %s: %v", part, err)
+	}
+	written, err := io.Copy(file, resp.Body())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, resumed, &downloadError{fmt.Errorf("failed to download %s: %w", url, err)}
+	}
+	if resumed {
+		written += offset
+	}
+	if err := os.Rename(part, path); err != nil {
+		return 0, resumed, fmt.Errorf("failed to save %s: %v", path, err)
+	}
+	os.Remove(part + ".validator")
+	return written, resumed, nil
+}
+
+// saveValidator keeps what identifies the version of the file being written
+// to part: its strong ETag, or else its Last-Modified date. Without either,
+// the .part file can't be resumed safely.
+func saveValidator(part string, header http.Header) error {
+	validator := header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = header.Get("Last-Modified")
+	}
+	if validator == "" {
+		if err := os.Remove(part + ".validator"); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s.validator: %v", part, err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(part+".validator", []byte(validator), 0o644); err != nil {
+		return fmt.Errorf("failed to save %s.validator: %v", part, err)
+	}
+	return nil
+}
+
+// contentRange parses a Content-Range header such as "bytes 100-199/200" or
+// "bytes */200". total is -1 if unknown.
+func contentRange(header http.Header) (start, total int64, ok bool) {
+	value, found := strings.CutPrefix(header.Get("Content-Range"), "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	span, size, found := strings.Cut(value, "/")
+	if !found {
+		return 0, 0, false
+	}
+	total = -1
+	if size != "*" {
+		var err error
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	if span == "*" {
+		return 0, total, true
+	}
+	first, _, _ := strings.Cut(span, "-")
+	start, err := strconv.ParseInt(first, 10, 64)
+	return start, total, err == nil
+}
+
+// DownloadJob is a file for a DownloadManager to fetch.
+type DownloadJob struct {
+	URL  string
+	Path string
+}
+
+// DownloadResult is the outcome of a DownloadJob.
+type DownloadResult struct {
+	DownloadJob
+	Bytes    int64
+	Resumed  bool
+	Attempts int
+	Err      error
+}
+
+// DownloadProgress is reported after every finished job.
+type DownloadProgress struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	Bytes     int64
+	Last      DownloadResult
+}
+
+// DownloadReport summarizes a DownloadManager run. Results are in job order.
+type DownloadReport struct {
+	Results   []DownloadResult
+	Succeeded int
+	Failed    int
+	Bytes     int64
+	Duration  time.Duration
+}
+
+// Err joins the errors of the failed jobs, or returns nil if all succeeded.
+func (r DownloadReport) Err() error {
+	var errs []error
+	for _, result := range r.Results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DownloadManager downloads many files with bounded concurrency, per-host
+// politeness and resumable retries, e.g. to mirror a site or fetch a
+// dataset:
+//
+//	report := reqwest.NewDownloadManager(client).
+//		WithConcurrency(8).
+//		WithHostInterval(100 * time.Millisecond).
+//		Run(ctx, jobs)
+//	if err := report.Err(); err != nil { ... }
+//
+// Requests go through doer, so its retries apply to every request; the
+// manager additionally resumes downloads that fail mid-transfer.
+type DownloadManager struct {
+	doer         Doer
+	concurrency  int
+	perHost      int
+	hostInterval time.Duration
+	attempts     int
+	onProgress   func(DownloadProgress)
+
+	mu    sync.Mutex
+	hosts map[string]*hostGate
+}
+
+// NewDownloadManager returns a manager that downloads through doer, four
+// files at a time, at most two per host, with three attempts per file.
+func NewDownloadManager(doer Doer) *DownloadManager {
+	return &DownloadManager{doer: doer, concurrency: 4, perHost: 2, attempts: 3}
+}
+
+// WithConcurrency bounds how many files are downloaded at once.
+func (m *DownloadManager) WithConcurrency(n int) *DownloadManager {
+	m.concurrency = n
+	return m
+}
+
+// WithPerHostLimit bounds how many files are downloaded from one host at once.
+func (m *DownloadManager) WithPerHostLimit(n int) *DownloadManager {
+	m.perHost = n
+	return m
+}
+
+// WithHostInterval spaces requests to the same host at least d apart.
+func (m *DownloadManager) WithHostInterval(d time.Duration) *DownloadManager {
+	m.hostInterval = d
+	return m
+}
+
+// WithAttempts sets how often a download failing mid-transfer is attempted,
+// resuming where the previous attempt stopped.
+func (m *DownloadManager) WithAttempts(n int) *DownloadManager {
+	m.attempts = n
+	return m
+}
+
+// WithProgress calls report after every finished job. Calls are serialized.
+func (m *DownloadManager) WithProgress(report func(DownloadProgress)) *DownloadManager {
+	m.onProgress = report
+	return m
+}
+
+// Run downloads jobs and reports their outcomes. Cancelling ctx stops the
+// remaining jobs, which are reported as failed.
+func (m *DownloadManager) Run(ctx context.Context, jobs []DownloadJob) DownloadReport {
+	start := time.Now()
+	report := DownloadReport{Results: make([]DownloadResult, len(jobs))}
+	indexes := make(chan int)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range max(m.concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result := m.download(ctx, jobs[i])
+				mu.Lock()
+				report.Results[i] = result
+				if result.Err != nil {
+					report.Failed++
+				} else {
+					report.Succeeded++
+					report.Bytes += result.Bytes
+				}
+				if m.onProgress != nil {
+					m.onProgress(DownloadProgress{
+						Total:     len(jobs),
+						Succeeded: report.Succeeded,
+						Failed:    report.Failed,
+						Bytes:     report.Bytes,
+						Last:      result,
+					})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range jobs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	report.Duration = time.Since(start)
+	return report
+}
+
+func (m *DownloadManager) download(ctx context.Context, job DownloadJob) DownloadResult {
+	result := DownloadResult{DownloadJob: job}
+	gate := m.gate(job.URL)
+	for result.Attempts < max(m.attempts, 1) {
+		if err := gate.acquire(ctx, m.hostInterval); err != nil {
+			result.Err = fmt.Errorf("failed to download %s: %w", job.URL, err)
+			return result
+		}
+		result.Attempts++
+		size, resumed, err := downloadOnce(ctx, m.doer, job.URL, job.Path)
+		gate.release()
+		result.Bytes, result.Resumed, result.Err = size, result.Resumed || resumed, err
+		var transferErr *downloadError
+		if err == nil || !errors.As(err, &transferErr) || ctx.Err() != nil {
+			break
+		}
+	}
+	return result
+}
+
+// gate returns the politeness gate of rawURL's host.
+func (m *DownloadManager) gate(rawURL string) *hostGate {
+	var host string
+	if parsed, err := url.Parse(rawURL); err == nil {
+		host = parsed.Host
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.hosts == nil {
+		m.hosts = make(map[string]*hostGate)
+	}
+	gate, ok := m.hosts[host]
+	if !ok {
+		gate = &hostGate{slots: make(chan struct{}, max(m.perHost, 1))}
+		m.hosts[host] = gate
+	}
+	return gate
+}
+
+// hostGate limits concurrent requests to one host and spaces them apart.
+type hostGate struct {
+	slots chan struct{}
+	mu    sync.Mutex
+	next  time.Time
+}
+
+func (g *hostGate) acquire(ctx context.Context, interval time.Duration) error {
+	select {
+	case g.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if interval <= 0 {
+		return nil
+	}
+	g.mu.Lock()
+	now := time.Now()
+	start := g.next
+	if start.Before(now) {
+		start = now
+	}
+	g.next = start.Add(interval)
+	g.mu.Unlock()
+	if err := sleep(ctx, nil, start.Sub(now)); err != nil {
+		g.release()
+		return err
+	}
+	return nil
+}
+
+func (g *hostGate) release() {
+	<-g.slots
+}
@@ -0,0 +1,164 @@
+package reqwest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newFileServer serves content for every path under /files/ with the ETag
+// "v1", honouring Range and If-Range requests. The first response for paths under /files/flaky/ is cut off
+// halfway through the body.
+func newFileServer(t *testing.T, content []byte) (*httptest.Server, *sync.Map) {
+	var cut sync.Map
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/files/") {
+			http.NotFound(w, r)
+			return
+		}
+		if _, done := cut.LoadOrStore(r.URL.Path, true); !done && strings.HasPrefix(r.URL.Path, "/files/flaky/") {
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("Failed to hijack: %v", err)
+				return
+			}
+			defer conn.Close()
+			fmt.Fprintf(buf, "HTTP/1.1 200 OK\r\nETag: \"v1\"\r\nContent-Length: %d\r\n\r\n", len(content))
+			buf.Write(content[:len(content)/2])
+			buf.Flush()
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	return server, &cut
+}
+
+func TestDownloadFile(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	server, _ := newFileServer(t, content)
+	defer server.Close()
+	client := NewClientBuilder().WithBaseURL(server.URL).Build()
+	dir := t.TempDir()
+
+	t.Run("resumes a partial file", func(t *testing.T) {
+		path := filepath.Join(dir, "resumed")
+		os.WriteFile(path+".part", content[:1234], 0o644)
+		os.WriteFile(path+".part.validator", []byte(`"v1"`), 0o644)
+		size, err := DownloadFile(context.Background(), client, "/files/a", path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got, _ := os.ReadFile(path)
+		if size != int64(len(content)) || !bytes.Equal(got, content) {
+			t.Errorf("Expected the full content, got %d bytes", size)
+		}
+		if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+			t.Error("Expected the .part file to be renamed")
+		}
+		if _, err := os.Stat(path + ".part.validator"); !os.IsNotExist(err) {
+			t.Error("Expected the validator to be removed")
+		}
+	})
+
+	t.Run("changed file is downloaded again", func(t *testing.T) {
+		for name, validator := range map[string]string{"stale validator": `"v0"`, "no validator": ""} {
+			path := filepath.Join(dir, strings.ReplaceAll(name, " ", "-"))
+			os.WriteFile(path+".part", []byte("old version"), 0o644)
+			if validator != "" {
+				os.WriteFile(path+".part.validator", []byte(validator), 0o644)
+			}
+			if _, err := DownloadFile(context.Background(), client, "/files/a", path); err != nil {
+				t.Fatalf("%s: unexpected error: %v", name, err)
+			}
+			if got, _ := os.ReadFile(path); !bytes.Equal(got, content) {
+				t.Errorf("%s: expected the new content, got %d bytes", name, len(got))
+			}
+		}
+	})
+
+	t.Run("complete partial file", func(t *testing.T) {
+		path := filepath.Join(dir, "complete")
+		os.WriteFile(path+".part", content, 0o644)
+		os.WriteFile(path+".part.validator", []byte(`"v1"`), 0o644)
+		if _, err := DownloadFile(context.Background(), client, "/files/a", path); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got, _ := os.ReadFile(path); !bytes.Equal(got, content) {
+			t.Error("Expected the partial file to be kept")
+		}
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		path := filepath.Join(dir, "missing")
+		_, err := DownloadFile(context.Background(), client, "/missing", path)
+		if err == nil || !strings.Contains(err.Error(), "unexpected status 404") {
+			t.Errorf("Expected a 404 error, got %v", err)
+		}
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Error("Expected no file")
+		}
+	})
+}
+
+func TestDownloadManager(t *testing.T) {
+	content := bytes.Repeat([]byte("abcdefgh"), 512)
+	var active, peak int32
+	files, _ := newFileServer(t, content)
+	defer files.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		http.Redirect(w, r, files.URL+r.URL.Path, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	jobs := []DownloadJob{
+		{URL: server.URL + "/files/one", Path: filepath.Join(dir, "one")},
+		{URL: server.URL + "/files/flaky/two", Path: filepath.Join(dir, "two")},
+		{URL: server.URL + "/files/three", Path: filepath.Join(dir, "three")},
+		{URL: server.URL + "/missing", Path: filepath.Join(dir, "missing")},
+	}
+	var progress []DownloadProgress
+	report := NewDownloadManager(NewClientBuilder().WithRetryConfig(nil).Build()).
+		WithConcurrency(4).
+		WithPerHostLimit(1).
+		WithProgress(func(p DownloadProgress) { progress = append(progress, p) }).
+		Run(context.Background(), jobs)
+
+	if report.Succeeded != 3 || report.Failed != 1 || report.Bytes != int64(3*len(content)) {
+		t.Errorf("Expected 3 successes and 1 failure, got %+v", report)
+	}
+	if err := report.Err(); err == nil || !strings.Contains(err.Error(), "/missing") {
+		t.Errorf("Expected the missing file to fail, got %v", err)
+	}
+	if flaky := report.Results[1]; flaky.Err != nil || !flaky.Resumed || flaky.Attempts != 2 {
+		t.Errorf("Expected the flaky download to be resumed, got %+v", flaky)
+	}
+	if got, _ := os.ReadFile(jobs[1].Path); !bytes.Equal(got, content) {
+		t.Error("Expected the resumed file to be complete")
+	}
+	if peak > 1 {
+		t.Errorf("Expected at most 1 request per host, got %d", peak)
+	}
+	if len(progress) != len(jobs) || progress[len(progress)-1].Succeeded != 3 {
+		t.Errorf("Expected a progress report per job, got %+v", progress)
+	}
+}
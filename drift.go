@@ -0,0 +1,212 @@
+package reqwest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// DriftEvent reports fields that appeared in or disappeared from a response
+// compared to the shape captured for the same endpoint.
+type DriftEvent struct {
+	Key     string   `json:"key"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// DefaultMaxDriftBaselines is how many baselines a DriftMonitor learns from
+// live traffic unless WithMaxBaselines says otherwise.
+const DefaultMaxDriftBaselines = 1000
+
+// DriftMonitor compares live JSON response shapes against captured ones.
+// Endpoints are keyed by method and route, e.g. "GET /users/{id}": the path
+// template of requests built with NewTemplateRequest, or the path with ID-like
+// segments replaced (see NormalizeRoute). The first response seen for an
+// endpoint becomes its baseline unless one was loaded or learned beforehand.
+// Baselines are never overwritten by live traffic, so a drifting endpoint
+// keeps reporting until the capture is refreshed. It is safe for concurrent
+// use.
+type DriftMonitor struct {
+	mu           sync.Mutex
+	shapes       map[string][]string
+	onDrift      func(DriftEvent)
+	key          func(*http.Request) string
+	maxBaselines int
+}
+
+// NewDriftMonitor creates a monitor that calls onDrift for every response
+// whose shape differs from its baseline.
+func NewDriftMonitor(onDrift func(DriftEvent)) *DriftMonitor {
+	return &DriftMonitor{
+		shapes:       make(map[string][]string),
+		onDrift:      onDrift,
+		key:          driftKey,
+		maxBaselines: DefaultMaxDriftBaselines,
+	}
+}
+
+// WithKeyFunc keys responses by key(req) instead of method and route, e.g.
+// to group endpoints whose paths NormalizeRoute can't tell apart. Responses
+// it returns "" for are not monitored.
+func (m *DriftMonitor) WithKeyFunc(key func(req *http.Request) string) *DriftMonitor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.key = key
+	return m
+}
+
+// WithMaxBaselines stops learning baselines from live traffic once there are
+// n of them, so an unexpected key, such as a path NormalizeRoute doesn't
+// recognize as an ID, can't grow the monitor without bound; zero removes the
+// limit. Learn and Load are not limited.
+func (m *DriftMonitor) WithMaxBaselines(n int) *DriftMonitor {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxBaselines = n
+	return m
+}
+
+// driftKey is the default key: method and route.
+func driftKey(req *http.Request) string {
+	route := RouteFromContext(req.Context())
+	if route == "" {
+		route = NormalizeRoute(req.URL.String())
+	}
+	return req.Method + " " + route
+}
+
+// LearnResponse records body as the baseline shape for the endpoint of req,
+// keyed like live responses, e.g. to seed baselines from recorded fixtures.
+func (m *DriftMonitor) LearnResponse(req *http.Request, body []byte) error {
+	m.mu.Lock()
+	key := m.key(req)
+	m.mu.Unlock()
+	if key == "" {
+		return nil
+	}
+	return m.Learn(key, body)
+}
+
+// observeResponse compares body with the baseline for the endpoint of req.
+func (m *DriftMonitor) observeResponse(req *http.Request, body []byte) {
+	m.mu.Lock()
+	key := m.key(req)
+	m.mu.Unlock()
+	if key != "" {
+		m.Observe(key, body)
+	}
+}
+
+// Learn records body as the baseline shape for key, replacing any previous
+// capture. Keys have the form "METHOD /route" unless set by WithKeyFunc.
+func (m *DriftMonitor) Learn(key string, body []byte) error {
+	shape, err := jsonShape(body)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shapes[key] = shape
+	return nil
+}
+
+// Observe compares body with the baseline for key and emits a DriftEvent when
+// fields were added or removed. Bodies that are not JSON are ignored.
+func (m *DriftMonitor) Observe(key string, body []byte) {
+	shape, err := jsonShape(body)
+	if err != nil {
+		return
+	}
+
+	m.mu.Lock()
+	baseline, ok := m.shapes[key]
+	if !ok && (m.maxBaselines <= 0 || len(m.shapes) < m.maxBaselines) {
+		m.shapes[key] = shape
+	}
+	m.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	event := DriftEvent{
+		Key:     key,
+		Added:   difference(shape, baseline),
+		Removed: difference(baseline, shape),
+	}
+	if (len(event.Added) > 0 || len(event.Removed) > 0) && m.onDrift != nil {
+		m.onDrift(event)
+	}
+}
+
+// Save writes all baselines as JSON so they can be committed next to
+// recorded fixtures and loaded by later runs.
+func (m *DriftMonitor) Save(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(m.shapes); err != nil {
+		return fmt.Errorf("failed to save drift baselines: %v", err)
+	}
+	return nil
+}
+
+// Load merges baselines previously written by Save.
+func (m *DriftMonitor) Load(r io.Reader) error {
+	var shapes map[string][]string
+	if err := json.NewDecoder(r).Decode(&shapes); err != nil {
+		return fmt.Errorf("failed to load drift baselines: %v", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, shape := range shapes {
+		m.shapes[key] = shape
+	}
+	return nil
+}
+
+// jsonShape flattens a JSON document into the sorted set of its field paths,
+// using the same notation as unknown field reporting.
+func jsonShape(body []byte) ([]string, error) {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode json: %v", err)
+	}
+	seen := make(map[string]bool)
+	collectShape(doc, "", seen)
+	shape := make([]string, 0, len(seen))
+	for path := range seen {
+		shape = append(shape, path)
+	}
+	sort.Strings(shape)
+	return shape, nil
+}
+
+func collectShape(doc any, path string, seen map[string]bool) {
+	switch value := doc.(type) {
+	case map[string]any:
+		for key, child := range value {
+			childPath := joinFieldPath(path, key)
+			seen[childPath] = true
+			collectShape(child, childPath, seen)
+		}
+	case []any:
+		for _, item := range value {
+			collectShape(item, path+"[]", seen)
+		}
+	}
+}
+
+func difference(a, b []string) []string {
+	var diff []string
+	for _, item := range a {
+		if _, found := slices.BinarySearch(b, item); !found {
+			diff = append(diff, item)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,182 @@
+package reqwest
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDriftMonitor(t *testing.T) {
+	t.Run("first observation becomes baseline", func(t *testing.T) {
+		var events []DriftEvent
+		monitor := NewDriftMonitor(func(e DriftEvent) { events = append(events, e) })
+
+		monitor.Observe("GET /users", []byte(`{"id": 1, "name": "a"}`))
+		monitor.Observe("GET /users", []byte(`{"id": 2, "name": "b"}`))
+
+		if len(events) != 0 {
+			t.Errorf("Expected no drift for identical shapes, got %v", events)
+		}
+	})
+
+	t.Run("added and removed fields", func(t *testing.T) {
+		var events []DriftEvent
+		monitor := NewDriftMonitor(func(e DriftEvent) { events = append(events, e) })
+
+		monitor.Observe("GET /orders", []byte(`{"items": [{"sku": "a", "qty": 1}], "total": 1}`))
+		monitor.Observe("GET /orders", []byte(`{"items": [{"sku": "a", "discount": 0}], "total": 1}`))
+
+		if len(events) != 1 {
+			t.Fatalf("Expected 1 drift event, got %d", len(events))
+		}
+		event := events[0]
+		if event.Key != "GET /orders" {
+			t.Errorf("Expected key GET /orders, got %q", event.Key)
+		}
+		if strings.Join(event.Added, ",") != "items[].discount" {
+			t.Errorf("Expected added items[].discount, got %v", event.Added)
+		}
+		if strings.Join(event.Removed, ",") != "items[].qty" {
+			t.Errorf("Expected removed items[].qty, got %v", event.Removed)
+		}
+	})
+
+	t.Run("non-json bodies are ignored", func(t *testing.T) {
+		called := false
+		monitor := NewDriftMonitor(func(DriftEvent) { called = true })
+
+		monitor.Observe("GET /", []byte("<html>"))
+		monitor.Observe("GET /", []byte(`{"a": 1}`))
+
+		if called {
+			t.Error("Expected no drift event")
+		}
+		if err := monitor.Learn("GET /", []byte("<html>")); err == nil {
+			t.Error("Expected Learn to reject non-json body")
+		}
+	})
+
+	t.Run("save and load baselines", func(t *testing.T) {
+		recorded := NewDriftMonitor(nil)
+		if err := recorded.Learn("GET /users", []byte(`{"id": 1}`)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var buf bytes.Buffer
+		if err := recorded.Save(&buf); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		var events []DriftEvent
+		live := NewDriftMonitor(func(e DriftEvent) { events = append(events, e) })
+		if err := live.Load(&buf); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		live.Observe("GET /users", []byte(`{"id": 1, "email": "x"}`))
+
+		if len(events) != 1 || strings.Join(events[0].Added, ",") != "email" {
+			t.Errorf("Expected email to be reported as added, got %v", events)
+		}
+
+		if err := live.Load(strings.NewReader("not json")); err == nil {
+			t.Error("Expected error loading invalid baselines")
+		}
+	})
+
+	t.Run("max baselines", func(t *testing.T) {
+		var events []DriftEvent
+		monitor := NewDriftMonitor(func(e DriftEvent) { events = append(events, e) }).WithMaxBaselines(1)
+
+		monitor.Observe("GET /a", []byte(`{"id": 1}`))
+		monitor.Observe("GET /b", []byte(`{"id": 1}`))
+		monitor.Observe("GET /b", []byte(`{"name": "x"}`))
+
+		if len(events) != 0 {
+			t.Errorf("Expected no baseline for GET /b, got %v", events)
+		}
+	})
+}
+
+func TestClientBuilder_WithDriftMonitor(t *testing.T) {
+	responses := []string{`{"id": 1}`, `{"id": 1, "extra": true}`}
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(responses[calls]))
+		calls++
+	}))
+	defer server.Close()
+
+	var events []DriftEvent
+	monitor := NewDriftMonitor(func(e DriftEvent) { events = append(events, e) })
+	cli := NewClientBuilder().WithBaseURL(server.URL).WithDriftMonitor(monitor).Build()
+
+	for range responses {
+		resp, err := cli.Get(context.TODO(), "/things/")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var out map[string]any
+		if err := resp.JSON(&out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if len(events) != 1 || events[0].Key != "GET /things/" {
+		t.Errorf("Expected one drift event for GET /things/, got %v", events)
+	}
+}
+
+func TestDriftMonitor_Keys(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/2") || strings.HasSuffix(r.URL.Path, "/bob") {
+			_, _ = w.Write([]byte(`{"id": 2, "email": "x"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+
+	decode := func(t *testing.T, cli Client, req *Request) {
+		t.Helper()
+		resp, err := cli.Do(context.TODO(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var out map[string]any
+		if err := resp.JSON(&out); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	t.Run("route", func(t *testing.T) {
+		var events []DriftEvent
+		monitor := NewDriftMonitor(func(e DriftEvent) { events = append(events, e) })
+		cli := NewClientBuilder().WithBaseURL(server.URL).WithDriftMonitor(monitor).Build()
+
+		decode(t, cli, NewRequest(http.MethodGet, "/users/1"))
+		decode(t, cli, NewRequest(http.MethodGet, "/users/2"))
+		decode(t, cli, NewTemplateRequest(http.MethodGet, "/people/{name}", map[string]string{"name": "alice"}))
+		decode(t, cli, NewTemplateRequest(http.MethodGet, "/people/{name}", map[string]string{"name": "bob"}))
+
+		if len(events) != 2 || events[0].Key != "GET /users/{id}" || events[1].Key != "GET /people/{name}" {
+			t.Errorf("Expected drift for GET /users/{id} and GET /people/{name}, got %v", events)
+		}
+	})
+
+	t.Run("key func", func(t *testing.T) {
+		var events []DriftEvent
+		monitor := NewDriftMonitor(func(e DriftEvent) { events = append(events, e) }).
+			WithKeyFunc(func(req *http.Request) string { return "users" })
+		cli := NewClientBuilder().WithBaseURL(server.URL).WithDriftMonitor(monitor).Build()
+
+		decode(t, cli, NewRequest(http.MethodGet, "/users/alice"))
+		decode(t, cli, NewRequest(http.MethodGet, "/users/bob"))
+
+		if len(events) != 1 || events[0].Key != "users" {
+			t.Errorf("Expected drift for users, got %v", events)
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// IPPreference selects the address family dialed first.
+type IPPreference int
+
+const (
+	// IPDefault dials the family of the resolver's first address first, as
+	// net.Dialer does.
+	IPDefault IPPreference = iota
+	// PreferIPv4 dials IPv4 addresses first and races IPv6 ones after the
+	// fallback delay. Hosts without IPv4 addresses are dialed over IPv6.
+	PreferIPv4
+	// PreferIPv6 dials IPv6 addresses first and races IPv4 ones after the
+	// fallback delay. Hosts without IPv6 addresses are dialed over IPv4.
+	PreferIPv6
+	// IPv4Only never dials IPv6 addresses, for networks with broken IPv6,
+	// including IP literals.
+	IPv4Only
+	// IPv6Only never dials IPv4 addresses.
+	IPv6Only
+)
+
+var ipPreferenceNames = [...]string{"default", "prefer-ipv4", "prefer-ipv6", "ipv4-only", "ipv6-only"}
+
+func (p IPPreference) String() string {
+	if p >= 0 && int(p) < len(ipPreferenceNames) {
+		return ipPreferenceNames[p]
+	}
+	return fmt.Sprintf("IPPreference(%d)", int(p))
+}
+
+// DualStack configures WithDualStack.
+type DualStack struct {
+	Preference IPPreference `json:"preference"`
+	// FallbackDelay is how long the preferred family has before the other
+	// one is dialed in parallel ("Happy Eyeballs"), 300ms by default.
+	FallbackDelay time.Duration `json:"fallback_delay"`
+	// DisableRacing dials the other family only after every address of the
+	// preferred one failed.
+	DisableRacing bool `json:"disable_racing"`
+}
+
+func (d DualStack) withDefaults() DualStack {
+	if d.FallbackDelay <= 0 {
+		d.FallbackDelay = 300 * time.Millisecond
+	}
+	return d
+}
+
+// transportOption resolves host names itself, with dns if it is set, so it
+// can order and race the addresses.
+func (d DualStack) transportOption(dns *dnsCache) transportOption {
+	return func(t *http.Transport) {
+		dial := t.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+		}
+		lookup := net.DefaultResolver.LookupHost
+		if dns != nil {
+			lookup = dns.lookup
+		}
+		t.DialContext = d.dial(dial, lookup)
+	}
+}
+
+func (d DualStack) dial(next DialContextFunc, lookup func(context.Context, string) ([]string, error)) DialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return next(ctx, network, addr)
+		}
+		if net.ParseIP(host) != nil {
+			if primary, _ := d.partition([]string{host}); len(primary) == 0 {
+				return nil, fmt.Errorf("failed to dial %s: address not allowed by %v", host, d.Preference)
+			}
+			return next(ctx, network, addr)
+		}
+		addrs, err := resolveTraced(ctx, host, lookup)
+		if err != nil {
+			return nil, err
+		}
+		primary, fallback := d.partition(addrs)
+		if len(primary) == 0 {
+			return nil, fmt.Errorf("failed to resolve %s: no address allowed by %v", host, d.Preference)
+		}
+		if d.DisableRacing || len(fallback) == 0 {
+			return dialSerial(ctx, next, network, append(primary, fallback...), port)
+		}
+		return d.race(ctx, next, network, primary, fallback, port)
+	}
+}
+
+// partition splits addrs into the family to dial first and the other one.
+// A preference falls back to the other family when the preferred one has no
+// addresses; only the *Only modes drop addresses.
+func (d DualStack) partition(addrs []string) (primary, fallback []string) {
+	var v4, v6 []string
+	for _, addr := range addrs {
+		if ip := net.ParseIP(addr); ip != nil && ip.To4() != nil {
+			v4 = append(v4, addr)
+		} else {
+			v6 = append(v6, addr)
+		}
+	}
+	switch d.Preference {
+	case PreferIPv4:
+		if len(v4) == 0 {
+			return v6, nil
+		}
+		return v4, v6
+	case PreferIPv6:
+		if len(v6) == 0 {
+			return v4, nil
+		}
+		return v6, v4
+	case IPv4Only:
+		return v4, nil
+	case IPv6Only:
+		return v6, nil
+	}
+	if len(addrs) > 0 && len(v6) > 0 && v6[0] == addrs[0] {
+		return v6, v4
+	}
+	return v4, v6
+}
+
+// race dials primary and, once it has failed or FallbackDelay has passed,
+// fallback in parallel. The first connection wins; a later one is closed.
+func (d DualStack) race(ctx context.Context, next DialContextFunc, network string, primary, fallback []string, port string) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		conn net.Conn
+		err  error
+	}
+	results := make(chan result, 2)
+	start := func(addrs []string) {
+		go func() {
+			conn, err := dialSerial(ctx, next, network, addrs, port)
+			results <- result{conn, err}
+		}()
+	}
+	start(primary)
+	timer := time.NewTimer(d.FallbackDelay)
+	defer timer.Stop()
+	pending, raced := 1, false
+	var errs []error
+	for {
+		select {
+		case <-timer.C:
+			if !raced {
+				start(fallback)
+				pending, raced = pending+1, true
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				go func(pending int) {
+					for range pending {
+						if late := <-results; late.conn != nil {
+							late.conn.Close()
+						}
+					}
+				}(pending)
+				return r.conn, nil
+			}
+			errs = append(errs, r.err)
+			if !raced && ctx.Err() == nil {
+				start(fallback)
+				pending, raced = pending+1, true
+			}
+			if pending == 0 {
+				return nil, errors.Join(errs...)
+			}
+		}
+	}
+}
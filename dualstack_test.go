@@ -0,0 +1,202 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDualStack_partition(t *testing.T) {
+	addrs := []string{"2001:db8::1", "192.0.2.1", "2001:db8::2", "192.0.2.2"}
+	tests := []struct {
+		preference        IPPreference
+		addrs             []string
+		primary, fallback []string
+	}{
+		{IPDefault, addrs, []string{"2001:db8::1", "2001:db8::2"}, []string{"192.0.2.1", "192.0.2.2"}},
+		{IPDefault, []string{"192.0.2.1", "2001:db8::1"}, []string{"192.0.2.1"}, []string{"2001:db8::1"}},
+		{PreferIPv4, addrs, []string{"192.0.2.1", "192.0.2.2"}, []string{"2001:db8::1", "2001:db8::2"}},
+		{PreferIPv6, []string{"192.0.2.1", "2001:db8::1"}, []string{"2001:db8::1"}, []string{"192.0.2.1"}},
+		{PreferIPv4, []string{"2001:db8::1"}, []string{"2001:db8::1"}, nil},
+		{PreferIPv6, []string{"192.0.2.1"}, []string{"192.0.2.1"}, nil},
+		{IPv4Only, addrs, []string{"192.0.2.1", "192.0.2.2"}, nil},
+		{IPv6Only, addrs, []string{"2001:db8::1", "2001:db8::2"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.preference.String(), func(t *testing.T) {
+			primary, fallback := DualStack{Preference: tt.preference}.partition(tt.addrs)
+			if !slices.Equal(primary, tt.primary) || !slices.Equal(fallback, tt.fallback) {
+				t.Errorf("partition() = %v, %v, want %v, %v", primary, fallback, tt.primary, tt.fallback)
+			}
+		})
+	}
+}
+
+func TestDualStack_dial(t *testing.T) {
+	lookup := func(context.Context, string) ([]string, error) {
+		return []string{"2001:db8::1", "192.0.2.1"}, nil
+	}
+	// fakeDialer connects to IPv4 addresses and black-holes IPv6 ones, or
+	// refuses them at once if refuse is set.
+	type fakeDialer struct {
+		mu     sync.Mutex
+		dialed []string
+		refuse bool
+	}
+	dialer := func(f *fakeDialer) DialContextFunc {
+		return func(ctx context.Context, network, addr string) (net.Conn, error) {
+			f.mu.Lock()
+			f.dialed = append(f.dialed, addr)
+			f.mu.Unlock()
+			if strings.HasPrefix(addr, "[") {
+				if f.refuse {
+					return nil, errors.New("connection refused")
+				}
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			conn, _ := net.Pipe()
+			return conn, nil
+		}
+	}
+
+	t.Run("races the other family after the delay", func(t *testing.T) {
+		f := &fakeDialer{}
+		dial := DualStack{FallbackDelay: 20 * time.Millisecond}.dial(dialer(f), lookup)
+		start := time.Now()
+		conn, err := dial(context.Background(), "tcp", "example.com:443")
+		if err != nil {
+			t.Fatalf("dial() error = %v", err)
+		}
+		conn.Close()
+		if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+			t.Errorf("dial() took %v, want the fallback delay first", elapsed)
+		}
+		if want := []string{"[2001:db8::1]:443", "192.0.2.1:443"}; !slices.Equal(f.dialed, want) {
+			t.Errorf("dialed %v, want %v", f.dialed, want)
+		}
+	})
+
+	t.Run("prefer", func(t *testing.T) {
+		f := &fakeDialer{}
+		dial := DualStack{Preference: PreferIPv4, FallbackDelay: time.Hour}.dial(dialer(f), lookup)
+		conn, err := dial(context.Background(), "tcp", "example.com:443")
+		if err != nil {
+			t.Fatalf("dial() error = %v", err)
+		}
+		conn.Close()
+		if want := []string{"192.0.2.1:443"}; !slices.Equal(f.dialed, want) {
+			t.Errorf("dialed %v, want %v", f.dialed, want)
+		}
+	})
+
+	t.Run("prefer with a single family", func(t *testing.T) {
+		for _, tt := range []struct {
+			preference IPPreference
+			addr       string
+			want       string
+		}{
+			{PreferIPv4, "2001:db8::1", "[2001:db8::1]:443"},
+			{PreferIPv6, "192.0.2.1", "192.0.2.1:443"},
+		} {
+			f := &fakeDialer{refuse: true}
+			dial := DualStack{Preference: tt.preference}.dial(dialer(f), func(context.Context, string) ([]string, error) {
+				return []string{tt.addr}, nil
+			})
+			if conn, err := dial(context.Background(), "tcp", "example.com:443"); err == nil {
+				conn.Close()
+			}
+			if want := []string{tt.want}; !slices.Equal(f.dialed, want) {
+				t.Errorf("%v dialed %v, want %v", tt.preference, f.dialed, want)
+			}
+		}
+	})
+
+	t.Run("falls back at once when the preferred family fails", func(t *testing.T) {
+		f := &fakeDialer{refuse: true}
+		dial := DualStack{FallbackDelay: time.Hour}.dial(dialer(f), lookup)
+		conn, err := dial(context.Background(), "tcp", "example.com:443")
+		if err != nil {
+			t.Fatalf("dial() error = %v", err)
+		}
+		conn.Close()
+	})
+
+	t.Run("without racing", func(t *testing.T) {
+		f := &fakeDialer{}
+		dial := DualStack{DisableRacing: true}.dial(dialer(f), lookup)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		if _, err := dial(ctx, "tcp", "example.com:443"); err == nil {
+			t.Fatal("dial() error = nil, want the black-holed IPv6 dial to time out")
+		}
+		if want := []string{"[2001:db8::1]:443"}; !slices.Equal(f.dialed, want) {
+			t.Errorf("dialed %v, want only IPv6 while it is pending", f.dialed)
+		}
+	})
+
+	t.Run("only", func(t *testing.T) {
+		f := &fakeDialer{}
+		dial := DualStack{Preference: IPv6Only}.dial(dialer(f), func(context.Context, string) ([]string, error) {
+			return []string{"192.0.2.1"}, nil
+		})
+		if _, err := dial(context.Background(), "tcp", "example.com:443"); err == nil || len(f.dialed) != 0 {
+			t.Errorf("dial() error = %v after dialing %v, want an error without dialing", err, f.dialed)
+		}
+	})
+
+	t.Run("only applies to IP literals", func(t *testing.T) {
+		f := &fakeDialer{}
+		dial := DualStack{Preference: IPv4Only}.dial(dialer(f), lookup)
+		if _, err := dial(context.Background(), "tcp", "[2001:db8::1]:443"); err == nil || len(f.dialed) != 0 {
+			t.Errorf("dial() error = %v after dialing %v, want an error without dialing", err, f.dialed)
+		}
+		conn, err := dial(context.Background(), "tcp", "192.0.2.1:443")
+		if err != nil {
+			t.Fatalf("dial() error = %v", err)
+		}
+		conn.Close()
+	})
+}
+
+func TestClientBuilder_WithDualStack(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+
+	cli := NewClientBuilder().WithDualStack(DualStack{Preference: PreferIPv4}).Build()
+	resp, err := cli.Get(context.Background(), "http://localhost:"+port+"/")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body().Close()
+	if policy := cli.Policy().DualStack; policy == nil || policy.Preference != PreferIPv4 || policy.FallbackDelay != 300*time.Millisecond {
+		t.Errorf("Policy().DualStack = %+v, want the preference and default delay", policy)
+	}
+
+	t.Run("custom dialers get the host name", func(t *testing.T) {
+		var dialed string
+		cli := NewClientBuilder().
+			WithDualStack(DualStack{Preference: IPv4Only}).
+			WithDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+				dialed = addr
+				return new(net.Dialer).DialContext(ctx, network, server.Listener.Addr().String())
+			}).
+			Build()
+		resp, err := cli.Get(context.Background(), "http://service.invalid/")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Body().Close()
+		if dialed != "service.invalid:80" {
+			t.Errorf("dialed %q, want service.invalid:80", dialed)
+		}
+	})
+}
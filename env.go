@@ -0,0 +1,204 @@
+package reqwest
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by WithEnvDefaults.
+const (
+	EnvBaseURL               = "REQWEST_BASE_URL"
+	EnvTimeout               = "REQWEST_TIMEOUT"
+	EnvMaxRetries            = "REQWEST_MAX_RETRIES"
+	EnvMaxConcurrentRequests = "REQWEST_MAX_CONCURRENT_REQUESTS"
+)
+
+// EnvConfig is the configuration WithEnvDefaults resolved from the
+// environment. Unset variables are left zero.
+type EnvConfig struct {
+	BaseURL               string        `json:"base_url,omitempty"`
+	Timeout               time.Duration `json:"timeout,omitempty"`
+	MaxRetries            *int          `json:"max_retries,omitempty"`
+	MaxConcurrentRequests int           `json:"max_concurrent_requests,omitempty"`
+	HTTPProxy             string        `json:"http_proxy,omitempty"`
+	HTTPSProxy            string        `json:"https_proxy,omitempty"`
+	NoProxy               string        `json:"no_proxy,omitempty"`
+	// Invalid lists variables that were ignored because their value could
+	// not be parsed.
+	Invalid []string `json:"invalid,omitempty"`
+}
+
+// LoadEnvConfig reads the variables WithEnvDefaults honours, e.g. to validate
+// them at startup. It fails if any of them is invalid.
+func LoadEnvConfig() (EnvConfig, error) {
+	env := loadEnvConfig(os.LookupEnv)
+	if len(env.Invalid) > 0 {
+		return env, fmt.Errorf("failed to load environment: %s", strings.Join(env.Invalid, "; "))
+	}
+	return env, nil
+}
+
+func loadEnvConfig(lookup func(string) (string, bool)) EnvConfig {
+	var env EnvConfig
+	invalid := func(name, value string, err error) {
+		env.Invalid = append(env.Invalid, fmt.Sprintf("%s=%q: %v", name, value, err))
+	}
+	env.BaseURL, _ = lookup(EnvBaseURL)
+	if value, ok := lookup(EnvTimeout); ok {
+		if timeout, err := time.ParseDuration(value); err != nil || timeout < 0 {
+			invalid(EnvTimeout, value, fmt.Errorf("not a duration"))
+		} else {
+			env.Timeout = timeout
+		}
+	}
+	if value, ok := lookup(EnvMaxRetries); ok {
+		if retries, err := strconv.Atoi(value); err != nil || retries < 0 {
+			invalid(EnvMaxRetries, value, fmt.Errorf("not a non-negative integer"))
+		} else {
+			env.MaxRetries = &retries
+		}
+	}
+	if value, ok := lookup(EnvMaxConcurrentRequests); ok {
+		if limit, err := strconv.Atoi(value); err != nil || limit < 0 {
+			invalid(EnvMaxConcurrentRequests, value, fmt.Errorf("not a non-negative integer"))
+		} else {
+			env.MaxConcurrentRequests = limit
+		}
+	}
+	env.HTTPProxy = lookupEither(lookup, "HTTP_PROXY", "http_proxy")
+	env.HTTPSProxy = lookupEither(lookup, "HTTPS_PROXY", "https_proxy")
+	env.NoProxy = lookupEither(lookup, "NO_PROXY", "no_proxy")
+	for name, value := range map[string]string{"HTTP_PROXY": env.HTTPProxy, "HTTPS_PROXY": env.HTTPSProxy} {
+		if _, err := parseProxyURL(value); value != "" && err != nil {
+			invalid(name, value, err)
+		}
+	}
+	return env
+}
+
+func (env *EnvConfig) clone() *EnvConfig {
+	if env == nil {
+		return nil
+	}
+	c := *env
+	if env.MaxRetries != nil {
+		retries := *env.MaxRetries
+		c.MaxRetries = &retries
+	}
+	c.Invalid = slices.Clone(env.Invalid)
+	return &c
+}
+
+func lookupEither(lookup func(string) (string, bool), names ...string) string {
+	for _, name := range names {
+		if value, ok := lookup(name); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseProxyURL parses a proxy variable, which may omit the scheme.
+func parseProxyURL(value string) (*url.URL, error) {
+	if !strings.Contains(value, "://") {
+		value = "http://" + value
+	}
+	proxy, err := url.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+	if proxy.Host == "" {
+		return nil, fmt.Errorf("missing host")
+	}
+	return proxy, nil
+}
+
+// proxy returns the proxy function the environment describes, or nil if no
+// proxy is set.
+func (env EnvConfig) proxy() func(*http.Request) (*url.URL, error) {
+	httpProxy, _ := parseProxyURL(env.HTTPProxy)
+	httpsProxy, _ := parseProxyURL(env.HTTPSProxy)
+	if httpProxy == nil && httpsProxy == nil {
+		return nil
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		proxy := httpProxy
+		if req.URL.Scheme == "https" {
+			proxy = httpsProxy
+		}
+		if proxy == nil || bypassProxy(env.NoProxy, req.URL) {
+			return nil, nil
+		}
+		return proxy, nil
+	}
+}
+
+// bypassProxy reports whether noProxy, a comma-separated list of host names,
+// domain suffixes, IP addresses or CIDR ranges with optional ports, or "*",
+// matches target.
+func bypassProxy(noProxy string, target *url.URL) bool {
+	host, port := target.Hostname(), target.Port()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return true
+	}
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			return true
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if entryHost, entryPort, err := net.SplitHostPort(entry); err == nil {
+			if entryPort != port {
+				continue
+			}
+			entry = entryHost
+		}
+		entry = strings.TrimPrefix(strings.TrimPrefix(entry, "*"), ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// withEnvDefaults returns a copy of cb with settings it leaves unset taken
+// from env.
+func (cb *ClientBuilder) withEnvDefaults(env EnvConfig) *ClientBuilder {
+	cb = cb.Snapshot()
+	if env.BaseURL != "" && cb.baseURL == "" && len(cb.endpoints) == 0 {
+		cb.baseURL = strings.TrimRight(env.BaseURL, "/")
+	}
+	if env.Timeout > 0 && cb.timeout == 0 {
+		cb.timeout = env.Timeout
+	}
+	if env.MaxRetries != nil && *env.MaxRetries > 0 && cb.retryConfig == nil {
+		cb.retryConfig = NewRetryConfigBuilder().WithMaxRetries(*env.MaxRetries).Build()
+	}
+	if env.MaxConcurrentRequests > 0 && cb.maxConcurrent == 0 {
+		cb.maxConcurrent = env.MaxConcurrentRequests
+	}
+	if proxy := env.proxy(); proxy != nil {
+		// Applied first, so a WithProxy option still wins
+		cb.transportOptions = append([]transportOption{withProxy(proxy)}, cb.transportOptions...)
+	}
+	return cb
+}
@@ -0,0 +1,97 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientBuilder_WithEnvDefaults(t *testing.T) {
+	t.Run("fills unset settings", func(t *testing.T) {
+		t.Setenv(EnvBaseURL, "https://env.example.com/")
+		t.Setenv(EnvTimeout, "3s")
+		t.Setenv(EnvMaxRetries, "2")
+		t.Setenv(EnvMaxConcurrentRequests, "8")
+		cli := NewClientBuilder().WithEnvDefaults().Build()
+		policy := cli.Policy()
+		if cli.BaseURL() != "https://env.example.com" || policy.Timeout != 3*time.Second ||
+			policy.Retry.maxRetries != 2 || policy.MaxConcurrentRequests != 8 {
+			t.Errorf("Expected the environment to apply, got %v", policy)
+		}
+		if policy.Env == nil || *policy.Env.MaxRetries != 2 {
+			t.Errorf("Expected the resolved environment in the policy, got %+v", policy.Env)
+		}
+	})
+
+	t.Run("explicit settings win", func(t *testing.T) {
+		t.Setenv(EnvBaseURL, "https://env.example.com")
+		t.Setenv(EnvTimeout, "3s")
+		cli := NewClientBuilder().WithEnvDefaults().WithBaseURL("https://code.example.com").WithTimeout(time.Second).Build()
+		if cli.BaseURL() != "https://code.example.com" || cli.Policy().Timeout != time.Second {
+			t.Errorf("Expected builder settings to win, got %v", cli.Policy())
+		}
+	})
+
+	t.Run("invalid values are ignored", func(t *testing.T) {
+		t.Setenv(EnvTimeout, "soon")
+		t.Setenv(EnvMaxRetries, "-1")
+		cli := NewClientBuilder().WithEnvDefaults().Build()
+		if env := cli.Policy().Env; len(env.Invalid) != 2 || cli.Policy().Timeout != 0 {
+			t.Errorf("Expected two ignored variables, got %+v", env)
+		}
+		if _, err := LoadEnvConfig(); err == nil || !strings.Contains(err.Error(), EnvTimeout) {
+			t.Errorf("Expected LoadEnvConfig to report %s, got %v", EnvTimeout, err)
+		}
+	})
+
+	t.Run("proxy variables", func(t *testing.T) {
+		var proxied string
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			proxied = r.URL.String()
+		}))
+		defer proxy.Close()
+		t.Setenv("HTTP_PROXY", proxy.URL)
+		t.Setenv("NO_PROXY", "internal.invalid")
+		cli := NewClientBuilder().WithEnvDefaults().WithRetryConfig(nil).Build()
+		if _, err := cli.Get(context.Background(), "http://upstream.invalid/x"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if proxied != "http://upstream.invalid/x" {
+			t.Errorf("Expected the request to go through the proxy, got %q", proxied)
+		}
+		proxied = ""
+		cli.Get(context.Background(), "http://api.internal.invalid/x")
+		if proxied != "" {
+			t.Errorf("Expected NO_PROXY hosts to bypass the proxy, got %q", proxied)
+		}
+	})
+}
+
+func TestBypassProxy(t *testing.T) {
+	tests := []struct {
+		noProxy string
+		target  string
+		want    bool
+	}{
+		{"", "http://example.com", false},
+		{"", "http://localhost:8080", true},
+		{"*", "http://example.com", true},
+		{"example.com", "http://api.example.com", true},
+		{".example.com", "http://example.com", true},
+		{"example.com", "http://badexample.com", false},
+		{"example.com:8443", "https://example.com:8443", true},
+		{"example.com:8443", "https://example.com", false},
+		{"10.0.0.0/8, 192.168.1.5", "http://10.1.2.3", true},
+		{"10.0.0.0/8, 192.168.1.5", "http://192.168.1.5:9000", true},
+	}
+	for _, tt := range tests {
+		target, _ := url.Parse(tt.target)
+		if got := bypassProxy(tt.noProxy, target); got != tt.want {
+			t.Errorf("bypassProxy(%q, %s) = %v, want %v", tt.noProxy, tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package reqwest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// RetryableError is implemented by errors that know whether the request that
+// failed with them is worth retrying, e.g. domain errors returned by a
+// WithErrorDecoder decoder.
+type RetryableError interface {
+	Retryable() bool
+}
+
+// Retryable marks err as worth retrying.
+func Retryable(err error) error {
+	return retryableError{err}
+}
+
+type retryableError struct {
+	error
+}
+
+func (retryableError) Retryable() bool { return true }
+
+func (e retryableError) Unwrap() error { return e.error }
+
+func isRetryable(err error) bool {
+	var retryable RetryableError
+	return errors.As(err, &retryable) && retryable.Retryable()
+}
+
+// decodeError runs the client's error decoder on resp, leaving the body
+// readable for the caller.
+func (c *HTTPClient) decodeError(resp *Response) error {
+	data, err := resp.readBody()
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	resp.body = io.NopCloser(bytes.NewReader(data))
+	decoded := c.errorDecoder(resp)
+	resp.body = io.NopCloser(bytes.NewReader(data))
+	return decoded
+}
@@ -0,0 +1,88 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+type apiError struct {
+	Code      string `json:"code"`
+	Transient bool   `json:"transient"`
+}
+
+func (e *apiError) Error() string { return "api error " + e.Code }
+
+func decodeAPIError(resp *Response) error {
+	var envelope struct {
+		Error *apiError `json:"error"`
+	}
+	if err := resp.JSON(&envelope); err != nil || envelope.Error == nil {
+		return nil
+	}
+	if envelope.Error.Transient {
+		return Retryable(envelope.Error)
+	}
+	return envelope.Error
+}
+
+func TestWithErrorDecoder(t *testing.T) {
+	testCases := []struct {
+		name      string
+		status    int
+		body      string
+		wantCalls int32
+		wantCode  string
+	}{
+		{name: "decoded error", status: http.StatusBadRequest, body: `{"error":{"code":"invalid_sku"}}`, wantCalls: 1, wantCode: "invalid_sku"},
+		{name: "decoder vetoes retryable status", status: http.StatusServiceUnavailable, body: `{"error":{"code":"maintenance"}}`, wantCalls: 1, wantCode: "maintenance"},
+		{name: "decoder asks for retry", status: http.StatusConflict, body: `{"error":{"code":"busy","transient":true}}`, wantCalls: 3, wantCode: "busy"},
+		{name: "undecodable body falls back to status", status: http.StatusServiceUnavailable, body: `oops`, wantCalls: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			cli := NewClientBuilder().
+				WithRetryConfig(fastRetryConfig(2)).
+				WithErrorDecoder(decodeAPIError).
+				Build()
+			resp, err := cli.Get(context.Background(), server.URL)
+			if got := calls.Load(); got != tc.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tc.wantCalls)
+			}
+			if tc.wantCode == "" {
+				if err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				if body, _ := resp.Bytes(); string(body) != tc.body {
+					t.Errorf("body = %q, want %q", body, tc.body)
+				}
+				return
+			}
+
+			var domainErr *apiError
+			if !errors.As(err, &domainErr) || domainErr.Code != tc.wantCode {
+				t.Fatalf("Get() error = %v, want api error %s", err, tc.wantCode)
+			}
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) || httpErr.StatusCode != tc.status {
+				t.Errorf("Get() error = %v, want *HTTPError with status %d", err, tc.status)
+			}
+			if string(httpErr.Body) != tc.body {
+				t.Errorf("HTTPError.Body = %q, want %q", httpErr.Body, tc.body)
+			}
+		})
+	}
+}
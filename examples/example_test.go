@@ -0,0 +1,119 @@
+package examples
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rbhujang/reqwest"
+)
+
+// Retries with backoff ride out intermittent 503s.
+func ExampleNewFlakyServer() {
+	server := NewFlakyServer(2)
+	defer server.Close()
+
+	client := reqwest.NewClientBuilder().
+		WithBaseURL(server.URL).
+		WithRetryConfig(reqwest.NewRetryConfigBuilder().
+			WithMaxRetries(3).
+			WithBackoffStrategy(reqwest.NewFixedBackoffBuilder().WithDelay(10 * time.Millisecond).Build()).
+			Build()).
+		Build()
+
+	resp, err := client.Get(context.Background(), "/orders")
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	var item Item
+	if err := resp.JSON(&item); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Printf("%s succeeded on attempt %d after %d retries\n", item.Path, item.Attempt, resp.RetryAttempts())
+	// Output: /orders succeeded on attempt 3 after 2 retries
+}
+
+// A server-directed backoff waits as long as Retry-After says instead of
+// hammering a rate-limited server.
+func ExampleNewRateLimitedServer() {
+	server := NewRateLimitedServer(1, time.Second)
+	defer server.Close()
+
+	client := reqwest.NewClientBuilder().
+		WithBaseURL(server.URL).
+		WithRetryConfig(reqwest.NewRetryConfigBuilder().
+			WithMaxRetries(2).
+			WithBackoffStrategy(reqwest.NewRetryAfterBackoffBuilder().WithMaxDelay(2 * time.Second).Build()).
+			Build()).
+		Build()
+
+	for range 2 {
+		resp, err := client.Get(context.Background(), "/quota")
+		if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		resp.Body().Close()
+		fmt.Println(resp.StatusCode(), "after", resp.RetryAttempts(), "retries")
+	}
+	// Output:
+	// 200 after 0 retries
+	// 200 after 1 retries
+}
+
+// WithAuthenticator answers the first Digest challenge and pre-authenticates
+// later requests, so only nonce rotation costs another 401.
+func ExampleNewAuthServer() {
+	server := NewAuthServer("alice", "wonderland", 3)
+	defer server.Close()
+
+	client := reqwest.NewClientBuilder().
+		WithBaseURL(server.URL).
+		WithAuthenticator(reqwest.NewDigestAuth("alice", "wonderland")).
+		WithErrorOnNon2xx().
+		Build()
+
+	for range 4 {
+		resp, err := client.Get(context.Background(), "/private")
+		if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		resp.Body().Close()
+	}
+	fmt.Println("challenges:", server.Challenges())
+	// Output: challenges: 2
+}
+
+// Streamed bodies can be consumed as they arrive.
+func ExampleNewStreamingServer() {
+	server := NewStreamingServer(10 * time.Millisecond)
+	defer server.Close()
+
+	client := reqwest.NewClientBuilder().WithBaseURL(server.URL).Build()
+	resp, err := client.Do(context.Background(), reqwest.NewRequest(http.MethodGet, "/events?count=3"))
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	defer resp.Body().Close()
+
+	scanner := bufio.NewScanner(resp.Body())
+	for scanner.Scan() {
+		var item Item
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		fmt.Println("event", item.Attempt)
+	}
+	// Output:
+	// event 1
+	// event 2
+	// event 3
+}
@@ -0,0 +1,186 @@
+package examples
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rbhujang/reqwest"
+)
+
+func fastRetries(maxRetries int) *reqwest.RetryConfig {
+	return reqwest.NewRetryConfigBuilder().
+		WithMaxRetries(maxRetries).
+		WithBackoffStrategy(reqwest.NewFixedBackoffBuilder().WithDelay(5 * time.Millisecond).Build()).
+		Build()
+}
+
+// TestFeatureMatrix runs combinations of client features against the
+// reference servers. It takes a few seconds, so it is skipped with -short.
+func TestFeatureMatrix(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests in short mode")
+	}
+	ctx := context.Background()
+
+	t.Run("circuit breaker with fallback", func(t *testing.T) {
+		server := NewFlakyServer(100)
+		defer server.Close()
+
+		cli := reqwest.NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithRetryConfig(fastRetries(1)).
+			WithCircuitBreaker(2, time.Minute).
+			WithFallback(func(req *reqwest.Request, err error) (*reqwest.Response, error) {
+				if !errors.Is(err, reqwest.ErrCircuitOpen) {
+					return nil, err
+				}
+				return reqwest.NewResponse(http.StatusOK, nil, []byte(`{"path":"stub"}`)), nil
+			}).
+			Build()
+
+		resp, err := cli.Get(ctx, "/orders")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if resp.StatusCode() != http.StatusServiceUnavailable || resp.RetryAttempts() != 1 {
+			t.Errorf("first Get() = %d after %d retries, want 503 after 1", resp.StatusCode(), resp.RetryAttempts())
+		}
+		resp, err = cli.Get(ctx, "/orders")
+		if err != nil {
+			t.Fatalf("Get() with open circuit error = %v", err)
+		}
+		var item Item
+		if err := resp.JSON(&item); err != nil || !resp.FromFallback() || item.Path != "stub" {
+			t.Errorf("Get() with open circuit = %+v (fallback %v, err %v), want the stub", item, resp.FromFallback(), err)
+		}
+	})
+
+	t.Run("client rate limit stays under the server's", func(t *testing.T) {
+		server := NewRateLimitedServer(5, time.Second)
+		defer server.Close()
+
+		cli := reqwest.NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithRateLimit(4, 1).
+			WithErrorOnNon2xx().
+			Build()
+		for i := range 8 {
+			resp, err := cli.Get(ctx, "/quota")
+			if err != nil {
+				t.Fatalf("Get() #%d error = %v", i+1, err)
+			}
+			resp.Body().Close()
+		}
+	})
+
+	t.Run("retry-after recovers from server rate limits", func(t *testing.T) {
+		server := NewRateLimitedServer(2, 500*time.Millisecond)
+		defer server.Close()
+
+		cli := reqwest.NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithRetryConfig(reqwest.NewRetryConfigBuilder().
+				WithMaxRetries(3).
+				WithBackoffStrategy(reqwest.NewRetryAfterBackoffBuilder().WithMaxDelay(time.Second).Build()).
+				Build()).
+			WithErrorOnNon2xx().
+			Build()
+		var wg sync.WaitGroup
+		errs := make(chan error, 4)
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := cli.Get(ctx, "/quota")
+				if err == nil {
+					resp.Body().Close()
+				}
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+			}
+		}
+	})
+
+	t.Run("digest auth", func(t *testing.T) {
+		server := NewAuthServer("alice", "wonderland", 2)
+		defer server.Close()
+
+		cli := reqwest.NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithAuthenticator(reqwest.NewDigestAuth("alice", "wonderland")).
+			WithRequestCoalescing().
+			WithErrorOnNon2xx().
+			Build()
+		for i := range 6 {
+			resp, err := cli.Post(ctx, "/private", []byte(`{}`))
+			if err != nil {
+				t.Fatalf("Post() #%d error = %v", i+1, err)
+			}
+			resp.Body().Close()
+		}
+		// One challenge up front, then one per nonce rotation
+		if got := server.Challenges(); got != 3 {
+			t.Errorf("challenges = %d, want 3", got)
+		}
+
+		wrong := reqwest.NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithAuthenticator(reqwest.NewDigestAuth("alice", "looking-glass")).
+			WithErrorOnNon2xx().
+			Build()
+		_, err := wrong.Get(ctx, "/private")
+		var httpErr *reqwest.HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Get() with wrong password error = %v, want 401", err)
+		}
+	})
+
+	t.Run("memoized reads", func(t *testing.T) {
+		server := NewFlakyServer(0)
+		defer server.Close()
+
+		cli := reqwest.NewClientBuilder().WithBaseURL(server.URL).Build()
+		memoCtx := reqwest.WithMemo(ctx)
+		first, err := cli.Get(memoCtx, "/profile")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		second, err := cli.Get(memoCtx, "/profile")
+		if err != nil {
+			t.Fatalf("second Get() error = %v", err)
+		}
+		var a, b Item
+		first.JSON(&a)
+		second.JSON(&b)
+		if !second.Memoized() || a != b {
+			t.Errorf("second Get() = %+v (memoized %v), want %+v from memo", b, second.Memoized(), a)
+		}
+	})
+
+	t.Run("streaming under a deadline", func(t *testing.T) {
+		server := NewStreamingServer(50 * time.Millisecond)
+		defer server.Close()
+
+		cli := reqwest.NewClientBuilder().WithBaseURL(server.URL).WithTimeout(120 * time.Millisecond).Build()
+		resp, err := cli.Get(ctx, "/events?count=10")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		_, err = io.ReadAll(resp.Body())
+		resp.Body().Close()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("reading the stream error = %v, want the deadline to cut it off", err)
+		}
+	})
+}
@@ -0,0 +1,201 @@
+// Package examples runs reqwest against reference servers that misbehave the
+// way real upstreams do: failing intermittently, rate limiting, demanding
+// authentication and streaming. Its examples and integration tests double as
+// executable documentation of the client's features and as a regression net
+// for how they combine.
+//
+// The servers are exported so other packages can reuse them:
+//
+//	server := examples.NewFlakyServer(2)
+//	defer server.Close()
+//	client := reqwest.NewClientBuilder().WithBaseURL(server.URL).WithRetries().Build()
+package examples
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Item is the JSON document the reference servers answer with.
+type Item struct {
+	Path    string `json:"path"`
+	Attempt int    `json:"attempt,omitempty"`
+}
+
+func writeItem(w http.ResponseWriter, item Item) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// NewFlakyServer starts a server that fails the first failures requests to
+// each path with 503 Service Unavailable and succeeds afterwards. Successful
+// responses report which attempt got through.
+func NewFlakyServer(failures int) *httptest.Server {
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts[r.URL.Path]++
+		attempt := attempts[r.URL.Path]
+		mu.Unlock()
+		if attempt <= failures {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		writeItem(w, Item{Path: r.URL.Path, Attempt: attempt})
+	}))
+}
+
+// NewRateLimitedServer starts a server that accepts limit requests per
+// window and answers the rest with 429 Too Many Requests and a Retry-After
+// header saying when the window resets.
+func NewRateLimitedServer(limit int, window time.Duration) *httptest.Server {
+	var mu sync.Mutex
+	var count int
+	var reset time.Time
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		now := time.Now()
+		if now.After(reset) {
+			count, reset = 0, now.Add(window)
+		}
+		count++
+		allowed, wait := count <= limit, reset.Sub(now)
+		mu.Unlock()
+		if !allowed {
+			// Retry-After has whole seconds; round up so clients don't come back early
+			w.Header().Set("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		writeItem(w, Item{Path: r.URL.Path})
+	}))
+}
+
+// AuthServer is a server protected by HTTP Digest authentication (RFC 7616,
+// MD5 with qop=auth). It issues a new nonce after every NonceUses
+// authenticated requests, like servers that limit nonce lifetimes.
+type AuthServer struct {
+	*httptest.Server
+	username  string
+	password  string
+	nonceUses int
+
+	mu         sync.Mutex
+	nonce      string
+	uses       int
+	challenges int
+}
+
+// NewAuthServer starts a server that accepts username and password, issuing
+// a new nonce every nonceUses requests.
+func NewAuthServer(username, password string, nonceUses int) *AuthServer {
+	s := &AuthServer{username: username, password: password, nonceUses: nonceUses, nonce: newNonce()}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Challenges returns how many 401 challenges the server has sent.
+func (s *AuthServer) Challenges() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.challenges
+}
+
+func (s *AuthServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	authorized := s.authorized(r)
+	if authorized {
+		if s.uses++; s.uses >= s.nonceUses {
+			s.nonce, s.uses = newNonce(), 0
+		}
+	} else {
+		s.challenges++
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="examples", qop="auth", nonce=%q, algorithm=MD5`, s.nonce))
+	}
+	s.mu.Unlock()
+	if !authorized {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	writeItem(w, Item{Path: r.URL.Path})
+}
+
+func (s *AuthServer) authorized(r *http.Request) bool {
+	scheme, rest, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return false
+	}
+	params := parseAuthParams(rest)
+	if params["username"] != s.username || params["nonce"] != s.nonce || params["uri"] != r.URL.RequestURI() {
+		return false
+	}
+	h := func(v string) string {
+		sum := md5.Sum([]byte(v))
+		return hex.EncodeToString(sum[:])
+	}
+	ha1 := h(s.username + ":examples:" + s.password)
+	ha2 := h(r.Method + ":" + params["uri"])
+	want := h(strings.Join([]string{ha1, params["nonce"], params["nc"], params["cnonce"], "auth", ha2}, ":"))
+	return params["qop"] == "auth" && params["response"] == want
+}
+
+// parseAuthParams parses the comma-separated name=value pairs of an
+// Authorization header, unquoting quoted values.
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for s != "" {
+		var name, value string
+		name, s, _ = strings.Cut(s, "=")
+		if strings.HasPrefix(s, `"`) {
+			value, s, _ = strings.Cut(s[1:], `"`)
+		} else {
+			value, s, _ = strings.Cut(s, ",")
+		}
+		params[strings.TrimSpace(name)] = value
+		s = strings.TrimLeft(s, ", ")
+	}
+	return params
+}
+
+func newNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// NewStreamingServer starts a server that streams newline-delimited JSON
+// items, one every interval, flushing each as it is written. The count query
+// parameter sets how many items are sent (default 3).
+func NewStreamingServer(interval time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count, err := strconv.Atoi(r.URL.Query().Get("count"))
+		if err != nil {
+			count = 3
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		flusher, _ := w.(http.Flusher)
+		for i := range count {
+			if i > 0 {
+				select {
+				case <-r.Context().Done():
+					return
+				case <-time.After(interval):
+				}
+			}
+			json.NewEncoder(w).Encode(Item{Path: r.URL.Path, Attempt: i + 1})
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}))
+}
@@ -0,0 +1,79 @@
+package reqwest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// acceptEncoding advertises the registered content encodings. Setting the
+// header disables the transport's transparent gzip handling, so gzip is only
+// offered when a decoder for it is registered too.
+func (c *HTTPClient) acceptEncoding() string {
+	encodings := make([]string, 0, len(c.contentDecoders))
+	for encoding := range c.contentDecoders {
+		encodings = append(encodings, encoding)
+	}
+	sort.Strings(encodings)
+	return strings.Join(encodings, ", ")
+}
+
+// decodeContent replaces resp.Body with its decoded form when it was encoded
+// with a registered content encoding, metering both sides when compression
+// metrics are enabled.
+func (c *HTTPClient) decodeContent(resp *http.Response) error {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	var meter *compressionMeter
+	if c.onCompression != nil {
+		meter = newCompressionMeter(resp, encoding, c.onCompression)
+		resp.Body = meter.wire(resp.Body)
+	}
+	if err := c.decodeBody(resp, encoding); err != nil {
+		return err
+	}
+	if meter != nil {
+		resp.Body = meter.decoded(resp.Body)
+	}
+	return nil
+}
+
+func (c *HTTPClient) decodeBody(resp *http.Response, encoding string) error {
+	decoder, ok := c.contentDecoders[encoding]
+	if encoding == "" || !ok || !bodyAllowed(resp) {
+		return nil
+	}
+	body, err := decoder(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s response body: %v", encoding, err)
+	}
+	resp.Body = &decodedBody{ReadCloser: body, raw: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// bodyAllowed reports whether resp can have a body; HEAD, 204 and 304
+// responses carry Content-Encoding without any encoded bytes.
+func bodyAllowed(resp *http.Response) bool {
+	return resp.Request.Method != http.MethodHead &&
+		resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotModified
+}
+
+// decodedBody closes the raw body along with the decoder, since decoders such
+// as flate readers don't close their source.
+type decodedBody struct {
+	io.ReadCloser
+	raw io.Closer
+}
+
+func (b *decodedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if rawErr := b.raw.Close(); err == nil {
+		err = rawErr
+	}
+	return err
+}
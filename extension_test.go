@@ -0,0 +1,96 @@
+package reqwest
+
+import (
+	"compress/flate"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type staticTokenSource string
+
+func (s staticTokenSource) Token(context.Context) (string, error) {
+	return string(s), nil
+}
+
+func TestClientBuilder_WithTokenSource(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	client := NewClientBuilder().WithTokenSource(staticTokenSource("abc123")).Build()
+	if _, err := client.Get(context.Background(), server.URL); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotAuth != "Bearer abc123" {
+		t.Errorf("Expected bearer token, got %q", gotAuth)
+	}
+}
+
+func TestClientBuilder_WithRoundTripperWrapper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var order []string
+	wrapper := func(name string) RoundTripperWrapper {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	client := NewClientBuilder().
+		WithRoundTripperWrapper(wrapper("inner")).
+		WithRoundTripperWrapper(wrapper("outer")).
+		Build()
+
+	if _, err := client.Get(context.Background(), server.URL); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("Expected outer wrapper to run first, got %v", order)
+	}
+}
+
+func TestClientBuilder_WithContentDecoder(t *testing.T) {
+	var gotAcceptEncoding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAcceptEncoding = r.Header.Get("Accept-Encoding")
+		w.Header().Set("Content-Encoding", "deflate")
+		fw, _ := flate.NewWriter(w, flate.BestSpeed)
+		fw.Write([]byte("hello, world"))
+		fw.Close()
+	}))
+	defer server.Close()
+
+	client := NewClientBuilder().
+		WithContentDecoder("deflate", func(body io.ReadCloser) (io.ReadCloser, error) {
+			return flate.NewReader(body), nil
+		}).
+		Build()
+
+	resp, err := client.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body().Close()
+
+	if gotAcceptEncoding != "deflate" {
+		t.Errorf("Expected Accept-Encoding deflate, got %q", gotAcceptEncoding)
+	}
+	body, err := io.ReadAll(resp.Body())
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+	if string(body) != "hello, world" {
+		t.Errorf("Expected decoded body, got %q", body)
+	}
+	if resp.Header().Get("Content-Encoding") != "" {
+		t.Error("Expected Content-Encoding to be removed after decoding")
+	}
+}
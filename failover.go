@@ -0,0 +1,46 @@
+package reqwest
+
+import "context"
+
+// FailoverStrategy selects which base URLs configured with WithBaseURLs the
+// attempts of a logical request target.
+type FailoverStrategy int
+
+const (
+	// FailoverNone sends every attempt to the endpoint picked for the request.
+	FailoverNone FailoverStrategy = iota
+	// FailoverRoundRobin starts at the endpoint picked for the request and
+	// moves to the next endpoint on every retry.
+	FailoverRoundRobin
+	// FailoverPriority starts at the first endpoint, in the order they were
+	// configured, and moves down the list on every retry.
+	FailoverPriority
+)
+
+// attemptURLs returns the URLs that successive attempts of request cycle
+// through. With failover, endpoints whose circuit is open are tried last.
+func (c *HTTPClient) attemptURLs(ctx context.Context, request *Request) []string {
+	if c.baseURLs == nil {
+		return []string{c.buildURL(request.url)}
+	}
+	endpoints := c.baseURLs.endpoints
+	start := 0
+	if c.failover != FailoverPriority {
+		start = c.baseURLs.pick(request)
+	}
+	if c.failover == FailoverNone {
+		return []string{joinURL(endpoints[start], request.url)}
+	}
+
+	urls := make([]string, 0, len(endpoints))
+	var open []string
+	for i := range endpoints {
+		url := joinURL(endpoints[(start+i)%len(endpoints)], request.url)
+		if c.breaker != nil && c.breaker.allow(ctx, hostOf(url)) != nil {
+			open = append(open, url)
+			continue
+		}
+		urls = append(urls, url)
+	}
+	return append(urls, open...)
+}
@@ -0,0 +1,84 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newCountingServer(status int) (*httptest.Server, *int32) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(status)
+	}))
+	return server, &hits
+}
+
+func TestClient_WithFailover(t *testing.T) {
+	down, downHits := newCountingServer(http.StatusServiceUnavailable)
+	defer down.Close()
+	up, upHits := newCountingServer(http.StatusOK)
+	defer up.Close()
+
+	t.Run("priority moves down the list on retry", func(t *testing.T) {
+		atomic.StoreInt32(downHits, 0)
+		atomic.StoreInt32(upHits, 0)
+		client := NewClientBuilder().
+			WithBaseURLs(down.URL, up.URL).
+			WithFailover(FailoverPriority).
+			WithRetryConfig(fastRetryConfig(2)).
+			Build()
+
+		for i := 0; i < 3; i++ {
+			resp, err := client.Get(context.Background(), "/")
+			if err != nil || resp.StatusCode() != http.StatusOK {
+				t.Fatalf("Expected failover to the healthy endpoint, got %v", err)
+			}
+		}
+		if atomic.LoadInt32(downHits) != 3 || atomic.LoadInt32(upHits) != 3 {
+			t.Errorf("Expected 3 hits on each endpoint, got down=%d up=%d",
+				atomic.LoadInt32(downHits), atomic.LoadInt32(upHits))
+		}
+	})
+
+	t.Run("open circuits are tried last", func(t *testing.T) {
+		atomic.StoreInt32(downHits, 0)
+		atomic.StoreInt32(upHits, 0)
+		client := NewClientBuilder().
+			WithBaseURLs(down.URL, up.URL).
+			WithFailover(FailoverPriority).
+			WithCircuitBreaker(1, time.Minute).
+			WithRetryConfig(fastRetryConfig(2)).
+			Build()
+
+		for i := 0; i < 3; i++ {
+			if _, err := client.Get(context.Background(), "/"); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if got := atomic.LoadInt32(downHits); got != 1 {
+			t.Errorf("Expected the failing endpoint to be skipped once its circuit opened, got %d hits", got)
+		}
+	})
+
+	t.Run("without failover retries stay on one endpoint", func(t *testing.T) {
+		atomic.StoreInt32(downHits, 0)
+		client := NewClientBuilder().
+			WithBaseURLs(down.URL, up.URL).
+			WithRetryConfig(fastRetryConfig(2)).
+			Build()
+
+		resp, err := client.Get(context.Background(), "/")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.StatusCode() != http.StatusServiceUnavailable || atomic.LoadInt32(downHits) != 3 {
+			t.Errorf("Expected all 3 attempts on the first endpoint, got status %d and %d hits",
+				resp.StatusCode(), atomic.LoadInt32(downHits))
+		}
+	})
+}
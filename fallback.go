@@ -0,0 +1,24 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+)
+
+// degrade replaces the failure of request with the response of the client's
+// fallback function. Requests the caller gave up on fail as they are.
+func (c *HTTPClient) degrade(ctx context.Context, request *Request, err error) (*Response, error) {
+	if ctx.Err() != nil || errors.Is(err, ErrForceCancelled) {
+		return nil, err
+	}
+	resp, fallbackErr := c.fallback(request, err)
+	if fallbackErr != nil {
+		return nil, fallbackErr
+	}
+	if resp == nil {
+		return nil, err
+	}
+	resp.fromFallback = true
+	resp.decode = c.decode
+	return resp, nil
+}
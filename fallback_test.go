@@ -0,0 +1,90 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientBuilder_WithFallback(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	t.Run("serves the fallback response", func(t *testing.T) {
+		var gotReq *Request
+		var gotErr error
+		cli := NewClientBuilder().
+			WithFallback(func(req *Request, err error) (*Response, error) {
+				gotReq, gotErr = req, err
+				return NewResponse(http.StatusOK, nil, []byte(`{"items":[]}`)), nil
+			}).
+			Build()
+		resp, err := cli.Get(context.Background(), downURL+"/items")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var out struct{ Items []int }
+		if err := resp.JSON(&out); err != nil {
+			t.Fatalf("Unexpected decode error: %v", err)
+		}
+		if !resp.FromFallback() || out.Items == nil {
+			t.Errorf("Expected the fallback body, got %+v (fallback %t)", out, resp.FromFallback())
+		}
+		if gotReq == nil || gotReq.URL() != downURL+"/items" || gotErr == nil {
+			t.Errorf("Expected the failed request and its error, got %v and %v", gotReq, gotErr)
+		}
+	})
+
+	t.Run("covers open circuits", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		var gotErr error
+		cli := NewClientBuilder().
+			WithCircuitBreaker(1, time.Hour).
+			WithFallback(func(req *Request, err error) (*Response, error) {
+				gotErr = err
+				return NewResponse(http.StatusOK, nil, nil), nil
+			}).
+			Build()
+		resp, err := cli.Get(context.Background(), server.URL)
+		if err != nil || resp.FromFallback() {
+			t.Fatalf("Expected the 500 to be returned as is, got %v (fallback %t)", err, resp.FromFallback())
+		}
+		resp, err = cli.Get(context.Background(), server.URL)
+		if err != nil || !resp.FromFallback() || !errors.Is(gotErr, ErrCircuitOpen) {
+			t.Errorf("Expected a fallback for the open circuit, got %v (fallback error %v)", err, gotErr)
+		}
+	})
+
+	t.Run("fallback errors are returned", func(t *testing.T) {
+		errNoDefault := errors.New("no default")
+		cli := NewClientBuilder().
+			WithFallback(func(req *Request, err error) (*Response, error) { return nil, errNoDefault }).
+			Build()
+		if _, err := cli.Get(context.Background(), downURL); !errors.Is(err, errNoDefault) {
+			t.Errorf("Expected the fallback error, got %v", err)
+		}
+	})
+
+	t.Run("not consulted for cancelled requests", func(t *testing.T) {
+		called := false
+		cli := NewClientBuilder().
+			WithFallback(func(req *Request, err error) (*Response, error) {
+				called = true
+				return NewResponse(http.StatusOK, nil, nil), nil
+			}).
+			Build()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := cli.Get(ctx, downURL); err == nil || called {
+			t.Errorf("Expected the cancellation error without a fallback, got %v (called %t)", err, called)
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package reqwest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInjectedFault is wrapped by every failure injected by a FaultInjector.
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultInjector randomly slows down or fails attempts so resilience logic
+// (retries, circuit breakers, fallbacks) can be exercised against real
+// dependencies. Unlike SimulateOutage, which fails every attempt to a host,
+// faults hit a configurable fraction of attempts.
+type FaultInjector struct {
+	latencyRate float64
+	latency     time.Duration
+	dropRate    float64
+	errorRate   float64
+	errorStatus int
+	hosts       []string
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+type faultInjectorBuilder struct {
+	injector *FaultInjector
+}
+
+// NewFaultInjectorBuilder creates a new builder for FaultInjector.
+// The builder is not safe for concurrent use. Each goroutine should create
+// its own builder instance.
+func NewFaultInjectorBuilder() *faultInjectorBuilder {
+	return &faultInjectorBuilder{
+		injector: &FaultInjector{},
+	}
+}
+
+// WithLatency delays a fraction rate of attempts by up to d.
+func (b *faultInjectorBuilder) WithLatency(rate float64, d time.Duration) *faultInjectorBuilder {
+	b.injector.latencyRate = rate
+	b.injector.latency = d
+	return b
+}
+
+// WithDroppedConnections fails a fraction rate of attempts as if the
+// connection dropped before a response arrived. The request is not sent.
+func (b *faultInjectorBuilder) WithDroppedConnections(rate float64) *faultInjectorBuilder {
+	b.injector.dropRate = rate
+	return b
+}
+
+// WithServerErrors answers a fraction rate of attempts with status instead
+// of sending them.
+func (b *faultInjectorBuilder) WithServerErrors(rate float64, status int) *faultInjectorBuilder {
+	b.injector.errorRate = rate
+	b.injector.errorStatus = status
+	return b
+}
+
+// WithHosts limits faults to hosts matching patterns, in path.Match syntax
+// like SimulateOutage. By default every host is affected.
+func (b *faultInjectorBuilder) WithHosts(patterns ...string) *faultInjectorBuilder {
+	b.injector.hosts = patterns
+	return b
+}
+
+// WithSeed makes the injected faults reproducible.
+func (b *faultInjectorBuilder) WithSeed(seed int64) *faultInjectorBuilder {
+	// #nosec G404 - Using math/rand for fault injection is acceptable
+	b.injector.rand = rand.New(rand.NewSource(seed))
+	return b
+}
+
+func (b *faultInjectorBuilder) Build() *FaultInjector {
+	// Default to 503 Service Unavailable
+	if b.injector.errorStatus == 0 {
+		b.injector.errorStatus = http.StatusServiceUnavailable
+	}
+	if b.injector.rand == nil {
+		// #nosec G404 - Using math/rand for fault injection is acceptable
+		b.injector.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return b.injector
+}
+
+// fault is what happens to one attempt.
+type fault struct {
+	delay  time.Duration
+	drop   bool
+	status int
+}
+
+// roll decides the faults of an attempt to host.
+func (f *FaultInjector) roll(host string) fault {
+	if !f.affects(host) {
+		return fault{}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out fault
+	if f.latency > 0 && f.rand.Float64() < f.latencyRate {
+		out.delay = time.Duration(f.rand.Int63n(int64(f.latency)) + 1)
+	}
+	switch r := f.rand.Float64(); {
+	case r < f.dropRate:
+		out.drop = true
+	case r < f.dropRate+f.errorRate:
+		out.status = f.errorStatus
+	}
+	return out
+}
+
+func (f *FaultInjector) affects(host string) bool {
+	if len(f.hosts) == 0 {
+		return true
+	}
+	for _, pattern := range f.hosts {
+		if ok, _ := path.Match(strings.ToLower(pattern), host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// injectFault applies the client's fault injector to req. It reports true,
+// with the injected result, when the attempt must not be sent.
+func (c *HTTPClient) injectFault(req *http.Request) (*Response, bool, error) {
+	if c.faults == nil {
+		return nil, false, nil
+	}
+	fault := c.faults.roll(strings.ToLower(req.URL.Hostname()))
+	if err := sleep(req.Context(), c.clock, fault.delay); err != nil {
+		return nil, true, err
+	}
+	switch {
+	case fault.drop:
+		return nil, true, fmt.Errorf("failed to do http request: %w: connection dropped: %w", ErrInjectedFault, io.ErrUnexpectedEOF)
+	case fault.status != 0:
+		return &Response{
+			statusCode: fault.status,
+			proto:      "HTTP/1.1",
+			header:     http.Header{"X-Injected-Fault": {"true"}},
+			request:    req,
+			body:       io.NopCloser(strings.NewReader("")),
+			decode:     c.decode,
+		}, true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,104 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientBuilder_WithFaultInjector(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	t.Run("server errors go through retries and the breaker", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		client := NewClientBuilder().
+			WithRetryConfig(fastRetryConfig(2)).
+			WithCircuitBreaker(3, time.Hour).
+			WithFaultInjector(NewFaultInjectorBuilder().WithServerErrors(1, http.StatusBadGateway).Build()).
+			Build()
+
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.StatusCode() != http.StatusBadGateway || resp.Header().Get("X-Injected-Fault") != "true" || resp.RetryAttempts() != 2 {
+			t.Errorf("Expected an injected 502 after 2 retries, got %d after %d", resp.StatusCode(), resp.RetryAttempts())
+		}
+		if _, err := client.Get(context.Background(), server.URL); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Expected injected failures to open the circuit, got %v", err)
+		}
+		if got := atomic.LoadInt32(&hits); got != 0 {
+			t.Errorf("Expected no real requests, got %d", got)
+		}
+	})
+
+	t.Run("dropped connections are retried", func(t *testing.T) {
+		var attempts int
+		client := NewClientBuilder().
+			WithRetryConfig(fastRetryConfig(2)).
+			WithOnRetry(func(int, *Response, error, time.Duration) { attempts++ }).
+			WithFaultInjector(NewFaultInjectorBuilder().WithDroppedConnections(1).Build()).
+			Build()
+
+		if _, err := client.Get(context.Background(), server.URL); !errors.Is(err, ErrInjectedFault) {
+			t.Fatalf("Expected ErrInjectedFault, got %v", err)
+		}
+		if attempts != 2 {
+			t.Errorf("Expected 2 retries, got %d", attempts)
+		}
+	})
+
+	t.Run("latency waits on the clock", func(t *testing.T) {
+		clock := newManualClock(time.Now())
+		client := NewClientBuilder().
+			WithClock(clock).
+			WithFaultInjector(NewFaultInjectorBuilder().WithLatency(1, time.Hour).Build()).
+			Build()
+		done := make(chan error, 1)
+		go func() {
+			resp, err := client.Get(context.Background(), server.URL)
+			if err == nil {
+				resp.Body().Close()
+			}
+			done <- err
+		}()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for clock.waiting() == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("Expected the attempt to be delayed")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		clock.advance(time.Hour)
+		if err := <-done; err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("rates and hosts", func(t *testing.T) {
+		injector := NewFaultInjectorBuilder().WithServerErrors(0.3, 0).WithSeed(1).Build()
+		failed := 0
+		for range 1000 {
+			if injector.roll("api.example.com").status == http.StatusServiceUnavailable {
+				failed++
+			}
+		}
+		if failed < 230 || failed > 370 {
+			t.Errorf("Expected about 300 of 1000 attempts to fail, got %d", failed)
+		}
+
+		scoped := NewFaultInjectorBuilder().WithServerErrors(1, 0).WithHosts("*.example.com").Build()
+		if scoped.roll("api.example.com").status == 0 || scoped.roll("127.0.0.1").status != 0 {
+			t.Error("Expected only matching hosts to be affected")
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Grouper starts request groups.
+type Grouper interface {
+	Group(ctx context.Context) *Group
+}
+
+// Group runs requests concurrently under a shared context. By default the
+// first failure cancels every other request in the group; Wait returns once
+// all of them have finished.
+//
+//	g := client.Group(ctx)
+//	user := reqwest.GoJSON[User](g, reqwest.NewRequest("GET", "/users/1"))
+//	orders := reqwest.GoJSON[[]Order](g, reqwest.NewRequest("GET", "/users/1/orders"))
+//	if err := g.Wait(); err != nil { ... }
+//	u, _ := user.Get()
+type Group struct {
+	doer     Doer
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+	wg       sync.WaitGroup
+	slots    chan struct{}
+	failFast bool
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewGroup starts a group that executes requests with doer.
+func NewGroup(ctx context.Context, doer Doer) *Group {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{doer: doer, ctx: ctx, cancel: cancel, failFast: true}
+}
+
+// Group starts a request group executed by the client.
+func (c *HTTPClient) Group(ctx context.Context) *Group {
+	return NewGroup(ctx, c)
+}
+
+// WithFailFast sets whether the first failure cancels the rest of the group.
+// Without it every request runs to completion and Wait reports all failures.
+// Configure it before starting requests.
+func (g *Group) WithFailFast(failFast bool) *Group {
+	g.failFast = failFast
+	return g
+}
+
+// WithLimit bounds how many requests of the group run at once. Configure it
+// before starting requests.
+func (g *Group) WithLimit(n int) *Group {
+	g.slots = make(chan struct{}, n)
+	return g
+}
+
+// Go executes req in the background and passes the response to handle, which
+// must finish with the body before returning. A request error or an error
+// returned by handle fails the group.
+func (g *Group) Go(req *Request, handle func(*Response) error) {
+	g.spawn(req, handle, nil)
+}
+
+// spawn runs req in the background, reporting its outcome to done if set.
+func (g *Group) spawn(req *Request, handle func(*Response) error, done func(error)) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		err := g.run(req, handle)
+		if err != nil {
+			err = fmt.Errorf("%s %s: %w", req.method, req.url, err)
+			g.fail(err)
+		}
+		if done != nil {
+			done(err)
+		}
+	}()
+}
+
+func (g *Group) run(req *Request, handle func(*Response) error) error {
+	if g.slots != nil {
+		select {
+		case g.slots <- struct{}{}:
+			defer func() { <-g.slots }()
+		case <-g.ctx.Done():
+			return context.Cause(g.ctx)
+		}
+	}
+	resp, err := g.doer.Do(g.ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body().Close()
+	return handle(resp)
+}
+
+func (g *Group) fail(err error) {
+	g.mu.Lock()
+	g.errs = append(g.errs, err)
+	g.mu.Unlock()
+	if g.failFast {
+		g.cancel(err)
+	}
+}
+
+// Wait blocks until every request has finished. With fail-fast it returns the
+// first failure, otherwise all failures joined.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.errs) == 0 {
+		return nil
+	}
+	if g.failFast {
+		return g.errs[0]
+	}
+	return errors.Join(g.errs...)
+}
+
+// Result holds the decoded outcome of a request started with GoJSON.
+type Result[T any] struct {
+	value T
+	err   error
+}
+
+// Get returns the decoded value. It is only valid after Group.Wait.
+func (r *Result[T]) Get() (T, error) {
+	return r.value, r.err
+}
+
+// GoJSON executes req in g and decodes a 2xx JSON response into a T. Other
+// statuses and content types fail the group.
+func GoJSON[T any](g *Group, req *Request) *Result[T] {
+	result := &Result[T]{}
+	req = req.Clone().ExpectJSON()
+	g.spawn(req, func(resp *Response) error {
+		if resp.StatusCode() < http.StatusOK || resp.StatusCode() >= http.StatusMultipleChoices {
+			return fmt.Errorf("unexpected status %d", resp.StatusCode())
+		}
+		return resp.JSON(&result.value)
+	}, func(err error) { result.err = err })
+	return result
+}
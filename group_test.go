@@ -0,0 +1,106 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Group(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			w.Write([]byte(`{"name":"ada"}`))
+		case "/orders":
+			w.Write([]byte(`[1,2,3]`))
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "/slow":
+			select {
+			case <-time.After(5 * time.Second):
+			case <-r.Context().Done():
+			}
+		}
+	}))
+	defer server.Close()
+	client := NewClientBuilder().WithBaseURL(server.URL).Build()
+
+	t.Run("typed results", func(t *testing.T) {
+		g := client.Group(context.Background())
+		user := GoJSON[struct{ Name string }](g, NewRequest(http.MethodGet, "/user"))
+		orders := GoJSON[[]int](g, NewRequest(http.MethodGet, "/orders"))
+		if err := g.Wait(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		u, err := user.Get()
+		if err != nil || u.Name != "ada" {
+			t.Errorf("Expected user ada, got %+v (%v)", u, err)
+		}
+		o, err := orders.Get()
+		if err != nil || len(o) != 3 {
+			t.Errorf("Expected 3 orders, got %v (%v)", o, err)
+		}
+	})
+
+	t.Run("failure cancels the rest", func(t *testing.T) {
+		g := client.Group(context.Background())
+		slow := GoJSON[any](g, NewRequest(http.MethodGet, "/slow"))
+		GoJSON[any](g, NewRequest(http.MethodGet, "/fail"))
+
+		start := time.Now()
+		err := g.Wait()
+		if err == nil || !strings.Contains(err.Error(), "/fail") {
+			t.Fatalf("Expected the /fail error, got %v", err)
+		}
+		if time.Since(start) > 2*time.Second {
+			t.Error("Expected the slow request to be cancelled")
+		}
+		if _, err := slow.Get(); err == nil {
+			t.Error("Expected the cancelled request to report an error")
+		}
+	})
+
+	t.Run("without fail-fast all errors are reported", func(t *testing.T) {
+		g := client.Group(context.Background()).WithFailFast(false)
+		GoJSON[any](g, NewRequest(http.MethodGet, "/fail"))
+		GoJSON[any](g, NewRequest(http.MethodGet, "/fail"))
+		user := GoJSON[struct{ Name string }](g, NewRequest(http.MethodGet, "/user"))
+
+		err := g.Wait()
+		if err == nil || strings.Count(err.Error(), "unexpected status 500") != 2 {
+			t.Errorf("Expected two joined failures, got %v", err)
+		}
+		if _, err := user.Get(); err != nil {
+			t.Errorf("Expected the successful request to complete, got %v", err)
+		}
+	})
+
+	t.Run("limit bounds concurrency", func(t *testing.T) {
+		var inFlight, peak int32
+		doer := DoerFunc(func(ctx context.Context, req *Request) (*Response, error) {
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&inFlight, -1)
+			return nil, errors.New("done")
+		})
+		g := NewGroup(context.Background(), doer).WithFailFast(false).WithLimit(2)
+		for i := 0; i < 6; i++ {
+			g.Go(NewRequest(http.MethodGet, "/"), func(*Response) error { return nil })
+		}
+		g.Wait()
+		if got := atomic.LoadInt32(&peak); got > 2 {
+			t.Errorf("Expected at most 2 requests in flight, got %d", got)
+		}
+	})
+}
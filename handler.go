@@ -0,0 +1,107 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Next sends req on through the rest of the client.
+type Next func(ctx context.Context, req *http.Request) (*Response, error)
+
+// Handler wraps a whole exchange rather than only mutating the request: it
+// may change req before calling next, inspect or replace the response, or
+// answer without calling next at all, e.g. to serve from its own cache.
+// Handlers run in the order they were added, the first one outermost.
+type Handler func(ctx context.Context, req *http.Request, next Next) (*Response, error)
+
+var errNoResponse = errors.New("handler returned neither a response nor an error")
+
+// handleRequest runs the client's handlers around send for one logical
+// request, so they see it once regardless of retries.
+func (c *HTTPClient) handleRequest(ctx context.Context, request *Request, send func(context.Context, *Request) (*Response, error)) (*Response, error) {
+	if len(c.handlers) == 0 {
+		return send(ctx, request)
+	}
+	url := c.buildURL(request.url)
+	req, err := newHandlerRequest(ctx, request.method, url, request.header, request.body)
+	if err != nil {
+		return nil, err
+	}
+	return runHandlers(ctx, req, c.handlers, func(ctx context.Context, req *http.Request) (*Response, error) {
+		body, err := handlerRequestBody(req)
+		if err != nil {
+			return nil, err
+		}
+		next := request.Clone()
+		next.method = req.Method
+		// Keep relative URLs relative so base URL failover still applies
+		if req.URL.String() != url {
+			next.url = req.URL.String()
+		}
+		next.header = req.Header
+		next.body = body
+		return send(ctx, next)
+	})
+}
+
+// handleAttempt runs the client's attempt handlers around send for a single
+// attempt, so they see every retry.
+func (c *HTTPClient) handleAttempt(ctx context.Context, url, method string, body []byte, header http.Header, send func(context.Context, string, string, []byte, http.Header) (*Response, error)) (*Response, error) {
+	if len(c.attemptHandlers) == 0 {
+		return send(ctx, url, method, body, header)
+	}
+	req, err := newHandlerRequest(ctx, method, url, header, body)
+	if err != nil {
+		return nil, err
+	}
+	return runHandlers(ctx, req, c.attemptHandlers, func(ctx context.Context, req *http.Request) (*Response, error) {
+		body, err := handlerRequestBody(req)
+		if err != nil {
+			return nil, err
+		}
+		return send(ctx, req.URL.String(), req.Method, body, req.Header)
+	})
+}
+
+func runHandlers(ctx context.Context, req *http.Request, handlers []Handler, send Next) (*Response, error) {
+	next := send
+	for i := len(handlers) - 1; i >= 0; i-- {
+		handler, inner := handlers[i], next
+		next = func(ctx context.Context, req *http.Request) (*Response, error) {
+			resp, err := handler(ctx, req, inner)
+			if resp == nil && err == nil {
+				return nil, errNoResponse
+			}
+			return resp, err
+		}
+	}
+	return next(ctx, req)
+}
+
+func newHandlerRequest(ctx context.Context, method, url string, header http.Header, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReaderFromByteSlice(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header = header.Clone()
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	return req, nil
+}
+
+// handlerRequestBody returns the body a handler left on req.
+func handlerRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %v", err)
+	}
+	return body, nil
+}
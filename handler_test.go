@@ -0,0 +1,124 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClientBuilder_WithHandler(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("X-Seen-Tenant", r.Header.Get("X-Tenant"))
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	t.Run("short-circuit", func(t *testing.T) {
+		hits.Store(0)
+		cli := NewClientBuilder().
+			WithHandler(func(ctx context.Context, req *http.Request, next Next) (*Response, error) {
+				if req.URL.Path == "/cached" {
+					return NewResponse(http.StatusOK, nil, []byte("from handler")), nil
+				}
+				return next(ctx, req)
+			}).
+			Build()
+
+		resp, err := cli.Get(context.Background(), server.URL+"/cached")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		data, _ := resp.Bytes()
+		if string(data) != "from handler" || hits.Load() != 0 {
+			t.Errorf("body = %q with %d server hits, want handler response", data, hits.Load())
+		}
+	})
+
+	t.Run("order and mutation", func(t *testing.T) {
+		var order []string
+		cli := NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithHandler(func(ctx context.Context, req *http.Request, next Next) (*Response, error) {
+				order = append(order, "outer")
+				req.Header.Set("X-Tenant", "acme")
+				resp, err := next(ctx, req)
+				if err != nil {
+					return nil, err
+				}
+				data, _ := resp.Bytes()
+				return NewResponse(resp.StatusCode(), resp.Header(), []byte(strings.ToUpper(string(data)))), nil
+			}).
+			WithHandler(func(ctx context.Context, req *http.Request, next Next) (*Response, error) {
+				order = append(order, "inner")
+				req.URL.Path = "/rewritten"
+				return next(ctx, req)
+			}).
+			Build()
+
+		resp, err := cli.Get(context.Background(), "/original")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		data, _ := resp.Bytes()
+		if string(data) != "/REWRITTEN" {
+			t.Errorf("body = %q, want /REWRITTEN", data)
+		}
+		if got := resp.Header().Get("X-Seen-Tenant"); got != "acme" {
+			t.Errorf("server saw tenant %q, want acme", got)
+		}
+		if strings.Join(order, ",") != "outer,inner" {
+			t.Errorf("order = %v, want outer,inner", order)
+		}
+	})
+
+	t.Run("no response", func(t *testing.T) {
+		cli := NewClientBuilder().
+			WithHandler(func(ctx context.Context, req *http.Request, next Next) (*Response, error) {
+				return nil, nil
+			}).
+			Build()
+		if _, err := cli.Get(context.Background(), server.URL); !errors.Is(err, errNoResponse) {
+			t.Errorf("Get() error = %v, want errNoResponse", err)
+		}
+	})
+}
+
+func TestClientBuilder_WithAttemptHandler(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var logical, attempts atomic.Int32
+	cli := NewClientBuilder().
+		WithRetryConfig(fastRetryConfig(3)).
+		WithHandler(func(ctx context.Context, req *http.Request, next Next) (*Response, error) {
+			logical.Add(1)
+			return next(ctx, req)
+		}).
+		WithAttemptHandler(func(ctx context.Context, req *http.Request, next Next) (*Response, error) {
+			attempts.Add(1)
+			return next(ctx, req)
+		}).
+		Build()
+
+	resp, err := cli.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if resp.StatusCode() != http.StatusOK {
+		t.Errorf("StatusCode() = %d, want 200", resp.StatusCode())
+	}
+	if logical.Load() != 1 || attempts.Load() != 3 {
+		t.Errorf("handler saw %d requests and attempt handler %d, want 1 and 3", logical.Load(), attempts.Load())
+	}
+}
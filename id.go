@@ -0,0 +1,58 @@
+package reqwest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// DefaultRequestIDHeader is the header used by WithRequestID when no header
+// name is given.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+type requestIDConfig struct {
+	generator func() string
+	header    string
+}
+
+// NewUUID returns a random (version 4) UUID. It is the default generator for
+// request IDs.
+func NewUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("reqwest: failed to read random bytes: %v", err))
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// TraceparentHeader carries the W3C trace context added by WithTraceContext.
+const TraceparentHeader = "Traceparent"
+
+// traceIDOf returns the trace ID of a valid traceparent value, or "".
+func traceIDOf(traceparent string) string {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return ""
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil || strings.Trim(parts[1], "0") == "" {
+		return ""
+	}
+	return strings.ToLower(parts[1])
+}
+
+// newTraceparent returns a sampled traceparent for traceID with a fresh span
+// ID, so every attempt shows up as its own span of the same trace.
+func newTraceparent(traceID string) string {
+	return fmt.Sprintf("00-%s-%s-01", traceID, randomHex(8))
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("reqwest: failed to read random bytes: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,218 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNewUUID(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := NewUUID()
+		if !pattern.MatchString(id) {
+			t.Fatalf("NewUUID() = %q is not a version 4 UUID", id)
+		}
+		if seen[id] {
+			t.Fatalf("NewUUID() returned duplicate %q", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestClientBuilder_WithRequestID(t *testing.T) {
+	t.Run("stable across retries", func(t *testing.T) {
+		var mu sync.Mutex
+		var received []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			received = append(received, r.Header.Get("X-Correlation-ID"))
+			attempt := len(received)
+			mu.Unlock()
+			if attempt < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		counter := 0
+		cli := NewClientBuilder().
+			WithRequestID(func() string {
+				counter++
+				return "req-" + string(rune('0'+counter))
+			}, "X-Correlation-ID").
+			WithRetryConfig(NewRetryConfigBuilder().
+				WithBackoffStrategy(NewFixedBackoffBuilder().WithDelay(time.Millisecond).Build()).
+				Build()).
+			Build()
+
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		if len(received) != 3 {
+			t.Fatalf("Expected 3 attempts, got %d", len(received))
+		}
+		for _, id := range received {
+			if id != "req-1" {
+				t.Errorf("Expected every attempt to carry req-1, got %v", received)
+				break
+			}
+		}
+		if resp.RequestID() != "req-1" {
+			t.Errorf("Expected Response.RequestID() req-1, got %q", resp.RequestID())
+		}
+
+		resp, err = cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+		if resp.RequestID() != "req-2" {
+			t.Errorf("Expected a new ID for the next logical request, got %q", resp.RequestID())
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		var header string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Get(DefaultRequestIDHeader)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().WithRequestID(nil, "").Build()
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		if header == "" || header != resp.RequestID() {
+			t.Errorf("Expected generated ID in %s, got header %q and response %q",
+				DefaultRequestIDHeader, header, resp.RequestID())
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(DefaultRequestIDHeader) != "" {
+				t.Error("Expected no request ID header")
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		resp, err := NewClientBuilder().Build().Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+		if resp.RequestID() != "" {
+			t.Errorf("Expected empty request ID, got %q", resp.RequestID())
+		}
+	})
+
+	t.Run("caller-provided ID is kept", func(t *testing.T) {
+		var header string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Get(DefaultRequestIDHeader)
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().WithRequestID(nil, "").Build()
+		req := NewRequest(http.MethodGet, server.URL).WithHeader(DefaultRequestIDHeader, "inbound-42")
+		resp, err := cli.Do(context.TODO(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		if header != "inbound-42" || resp.RequestID() != "inbound-42" {
+			t.Errorf("Expected inbound-42 to be propagated, got header %q and response %q", header, resp.RequestID())
+		}
+	})
+}
+
+func TestClientBuilder_WithTraceContext(t *testing.T) {
+	t.Run("one trace across retries, one span per attempt", func(t *testing.T) {
+		var mu sync.Mutex
+		var received []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			received = append(received, r.Header.Get(TraceparentHeader))
+			attempt := len(received)
+			mu.Unlock()
+			if attempt < 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().
+			WithTraceContext().
+			WithRetryConfig(NewRetryConfigBuilder().
+				WithBackoffStrategy(NewFixedBackoffBuilder().WithDelay(time.Millisecond).Build()).
+				Build()).
+			Build()
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		if len(received) != 2 {
+			t.Fatalf("Expected 2 attempts, got %d", len(received))
+		}
+		traceID := resp.TraceID()
+		if len(traceID) != 32 {
+			t.Fatalf("Expected a 32 hex digit trace ID, got %q", traceID)
+		}
+		for _, traceparent := range received {
+			if traceIDOf(traceparent) != traceID {
+				t.Errorf("Expected trace %s in %q", traceID, traceparent)
+			}
+		}
+		if received[0] == received[1] {
+			t.Error("Expected a new span ID per attempt")
+		}
+	})
+
+	t.Run("incoming trace is continued", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		cli := NewClientBuilder().WithTraceContext().Build()
+		req := NewRequest(http.MethodGet, server.URL).
+			WithHeader(TraceparentHeader, "00-"+traceID+"-00f067aa0ba902b7-01")
+		resp, err := cli.Do(context.TODO(), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		if resp.TraceID() != traceID {
+			t.Errorf("Expected trace %s, got %q", traceID, resp.TraceID())
+		}
+	})
+
+	t.Run("invalid traceparent values", func(t *testing.T) {
+		for _, value := range []string{"", "garbage", "00-" + strings.Repeat("0", 32) + "-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01"} {
+			if got := traceIDOf(value); got != "" {
+				t.Errorf("Expected %q to be rejected, got %q", value, got)
+			}
+		}
+	})
+}
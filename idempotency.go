@@ -0,0 +1,23 @@
+package reqwest
+
+import "net/http"
+
+// IdempotencyKeyHeader is the header carrying idempotency keys, as used by
+// Stripe-style APIs.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WithIdempotencyKey attaches key to every attempt of the request so the
+// server can deduplicate retried mutations.
+func (r *Request) WithIdempotencyKey(key string) *Request {
+	return r.WithHeader(IdempotencyKeyHeader, key)
+}
+
+// needsIdempotencyKey reports whether a generated key should be attached:
+// only methods that are not idempotent by definition and that don't already
+// carry a caller-provided key.
+func needsIdempotencyKey(method string, header http.Header) bool {
+	if header.Get(IdempotencyKeyHeader) != "" {
+		return false
+	}
+	return method == http.MethodPost || method == http.MethodPatch
+}
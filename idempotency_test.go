@@ -0,0 +1,124 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func newKeyRecordingServer(failures int) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		attempt := len(keys)
+		mu.Unlock()
+		if attempt <= failures {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), keys...)
+	}
+}
+
+func TestClientBuilder_WithIdempotencyKeys(t *testing.T) {
+	t.Run("generated key reused across retries", func(t *testing.T) {
+		server, keys := newKeyRecordingServer(2)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithIdempotencyKeys().WithRetryConfig(fastRetryConfig(3)).Build()
+		resp, err := cli.Post(context.TODO(), server.URL, []byte(`{"amount": 100}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		received := keys()
+		if len(received) != 3 || received[0] == "" {
+			t.Fatalf("Expected 3 attempts with a key, got %v", received)
+		}
+		if received[1] != received[0] || received[2] != received[0] {
+			t.Errorf("Expected the same key on every attempt, got %v", received)
+		}
+	})
+
+	t.Run("new key per logical request", func(t *testing.T) {
+		server, keys := newKeyRecordingServer(0)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithIdempotencyKeys().Build()
+		for i := 0; i < 2; i++ {
+			resp, err := cli.Post(context.TODO(), server.URL, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body().Close()
+		}
+
+		received := keys()
+		if received[0] == received[1] {
+			t.Errorf("Expected distinct keys for separate requests, got %v", received)
+		}
+	})
+
+	t.Run("idempotent methods get no key", func(t *testing.T) {
+		server, keys := newKeyRecordingServer(0)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithIdempotencyKeys().Build()
+		for _, method := range []string{http.MethodGet, http.MethodPut, http.MethodDelete} {
+			resp, err := cli.Do(context.TODO(), NewRequest(method, server.URL))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body().Close()
+		}
+
+		for _, key := range keys() {
+			if key != "" {
+				t.Errorf("Expected no key for idempotent methods, got %q", key)
+			}
+		}
+	})
+
+	t.Run("explicit key wins", func(t *testing.T) {
+		server, keys := newKeyRecordingServer(1)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithIdempotencyKeys().WithRetryConfig(fastRetryConfig(1)).Build()
+		resp, err := cli.Do(context.TODO(), NewRequest(http.MethodPost, server.URL).WithIdempotencyKey("order-42"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		for _, key := range keys() {
+			if key != "order-42" {
+				t.Errorf("Expected explicit key order-42, got %q", key)
+			}
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		server, keys := newKeyRecordingServer(0)
+		defer server.Close()
+
+		resp, err := NewClientBuilder().Build().Post(context.TODO(), server.URL, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		if keys()[0] != "" {
+			t.Errorf("Expected no key by default, got %q", keys()[0])
+		}
+	})
+}
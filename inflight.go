@@ -0,0 +1,95 @@
+package reqwest
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// InflightReporter lists the requests a client is currently executing, e.g.
+// to find stuck calls in production.
+type InflightReporter interface {
+	Inflight() []InflightRequest
+}
+
+// InflightRequest is a logical request that has not finished yet. URL is the
+// URL passed to the client without its query string, so secrets in query
+// parameters don't end up in debug output.
+type InflightRequest struct {
+	RequestID string        `json:"request_id,omitempty"`
+	Method    string        `json:"method"`
+	URL       string        `json:"url"`
+	StartedAt time.Time     `json:"started_at"`
+	Elapsed   time.Duration `json:"elapsed"`
+	Attempt   int           `json:"attempt"`
+}
+
+// InflightHandler serves the reporter's in-flight requests as JSON, oldest
+// first, for mounting on an admin endpoint.
+func InflightHandler(reporter InflightReporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(reporter.Inflight())
+	})
+}
+
+type inflightCall struct {
+	requestID string
+	method    string
+	url       string
+	started   time.Time
+	attempt   atomic.Int32
+}
+
+// inflightSet tracks executing requests. Its zero value is empty.
+type inflightSet struct {
+	mu    sync.Mutex
+	calls map[*inflightCall]struct{}
+}
+
+func (s *inflightSet) add(request *Request, requestID string) *inflightCall {
+	url, _, _ := strings.Cut(request.url, "?")
+	call := &inflightCall{requestID: requestID, method: request.method, url: url, started: time.Now()}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.calls == nil {
+		s.calls = make(map[*inflightCall]struct{})
+	}
+	s.calls[call] = struct{}{}
+	return call
+}
+
+func (s *inflightSet) remove(call *inflightCall) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.calls, call)
+}
+
+func (s *inflightSet) snapshot() []InflightRequest {
+	now := time.Now()
+	s.mu.Lock()
+	requests := make([]InflightRequest, 0, len(s.calls))
+	for call := range s.calls {
+		requests = append(requests, InflightRequest{
+			RequestID: call.requestID,
+			Method:    call.method,
+			URL:       call.url,
+			StartedAt: call.started,
+			Elapsed:   now.Sub(call.started),
+			Attempt:   int(call.attempt.Load()),
+		})
+	}
+	s.mu.Unlock()
+	slices.SortFunc(requests, func(a, b InflightRequest) int { return a.StartedAt.Compare(b.StartedAt) })
+	return requests
+}
+
+// Inflight returns a snapshot of the requests currently executing, oldest
+// first. Attempt is 1-based and 0 while waiting for the first attempt.
+func (c *HTTPClient) Inflight() []InflightRequest {
+	return c.inflight.snapshot()
+}
@@ -0,0 +1,73 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_Inflight(t *testing.T) {
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/stuck" {
+			entered <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cli := NewClientBuilder().WithBaseURL(server.URL).Build()
+	if got := cli.Inflight(); len(got) != 0 {
+		t.Fatalf("Expected no in-flight requests, got %v", got)
+	}
+
+	done := make(chan error)
+	go func() {
+		resp, err := cli.Get(context.TODO(), "/stuck?token=secret")
+		if err == nil {
+			resp.Body().Close()
+		}
+		done <- err
+	}()
+	<-entered
+
+	t.Run("snapshot describes executing requests", func(t *testing.T) {
+		inflight := cli.Inflight()
+		if len(inflight) != 1 {
+			t.Fatalf("Expected 1 in-flight request, got %d", len(inflight))
+		}
+		got := inflight[0]
+		if got.Method != http.MethodGet || got.URL != "/stuck" || got.Attempt != 1 {
+			t.Errorf("Unexpected in-flight request: %+v", got)
+		}
+		if got.Elapsed <= 0 || time.Since(got.StartedAt) < got.Elapsed {
+			t.Errorf("Unexpected timing: %+v", got)
+		}
+	})
+
+	t.Run("handler serves the snapshot as JSON", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		InflightHandler(cli).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/inflight", nil))
+
+		var inflight []InflightRequest
+		if err := json.Unmarshal(recorder.Body.Bytes(), &inflight); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(inflight) != 1 || inflight[0].URL != "/stuck" {
+			t.Errorf("Unexpected handler output: %s", recorder.Body.String())
+		}
+	})
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := cli.Inflight(); len(got) != 0 {
+		t.Errorf("Expected finished requests to be removed, got %v", got)
+	}
+}
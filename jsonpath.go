@@ -0,0 +1,200 @@
+package reqwest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrJSONPathNotFound is returned when a JSONPath names a field or index the
+// document doesn't have.
+var ErrJSONPathNotFound = errors.New("json path not found")
+
+// JSONPath extracts a value from a JSON body without declaring a struct, for
+// quick scripts and tests:
+//
+//	id, err := resp.JSONPath("$.items[0].id")
+//
+// The first call reads and closes the body; later calls query the same
+// document. Values are decoded as by JSON into an any, honouring the
+// client's number mode. See EvalJSONPath for the supported syntax.
+func (r *Response) JSONPath(path string) (any, error) {
+	if r.jsonDoc == nil {
+		if err := r.checkContentType(); err != nil {
+			return nil, err
+		}
+		data, err := r.readBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		var doc any
+		if err := decodeJSON(data, &doc, decodeConfig{numberMode: r.decode.numberMode}); err != nil {
+			return nil, err
+		}
+		r.jsonDoc = &doc
+	}
+	return EvalJSONPath(*r.jsonDoc, path)
+}
+
+// EvalJSONPath evaluates path against doc, a value decoded from JSON. Paths
+// start at the root "$" and chain
+//
+//   - .name or ['name'] to select an object field,
+//   - [n] to select an array element, counting from the end if negative,
+//   - .* or [*] to select every field or element.
+//
+// A path with a wildcard returns a []any of all matches and skips elements
+// that lack the rest of the path; otherwise a missing field or index fails
+// with ErrJSONPathNotFound.
+func EvalJSONPath(doc any, path string) (any, error) {
+	segments, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	nodes := []any{doc}
+	wildcard := false
+	for i, segment := range segments {
+		wildcard = wildcard || segment.wildcard
+		var next []any
+		for _, node := range nodes {
+			selected, ok := segment.selectFrom(node)
+			if !ok && !wildcard {
+				return nil, fmt.Errorf("%w: %s has no %s", ErrJSONPathNotFound, jsonPathPrefix(segments[:i]), segment)
+			}
+			next = append(next, selected...)
+		}
+		nodes = next
+	}
+	if wildcard {
+		if nodes == nil {
+			nodes = []any{}
+		}
+		return nodes, nil
+	}
+	return nodes[0], nil
+}
+
+type jsonPathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func (s jsonPathSegment) String() string {
+	switch {
+	case s.wildcard:
+		return "[*]"
+	case s.isIndex:
+		return "[" + strconv.Itoa(s.index) + "]"
+	}
+	return "[" + strconv.Quote(s.key) + "]"
+}
+
+// selectFrom returns the values s selects from node, and false if node
+// doesn't have them.
+func (s jsonPathSegment) selectFrom(node any) ([]any, bool) {
+	switch node := node.(type) {
+	case map[string]any:
+		if s.wildcard {
+			keys := make([]string, 0, len(node))
+			for key := range node {
+				keys = append(keys, key)
+			}
+			slices.Sort(keys)
+			values := make([]any, len(keys))
+			for i, key := range keys {
+				values[i] = node[key]
+			}
+			return values, true
+		}
+		if value, ok := node[s.key]; ok && !s.isIndex {
+			return []any{value}, true
+		}
+	case []any:
+		if s.wildcard {
+			return node, true
+		}
+		index := s.index
+		if index < 0 {
+			index += len(node)
+		}
+		if s.isIndex && index >= 0 && index < len(node) {
+			return []any{node[index]}, true
+		}
+	}
+	return nil, false
+}
+
+func jsonPathPrefix(segments []jsonPathSegment) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, segment := range segments {
+		b.WriteString(segment.String())
+	}
+	return b.String()
+}
+
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid json path %q: must start with $", path)
+	}
+	var segments []jsonPathSegment
+	rest := path[1:]
+	for rest != "" {
+		var segment jsonPathSegment
+		var err error
+		switch rest[0] {
+		case '.':
+			segment, rest, err = parseJSONPathField(rest[1:])
+		case '[':
+			segment, rest, err = parseJSONPathBracket(rest[1:])
+		default:
+			err = fmt.Errorf("unexpected %q", rest[0])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid json path %q: %v", path, err)
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+func parseJSONPathField(s string) (jsonPathSegment, string, error) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		end = len(s)
+	}
+	name := s[:end]
+	switch name {
+	case "":
+		return jsonPathSegment{}, "", errors.New("empty field name")
+	case "*":
+		return jsonPathSegment{wildcard: true}, s[end:], nil
+	}
+	return jsonPathSegment{key: name}, s[end:], nil
+}
+
+func parseJSONPathBracket(s string) (jsonPathSegment, string, error) {
+	if s != "" && (s[0] == '\'' || s[0] == '"') {
+		end := strings.IndexByte(s[1:], s[0])
+		if end < 0 || !strings.HasPrefix(s[end+2:], "]") {
+			return jsonPathSegment{}, "", errors.New("unterminated quoted field name")
+		}
+		return jsonPathSegment{key: s[1 : end+1]}, s[end+3:], nil
+	}
+	end := strings.IndexByte(s, ']')
+	if end < 0 {
+		return jsonPathSegment{}, "", errors.New("missing ]")
+	}
+	if s[:end] == "*" {
+		return jsonPathSegment{wildcard: true}, s[end+1:], nil
+	}
+	index, err := strconv.Atoi(s[:end])
+	if err != nil {
+		return jsonPathSegment{}, "", fmt.Errorf("invalid index %q", s[:end])
+	}
+	return jsonPathSegment{index: index, isIndex: true}, s[end+1:], nil
+}
@@ -0,0 +1,83 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestEvalJSONPath(t *testing.T) {
+	var doc any
+	json.Unmarshal([]byte(`{
+		"items": [{"id": 1, "tags": ["a"]}, {"id": 2}],
+		"meta": {"total": 2, "next page": "/p2"}
+	}`), &doc)
+
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"$", doc},
+		{"$.items[0].id", 1.0},
+		{"$.items[-1].id", 2.0},
+		{"$['meta']['next page']", "/p2"},
+		{`$.meta["total"]`, 2.0},
+		{"$.items[*].id", []any{1.0, 2.0}},
+		{"$.items[*].tags[0]", []any{"a"}},
+		{"$.meta.*", []any{"/p2", 2.0}},
+		{"$.items[*].missing", []any{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := EvalJSONPath(doc, tt.path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %#v, got %#v", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("missing values", func(t *testing.T) {
+		for _, path := range []string{"$.nope", "$.items[5]", "$.meta[0]", "$.items.id"} {
+			if _, err := EvalJSONPath(doc, path); !errors.Is(err, ErrJSONPathNotFound) {
+				t.Errorf("%s: expected ErrJSONPathNotFound, got %v", path, err)
+			}
+		}
+	})
+
+	t.Run("invalid paths", func(t *testing.T) {
+		for _, path := range []string{"items", "$.", "$[1", "$['a]", "$[x]", "$..id"} {
+			if _, err := EvalJSONPath(doc, path); err == nil || errors.Is(err, ErrJSONPathNotFound) {
+				t.Errorf("%s: expected a syntax error, got %v", path, err)
+			}
+		}
+	})
+}
+
+func TestResponse_JSONPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items": [{"id": 12345678901234567890}], "count": 1}`))
+	}))
+	defer server.Close()
+
+	resp, err := NewClientBuilder().WithNumberMode(NumberJSON).Build().Get(context.TODO(), server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	id, err := resp.JSONPath("$.items[0].id")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if id != json.Number("12345678901234567890") {
+		t.Errorf("Expected the exact number, got %#v", id)
+	}
+	if count, err := resp.JSONPath("$.count"); err != nil || count != json.Number("1") {
+		t.Errorf("Expected a second query to reuse the body, got %v, %v", count, err)
+	}
+}
@@ -0,0 +1,124 @@
+package reqwest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Reasons a BodyLeak was reported.
+const (
+	LeakCollected = "garbage collected without being closed"
+	LeakIdle      = "idle"
+)
+
+// BodyLeak describes a response body the caller didn't close.
+type BodyLeak struct {
+	Method string
+	// URL has credentials and API keys redacted.
+	URL      string
+	OpenedAt time.Time
+	// Reason is LeakCollected or LeakIdle.
+	Reason string
+	// Stack is where the request was made.
+	Stack string
+}
+
+// leakConfig is the client's WithBodyLeakDetection setup.
+type leakConfig struct {
+	idleTimeout time.Duration
+	onLeak      func(BodyLeak)
+}
+
+// leakState is shared by a leakTrackedBody and its cleanup, which must not
+// reference the body wrapper itself or it would never be collected.
+type leakState struct {
+	body   io.ReadCloser
+	closed atomic.Bool
+	leak   BodyLeak
+	// timer is atomic as its own callback may run before it is stored
+	timer atomic.Pointer[time.Timer]
+}
+
+// close closes the underlying body and reports whether this call did.
+func (s *leakState) close() (bool, error) {
+	if !s.closed.CompareAndSwap(false, true) {
+		return false, nil
+	}
+	if timer := s.timer.Load(); timer != nil {
+		timer.Stop()
+	}
+	return true, s.body.Close()
+}
+
+// report closes a body the caller left open and reports it.
+func (s *leakState) report(reason string, onLeak func(BodyLeak)) {
+	if closed, _ := s.close(); closed {
+		leak := s.leak
+		leak.Reason = reason
+		onLeak(leak)
+	}
+}
+
+// leakTrackedBody reports bodies that are dropped, or left unread for the
+// idle timeout, without being closed, and closes them so their connection
+// goes back to the pool.
+type leakTrackedBody struct {
+	state       *leakState
+	idleTimeout time.Duration
+}
+
+// trackLeaks wraps the body of a response to req. The wrapper must only be
+// referenced from the Response: net/http holds on to the *http.Response until
+// its body is closed, which would keep a leaked wrapper from being collected.
+func (c *HTTPClient) trackLeaks(ctx context.Context, req *http.Request, body io.ReadCloser) io.ReadCloser {
+	leak := BodyLeak{
+		Method:   req.Method,
+		URL:      RedactQuery(req.URL.Redacted(), SecretsFromContext(ctx).QueryParams...),
+		OpenedAt: orSystemClock(c.clock).Now(),
+		Stack:    callerStack(),
+	}
+	state := &leakState{body: body, leak: leak}
+	tracked := &leakTrackedBody{state: state, idleTimeout: c.leaks.idleTimeout}
+	onLeak := c.leaks.onLeak
+	if tracked.idleTimeout > 0 {
+		state.timer.Store(time.AfterFunc(tracked.idleTimeout, func() { state.report(LeakIdle, onLeak) }))
+	}
+	runtime.AddCleanup(tracked, func(state *leakState) { state.report(LeakCollected, onLeak) }, state)
+	return tracked
+}
+
+func (b *leakTrackedBody) Read(p []byte) (int, error) {
+	if timer := b.state.timer.Load(); timer != nil {
+		timer.Reset(b.idleTimeout)
+	}
+	return b.state.body.Read(p)
+}
+
+func (b *leakTrackedBody) Close() error {
+	_, err := b.state.close()
+	return err
+}
+
+// callerStack returns the stack of the goroutine making a request, without
+// the client's own frames.
+func callerStack() string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	var stack strings.Builder
+	for {
+		frame, more := frames.Next()
+		internal := strings.HasPrefix(frame.Function, "github.com/rbhujang/reqwest.") && !strings.HasSuffix(frame.File, "_test.go")
+		if !internal && frame.Function != "" {
+			stack.WriteString(frame.Function + "\n\t" + frame.File + ":" + strconv.Itoa(frame.Line) + "\n")
+		}
+		if !more {
+			return stack.String()
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithBodyLeakDetection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	newClient := func(idleTimeout time.Duration) (*HTTPClient, chan BodyLeak) {
+		leaks := make(chan BodyLeak, 4)
+		cli := NewClientBuilder().
+			WithAPIKey("api_key", "s3cr3t", InQuery).
+			WithBodyLeakDetection(idleTimeout, func(leak BodyLeak) { leaks <- leak }).
+			Build()
+		return cli, leaks
+	}
+
+	t.Run("idle body is closed", func(t *testing.T) {
+		cli, leaks := newClient(20 * time.Millisecond)
+		resp, err := cli.Get(context.Background(), server.URL+"/idle")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		select {
+		case leak := <-leaks:
+			if leak.Reason != LeakIdle || leak.Method != http.MethodGet {
+				t.Errorf("leak = %+v, want an idle GET", leak)
+			}
+			if !strings.Contains(leak.URL, "/idle?api_key="+RedactedValue) {
+				t.Errorf("leak URL = %q, want the API key redacted", leak.URL)
+			}
+			if !strings.Contains(leak.Stack, "TestWithBodyLeakDetection") {
+				t.Errorf("leak stack = %q, want the test that made the request", leak.Stack)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("no leak reported")
+		}
+		if _, err := resp.Bytes(); err == nil {
+			t.Error("Bytes() after the idle close succeeded")
+		}
+	})
+
+	t.Run("dropped body is closed", func(t *testing.T) {
+		cli, leaks := newClient(0)
+		func() {
+			if _, err := cli.Get(context.Background(), server.URL+"/dropped"); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+		}()
+		deadline := time.After(2 * time.Second)
+		for {
+			runtime.GC()
+			select {
+			case leak := <-leaks:
+				if leak.Reason != LeakCollected {
+					t.Errorf("Reason = %q, want %q", leak.Reason, LeakCollected)
+				}
+				return
+			case <-deadline:
+				t.Fatal("no leak reported")
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	})
+
+	t.Run("closed bodies are not reported", func(t *testing.T) {
+		cli, leaks := newClient(20 * time.Millisecond)
+		resp, err := cli.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if _, err := resp.Bytes(); err != nil {
+			t.Fatalf("Bytes() error = %v", err)
+		}
+		resp = nil
+		runtime.GC()
+		select {
+		case leak := <-leaks:
+			t.Errorf("unexpected leak %+v", leak)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrWouldExceedDeadline is returned when waiting for client capacity would
+// outlast the context deadline, so the request fails fast instead of timing
+// out mid-flight.
+var ErrWouldExceedDeadline = errors.New("waiting for capacity would exceed the context deadline")
+
+// holdTimeSmoothing is the weight of the newest sample in the moving average
+// of slot hold times.
+const holdTimeSmoothing = 0.2
+
+// concurrencyLimiter bounds the number of attempts in flight. It keeps a
+// moving average of how long attempts hold a slot to estimate queueing time.
+type concurrencyLimiter struct {
+	slots   chan struct{}
+	mu      sync.Mutex
+	avgHold time.Duration
+	waiting int
+}
+
+func newConcurrencyLimiter(limit int) *concurrencyLimiter {
+	return &concurrencyLimiter{slots: make(chan struct{}, limit)}
+}
+
+// acquire takes a slot, failing immediately with ErrWouldExceedDeadline when
+// the estimated wait is longer than the time left before ctx's deadline.
+func (l *concurrencyLimiter) acquire(ctx context.Context) (func(), error) {
+	select {
+	case l.slots <- struct{}{}:
+		return l.releaser(), nil
+	default:
+	}
+
+	l.mu.Lock()
+	l.waiting++
+	estimate := l.avgHold * time.Duration(l.waiting) / time.Duration(cap(l.slots))
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.waiting--
+		l.mu.Unlock()
+	}()
+
+	if deadline, ok := ctx.Deadline(); ok && estimate > 0 && time.Until(deadline) < estimate {
+		return nil, fmt.Errorf("%w: estimated wait %v", ErrWouldExceedDeadline, estimate)
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.releaser(), nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w: %v", ErrWouldExceedDeadline, ctx.Err())
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (l *concurrencyLimiter) releaser() func() {
+	start := time.Now()
+	return func() {
+		held := time.Since(start)
+		l.mu.Lock()
+		if l.avgHold == 0 {
+			l.avgHold = held
+		} else {
+			l.avgHold = time.Duration(holdTimeSmoothing*float64(held) + (1-holdTimeSmoothing)*float64(l.avgHold))
+		}
+		l.mu.Unlock()
+		<-l.slots
+	}
+}
@@ -0,0 +1,146 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	t.Run("fails fast when the estimate exceeds the deadline", func(t *testing.T) {
+		limiter := newConcurrencyLimiter(1)
+		limiter.avgHold = 200 * time.Millisecond
+		release, _ := limiter.acquire(context.Background())
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := limiter.acquire(ctx)
+		if !errors.Is(err, ErrWouldExceedDeadline) {
+			t.Fatalf("Expected ErrWouldExceedDeadline, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 20*time.Millisecond {
+			t.Errorf("Expected immediate failure, waited %v", elapsed)
+		}
+	})
+
+	t.Run("waits when the deadline allows", func(t *testing.T) {
+		limiter := newConcurrencyLimiter(1)
+		release, _ := limiter.acquire(context.Background())
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			release()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		second, err := limiter.acquire(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		second()
+
+		if limiter.avgHold < 20*time.Millisecond {
+			t.Errorf("Expected hold time to be tracked, got %v", limiter.avgHold)
+		}
+	})
+
+	t.Run("deadline reached while queued", func(t *testing.T) {
+		limiter := newConcurrencyLimiter(1)
+		release, _ := limiter.acquire(context.Background())
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := limiter.acquire(ctx)
+		if !errors.Is(err, ErrWouldExceedDeadline) {
+			t.Errorf("Expected ErrWouldExceedDeadline, got %v", err)
+		}
+	})
+
+	t.Run("cancellation is reported as such", func(t *testing.T) {
+		limiter := newConcurrencyLimiter(1)
+		release, _ := limiter.acquire(context.Background())
+		defer release()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := limiter.acquire(ctx)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestClientBuilder_WithMaxConcurrentRequests(t *testing.T) {
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+				break
+			}
+		}
+		time.Sleep(40 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cli := NewClientBuilder().WithMaxConcurrentRequests(2).Build()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := cli.Get(context.Background(), server.URL)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+			resp.Body().Close()
+		}()
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&peak) > 2 {
+		t.Errorf("Expected at most 2 concurrent requests, saw %d", peak)
+	}
+
+	// With hold times learned, a tight deadline behind a busy slot fails fast
+	blocker := make(chan struct{})
+	go func() {
+		resp, err := cli.Get(context.Background(), server.URL)
+		if err == nil {
+			resp.Body().Close()
+		}
+		close(blocker)
+	}()
+	go func() {
+		resp, err := cli.Get(context.Background(), server.URL)
+		if err == nil {
+			resp.Body().Close()
+		}
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	_, err := cli.Get(ctx, server.URL)
+	if !errors.Is(err, ErrWouldExceedDeadline) {
+		t.Errorf("Expected ErrWouldExceedDeadline, got %v", err)
+	}
+	<-blocker
+}
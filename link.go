@@ -0,0 +1,73 @@
+package reqwest
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Links parses the response's Link headers (RFC 8288) into a map from
+// relation type, e.g. "next" or "preload", to target URL. Relative targets
+// are resolved against the request URL.
+func (r *Response) Links() map[string]string {
+	links := parseLinkHeader(r.header.Values("Link"))
+	if r.request == nil {
+		return links
+	}
+	for rel, target := range links {
+		if ref, err := url.Parse(target); err == nil {
+			links[rel] = r.request.URL.ResolveReference(ref).String()
+		}
+	}
+	return links
+}
+
+// parseLinkHeader maps each rel of RFC 8288 Link header values to its target
+// URL. When several links share a rel, the first one wins.
+func parseLinkHeader(values []string) map[string]string {
+	links := make(map[string]string)
+	for _, value := range values {
+		for _, link := range splitLinks(value) {
+			target, params, ok := strings.Cut(link, ";")
+			target = strings.TrimSpace(target)
+			if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			target = target[1 : len(target)-1]
+			for _, param := range strings.Split(params, ";") {
+				name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+				// A rel may list several space-separated relation types
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+					rel = strings.ToLower(rel)
+					if _, seen := links[rel]; !seen {
+						links[rel] = target
+					}
+				}
+			}
+		}
+	}
+	return links
+}
+
+// splitLinks splits a Link header value on the commas between links, leaving
+// commas inside <...> targets and quoted strings alone.
+func splitLinks(value string) []string {
+	var links []string
+	inTarget, inQuotes, start := false, false, 0
+	for i, r := range value {
+		switch {
+		case r == '<' && !inQuotes:
+			inTarget = true
+		case r == '>' && !inQuotes:
+			inTarget = false
+		case r == '"' && !inTarget:
+			inQuotes = !inQuotes
+		case r == ',' && !inTarget && !inQuotes:
+			links = append(links, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(links, value[start:])
+}
@@ -0,0 +1,45 @@
+package reqwest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestParseLinkHeader(t *testing.T) {
+	links := parseLinkHeader([]string{
+		`<https://api.example.com/items?page=2&a=b,c>; rel="next", <https://api.example.com/items?page=9>; rel="last"`,
+		`<https://cdn.example.com/app.js>; rel="preload prefetch"; as="script"`,
+	})
+	want := map[string]string{
+		"next":     "https://api.example.com/items?page=2&a=b,c",
+		"last":     "https://api.example.com/items?page=9",
+		"preload":  "https://cdn.example.com/app.js",
+		"prefetch": "https://cdn.example.com/app.js",
+	}
+	if fmt.Sprint(links) != fmt.Sprint(want) {
+		t.Errorf("parseLinkHeader() = %v, want %v", links, want)
+	}
+}
+
+func TestResponse_Links(t *testing.T) {
+	requestURL, _ := url.Parse("https://api.github.com/repos/o/r/issues?page=2")
+	resp := &Response{
+		header: http.Header{"Link": {
+			`<https://api.github.com/repos/o/r/issues?page=3>; rel="next", </repos/o/r/issues?page=1>; rel="prev"`,
+		}},
+		request: &http.Request{URL: requestURL},
+	}
+
+	links := resp.Links()
+	if links["next"] != "https://api.github.com/repos/o/r/issues?page=3" {
+		t.Errorf("Unexpected next link %q", links["next"])
+	}
+	if links["prev"] != "https://api.github.com/repos/o/r/issues?page=1" {
+		t.Errorf("Expected relative prev link to be resolved, got %q", links["prev"])
+	}
+	if len((&Response{header: http.Header{}}).Links()) != 0 {
+		t.Error("Expected no links without a Link header")
+	}
+}
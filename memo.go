@@ -0,0 +1,83 @@
+package reqwest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+type memoKey struct{}
+
+// memo holds the GET responses of one logical operation.
+type memo struct {
+	mu      sync.Mutex
+	entries map[memoEntryKey]*memoEntry
+}
+
+type memoEntryKey struct {
+	client *HTTPClient
+	key    string
+}
+
+type memoEntry struct {
+	done chan struct{}
+	resp *Response
+	body []byte
+	err  error
+}
+
+// WithMemo returns a context under which repeated identical GETs (same
+// client, URL and headers) are fetched once: later calls, including
+// concurrent ones, get a copy of the first response. Scope it to one logical
+// operation, e.g. handling one inbound request, where seeing a single
+// snapshot of each resource is what you want. Errors and 5xx responses are
+// not remembered.
+func WithMemo(ctx context.Context) context.Context {
+	return context.WithValue(ctx, memoKey{}, &memo{entries: make(map[memoEntryKey]*memoEntry)})
+}
+
+// executeMemoized serves GETs from the context's memo, if it has one.
+func (c *HTTPClient) executeMemoized(ctx context.Context, request *Request, next func(context.Context, *Request) (*Response, error)) (*Response, error) {
+	m, _ := ctx.Value(memoKey{}).(*memo)
+	if m == nil || request.method != http.MethodGet {
+		return next(ctx, request)
+	}
+	key := memoEntryKey{client: c, key: coalesceKey(request, c.buildURL(request.url))}
+
+	m.mu.Lock()
+	entry, found := m.entries[key]
+	if !found {
+		entry = &memoEntry{done: make(chan struct{})}
+		m.entries[key] = entry
+	}
+	m.mu.Unlock()
+
+	if !found {
+		entry.resp, entry.err = next(ctx, request)
+		if entry.err == nil && entry.resp.body != nil {
+			entry.body, entry.err = entry.resp.readBody()
+			if entry.err != nil {
+				entry.err = fmt.Errorf("failed to read response body: %w", entry.err)
+			}
+		}
+		if entry.err != nil || entry.resp.statusCode >= http.StatusInternalServerError {
+			m.mu.Lock()
+			delete(m.entries, key)
+			m.mu.Unlock()
+		}
+		close(entry.done)
+	} else {
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	resp := entry.resp.withBody(entry.body)
+	resp.memoized = found
+	return resp, nil
+}
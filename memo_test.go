@@ -0,0 +1,79 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithMemo(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := hits.Add(1)
+		if r.URL.Path == "/flaky" && n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+	cli := NewClientBuilder().WithBaseURL(server.URL).Build()
+
+	t.Run("repeated GETs", func(t *testing.T) {
+		hits.Store(0)
+		ctx := WithMemo(context.Background())
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := cli.Get(ctx, "/users/1")
+				if err != nil {
+					t.Errorf("Get() error = %v", err)
+					return
+				}
+				if data, _ := resp.Bytes(); string(data) != "/users/1" {
+					t.Errorf("body = %q, want /users/1", data)
+				}
+			}()
+		}
+		wg.Wait()
+		resp, _ := cli.Get(ctx, "/users/1")
+		if !resp.Memoized() {
+			t.Error("Memoized() = false, want true")
+		}
+		if hits.Load() != 1 {
+			t.Errorf("server hits = %d, want 1", hits.Load())
+		}
+
+		cli.Get(ctx, "/users/2")
+		cli.Do(ctx, NewRequest(http.MethodGet, "/users/1").WithHeader("Accept", "text/plain"))
+		cli.Post(ctx, "/users/1", nil)
+		if hits.Load() != 4 {
+			t.Errorf("server hits = %d, want other URLs, headers and methods fetched", hits.Load())
+		}
+	})
+
+	t.Run("scoped to the context", func(t *testing.T) {
+		hits.Store(0)
+		cli.Get(WithMemo(context.Background()), "/users/1")
+		cli.Get(WithMemo(context.Background()), "/users/1")
+		cli.Get(context.Background(), "/users/1")
+		if hits.Load() != 3 {
+			t.Errorf("server hits = %d, want 3", hits.Load())
+		}
+	})
+
+	t.Run("failures are not remembered", func(t *testing.T) {
+		hits.Store(0)
+		ctx := WithMemo(context.Background())
+		first, _ := cli.Get(ctx, "/flaky")
+		second, _ := cli.Get(ctx, "/flaky")
+		if first.StatusCode() != http.StatusServiceUnavailable || second.StatusCode() != http.StatusOK {
+			t.Errorf("statuses = %d, %d; want the 503 retried", first.StatusCode(), second.StatusCode())
+		}
+	})
+}
@@ -0,0 +1,191 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrOffline is returned for a request that waited in the offline queue
+	// for longer than its TTL.
+	ErrOffline = errors.New("network is offline")
+	// ErrOfflineQueueFull is returned for a request issued while the network
+	// is down and the offline queue is full.
+	ErrOfflineQueueFull = errors.New("offline queue is full")
+)
+
+// OfflineQueue configures WithOfflineQueue. The zero value is usable.
+type OfflineQueue struct {
+	// MaxSize bounds the requests waiting for the network, 100 by default.
+	MaxSize int `json:"max_size"`
+	// TTL bounds how long a request waits, 5m by default.
+	TTL time.Duration `json:"ttl"`
+	// Detect reports whether a failed request means the network is down,
+	// IsNetworkDown by default.
+	Detect func(error) bool `json:"-"`
+	// Probe checks whether the network is back. The default sends a HEAD
+	// request, through the client's transport, to the URL whose request
+	// detected the outage.
+	Probe func(ctx context.Context) error `json:"-"`
+	// ProbeInterval is the wait between probes, 5s by default.
+	ProbeInterval time.Duration `json:"probe_interval"`
+	// OnDepth is told the number of waiting requests whenever it changes.
+	OnDepth func(depth int) `json:"-"`
+}
+
+func (o OfflineQueue) withDefaults() OfflineQueue {
+	if o.MaxSize <= 0 {
+		o.MaxSize = 100
+	}
+	if o.TTL <= 0 {
+		o.TTL = 5 * time.Minute
+	}
+	if o.Detect == nil {
+		o.Detect = IsNetworkDown
+	}
+	if o.ProbeInterval <= 0 {
+		o.ProbeInterval = 5 * time.Second
+	}
+	return o
+}
+
+// offlineQueue holds requests back while the network is down.
+type offlineQueue struct {
+	cfg   OfflineQueue
+	clock Clock
+
+	mu      sync.Mutex
+	offline bool
+	online  chan struct{} // closed when the network is back
+	depth   int
+}
+
+func newOfflineQueue(cfg OfflineQueue, clock Clock) *offlineQueue {
+	return &offlineQueue{cfg: cfg.withDefaults(), clock: orSystemClock(clock)}
+}
+
+// sendOnline sends request once the network is up, and sends it again after
+// it failed because the network went down.
+func (c *HTTPClient) sendOnline(ctx context.Context, request *Request, send func(context.Context, *Request) (*Response, error)) (*Response, error) {
+	q := c.offline
+	if q == nil {
+		return send(ctx, request)
+	}
+	var deadline time.Time
+	for {
+		if err := q.wait(ctx, &deadline); err != nil {
+			return nil, err
+		}
+		resp, err := send(ctx, request)
+		if err == nil || ctx.Err() != nil || !q.cfg.Detect(err) || !c.goOffline(request) {
+			return resp, err
+		}
+	}
+}
+
+// wait blocks while the network is down. deadline is when the request's TTL
+// runs out, set the first time it has to wait.
+func (q *offlineQueue) wait(ctx context.Context, deadline *time.Time) error {
+	q.mu.Lock()
+	if !q.offline {
+		q.mu.Unlock()
+		return nil
+	}
+	if q.depth >= q.cfg.MaxSize {
+		q.mu.Unlock()
+		return ErrOfflineQueueFull
+	}
+	q.depth++
+	depth, online := q.depth, q.online
+	q.mu.Unlock()
+	q.notify(depth)
+	defer func() {
+		q.mu.Lock()
+		q.depth--
+		depth := q.depth
+		q.mu.Unlock()
+		q.notify(depth)
+	}()
+
+	now := q.clock.Now()
+	if deadline.IsZero() {
+		*deadline = now.Add(q.cfg.TTL)
+	}
+	select {
+	case <-online:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-q.clock.After(deadline.Sub(now)):
+		return fmt.Errorf("%w: request queued for %v", ErrOffline, q.cfg.TTL)
+	}
+}
+
+func (q *offlineQueue) notify(depth int) {
+	if q.cfg.OnDepth != nil {
+		q.cfg.OnDepth(depth)
+	}
+}
+
+// goOffline marks the network down and, unless it already was, probes it
+// until it is back. It reports false if the client is closed.
+func (c *HTTPClient) goOffline(request *Request) bool {
+	q := c.offline
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.offline {
+		return true
+	}
+	probe := q.cfg.Probe
+	if probe == nil {
+		probe = c.headProbe(c.buildURL(request.url))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &ScheduledJob{cancel: cancel, done: make(chan struct{})}
+	if err := c.schedules.add(job); err != nil {
+		cancel()
+		return false
+	}
+	q.offline, q.online = true, make(chan struct{})
+	go func() {
+		defer close(job.done)
+		defer c.schedules.remove(job)
+		// Also when the client is closed, so the waiting requests fail on
+		// their own
+		for sleep(ctx, q.clock, q.cfg.ProbeInterval) == nil && probe(ctx) != nil {
+		}
+		q.mu.Lock()
+		q.offline = false
+		close(q.online)
+		q.mu.Unlock()
+	}()
+	return true
+}
+
+// headProbe sends a HEAD request for rawURL through the client's transport,
+// so it takes the same proxy, dialer, DNS cache and blocked hosts as the
+// requests. Any response means the network is back.
+func (c *HTTPClient) headProbe(rawURL string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, http.NoBody)
+		if err != nil {
+			return err
+		}
+		if c.hostGuard != nil {
+			if err := c.hostGuard.check(req.URL.Host); err != nil {
+				return err
+			}
+		}
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+}
@@ -0,0 +1,186 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestClientBuilder_WithOfflineQueue(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer server.Close()
+
+	var down atomic.Bool
+	unreachable := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if down.Load() {
+				return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ENETUNREACH}
+			}
+			return next.RoundTrip(req)
+		})
+	}
+	probe := func(context.Context) error {
+		if down.Load() {
+			return errors.New("still down")
+		}
+		return nil
+	}
+	var depth atomic.Int32
+	newClient := func(cfg OfflineQueue) *HTTPClient {
+		hits.Store(0)
+		depth.Store(0)
+		cfg.Probe = probe
+		cfg.ProbeInterval = 5 * time.Millisecond
+		cfg.OnDepth = func(n int) { depth.Store(int32(n)) }
+		return NewClientBuilder().WithBaseURL(server.URL).WithRoundTripperWrapper(unreachable).WithOfflineQueue(cfg).Build()
+	}
+	waitForDepth := func(t *testing.T, want int32) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for depth.Load() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("queue depth = %d, want %d", depth.Load(), want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	t.Run("flushes when the network is back", func(t *testing.T) {
+		cli := newClient(OfflineQueue{})
+		defer cli.Close()
+		down.Store(true)
+		errs := make(chan error, 2)
+		go func() {
+			_, err := cli.Post(context.Background(), "/events", nil)
+			errs <- err
+		}()
+		waitForDepth(t, 1)
+		go func() {
+			_, err := cli.Get(context.Background(), "/status")
+			errs <- err
+		}()
+		waitForDepth(t, 2)
+		if hits.Load() != 0 {
+			t.Fatalf("hits = %d while offline, want 0", hits.Load())
+		}
+
+		down.Store(false)
+		for range 2 {
+			if err := <-errs; err != nil {
+				t.Errorf("request error = %v, want it sent once online", err)
+			}
+		}
+		if hits.Load() != 2 || depth.Load() != 0 {
+			t.Errorf("hits = %d, depth = %d, want 2 and 0", hits.Load(), depth.Load())
+		}
+		if cli.Policy().OfflineQueue == nil {
+			t.Error("Policy().OfflineQueue = nil")
+		}
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		cli := newClient(OfflineQueue{MaxSize: 1})
+		defer cli.Close()
+		down.Store(true)
+		defer down.Store(false)
+		go cli.Get(context.Background(), "/first")
+		waitForDepth(t, 1)
+		if _, err := cli.Get(context.Background(), "/second"); !errors.Is(err, ErrOfflineQueueFull) {
+			t.Errorf("Get() error = %v, want ErrOfflineQueueFull", err)
+		}
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		cli := newClient(OfflineQueue{TTL: 20 * time.Millisecond})
+		defer cli.Close()
+		down.Store(true)
+		defer down.Store(false)
+		if _, err := cli.Get(context.Background(), "/"); !errors.Is(err, ErrOffline) {
+			t.Errorf("Get() error = %v, want ErrOffline", err)
+		}
+	})
+
+	t.Run("context", func(t *testing.T) {
+		cli := newClient(OfflineQueue{})
+		defer cli.Close()
+		down.Store(true)
+		defer down.Store(false)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if _, err := cli.Get(ctx, "/"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Get() error = %v, want the context's", err)
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		cli := newClient(OfflineQueue{})
+		down.Store(true)
+		defer down.Store(false)
+		errs := make(chan error, 1)
+		go func() {
+			_, err := cli.Get(context.Background(), "/")
+			errs <- err
+		}()
+		waitForDepth(t, 1)
+		cli.Close()
+		select {
+		case err := <-errs:
+			if !IsNetworkDown(err) {
+				t.Errorf("Get() error = %v, want the network error", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("request still queued after Close")
+		}
+	})
+
+	t.Run("default probe goes through the transport", func(t *testing.T) {
+		hits.Store(0)
+		cli := NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithRoundTripperWrapper(unreachable).
+			WithOfflineQueue(OfflineQueue{ProbeInterval: 5 * time.Millisecond}).
+			Build()
+		defer cli.Close()
+		down.Store(true)
+		errs := make(chan error, 1)
+		go func() {
+			_, err := cli.Get(context.Background(), "/status")
+			errs <- err
+		}()
+		time.Sleep(50 * time.Millisecond)
+		if hits.Load() != 0 {
+			t.Fatalf("hits = %d while the transport is down, want 0", hits.Load())
+		}
+		down.Store(false)
+		if err := <-errs; err != nil {
+			t.Errorf("request error = %v, want it sent once online", err)
+		}
+	})
+
+	t.Run("default probe honors blocked hosts", func(t *testing.T) {
+		cli := NewClientBuilder().WithBlockedCIDRs(netip.MustParsePrefix("127.0.0.0/8")).Build()
+		if err := cli.headProbe(server.URL)(context.Background()); !errors.Is(err, ErrHostBlocked) {
+			t.Errorf("probe error = %v, want ErrHostBlocked", err)
+		}
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+		cli := NewClientBuilder().WithOfflineQueue(OfflineQueue{Probe: probe}).Build()
+		defer cli.Close()
+		if _, err := cli.Get(context.Background(), closed.URL); !IsConnectionRefused(err) {
+			t.Errorf("Get() error = %v, want the refused connection", err)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package reqwest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// ErrSimulatedOutage is wrapped by every failure injected by SimulateOutage.
+var ErrSimulatedOutage = errors.New("simulated outage")
+
+// OutageMode selects how requests to a host fail during a simulated outage.
+type OutageMode int
+
+const (
+	// OutageNone removes a previously simulated outage.
+	OutageNone OutageMode = iota
+	// OutageConnectionRefused fails attempts as if the host refused the
+	// connection.
+	OutageConnectionRefused
+	// OutageTimeout blocks attempts until the context is done, as if the host
+	// never answered. Use it with a deadline.
+	OutageTimeout
+	// OutageServerError answers attempts with 503 Service Unavailable.
+	OutageServerError
+)
+
+// OutageSimulator forces failures for chosen hosts at runtime so resilience
+// logic (retries, fallbacks, circuit breakers) can be exercised in game days
+// without touching real dependencies.
+type OutageSimulator interface {
+	SimulateOutage(hostPattern string, mode OutageMode)
+}
+
+type outageRule struct {
+	pattern string
+	mode    OutageMode
+}
+
+// outageSimulator holds the active outage rules. Its zero value has none.
+type outageSimulator struct {
+	mu    sync.RWMutex
+	rules []outageRule
+}
+
+func (s *outageSimulator) set(pattern string, mode OutageMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, rule := range s.rules {
+		if rule.pattern == pattern {
+			s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			break
+		}
+	}
+	if mode != OutageNone {
+		s.rules = append(s.rules, outageRule{pattern: pattern, mode: mode})
+	}
+}
+
+// match returns the mode of the most recently set rule matching host.
+func (s *outageSimulator) match(host string) OutageMode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for i := len(s.rules) - 1; i >= 0; i-- {
+		if ok, _ := path.Match(s.rules[i].pattern, host); ok {
+			return s.rules[i].mode
+		}
+	}
+	return OutageNone
+}
+
+// SimulateOutage makes every attempt to a host matching hostPattern fail
+// according to mode until it is called again with OutageNone. Patterns use
+// path.Match syntax against the host name, e.g. "*.payments.internal".
+func (c *HTTPClient) SimulateOutage(hostPattern string, mode OutageMode) {
+	c.outages.set(strings.ToLower(hostPattern), mode)
+}
+
+// outageFor returns the simulated outage mode for req's host.
+func (c *HTTPClient) outageFor(req *http.Request) OutageMode {
+	return c.outages.match(strings.ToLower(req.URL.Hostname()))
+}
+
+// simulateOutage returns the injected result of an attempt for mode.
+func (c *HTTPClient) simulateOutage(req *http.Request, mode OutageMode) (*Response, error) {
+	switch mode {
+	case OutageConnectionRefused:
+		return nil, fmt.Errorf("failed to do http request: %w: connection refused", ErrSimulatedOutage)
+	case OutageTimeout:
+		<-req.Context().Done()
+		return nil, fmt.Errorf("failed to do http request: %w: timeout: %v", ErrSimulatedOutage, req.Context().Err())
+	case OutageServerError:
+		return &Response{
+			statusCode: http.StatusServiceUnavailable,
+			proto:      "HTTP/1.1",
+			header:     http.Header{"X-Simulated-Outage": {"true"}},
+			request:    req,
+			body:       io.NopCloser(strings.NewReader("")),
+			decode:     c.decode,
+		}, nil
+	}
+	return nil, fmt.Errorf("failed to do http request: %w: unknown mode %d", ErrSimulatedOutage, mode)
+}
@@ -0,0 +1,80 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_SimulateOutage(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	t.Run("connection refused is retried", func(t *testing.T) {
+		client := NewClientBuilder().WithRetryConfig(fastRetryConfig(2)).Build()
+		client.SimulateOutage("127.0.0.*", OutageConnectionRefused)
+
+		_, err := client.Get(context.Background(), server.URL)
+		if !errors.Is(err, ErrSimulatedOutage) {
+			t.Fatalf("Expected ErrSimulatedOutage, got %v", err)
+		}
+		if got := atomic.LoadInt32(&hits); got != 0 {
+			t.Errorf("Expected no real requests during the outage, got %d", got)
+		}
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		client := NewClientBuilder().Build()
+		client.SimulateOutage("127.0.0.1", OutageServerError)
+
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if resp.StatusCode() != http.StatusServiceUnavailable {
+			t.Errorf("Expected status 503, got %d", resp.StatusCode())
+		}
+	})
+
+	t.Run("timeout honours the context", func(t *testing.T) {
+		client := NewClientBuilder().Build()
+		client.SimulateOutage("*", OutageTimeout)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := client.Get(ctx, server.URL)
+		if !errors.Is(err, ErrSimulatedOutage) {
+			t.Fatalf("Expected ErrSimulatedOutage, got %v", err)
+		}
+	})
+
+	t.Run("cleared outage reaches the server", func(t *testing.T) {
+		client := NewClientBuilder().Build()
+		client.SimulateOutage("127.0.0.1", OutageConnectionRefused)
+		client.SimulateOutage("127.0.0.1", OutageNone)
+
+		before := atomic.LoadInt32(&hits)
+		if _, err := client.Get(context.Background(), server.URL); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if atomic.LoadInt32(&hits) != before+1 {
+			t.Error("Expected the request to reach the server after clearing the outage")
+		}
+	})
+
+	t.Run("other hosts are unaffected", func(t *testing.T) {
+		client := NewClientBuilder().Build()
+		client.SimulateOutage("*.payments.internal", OutageConnectionRefused)
+
+		if _, err := client.Get(context.Background(), server.URL); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,390 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrQueued is wrapped, together with the failure, by the error of a request
+// the outbox saved for a later replay.
+var ErrQueued = errors.New("request queued for replay")
+
+// Outbox configures WithOutbox. Only Store is required.
+type Outbox struct {
+	Store OutboxStore
+	// Filter picks the failed requests to save. The default saves every
+	// method but GET, HEAD and OPTIONS, whose results nobody would be
+	// around to read.
+	Filter func(*Request) bool
+	// Interval is how often the worker looks for entries due for a replay,
+	// 30s by default.
+	Interval time.Duration
+	// Backoff delays the replays of an entry, Delay(0) being the wait
+	// before the first one. The default grows from 30s to an hour.
+	Backoff BackoffStrategy
+	// MaxAttempts drops an entry after that many failed replays; zero keeps
+	// replaying it.
+	MaxAttempts int
+	// OnDrop is told about entries given up on: after MaxAttempts, or when
+	// the server rejected a replay with a status that isn't retryable.
+	OnDrop func(entry OutboxEntry, err error)
+}
+
+func (o Outbox) withDefaults() Outbox {
+	if o.Filter == nil {
+		o.Filter = func(req *Request) bool {
+			switch req.Method() {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return false
+			}
+			return true
+		}
+	}
+	if o.Interval <= 0 {
+		o.Interval = 30 * time.Second
+	}
+	if o.Backoff == nil {
+		o.Backoff = NewExponentialBackoffBuilder().
+			WithBaseDelay(30 * time.Second).
+			WithMaxDelay(time.Hour).
+			WithJitter(true).
+			Build()
+	}
+	return o
+}
+
+// OutboxEntry is a failed request saved for replay. It marshals to JSON, so
+// stores can keep it as a document.
+type OutboxEntry struct {
+	ID     string      `json:"id"`
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+	// Attempts counts the failed replays.
+	Attempts    int       `json:"attempts"`
+	Queued      time.Time `json:"queued"`
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// OutboxStore persists outbox entries so they survive restarts, e.g. in a
+// database table. Save inserts the entry or replaces the one with the same
+// ID; deleting a missing entry is not an error.
+type OutboxStore interface {
+	Save(ctx context.Context, entry OutboxEntry) error
+	List(ctx context.Context) ([]OutboxEntry, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryOutboxStore is an in-process OutboxStore. Its entries don't survive
+// the process, but still outlast outages shorter than its lifetime.
+type MemoryOutboxStore struct {
+	mu      sync.Mutex
+	entries map[string]OutboxEntry
+}
+
+// NewMemoryOutboxStore returns an empty in-process store.
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{entries: make(map[string]OutboxEntry)}
+}
+
+func (s *MemoryOutboxStore) Save(_ context.Context, entry OutboxEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry.Header = entry.Header.Clone()
+	entry.Body = slices.Clone(entry.Body)
+	s.entries[entry.ID] = entry
+	return nil
+}
+
+func (s *MemoryOutboxStore) List(_ context.Context) ([]OutboxEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]OutboxEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, entry)
+	}
+	sortOutboxEntries(entries)
+	return entries, nil
+}
+
+func (s *MemoryOutboxStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, id)
+	return nil
+}
+
+// FileOutboxStore is an OutboxStore keeping each entry as a JSON file in a
+// directory. Entries are written to a temporary file and renamed into place,
+// so a crash never leaves a partial one.
+type FileOutboxStore struct {
+	dir string
+}
+
+// NewFileOutboxStore returns a store in dir, creating it if needed.
+func NewFileOutboxStore(dir string) (*FileOutboxStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %v", err)
+	}
+	return &FileOutboxStore{dir: dir}, nil
+}
+
+func (s *FileOutboxStore) path(id string) (string, error) {
+	if id == "" || filepath.Base(id) != id || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid outbox entry id %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+func (s *FileOutboxStore) Save(_ context.Context, entry OutboxEntry) error {
+	path, err := s.path(entry.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox entry: %v", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("failed to save outbox entry: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save outbox entry: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save outbox entry: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save outbox entry: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save outbox entry: %v", err)
+	}
+	return nil
+}
+
+func (s *FileOutboxStore) List(_ context.Context) ([]OutboxEntry, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox entries: %v", err)
+	}
+	var entries []OutboxEntry
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			// Deleted since the directory was read
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read outbox entry: %v", err)
+		}
+		var entry OutboxEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("failed to decode outbox entry %s: %v", name, err)
+		}
+		entries = append(entries, entry)
+	}
+	sortOutboxEntries(entries)
+	return entries, nil
+}
+
+func (s *FileOutboxStore) Delete(_ context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete outbox entry: %v", err)
+	}
+	return nil
+}
+
+// sortOutboxEntries orders entries by when they were queued, so replays keep
+// the order of the original requests.
+func sortOutboxEntries(entries []OutboxEntry) {
+	slices.SortFunc(entries, func(a, b OutboxEntry) int {
+		if c := a.Queued.Compare(b.Queued); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+}
+
+// OutboxReplayer replays the requests a client's outbox saved.
+type OutboxReplayer interface {
+	// ReplayOutbox replays the entries that are due now, without waiting
+	// for the worker, e.g. once connectivity is known to be back. It fails
+	// only if the store does; requests that fail again are rescheduled.
+	ReplayOutbox(ctx context.Context) error
+}
+
+// outbox saves failed requests and replays them in the background.
+type outbox struct {
+	cfg Outbox
+	// replaying keeps the worker and ReplayOutbox, also on derived
+	// clients, from sending an entry twice.
+	replaying sync.Mutex
+}
+
+type outboxReplayKey struct{}
+
+// queue saves request to the outbox if it failed in a way a later replay may
+// fix, turning the outcome into an error wrapping ErrQueued.
+func (c *HTTPClient) queue(ctx context.Context, request *Request, resp *Response, err error) (*Response, error) {
+	if ctx.Value(outboxReplayKey{}) != nil || !c.outbox.cfg.Filter(request) {
+		return resp, err
+	}
+	if err == nil {
+		if !c.replayable(resp, nil) {
+			return resp, err
+		}
+		err = httpError(resp)
+	} else if errors.Is(ctx.Err(), context.Canceled) || !c.replayable(nil, err) {
+		return resp, err
+	}
+
+	now := orSystemClock(c.clock).Now()
+	entry := OutboxEntry{
+		ID:          NewUUID(),
+		Method:      request.method,
+		URL:         request.url,
+		Header:      request.header.Clone(),
+		Body:        slices.Clone(request.body),
+		Queued:      now,
+		NextAttempt: now.Add(c.outbox.cfg.Backoff.Delay(0)),
+		LastError:   err.Error(),
+	}
+	// The caller may have given up already; the entry must be saved anyway
+	if saveErr := c.outbox.cfg.Store.Save(context.WithoutCancel(ctx), entry); saveErr != nil {
+		return nil, errors.Join(err, fmt.Errorf("failed to queue request for replay: %w", saveErr))
+	}
+	return nil, fmt.Errorf("%w as %s: %w", ErrQueued, entry.ID, err)
+}
+
+// replayable reports whether a request that ended with resp or err failed in
+// a way that retrying later may fix: a status the retry policy retries, or an
+// error other than an invalid URL.
+func (c *HTTPClient) replayable(resp *Response, err error) bool {
+	status := 0
+	var httpErr *HTTPError
+	var urlErr *URLError
+	switch {
+	case resp != nil:
+		status = resp.statusCode
+	case errors.As(err, &httpErr):
+		status = httpErr.StatusCode
+	case errors.As(err, &urlErr):
+		return false
+	default:
+		return err != nil
+	}
+	if retry := c.currentRetryConfig(); retry != nil {
+		return retry.retryableStatus(status)
+	}
+	return slices.Contains(DefaultRetryableStatusCodes, status)
+}
+
+func (c *HTTPClient) ReplayOutbox(ctx context.Context) error {
+	if c.outbox == nil {
+		return nil
+	}
+	c.outbox.replaying.Lock()
+	defer c.outbox.replaying.Unlock()
+	entries, err := c.outbox.cfg.Store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to replay outbox: %v", err)
+	}
+	clock := orSystemClock(c.clock)
+	var errs []error
+	for _, entry := range entries {
+		if entry.NextAttempt.After(clock.Now()) {
+			continue
+		}
+		if err := c.replay(ctx, entry); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// replay sends entry once more, then deletes it or reschedules it.
+func (c *HTTPClient) replay(ctx context.Context, entry OutboxEntry) error {
+	req := NewRequest(entry.Method, entry.URL).WithBody(entry.Body)
+	for key, values := range entry.Header {
+		req.header[key] = slices.Clone(values)
+	}
+	resp, err := c.execute(context.WithValue(ctx, outboxReplayKey{}, true), req)
+	if ctx.Err() != nil {
+		if resp != nil {
+			resp.body.Close()
+		}
+		// Stopped, not failed: the entry stays as it is
+		return ctx.Err()
+	}
+	store := c.outbox.cfg.Store
+	if err == nil && resp.IsSuccess() {
+		resp.body.Close()
+		return store.Delete(ctx, entry.ID)
+	}
+	retry := c.replayable(resp, err)
+	if resp != nil {
+		err = httpError(resp)
+	}
+	entry.Attempts++
+	entry.LastError = err.Error()
+	if !retry || c.outbox.cfg.MaxAttempts > 0 && entry.Attempts >= c.outbox.cfg.MaxAttempts {
+		if deleteErr := store.Delete(ctx, entry.ID); deleteErr != nil {
+			return deleteErr
+		}
+		if c.outbox.cfg.OnDrop != nil {
+			c.outbox.cfg.OnDrop(entry, err)
+		}
+		return nil
+	}
+	entry.NextAttempt = orSystemClock(c.clock).Now().Add(c.outbox.cfg.Backoff.Delay(entry.Attempts))
+	return store.Save(ctx, entry)
+}
+
+// startOutbox runs the replay worker until the client is closed.
+func (c *HTTPClient) startOutbox() {
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &ScheduledJob{cancel: cancel, done: make(chan struct{})}
+	if err := c.schedules.add(job); err != nil {
+		cancel()
+		return
+	}
+	go func() {
+		defer close(job.done)
+		defer c.schedules.remove(job)
+		clock := orSystemClock(c.clock)
+		for {
+			// Entries left by an earlier process are due right away
+			_ = c.ReplayOutbox(ctx)
+			if err := sleep(ctx, clock, c.outbox.cfg.Interval); err != nil {
+				return
+			}
+		}
+	}()
+}
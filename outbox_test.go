@@ -0,0 +1,307 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientBuilder_WithOutbox(t *testing.T) {
+	var status atomic.Int32
+	var hits atomic.Int32
+	var mu sync.Mutex
+	var delivered []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		code := int(status.Load())
+		if code == http.StatusOK {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			delivered = append(delivered, r.Header.Get("X-Event")+" "+string(body))
+			mu.Unlock()
+		}
+		w.WriteHeader(code)
+	}))
+	defer server.Close()
+
+	newClient := func(t *testing.T, cfg Outbox) (*HTTPClient, *manualClock) {
+		t.Helper()
+		hits.Store(0)
+		mu.Lock()
+		delivered = nil
+		mu.Unlock()
+		clock := newManualClock(time.Now())
+		if cfg.Interval == 0 {
+			cfg.Interval = time.Hour
+		}
+		if cfg.Backoff == nil {
+			cfg.Backoff = NewFixedBackoffBuilder().WithDelay(time.Minute).Build()
+		}
+		cli := NewClientBuilder().WithBaseURL(server.URL).WithClock(clock).WithOutbox(cfg).Build()
+		t.Cleanup(func() { cli.Close() })
+		return cli, clock
+	}
+
+	t.Run("queues and replays", func(t *testing.T) {
+		store := NewMemoryOutboxStore()
+		cli, clock := newClient(t, Outbox{Store: store})
+		status.Store(http.StatusServiceUnavailable)
+		req := NewRequest(http.MethodPost, "/events").WithBody([]byte(`{"id":1}`)).WithHeader("X-Event", "created")
+		_, err := cli.Do(context.Background(), req)
+		if !errors.Is(err, ErrQueued) {
+			t.Fatalf("Do() error = %v, want ErrQueued", err)
+		}
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("Do() error = %v, want it to wrap the 503", err)
+		}
+		entries, _ := store.List(context.Background())
+		if len(entries) != 1 || entries[0].Method != http.MethodPost || entries[0].URL != "/events" {
+			t.Fatalf("store = %+v, want the POST", entries)
+		}
+
+		status.Store(http.StatusOK)
+		if err := cli.ReplayOutbox(context.Background()); err != nil {
+			t.Fatalf("ReplayOutbox() error = %v", err)
+		}
+		if hits.Load() != 1 {
+			t.Fatalf("replayed before the backoff elapsed")
+		}
+		clock.advance(time.Minute)
+		if err := cli.ReplayOutbox(context.Background()); err != nil {
+			t.Fatalf("ReplayOutbox() error = %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(delivered) != 1 || delivered[0] != `created {"id":1}` {
+			t.Errorf("delivered = %q, want the original header and body", delivered)
+		}
+		if entries, _ := store.List(context.Background()); len(entries) != 0 {
+			t.Errorf("store = %+v, want it empty after delivery", entries)
+		}
+	})
+
+	t.Run("after retries", func(t *testing.T) {
+		hits.Store(0)
+		cli := NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithRetryConfig(fastRetryConfig(2)).
+			WithOutbox(Outbox{Store: NewMemoryOutboxStore(), Interval: time.Hour}).
+			Build()
+		defer cli.Close()
+		status.Store(http.StatusBadGateway)
+		if _, err := cli.Post(context.Background(), "/events", nil); !errors.Is(err, ErrQueued) {
+			t.Fatalf("Post() error = %v, want ErrQueued", err)
+		}
+		if hits.Load() != 3 {
+			t.Errorf("hits = %d, want 3 attempts before queuing", hits.Load())
+		}
+	})
+
+	t.Run("not queued", func(t *testing.T) {
+		store := NewMemoryOutboxStore()
+		cli, _ := newClient(t, Outbox{Store: store})
+		status.Store(http.StatusServiceUnavailable)
+		resp, err := cli.Get(context.Background(), "/events")
+		if err != nil || resp.StatusCode() != http.StatusServiceUnavailable {
+			t.Errorf("Get() = %v, %v, want the 503 itself", resp, err)
+		}
+		status.Store(http.StatusUnprocessableEntity)
+		resp, err = cli.Post(context.Background(), "/events", nil)
+		if err != nil || resp.StatusCode() != http.StatusUnprocessableEntity {
+			t.Errorf("Post() = %v, %v, want the 422 itself", resp, err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := cli.Post(ctx, "/events", nil); errors.Is(err, ErrQueued) {
+			t.Errorf("Post() error = %v, want a canceled request not to be queued", err)
+		}
+		if entries, _ := store.List(context.Background()); len(entries) != 0 {
+			t.Errorf("store = %+v, want it empty", entries)
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		store := NewMemoryOutboxStore()
+		cli, _ := newClient(t, Outbox{Store: store, Filter: func(req *Request) bool {
+			return req.Header().Get("X-Event") != ""
+		}})
+		status.Store(http.StatusServiceUnavailable)
+		if _, err := cli.Post(context.Background(), "/events", nil); err != nil {
+			t.Errorf("Post() error = %v, want the filter to skip it", err)
+		}
+		if _, err := cli.Get(context.Background(), "/events", RequestHeader("X-Event", "poll")); !errors.Is(err, ErrQueued) {
+			t.Errorf("Get() error = %v, want ErrQueued", err)
+		}
+	})
+
+	t.Run("drops", func(t *testing.T) {
+		store := NewMemoryOutboxStore()
+		var dropped []string
+		cli, clock := newClient(t, Outbox{Store: store, MaxAttempts: 2, OnDrop: func(entry OutboxEntry, err error) {
+			dropped = append(dropped, entry.URL)
+		}})
+		status.Store(http.StatusServiceUnavailable)
+		cli.Post(context.Background(), "/exhausted", nil)
+		for range 2 {
+			clock.advance(time.Minute)
+			if err := cli.ReplayOutbox(context.Background()); err != nil {
+				t.Fatalf("ReplayOutbox() error = %v", err)
+			}
+		}
+		if entries, _ := store.List(context.Background()); len(entries) != 0 || len(dropped) != 1 {
+			t.Errorf("store = %+v, dropped = %v, want the entry dropped after 2 replays", entries, dropped)
+		}
+
+		cli.Post(context.Background(), "/rejected", nil)
+		status.Store(http.StatusBadRequest)
+		clock.advance(time.Minute)
+		cli.ReplayOutbox(context.Background())
+		if len(dropped) != 2 || dropped[1] != "/rejected" {
+			t.Errorf("dropped = %v, want a rejected replay dropped at once", dropped)
+		}
+	})
+
+	t.Run("derived clients share the worker", func(t *testing.T) {
+		cli, _ := newClient(t, Outbox{Store: NewMemoryOutboxStore()})
+		derived := cli.Derive().WithDefaultHeader("X-Tenant", "a").Build()
+		defer derived.Close()
+		if derived.outbox != cli.outbox {
+			t.Error("derived client has an outbox of its own")
+		}
+		if jobs := len(derived.schedules.jobs); jobs != 0 {
+			t.Errorf("derived client runs %d jobs, want none", jobs)
+		}
+		other := cli.Derive().WithOutbox(Outbox{Store: NewMemoryOutboxStore()}).Build()
+		defer other.Close()
+		if other.outbox == cli.outbox {
+			t.Error("WithOutbox on a derived builder kept the shared outbox")
+		}
+	})
+
+	t.Run("worker", func(t *testing.T) {
+		store := NewMemoryOutboxStore()
+		cli, clock := newClient(t, Outbox{Store: store, Interval: 2 * time.Minute})
+		status.Store(http.StatusServiceUnavailable)
+		cli.Post(context.Background(), "/events", nil)
+		status.Store(http.StatusOK)
+		deadline := time.Now().Add(time.Second)
+		for clock.waiting() == 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		clock.advance(2 * time.Minute)
+		for time.Now().Before(deadline) {
+			if entries, _ := store.List(context.Background()); len(entries) == 0 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if entries, _ := store.List(context.Background()); len(entries) != 0 {
+			t.Fatalf("store = %+v, want the worker to deliver the entry", entries)
+		}
+		cli.Close()
+		if hits.Load() != 2 {
+			t.Errorf("hits = %d, want 2", hits.Load())
+		}
+	})
+
+	t.Run("replays keep the idempotency key", func(t *testing.T) {
+		var fail atomic.Bool
+		fail.Store(true)
+		var keys []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+			mu.Unlock()
+			if fail.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+		clock := newManualClock(time.Now())
+		cli := NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithClock(clock).
+			WithIdempotencyKeys().
+			WithOutbox(Outbox{Store: NewMemoryOutboxStore(), Interval: time.Hour}).
+			Build()
+		defer cli.Close()
+
+		if _, err := cli.Post(context.Background(), "/events", nil); !errors.Is(err, ErrQueued) {
+			t.Fatalf("Post() error = %v, want ErrQueued", err)
+		}
+		fail.Store(false)
+		clock.advance(2 * time.Hour)
+		if err := cli.ReplayOutbox(context.Background()); err != nil {
+			t.Fatalf("ReplayOutbox() error = %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(keys) != 2 || keys[0] == "" || keys[1] != keys[0] {
+			t.Errorf("keys = %q, want the same key on the original attempt and the replay", keys)
+		}
+	})
+
+	t.Run("transport error", func(t *testing.T) {
+		store := NewMemoryOutboxStore()
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+		cli := NewClientBuilder().WithBaseURL(closed.URL).WithOutbox(Outbox{Store: store, Interval: time.Hour}).Build()
+		defer cli.Close()
+		if _, err := cli.Post(context.Background(), "/events", nil); !errors.Is(err, ErrQueued) {
+			t.Errorf("Post() error = %v, want ErrQueued", err)
+		}
+	})
+}
+
+func TestFileOutboxStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFileOutboxStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileOutboxStore() error = %v", err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	first := OutboxEntry{ID: "a", Method: http.MethodPost, URL: "/events", Header: http.Header{"X-Event": {"created"}}, Body: []byte("{}"), Queued: now}
+	second := OutboxEntry{ID: "b", Method: http.MethodPut, URL: "/events/1", Queued: now.Add(-time.Minute)}
+	for _, entry := range []OutboxEntry{first, second} {
+		if err := store.Save(ctx, entry); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	first.Attempts = 1
+	if err := store.Save(ctx, first); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reopened, _ := NewFileOutboxStore(dir)
+	entries, err := reopened.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != "b" || entries[1].ID != "a" {
+		t.Fatalf("List() = %+v, want b then a", entries)
+	}
+	if got := entries[1]; got.Attempts != 1 || string(got.Body) != "{}" || got.Header.Get("X-Event") != "created" || !got.Queued.Equal(now) {
+		t.Errorf("List()[1] = %+v, want the replaced entry", got)
+	}
+
+	if err := reopened.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := reopened.Delete(ctx, "a"); err != nil {
+		t.Errorf("Delete() of a missing entry error = %v, want nil", err)
+	}
+	if entries, _ := store.List(ctx); len(entries) != 1 {
+		t.Errorf("List() = %+v, want one entry left", entries)
+	}
+	if err := store.Save(ctx, OutboxEntry{ID: "../escape"}); err == nil {
+		t.Error("Save() with a path in the id error = nil, want error")
+	}
+}
@@ -0,0 +1,335 @@
+package reqwest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page is one fetched page. Its body has already been read.
+type Page struct {
+	Number   int
+	Request  *Request
+	Response *Response
+	Body     []byte
+}
+
+// JSON decodes the page body with the client's JSON decoding settings.
+func (p *Page) JSON(v any, opts ...DecodeOption) error {
+	return decodeJSON(p.Body, v, p.Response.decode.with(opts))
+}
+
+// NextPageFunc derives the request for the page after page, or returns nil
+// when page is the last one.
+type NextPageFunc func(page *Page) (*Request, error)
+
+// Paginator fetches pages lazily, one per call to Next:
+//
+//	pages := reqwest.NewPaginator(client, reqwest.NewRequest("GET", "/items"), reqwest.LinkHeaderPages())
+//	for pages.Next(ctx) {
+//		var items []Item
+//		if err := pages.Page().JSON(&items); err != nil { ... }
+//	}
+//	if err := pages.Err(); err != nil { ... }
+type Paginator struct {
+	doer        Doer
+	next        *Request
+	nextPage    NextPageFunc
+	page        *Page
+	err         error
+	maxPages    int
+	minInterval time.Duration
+	lastFetch   time.Time
+	clock       Clock
+}
+
+// NewPaginator returns a paginator that starts with first and follows next.
+// Pages are fetched through doer, so the client's retries and middleware
+// apply to every page. If doer is an *HTTPClient, page spacing follows its
+// clock.
+func NewPaginator(doer Doer, first *Request, next NextPageFunc) *Paginator {
+	clock := SystemClock
+	if client, ok := doer.(*HTTPClient); ok {
+		clock = orSystemClock(client.clock)
+	}
+	return &Paginator{doer: doer, next: first, nextPage: next, clock: clock}
+}
+
+// WithMaxPages stops pagination after n pages.
+func (p *Paginator) WithMaxPages(n int) *Paginator {
+	p.maxPages = n
+	return p
+}
+
+// WithMinInterval spaces page fetches at least d apart, to stay within an
+// API's rate limits.
+func (p *Paginator) WithMinInterval(d time.Duration) *Paginator {
+	p.minInterval = d
+	return p
+}
+
+// Next fetches the next page, reporting false when there are no more pages
+// or an error occurred.
+func (p *Paginator) Next(ctx context.Context) bool {
+	if p.err != nil || p.next == nil {
+		return false
+	}
+	number := 1
+	if p.page != nil {
+		number = p.page.Number + 1
+	}
+	if p.maxPages > 0 && number > p.maxPages {
+		return false
+	}
+	if err := p.throttle(ctx); err != nil {
+		p.err = err
+		return false
+	}
+
+	request := p.next
+	resp, err := p.doer.Do(ctx, request)
+	if err != nil {
+		p.err = fmt.Errorf("failed to fetch page %d: %w", number, err)
+		return false
+	}
+	body, err := resp.Bytes()
+	if err != nil {
+		p.err = fmt.Errorf("failed to fetch page %d: %w", number, err)
+		return false
+	}
+	if resp.StatusCode() < http.StatusOK || resp.StatusCode() >= http.StatusMultipleChoices {
+		p.err = fmt.Errorf("failed to fetch page %d: unexpected status %d", number, resp.StatusCode())
+		return false
+	}
+
+	p.page = &Page{Number: number, Request: request, Response: resp, Body: body}
+	p.next, err = p.nextPage(p.page)
+	if err != nil {
+		p.err = fmt.Errorf("failed to find page after %d: %w", number, err)
+		p.next = nil
+	}
+	return true
+}
+
+// Page returns the page fetched by the last successful call to Next.
+func (p *Paginator) Page() *Page {
+	return p.page
+}
+
+// Err returns the error that stopped pagination, if any.
+func (p *Paginator) Err() error {
+	return p.err
+}
+
+// All returns an iterator over the remaining pages. Iteration stops after
+// yielding an error.
+func (p *Paginator) All(ctx context.Context) iter.Seq2[*Page, error] {
+	return func(yield func(*Page, error) bool) {
+		for p.Next(ctx) {
+			if !yield(p.page, nil) {
+				return
+			}
+		}
+		if p.err != nil {
+			yield(nil, p.err)
+		}
+	}
+}
+
+func (p *Paginator) throttle(ctx context.Context) error {
+	if err := contextCancelled(ctx); err != nil {
+		return err
+	}
+	if p.minInterval > 0 && !p.lastFetch.IsZero() {
+		if err := sleep(ctx, p.clock, p.minInterval-p.clock.Now().Sub(p.lastFetch)); err != nil {
+			return err
+		}
+	}
+	p.lastFetch = p.clock.Now()
+	return nil
+}
+
+// LinkHeaderPages follows the rel="next" link of the Link header, as used by
+// GitHub-style APIs.
+func LinkHeaderPages() NextPageFunc {
+	return func(page *Page) (*Request, error) {
+		next, ok := page.Response.Links()["next"]
+		if !ok {
+			return nil, nil
+		}
+		return page.Request.withURL(next), nil
+	}
+}
+
+// CursorPages reads the cursor for the next page from field of the JSON body,
+// a dot-separated path such as "meta.next_cursor", and sends it as query
+// parameter param. An empty or missing cursor ends pagination.
+func CursorPages(field, param string) NextPageFunc {
+	return func(page *Page) (*Request, error) {
+		cursor, err := jsonFieldString(page.Body, field)
+		if err != nil || cursor == "" {
+			return nil, err
+		}
+		return withQueryParam(page.Request, param, cursor)
+	}
+}
+
+// OffsetPages advances query parameter offsetParam by limit on every page,
+// ending after a page with fewer than limit items in itemsField, a
+// dot-separated path to a JSON array ("" for a top-level array).
+func OffsetPages(offsetParam string, limit int, itemsField string) NextPageFunc {
+	return func(page *Page) (*Request, error) {
+		count, err := jsonArrayLen(page.Body, itemsField)
+		if err != nil || count < limit {
+			return nil, err
+		}
+		parsed, err := url.Parse(page.Request.url)
+		if err != nil {
+			return nil, err
+		}
+		offset, _ := strconv.Atoi(parsed.Query().Get(offsetParam))
+		return withQueryParam(page.Request, offsetParam, strconv.Itoa(offset+limit))
+	}
+}
+
+func withQueryParam(request *Request, param, value string) (*Request, error) {
+	parsed, err := url.Parse(request.url)
+	if err != nil {
+		return nil, err
+	}
+	query := parsed.Query()
+	query.Set(param, value)
+	parsed.RawQuery = query.Encode()
+	return request.withURL(parsed.String()), nil
+}
+
+// jsonField returns the value at a dot-separated path of a JSON object.
+func jsonField(body []byte, path string) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode page body: %v", err)
+	}
+	if path == "" {
+		return value, nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, nil
+		}
+		value = object[key]
+	}
+	return value, nil
+}
+
+func jsonFieldString(body []byte, path string) (string, error) {
+	value, err := jsonField(body, path)
+	if err != nil || value == nil {
+		return "", err
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	}
+	return "", fmt.Errorf("field %q is not a string or number", path)
+}
+
+func jsonArrayLen(body []byte, path string) (int, error) {
+	value, err := jsonField(body, path)
+	if err != nil {
+		return 0, err
+	}
+	items, ok := value.([]any)
+	if !ok && value != nil {
+		return 0, fmt.Errorf("field %q is not an array", path)
+	}
+	return len(items), nil
+}
+
+// PageConfig configures GetAllPages. The zero value follows Link headers and
+// expects each page to be a top-level JSON array.
+type PageConfig struct {
+	// Next finds the request for the following page; nil means
+	// LinkHeaderPages.
+	Next NextPageFunc
+	// ItemsField is the dot-separated path to the JSON array of items in
+	// each page body, "" for a top-level array.
+	ItemsField string
+	// MaxItems and MaxPages stop pagination once reached; zero means no
+	// limit. Results are truncated to MaxItems.
+	MaxItems int
+	MaxPages int
+	// MinInterval spaces page fetches at least this far apart, on top of
+	// any rate limit configured on the client.
+	MinInterval time.Duration
+}
+
+// GetAllPages fetches every page starting with first and concatenates their
+// items into one slice:
+//
+//	users, err := reqwest.GetAllPages[User](ctx, client, reqwest.NewRequest("GET", "/users"),
+//		reqwest.PageConfig{Next: reqwest.CursorPages("next", "cursor"), ItemsField: "data", MaxItems: 1000})
+//
+// Items are decoded with the client's JSON decoding settings. On error the
+// items of the pages fetched so far are returned along with it.
+func GetAllPages[T any](ctx context.Context, doer Doer, first *Request, cfg PageConfig) ([]T, error) {
+	next := cfg.Next
+	if next == nil {
+		next = LinkHeaderPages()
+	}
+	pages := NewPaginator(doer, first.Clone().ExpectJSON(), next).
+		WithMaxPages(cfg.MaxPages).
+		WithMinInterval(cfg.MinInterval)
+	var all []T
+	for pages.Next(ctx) {
+		page := pages.Page()
+		if err := page.Response.checkContentType(); err != nil {
+			return all, fmt.Errorf("failed to decode page %d: %w", page.Number, err)
+		}
+		raw, err := jsonRawField(page.Body, cfg.ItemsField)
+		if err != nil {
+			return all, fmt.Errorf("failed to decode page %d: %w", page.Number, err)
+		}
+		var items []T
+		if raw != nil {
+			if err := decodeJSON(raw, &items, page.Response.decode); err != nil {
+				return all, fmt.Errorf("failed to decode page %d: %w", page.Number, err)
+			}
+		}
+		all = append(all, items...)
+		if cfg.MaxItems > 0 && len(all) >= cfg.MaxItems {
+			return all[:cfg.MaxItems], nil
+		}
+	}
+	return all, pages.Err()
+}
+
+// jsonRawField returns the undecoded value at a dot-separated path of a JSON
+// object, or nil if it is missing.
+func jsonRawField(body []byte, path string) (json.RawMessage, error) {
+	raw := json.RawMessage(body)
+	if path == "" {
+		return raw, nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return nil, fmt.Errorf("field %q is not inside an object", path)
+		}
+		if raw = object[key]; raw == nil {
+			return nil, nil
+		}
+	}
+	return raw, nil
+}
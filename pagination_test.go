@@ -0,0 +1,231 @@
+package reqwest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPaginator_LinkHeaderPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		if page < 3 {
+			w.Header().Set("Link", fmt.Sprintf(`</items?page=%d>; rel="next", </items?page=3>; rel="last"`, page+1))
+		}
+		fmt.Fprintf(w, `[%d]`, page)
+	}))
+	defer server.Close()
+
+	client := NewClientBuilder().WithBaseURL(server.URL).Build()
+	pages := NewPaginator(client, NewRequest(http.MethodGet, "/items"), LinkHeaderPages())
+
+	var got []int
+	for pages.Next(context.Background()) {
+		var items []int
+		if err := pages.Page().JSON(&items); err != nil {
+			t.Fatalf("Unexpected decode error: %v", err)
+		}
+		got = append(got, items...)
+	}
+	if err := pages.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(got) != "[1 2 3]" {
+		t.Errorf("Expected pages [1 2 3], got %v", got)
+	}
+}
+
+func TestPaginator_CursorPages(t *testing.T) {
+	cursors := map[string]string{"": "b", "b": "c", "c": ""}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cursor := r.URL.Query().Get("cursor")
+		fmt.Fprintf(w, `{"items":[%q],"meta":{"next_cursor":%q}}`, cursor, cursors[cursor])
+	}))
+	defer server.Close()
+
+	client := NewClientBuilder().Build()
+	pages := NewPaginator(client, NewRequest(http.MethodGet, server.URL+"/items?limit=1"),
+		CursorPages("meta.next_cursor", "cursor"))
+
+	var requested []string
+	for page, err := range pages.All(context.Background()) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		requested = append(requested, page.Request.URL())
+	}
+	want := []string{
+		server.URL + "/items?limit=1",
+		server.URL + "/items?cursor=b&limit=1",
+		server.URL + "/items?cursor=c&limit=1",
+	}
+	if fmt.Sprint(requested) != fmt.Sprint(want) {
+		t.Errorf("Expected requests %v, got %v", want, requested)
+	}
+}
+
+func TestPaginator_OffsetPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if offset < 4 {
+			fmt.Fprintf(w, `{"data":[%d,%d]}`, offset, offset+1)
+			return
+		}
+		fmt.Fprintf(w, `{"data":[%d]}`, offset)
+	}))
+	defer server.Close()
+
+	client := NewClientBuilder().Build()
+	pages := NewPaginator(client, NewRequest(http.MethodGet, server.URL), OffsetPages("offset", 2, "data"))
+
+	var got []int
+	for pages.Next(context.Background()) {
+		var body struct{ Data []int }
+		if err := pages.Page().JSON(&body); err != nil {
+			t.Fatalf("Unexpected decode error: %v", err)
+		}
+		got = append(got, body.Data...)
+	}
+	if err := pages.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fmt.Sprint(got) != "[0 1 2 3 4]" {
+		t.Errorf("Expected items [0 1 2 3 4], got %v", got)
+	}
+}
+
+func TestPaginator_Limits(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("page") == "3" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		next, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		w.Header().Set("Link", fmt.Sprintf(`<?page=%d>; rel="next"`, next+1))
+	}))
+	defer server.Close()
+	client := NewClientBuilder().Build()
+
+	t.Run("max pages", func(t *testing.T) {
+		calls = 0
+		pages := NewPaginator(client, NewRequest(http.MethodGet, server.URL), LinkHeaderPages()).WithMaxPages(2)
+		for pages.Next(context.Background()) {
+		}
+		if pages.Err() != nil || calls != 2 {
+			t.Errorf("Expected 2 calls without error, got %d (%v)", calls, pages.Err())
+		}
+	})
+
+	t.Run("error status stops pagination", func(t *testing.T) {
+		pages := NewPaginator(client, NewRequest(http.MethodGet, server.URL), LinkHeaderPages())
+		n := 0
+		for pages.Next(context.Background()) {
+			n++
+		}
+		if pages.Err() == nil || n != 3 {
+			t.Errorf("Expected an error after 3 pages, got %d pages (%v)", n, pages.Err())
+		}
+	})
+
+	t.Run("min interval follows the client clock", func(t *testing.T) {
+		calls = 0
+		clock := newManualClock(time.Now())
+		client := NewClientBuilder().WithClock(clock).Build()
+		pages := NewPaginator(client, NewRequest(http.MethodGet, server.URL), LinkHeaderPages()).
+			WithMinInterval(time.Hour).WithMaxPages(2)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for pages.Next(context.Background()) {
+			}
+		}()
+		for clock.waiting() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		clock.advance(time.Hour)
+		<-done
+		if pages.Err() != nil || calls != 2 {
+			t.Errorf("Expected 2 calls without error, got %d (%v)", calls, pages.Err())
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		pages := NewPaginator(client, NewRequest(http.MethodGet, server.URL), LinkHeaderPages())
+		if pages.Next(ctx) || pages.Err() != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", pages.Err())
+		}
+	})
+}
+
+func TestGetAllPages(t *testing.T) {
+	// Pages hold two items each, five pages in total.
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		if page < 5 {
+			w.Header().Set("Link", fmt.Sprintf(`</items?page=%d>; rel="next"`, page+1))
+		}
+		fmt.Fprintf(w, `{"data":[{"id":%d},{"id":%d}]}`, 2*page-1, 2*page)
+	}))
+	defer server.Close()
+	client := NewClientBuilder().WithBaseURL(server.URL).Build()
+	type item struct {
+		ID int `json:"id"`
+	}
+	ids := func(items []item) string {
+		got := make([]int, len(items))
+		for i, it := range items {
+			got[i] = it.ID
+		}
+		return fmt.Sprint(got)
+	}
+
+	tests := []struct {
+		name     string
+		cfg      PageConfig
+		want     string
+		requests int
+	}{
+		{"all pages", PageConfig{ItemsField: "data"}, "[1 2 3 4 5 6 7 8 9 10]", 5},
+		{"max pages", PageConfig{ItemsField: "data", MaxPages: 2}, "[1 2 3 4]", 2},
+		{"max items", PageConfig{ItemsField: "data", MaxItems: 3}, "[1 2 3]", 2},
+		{"missing field", PageConfig{ItemsField: "items", MaxPages: 1}, "[]", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			items, err := GetAllPages[item](context.Background(), client, NewRequest(http.MethodGet, "/items"), tt.cfg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := ids(items); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+			if requests != tt.requests {
+				t.Errorf("Expected %d requests, got %d", tt.requests, requests)
+			}
+		})
+	}
+
+	t.Run("decode errors stop pagination", func(t *testing.T) {
+		items, err := GetAllPages[string](context.Background(), client, NewRequest(http.MethodGet, "/items"), PageConfig{ItemsField: "data"})
+		if err == nil || len(items) != 0 {
+			t.Errorf("Expected a decode error on page 1, got %v, %v", items, err)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package reqwest
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// CertificatePinError is returned when no certificate the server presented
+// matches a pin set with WithCertificatePinning.
+type CertificatePinError struct {
+	Host string
+	// Presented are the SPKI pins of the certificates the server presented,
+	// leaf first, to compare with the expected ones.
+	Presented []string
+}
+
+func (e *CertificatePinError) Error() string {
+	return fmt.Sprintf("certificate pin mismatch for %s: server presented %s", e.Host, strings.Join(e.Presented, ", "))
+}
+
+// CertificatePin returns the pin of cert's public key, as used by
+// WithCertificatePinning: "sha256/" and the base64 SHA-256 hash of its
+// SubjectPublicKeyInfo.
+func CertificatePin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// certificateHashPin is the pin of the whole certificate.
+func certificateHashPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return "sha256/" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// pinTransportOption checks the pins after the usual verification. It is
+// applied after the other options, so it keeps a TLS config set with
+// WithTLSConfig and its own VerifyConnection.
+func pinTransportOption(pins []string) transportOption {
+	return func(t *http.Transport) {
+		config := t.TLSClientConfig.Clone()
+		if config == nil {
+			config = &tls.Config{}
+		}
+		verify := config.VerifyConnection
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			if verify != nil {
+				if err := verify(state); err != nil {
+					return err
+				}
+			}
+			return verifyPins(state, pins)
+		}
+		t.TLSClientConfig = config
+	}
+}
+
+// verifyPins accepts a connection if a certificate of the verified chains, or
+// of the presented ones when verification is skipped, matches a pin by its
+// public key or as a whole.
+func verifyPins(state tls.ConnectionState, pins []string) error {
+	certs := state.PeerCertificates
+	for _, chain := range state.VerifiedChains {
+		certs = append(certs, chain...)
+	}
+	for _, cert := range certs {
+		if slices.Contains(pins, CertificatePin(cert)) || slices.Contains(pins, certificateHashPin(cert)) {
+			return nil
+		}
+	}
+	err := &CertificatePinError{Host: state.ServerName}
+	for _, cert := range state.PeerCertificates {
+		err.Presented = append(err.Presented, CertificatePin(cert))
+	}
+	return err
+}
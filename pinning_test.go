@@ -0,0 +1,97 @@
+package reqwest
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestWithCertificatePinning(t *testing.T) {
+	var handshakes atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			handshakes.Add(1)
+		}
+	}
+	server.StartTLS()
+	defer server.Close()
+	leaf := server.Certificate()
+
+	t.Run("public key pin", func(t *testing.T) {
+		cli := NewClientBuilder().
+			WithTransport(server.Client().Transport).
+			WithCertificatePinning("sha256/not-this-one=", CertificatePin(leaf)).
+			Build()
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.StatusCode() != http.StatusOK {
+			t.Errorf("Expected 200, got %d", resp.StatusCode())
+		}
+	})
+
+	t.Run("certificate pin", func(t *testing.T) {
+		cli := NewClientBuilder().
+			WithTransport(server.Client().Transport).
+			WithCertificatePinning(certificateHashPin(leaf)).
+			Build()
+		if _, err := cli.Get(context.TODO(), server.URL); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("mismatch is not retried", func(t *testing.T) {
+		handshakes.Store(0)
+		cli := NewClientBuilder().
+			WithTransport(server.Client().Transport).
+			WithRetryConfig(fastRetryConfig(2)).
+			WithCertificatePinning("sha256/YLh1dUR9y6Kja30RrAn7JKnbQG/uEtLMkBgFF2Fuihg=").
+			Build()
+		_, err := cli.Get(context.TODO(), server.URL)
+		var pinErr *CertificatePinError
+		if !errors.As(err, &pinErr) {
+			t.Fatalf("Expected CertificatePinError, got %v", err)
+		}
+		if len(pinErr.Presented) == 0 || pinErr.Presented[0] != CertificatePin(leaf) {
+			t.Errorf("Expected the leaf pin first, got %v", pinErr.Presented)
+		}
+		if got := handshakes.Load(); got != 1 {
+			t.Errorf("Expected 1 handshake, got %d", got)
+		}
+	})
+
+	t.Run("keeps the custom verification", func(t *testing.T) {
+		config := server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+		verified := false
+		config.VerifyConnection = func(tls.ConnectionState) error {
+			verified = true
+			return nil
+		}
+		cli := NewClientBuilder().
+			WithTLSConfig(config).
+			WithCertificatePinning(CertificatePin(leaf)).
+			Build()
+		if _, err := cli.Get(context.TODO(), server.URL); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !verified {
+			t.Error("Expected the configured VerifyConnection to run")
+		}
+	})
+
+	t.Run("policy", func(t *testing.T) {
+		cli := NewClientBuilder().WithCertificatePinning(CertificatePin(leaf)).Build()
+		if pins := cli.Policy().CertificatePins; len(pins) != 1 || pins[0] != CertificatePin(leaf) {
+			t.Errorf("Expected the pin in the policy, got %v", pins)
+		}
+	})
+}
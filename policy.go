@@ -0,0 +1,391 @@
+package reqwest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PolicyReporter describes the configuration a client is running with.
+type PolicyReporter interface {
+	Policy() ClientPolicy
+}
+
+// Inspector gives read-only access to a client's configuration, e.g. for
+// wrappers and tests that need to know how the client they were given is set
+// up.
+type Inspector interface {
+	// BaseURL returns the base URL relative URLs resolve against, the first
+	// one when the client balances over several, or "" if none is set.
+	BaseURL() string
+	// RetryPolicy returns a copy of the client's retry configuration, or nil
+	// if it doesn't retry.
+	RetryPolicy() *RetryConfig
+	MiddlewareCount() int
+	Timeouts() Timeouts
+}
+
+// Timeouts are the transport-level timeouts of a client. Zero means no
+// limit; request deadlines come from the context instead. Timeouts of a
+// custom transport that is not an *http.Transport are reported as zero.
+type Timeouts struct {
+	TLSHandshake       time.Duration `json:"tls_handshake"`
+	ResponseHeader     time.Duration `json:"response_header"`
+	ExpectContinue     time.Duration `json:"expect_continue"`
+	IdleConnection     time.Duration `json:"idle_connection"`
+	ConnectionLifetime time.Duration `json:"connection_lifetime"`
+}
+
+// transportTimeouts returns the timeouts of roundTripper, the transport the
+// client was built on, once options are applied.
+func transportTimeouts(roundTripper http.RoundTripper, options []transportOption, limits connLimits) Timeouts {
+	if roundTripper == nil {
+		roundTripper = http.DefaultTransport
+	}
+	timeouts := Timeouts{ConnectionLifetime: limits.lifetime}
+	if transport, ok := roundTripper.(*http.Transport); ok {
+		transport = transport.Clone()
+		for _, option := range options {
+			option(transport)
+		}
+		timeouts.TLSHandshake = transport.TLSHandshakeTimeout
+		timeouts.ResponseHeader = transport.ResponseHeaderTimeout
+		timeouts.ExpectContinue = transport.ExpectContinueTimeout
+		timeouts.IdleConnection = transport.IdleConnTimeout
+	}
+	return timeouts
+}
+
+func (c *HTTPClient) BaseURL() string {
+	return c.baseURL
+}
+
+func (c *HTTPClient) RetryPolicy() *RetryConfig {
+	return c.currentRetryConfig().clone()
+}
+
+func (c *HTTPClient) MiddlewareCount() int {
+	return len(c.middlewares)
+}
+
+func (c *HTTPClient) Timeouts() Timeouts {
+	return c.timeouts
+}
+
+// ClientPolicy is the effective configuration of a client, for logging and
+// debug endpoints. Features that are off are left zero.
+type ClientPolicy struct {
+	BaseURLs              []string         `json:"base_urls,omitempty"`
+	Retry                 *RetryConfig     `json:"retry"`
+	Timeout               time.Duration    `json:"timeout,omitempty"`
+	AdaptiveTimeout       *AdaptiveTimeout `json:"adaptive_timeout,omitempty"`
+	Env                   *EnvConfig       `json:"env,omitempty"`
+	CircuitBreaker        *BreakerPolicy   `json:"circuit_breaker,omitempty"`
+	RateLimit             *RateLimitPolicy `json:"rate_limit,omitempty"`
+	MaxConcurrentRequests int              `json:"max_concurrent_requests,omitempty"`
+	ResponseCacheTTL      time.Duration    `json:"response_cache_ttl,omitempty"`
+	PreflightCacheTTL     time.Duration    `json:"preflight_cache_ttl,omitempty"`
+	RedirectCacheTTL      time.Duration    `json:"redirect_cache_ttl,omitempty"`
+	DNSCacheTTL           time.Duration    `json:"dns_cache_ttl,omitempty"`
+	MaxRequestBytes       int64            `json:"max_request_bytes,omitempty"`
+	MaxResponseBytes      int64            `json:"max_response_bytes,omitempty"`
+	MaxHeaderCount        int              `json:"max_header_count,omitempty"`
+	MaxHeaderBytes        int              `json:"max_header_bytes,omitempty"`
+	BodyReadRetries       int              `json:"body_read_retries,omitempty"`
+	BufferedResponses     bool             `json:"buffered_responses"`
+	BodyLeakDetection     bool             `json:"body_leak_detection"`
+	BlockedCIDRs          []string         `json:"blocked_cidrs,omitempty"`
+	AllowedHosts          []string         `json:"allowed_hosts,omitempty"`
+	Middlewares           int              `json:"middlewares"`
+	Validators            int              `json:"validators"`
+	Handlers              int              `json:"handlers"`
+	Signed                bool             `json:"signed"`
+	VerifiesResponses     bool             `json:"verifies_responses"`
+	TokenSource           bool             `json:"token_source"`
+	Authenticator         bool             `json:"authenticator"`
+	APIKey                string           `json:"api_key,omitempty"`
+	Fallback              bool             `json:"fallback"`
+	ErrorOnNon2xx         bool             `json:"error_on_non_2xx"`
+	StrictURLs            bool             `json:"strict_urls"`
+	ErrorDecoder          bool             `json:"error_decoder"`
+	FaultInjection        bool             `json:"fault_injection"`
+	StaleEndpointRetry    bool             `json:"stale_endpoint_retry"`
+	Outbox                bool             `json:"outbox"`
+	OfflineQueue          *OfflineQueue    `json:"offline_queue,omitempty"`
+	ConnectionStats       bool             `json:"connection_stats"`
+	DualStack             *DualStack       `json:"dual_stack,omitempty"`
+	CertificatePins       []string         `json:"certificate_pins,omitempty"`
+}
+
+// BreakerPolicy is the circuit breaker part of a ClientPolicy.
+type BreakerPolicy struct {
+	FailureThreshold int           `json:"failure_threshold"`
+	Cooldown         time.Duration `json:"cooldown"`
+}
+
+// RateLimitPolicy is the rate limit part of a ClientPolicy.
+type RateLimitPolicy struct {
+	RequestsPerSecond float64       `json:"requests_per_second"`
+	Burst             int           `json:"burst"`
+	Warmup            time.Duration `json:"warmup,omitempty"`
+}
+
+// Policy returns the client's effective configuration.
+func (c *HTTPClient) Policy() ClientPolicy {
+	policy := ClientPolicy{
+		Retry:              c.currentRetryConfig().clone(),
+		Timeout:            c.timeout,
+		Env:                c.env.clone(),
+		MaxRequestBytes:    c.maxRequestBytes,
+		MaxResponseBytes:   c.maxResponseBytes,
+		MaxHeaderCount:     c.maxHeaderCount,
+		MaxHeaderBytes:     c.maxHeaderBytes,
+		BodyReadRetries:    c.bodyReadRetries,
+		BufferedResponses:  c.bufferResponses,
+		BodyLeakDetection:  c.leaks != nil,
+		Middlewares:        len(c.middlewares),
+		Validators:         len(c.validators),
+		Signed:             c.signer != nil,
+		VerifiesResponses:  c.verifier != nil,
+		TokenSource:        c.tokenSource != nil,
+		Authenticator:      c.authenticator != nil,
+		Fallback:           c.fallback != nil,
+		ErrorOnNon2xx:      c.errorOnNon2xx,
+		StrictURLs:         c.strictURLs,
+		ErrorDecoder:       c.errorDecoder != nil,
+		FaultInjection:     c.faults != nil,
+		StaleEndpointRetry: c.staleEndpoints != nil,
+		Outbox:             c.outbox != nil,
+		ConnectionStats:    c.stats != nil,
+	}
+	switch {
+	case c.baseURLs != nil:
+		policy.BaseURLs = slices.Clone(c.baseURLs.endpoints)
+	case c.baseURL != "":
+		policy.BaseURLs = []string{c.baseURL}
+	}
+	if c.breaker != nil {
+		threshold, cooldown := c.breaker.settings()
+		policy.CircuitBreaker = &BreakerPolicy{FailureThreshold: threshold, Cooldown: cooldown}
+	}
+	if c.rateLimiter != nil {
+		rate, burst := c.rateLimiter.settings()
+		policy.RateLimit = &RateLimitPolicy{
+			RequestsPerSecond: rate,
+			Burst:             int(burst),
+			Warmup:            c.rateLimiter.warmup,
+		}
+	}
+	if c.limiter != nil {
+		policy.MaxConcurrentRequests = cap(c.limiter.slots)
+	}
+	if c.cache != nil {
+		policy.ResponseCacheTTL = c.cache.store.ttl
+	}
+	if c.preflight != nil {
+		policy.PreflightCacheTTL = c.preflight.ttl
+	}
+	if c.hostGuard != nil {
+		for _, prefix := range c.hostGuard.blocked {
+			policy.BlockedCIDRs = append(policy.BlockedCIDRs, prefix.String())
+		}
+		policy.AllowedHosts = slices.Clone(c.hostGuard.allowedHosts)
+	}
+	if c.redirects != nil {
+		policy.RedirectCacheTTL = c.redirects.ttl
+	}
+	if c.dns != nil {
+		policy.DNSCacheTTL = c.dns.ttl
+	}
+	if c.apiKey != nil {
+		// Only where the key goes; the key itself never appears in a policy
+		policy.APIKey = c.apiKey.name + " in " + c.apiKey.in.String()
+	}
+	if c.dualStack != nil {
+		dualStack := *c.dualStack
+		policy.DualStack = &dualStack
+	}
+	policy.CertificatePins = slices.Clone(c.pins)
+	if c.offline != nil {
+		offline := c.offline.cfg
+		policy.OfflineQueue = &offline
+	}
+	if c.adaptive != nil {
+		adaptive := c.adaptive.cfg
+		policy.AdaptiveTimeout = &adaptive
+	}
+	return policy
+}
+
+// String describes the policy on one line, omitting features that are off.
+func (p ClientPolicy) String() string {
+	parts := []string{fmt.Sprintf("retry: %v", p.Retry)}
+	if p.Timeout > 0 {
+		parts = append(parts, fmt.Sprintf("timeout: %v", p.Timeout))
+	}
+	if p.AdaptiveTimeout != nil {
+		parts = append(parts, fmt.Sprintf("adaptive timeout: p%g x %g in [%v, %v]",
+			p.AdaptiveTimeout.Percentile*100, p.AdaptiveTimeout.Factor, p.AdaptiveTimeout.Min, p.AdaptiveTimeout.Max))
+	}
+	if len(p.BaseURLs) > 0 {
+		parts = append(parts, "base urls: "+strings.Join(p.BaseURLs, ", "))
+	}
+	if p.CircuitBreaker != nil {
+		parts = append(parts, fmt.Sprintf("circuit breaker: %d failures, %v cooldown",
+			p.CircuitBreaker.FailureThreshold, p.CircuitBreaker.Cooldown))
+	}
+	if p.RateLimit != nil {
+		parts = append(parts, fmt.Sprintf("rate limit: %g/s, burst %d", p.RateLimit.RequestsPerSecond, p.RateLimit.Burst))
+	}
+	if p.MaxConcurrentRequests > 0 {
+		parts = append(parts, fmt.Sprintf("max concurrent requests: %d", p.MaxConcurrentRequests))
+	}
+	if p.ResponseCacheTTL > 0 {
+		parts = append(parts, fmt.Sprintf("response cache: %v", p.ResponseCacheTTL))
+	}
+	if p.DNSCacheTTL > 0 {
+		parts = append(parts, fmt.Sprintf("dns cache: %v", p.DNSCacheTTL))
+	}
+	parts = append(parts, fmt.Sprintf("middlewares: %d", p.Middlewares))
+	return strings.Join(parts, "; ")
+}
+
+type retryConfigJSON struct {
+	MaxRetries           int      `json:"max_retries"`
+	RetryableStatusCodes []int    `json:"retryable_status_codes"`
+	RetryableErrors      []string `json:"retryable_errors"`
+	ErrorMatchers        []string `json:"error_matchers"`
+	Backoff              any      `json:"backoff"`
+}
+
+// MarshalJSON encodes the retry policy. Error matchers are listed by function
+// name.
+func (r *RetryConfig) MarshalJSON() ([]byte, error) {
+	errs := make([]string, 0, len(r.retryableError))
+	for err, enabled := range r.retryableError {
+		if enabled {
+			errs = append(errs, err)
+		}
+	}
+	slices.Sort(errs)
+	matchers := make([]string, len(r.errorMatchers))
+	for i, matcher := range r.errorMatchers {
+		matchers[i] = funcName(matcher)
+	}
+	return json.Marshal(retryConfigJSON{
+		MaxRetries:           r.maxRetries,
+		RetryableStatusCodes: r.retryableStatusCodes,
+		RetryableErrors:      errs,
+		ErrorMatchers:        matchers,
+		Backoff:              backoffJSON(r.backoffStrategy),
+	})
+}
+
+// String describes the retry policy, e.g. "up to 3 retries on [429 500 502
+// 503 504], exponential backoff ...".
+func (r *RetryConfig) String() string {
+	if r == nil {
+		return "no retries"
+	}
+	return fmt.Sprintf("up to %d retries on %v, %v", r.maxRetries, r.retryableStatusCodes, backoffString(r.backoffStrategy))
+}
+
+// backoffJSON returns what to encode for strategy. Strategies that don't
+// marshal themselves are described by their type.
+func backoffJSON(strategy BackoffStrategy) any {
+	if _, ok := strategy.(json.Marshaler); ok || strategy == nil {
+		return strategy
+	}
+	return map[string]string{"type": fmt.Sprintf("%T", strategy)}
+}
+
+func backoffString(strategy BackoffStrategy) string {
+	if stringer, ok := strategy.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprintf("%T backoff", strategy)
+}
+
+// funcName returns the package-qualified name of f, e.g.
+// "reqwest.IsTimeoutError".
+func funcName(f any) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	name := fn.Name()
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+func (s JitterStrategy) String() string {
+	switch s {
+	case JitterProportional:
+		return "proportional"
+	case JitterFull:
+		return "full"
+	case JitterDecorrelated:
+		return "decorrelated"
+	}
+	return fmt.Sprintf("JitterStrategy(%d)", int(s))
+}
+
+func (s JitterStrategy) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (e *ExponentialBackoff) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string          `json:"type"`
+		BaseDelay  time.Duration   `json:"base_delay"`
+		Multiplier float64         `json:"multiplier"`
+		MaxDelay   time.Duration   `json:"max_delay"`
+		Jitter     *JitterStrategy `json:"jitter,omitempty"`
+	}{"exponential", e.baseDelay, e.multiplier, e.maxDelay, e.jitterOrNil()})
+}
+
+func (e *ExponentialBackoff) jitterOrNil() *JitterStrategy {
+	if !e.jitter {
+		return nil
+	}
+	return &e.jitterStrategy
+}
+
+func (e *ExponentialBackoff) String() string {
+	s := fmt.Sprintf("exponential backoff from %v ×%g up to %v", e.baseDelay, e.multiplier, e.maxDelay)
+	if e.jitter {
+		s += fmt.Sprintf(" with %v jitter", e.jitterStrategy)
+	}
+	return s
+}
+
+func (f *FixedBackoff) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type   string        `json:"type"`
+		Delay  time.Duration `json:"delay"`
+		Jitter bool          `json:"jitter"`
+	}{"fixed", f.delay, f.jitter})
+}
+
+func (f *FixedBackoff) String() string {
+	if f.jitter {
+		return fmt.Sprintf("fixed backoff of %v with jitter", f.delay)
+	}
+	return fmt.Sprintf("fixed backoff of %v", f.delay)
+}
+
+func (b *RetryAfterBackoff) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type     string        `json:"type"`
+		MaxDelay time.Duration `json:"max_delay"`
+		Fallback any           `json:"fallback"`
+	}{"retry_after", b.maxDelay, backoffJSON(b.fallback)})
+}
+
+func (b *RetryAfterBackoff) String() string {
+	return fmt.Sprintf("server-directed backoff up to %v, else %v", b.maxDelay, backoffString(b.fallback))
+}
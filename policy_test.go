@@ -0,0 +1,160 @@
+package reqwest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type opaqueBackoff struct{}
+
+func (opaqueBackoff) Delay(int) time.Duration { return 0 }
+
+func TestRetryConfig_MarshalJSON(t *testing.T) {
+	config := NewRetryConfigBuilder().
+		WithBackoffStrategy(NewExponentialBackoffBuilder().WithJitterStrategy(JitterFull).Build()).
+		Build()
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var got struct {
+		MaxRetries      int      `json:"max_retries"`
+		RetryableErrors []string `json:"retryable_errors"`
+		ErrorMatchers   []string `json:"error_matchers"`
+		Backoff         struct {
+			Type      string        `json:"type"`
+			BaseDelay time.Duration `json:"base_delay"`
+			Jitter    string        `json:"jitter"`
+		} `json:"backoff"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got.MaxRetries != DefaultMaxRetries || len(got.RetryableErrors) != 0 {
+		t.Errorf("Expected the default limits, got %s", data)
+	}
+	if len(got.ErrorMatchers) == 0 || got.ErrorMatchers[0] != "reqwest.IsTimeoutError" {
+		t.Errorf("Expected matchers listed by name, got %v", got.ErrorMatchers)
+	}
+	if got.Backoff.Type != "exponential" || got.Backoff.BaseDelay != DefaultExponentialBaseDelay || got.Backoff.Jitter != "full" {
+		t.Errorf("Expected the exponential backoff, got %+v", got.Backoff)
+	}
+
+	t.Run("strategies without MarshalJSON", func(t *testing.T) {
+		data, err := json.Marshal(NewRetryConfigBuilder().WithBackoffStrategy(opaqueBackoff{}).Build())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(string(data), `"backoff":{"type":"reqwest.opaqueBackoff"}`) {
+			t.Errorf("Expected the strategy type, got %s", data)
+		}
+	})
+}
+
+func TestRetryConfig_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *RetryConfig
+		expected string
+	}{
+		{"nil", nil, "no retries"},
+		{"fixed", NewRetryConfigBuilder().
+			WithMaxRetries(2).
+			WithRetryableStatusCodes([]int{503}).
+			WithBackoffStrategy(NewFixedBackoffBuilder().WithDelay(time.Second).Build()).
+			Build(), "up to 2 retries on [503], fixed backoff of 1s"},
+		{"retry-after", NewRetryConfigBuilder().
+			WithRetryableStatusCodes([]int{429}).
+			WithBackoffStrategy(NewRetryAfterBackoffBuilder().
+				WithFallback(NewExponentialBackoffBuilder().WithJitter(true).Build()).
+				Build()).
+			Build(), "up to 3 retries on [429], server-directed backoff up to 1m0s, else exponential backoff from 100ms ×2 up to 10s with proportional jitter"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.String(); got != tt.expected {
+				t.Errorf("String() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClient_Policy(t *testing.T) {
+	cli := NewClientBuilder().
+		WithBaseURL("https://api.example.com").
+		WithRetries().
+		WithCircuitBreaker(5, time.Minute).
+		WithRateLimit(10, 20).
+		WithResponseCache(time.Minute).
+		WithMiddleware(func(*http.Request) error { return nil }).
+		Build()
+
+	policy := cli.Policy()
+	if policy.Retry == nil || policy.CircuitBreaker == nil || policy.CircuitBreaker.FailureThreshold != 5 ||
+		policy.RateLimit == nil || policy.RateLimit.Burst != 20 || policy.ResponseCacheTTL != time.Minute ||
+		policy.Middlewares != 1 || len(policy.BaseURLs) != 1 {
+		t.Errorf("Expected the configured policy, got %+v", policy)
+	}
+	if _, err := json.Marshal(policy); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	expected := "retry: up to 3 retries on [429 500 502 503 504], exponential backoff from 100ms ×2 up to 10s; " +
+		"base urls: https://api.example.com; circuit breaker: 5 failures, 1m0s cooldown; rate limit: 10/s, burst 20; " +
+		"response cache: 1m0s; middlewares: 1"
+	if got := policy.String(); got != expected {
+		t.Errorf("String() = %q, want %q", got, expected)
+	}
+
+	if got := NewClientBuilder().Build().Policy().String(); got != "retry: no retries; middlewares: 0" {
+		t.Errorf("Expected a minimal description, got %q", got)
+	}
+}
+
+func TestClient_Inspector(t *testing.T) {
+	t.Run("reports the configuration", func(t *testing.T) {
+		transport := &http.Transport{TLSHandshakeTimeout: 5 * time.Second, ResponseHeaderTimeout: 30 * time.Second}
+		cli := NewClientBuilder().
+			WithBaseURLs("https://a.example.com", "https://b.example.com").
+			WithRetries().
+			WithMiddleware(func(*http.Request) error { return nil }).
+			WithMiddleware(func(*http.Request) error { return nil }).
+			WithTransport(transport).
+			WithMaxConnectionLifetime(time.Minute).
+			Build()
+
+		if got := cli.BaseURL(); got != "https://a.example.com" {
+			t.Errorf("Expected the first base URL, got %q", got)
+		}
+		if policy := cli.RetryPolicy(); policy == nil || policy.maxRetries != DefaultMaxRetries {
+			t.Errorf("Expected the default retry policy, got %v", policy)
+		}
+		if got := cli.MiddlewareCount(); got != 2 {
+			t.Errorf("Expected 2 middlewares, got %d", got)
+		}
+		expected := Timeouts{TLSHandshake: 5 * time.Second, ResponseHeader: 30 * time.Second, ConnectionLifetime: time.Minute}
+		if got := cli.Timeouts(); got != expected {
+			t.Errorf("Timeouts() = %+v, want %+v", got, expected)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		cli := NewClientBuilder().Build()
+		if cli.BaseURL() != "" || cli.RetryPolicy() != nil || cli.MiddlewareCount() != 0 {
+			t.Errorf("Expected an unconfigured client, got %q, %v, %d", cli.BaseURL(), cli.RetryPolicy(), cli.MiddlewareCount())
+		}
+		if got := cli.Timeouts().TLSHandshake; got != http.DefaultTransport.(*http.Transport).TLSHandshakeTimeout {
+			t.Errorf("Expected the default transport's timeouts, got %v", got)
+		}
+	})
+
+	t.Run("retry policy is a copy", func(t *testing.T) {
+		cli := NewClientBuilder().WithRetries().Build()
+		cli.RetryPolicy().retryableStatusCodes[0] = 0
+		if cli.RetryPolicy().retryableStatusCodes[0] == 0 {
+			t.Error("Expected changes to the returned policy not to affect the client")
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package reqwest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrPrefetchFailed is wrapped when reading a prefetched body fails. Such
+// failures are retried like transport errors for idempotent methods.
+var ErrPrefetchFailed = errors.New("failed to prefetch response body")
+
+// prefetch reads the body of resp eagerly when its declared length is at most
+// limit, so the connection is released right away and read failures happen
+// inside the retry loop. Bodies of unknown or larger length keep streaming.
+func prefetch(resp *Response, contentLength, limit int64) error {
+	if contentLength < 0 || contentLength > limit {
+		return nil
+	}
+	data, err := io.ReadAll(resp.body)
+	resp.body.Close()
+	if err != nil {
+		if errors.Is(err, ErrBodyTooLarge) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrPrefetchFailed, err)
+	}
+	resp.body = io.NopCloser(bytes.NewReader(data))
+	return nil
+}
+
+// shouldRetryPrefetch reports whether a failed prefetch of a method's
+// response may be retried. A nil config never retries.
+func (r *RetryConfig) shouldRetryPrefetch(method string, err error) bool {
+	return r != nil && errors.Is(err, ErrPrefetchFailed) && idempotentMethod(method)
+}
@@ -0,0 +1,74 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_WithPrefetchBody(t *testing.T) {
+	t.Run("read failure is retried", func(t *testing.T) {
+		server, calls := newTruncatingServer(t, 1, `{"ok":true}`)
+		defer server.Close()
+
+		client := NewClientBuilder().
+			WithPrefetchBody(1024).
+			WithRetryConfig(fastRetryConfig(2)).
+			Build()
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := atomic.LoadInt32(calls); got != 2 || resp.RetryAttempts() != 1 {
+			t.Errorf("Expected 2 calls and 1 retry, got %d calls and %d retries", got, resp.RetryAttempts())
+		}
+		body, err := resp.Bytes()
+		if err != nil || string(body) != `{"ok":true}` {
+			t.Errorf("Expected prefetched body, got %q (%v)", body, err)
+		}
+	})
+
+	t.Run("read failure of POST is not retried", func(t *testing.T) {
+		server, calls := newTruncatingServer(t, 1, `{"ok":true}`)
+		defer server.Close()
+
+		client := NewClientBuilder().
+			WithPrefetchBody(1024).
+			WithRetryConfig(fastRetryConfig(2)).
+			Build()
+		_, err := client.Post(context.Background(), server.URL, []byte("{}"))
+		if !errors.Is(err, ErrPrefetchFailed) {
+			t.Fatalf("Expected ErrPrefetchFailed, got %v", err)
+		}
+		if got := atomic.LoadInt32(calls); got != 1 {
+			t.Errorf("Expected 1 call, got %d", got)
+		}
+	})
+
+	t.Run("large bodies keep streaming", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", strconv.Itoa(4096))
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			<-release
+			w.Write(make([]byte, 4096))
+		}))
+		defer server.Close()
+
+		client := NewClientBuilder().WithPrefetchBody(1024).Build()
+		resp, err := client.Get(context.Background(), server.URL)
+		close(release)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		body, err := resp.Bytes()
+		if err != nil || len(body) != 4096 {
+			t.Errorf("Expected 4096 streamed bytes, got %d (%v)", len(body), err)
+		}
+	})
+}
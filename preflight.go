@@ -0,0 +1,54 @@
+package reqwest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// preflightKey returns the scheme, host and path of rawURL, dropping the
+// query and fragment. OPTIONS responses are cached per key, so capability
+// probes issued before every operation cost one round trip per TTL instead
+// of one per operation; resourceOf uses it for cache invalidation.
+func preflightKey(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return parsed.Scheme + "://" + parsed.Host + parsed.Path
+}
+
+// Options performs an OPTIONS request. When a preflight cache is configured,
+// successful responses are served from the cache until their TTL expires.
+func (c *HTTPClient) Options(ctx context.Context, url string) (*Response, error) {
+	if c.preflight == nil {
+		return c.execute(ctx, NewRequest(http.MethodOptions, url))
+	}
+
+	key := preflightKey(c.buildURL(url))
+	if entry, ok := c.preflight.get(key); ok {
+		return entry.response(), nil
+	}
+
+	resp, err := c.execute(ctx, NewRequest(http.MethodOptions, url))
+	if err != nil || resp.statusCode < http.StatusOK || resp.statusCode >= http.StatusMultipleChoices {
+		return resp, err
+	}
+
+	defer resp.body.Close()
+	body, err := io.ReadAll(resp.body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+	resp.body = io.NopCloser(bytes.NewReader(body))
+	c.preflight.put(key, cachedResponse{
+		statusCode: resp.statusCode,
+		proto:      resp.proto,
+		header:     resp.header.Clone(),
+		body:       body,
+	})
+	return resp, nil
+}
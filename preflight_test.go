@@ -0,0 +1,133 @@
+package reqwest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Options(t *testing.T) {
+	newServer := func(calls *int32, status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodOptions {
+				t.Errorf("Expected OPTIONS method, got %s", r.Method)
+			}
+			atomic.AddInt32(calls, 1)
+			w.Header().Set("Allow", "GET, POST")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte("caps"))
+		}))
+	}
+
+	t.Run("without cache every call reaches the server", func(t *testing.T) {
+		var calls int32
+		server := newServer(&calls, http.StatusOK)
+		defer server.Close()
+
+		cli := NewClientBuilder().Build()
+		for i := 0; i < 2; i++ {
+			resp, err := cli.Options(context.TODO(), server.URL)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body().Close()
+			if resp.FromCache() {
+				t.Error("Expected uncached response")
+			}
+		}
+
+		if atomic.LoadInt32(&calls) != 2 {
+			t.Errorf("Expected 2 server calls, got %d", calls)
+		}
+	})
+
+	t.Run("cache serves repeated probes", func(t *testing.T) {
+		var calls int32
+		server := newServer(&calls, http.StatusOK)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithBaseURL(server.URL).WithPreflightCache(time.Minute).Build()
+		for i := 0; i < 3; i++ {
+			resp, err := cli.Options(context.TODO(), "/resource?probe=1")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body())
+			resp.Body().Close()
+
+			if resp.Header().Get("Allow") != "GET, POST" {
+				t.Errorf("Expected Allow header, got %q", resp.Header().Get("Allow"))
+			}
+			if string(body) != "caps" {
+				t.Errorf("Expected body 'caps', got %q", body)
+			}
+			if resp.FromCache() != (i > 0) {
+				t.Errorf("Call %d: unexpected FromCache=%t", i, resp.FromCache())
+			}
+		}
+
+		if atomic.LoadInt32(&calls) != 1 {
+			t.Errorf("Expected 1 server call, got %d", calls)
+		}
+	})
+
+	t.Run("entries expire after ttl", func(t *testing.T) {
+		var calls int32
+		server := newServer(&calls, http.StatusOK)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithPreflightCache(20 * time.Millisecond).Build()
+		for i := 0; i < 2; i++ {
+			resp, err := cli.Options(context.TODO(), server.URL)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body().Close()
+			time.Sleep(30 * time.Millisecond)
+		}
+
+		if atomic.LoadInt32(&calls) != 2 {
+			t.Errorf("Expected 2 server calls after expiry, got %d", calls)
+		}
+	})
+
+	t.Run("error responses are not cached", func(t *testing.T) {
+		var calls int32
+		server := newServer(&calls, http.StatusForbidden)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithPreflightCache(time.Minute).Build()
+		for i := 0; i < 2; i++ {
+			resp, err := cli.Options(context.TODO(), server.URL)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body().Close()
+		}
+
+		if atomic.LoadInt32(&calls) != 2 {
+			t.Errorf("Expected 2 server calls, got %d", calls)
+		}
+	})
+}
+
+func TestPreflightKey(t *testing.T) {
+	testCases := []struct {
+		url      string
+		expected string
+	}{
+		{"https://api.example.com/a?x=1", "https://api.example.com/a"},
+		{"http://localhost:8080", "http://localhost:8080"},
+		{"://bad", "://bad"},
+	}
+
+	for _, tc := range testCases {
+		if got := preflightKey(tc.url); got != tc.expected {
+			t.Errorf("preflightKey(%q) = %q, want %q", tc.url, got, tc.expected)
+		}
+	}
+}
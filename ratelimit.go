@@ -0,0 +1,153 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// rateLimitKey is the StateStore key of the client's token bucket. Replicas
+// sharing a store and a key share one budget.
+const rateLimitKey = "reqwest:ratelimit"
+
+// bucketState is the persisted state of a token bucket.
+type bucketState struct {
+	Tokens  float64   `json:"tokens"`
+	Updated time.Time `json:"updated"`
+	Last    time.Time `json:"last,omitempty"`
+}
+
+// minWarmFraction keeps the warm-up rate above zero.
+const minWarmFraction = 0.01
+
+type rateLimitConfig struct {
+	rate          float64
+	burst         int
+	warmup        time.Duration
+	warmFraction  float64
+	smoothingSpan time.Duration
+}
+
+// rateLimiter is a token bucket refilled at rate tokens per second up to
+// burst. During warm-up the refill rate and the initial tokens are scaled
+// down, ramping linearly to the full rate. With smoothing, a full burst is
+// spread evenly over the smoothing window instead of sent at once. Store
+// errors fail open.
+type rateLimiter struct {
+	rate         float64
+	burst        float64
+	warmup       time.Duration
+	warmFraction float64
+	smoothing    time.Duration
+	spacing      time.Duration
+	store        StateStore
+	clock        Clock
+
+	mu        sync.Mutex
+	warmStart time.Time
+}
+
+func newRateLimiter(cfg rateLimitConfig, store StateStore, clock Clock) *rateLimiter {
+	clock = orSystemClock(clock)
+	l := &rateLimiter{
+		rate:         cfg.rate,
+		warmup:       cfg.warmup,
+		warmFraction: min(max(cfg.warmFraction, minWarmFraction), 1),
+		smoothing:    cfg.smoothingSpan,
+		store:        store,
+		clock:        clock,
+		warmStart:    clock.Now(),
+	}
+	l.setBurst(cfg.burst)
+	return l
+}
+
+// setBurst sets the burst and the smoothing spacing derived from it.
+func (l *rateLimiter) setBurst(burst int) {
+	burst = max(burst, 1)
+	l.burst = float64(burst)
+	if l.smoothing > 0 {
+		l.spacing = l.smoothing / time.Duration(burst)
+	}
+}
+
+// update changes the rate and burst of a live limiter.
+func (l *rateLimiter) update(rate float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = rate
+	l.setBurst(burst)
+}
+
+func (l *rateLimiter) settings() (rate, burst float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate, l.burst
+}
+
+// restartWarmup ramps the rate up again from the warm-up fraction, e.g. after
+// a circuit closes.
+func (l *rateLimiter) restartWarmup() {
+	l.mu.Lock()
+	l.warmStart = l.clock.Now()
+	l.mu.Unlock()
+}
+
+// scale returns the fraction of the full rate in effect at now.
+func (l *rateLimiter) scale(now time.Time) float64 {
+	if l.warmup <= 0 {
+		return 1
+	}
+	progress := float64(now.Sub(l.warmStart)) / float64(l.warmup)
+	if progress >= 1 {
+		return 1
+	}
+	return l.warmFraction + (1-l.warmFraction)*progress
+}
+
+// wait blocks until a token is available or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		delay := l.take(ctx)
+		if delay <= 0 {
+			return nil
+		}
+		if err := sleep(ctx, l.clock, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// take consumes a token, or returns how long until one is available.
+func (l *rateLimiter) take(ctx context.Context) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.clock.Now()
+	scale := l.scale(now)
+	rate := l.rate * scale
+	state := bucketState{Tokens: l.burst * scale, Updated: now}
+	data, err := l.store.Load(ctx, rateLimitKey)
+	if err != nil {
+		return 0
+	}
+	if data != nil {
+		_ = json.Unmarshal(data, &state)
+	}
+	state.Tokens = min(l.burst, state.Tokens+now.Sub(state.Updated).Seconds()*rate)
+	state.Updated = now
+	if l.spacing > 0 && !state.Last.IsZero() {
+		if gap := l.spacing - now.Sub(state.Last); gap > 0 {
+			return gap
+		}
+	}
+	if state.Tokens < 1 {
+		return time.Duration((1 - state.Tokens) / rate * float64(time.Second))
+	}
+	state.Tokens--
+	state.Last = now
+	data, _ = json.Marshal(state)
+	_ = l.store.Store(ctx, rateLimitKey, data, time.Duration(l.burst/l.rate*float64(time.Second))+time.Minute)
+	return 0
+}
@@ -0,0 +1,109 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_WithRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	t.Run("burst then throttle", func(t *testing.T) {
+		client := NewClientBuilder().WithRateLimit(20, 2).Build()
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			if _, err := client.Get(context.Background(), server.URL); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		// Two requests fit the burst, the other two wait ~50ms each
+		if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+			t.Errorf("Expected throttling, 4 requests took %v", elapsed)
+		}
+	})
+
+	t.Run("waiting honours the context", func(t *testing.T) {
+		client := NewClientBuilder().WithRateLimit(0.1, 1).Build()
+		if _, err := client.Get(context.Background(), server.URL); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if _, err := client.Get(ctx, server.URL); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("replicas share one budget", func(t *testing.T) {
+		store := NewMemoryStateStore()
+		replica1 := NewClientBuilder().WithRateLimit(0.1, 1).WithStateStore(store).Build()
+		replica2 := NewClientBuilder().WithRateLimit(0.1, 1).WithStateStore(store).Build()
+		if _, err := replica1.Get(context.Background(), server.URL); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if _, err := replica2.Get(ctx, server.URL); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected replica2 to wait for the shared budget, got %v", err)
+		}
+	})
+}
+
+func TestClient_RateLimitShaping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	timeRequests := func(client Client, n int) time.Duration {
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			if _, err := client.Get(context.Background(), server.URL); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		return time.Since(start)
+	}
+
+	t.Run("warm start", func(t *testing.T) {
+		// 10% of a burst of 10 is available at first, refilled at ~10/s
+		client := NewClientBuilder().
+			WithRateLimit(100, 10).
+			WithRateLimitWarmup(time.Minute, 0.1).
+			Build()
+		if elapsed := timeRequests(client, 3); elapsed < 100*time.Millisecond {
+			t.Errorf("Expected a reduced rate during warm-up, 3 requests took %v", elapsed)
+		}
+	})
+
+	t.Run("restarts when a circuit closes", func(t *testing.T) {
+		impl := NewClientBuilder().
+			WithRateLimit(100, 10).
+			WithRateLimitWarmup(50*time.Millisecond, 0.5).
+			WithCircuitBreaker(1, time.Millisecond).
+			Build()
+		limiter := impl.rateLimiter
+		time.Sleep(60 * time.Millisecond)
+		if limiter.scale(time.Now()) != 1 {
+			t.Fatal("Expected warm-up to be complete")
+		}
+		impl.breaker.record(context.Background(), "upstream", false)
+		impl.breaker.record(context.Background(), "upstream", true)
+		if scale := limiter.scale(time.Now()); scale >= 1 {
+			t.Errorf("Expected warm-up to restart after the circuit closed, scale is %v", scale)
+		}
+	})
+
+	t.Run("smoothing spreads a burst", func(t *testing.T) {
+		client := NewClientBuilder().
+			WithRateLimit(1000, 4).
+			WithRateLimitSmoothing(100 * time.Millisecond).
+			Build()
+		if elapsed := timeRequests(client, 4); elapsed < 70*time.Millisecond {
+			t.Errorf("Expected the burst to be spread over the window, 4 requests took %v", elapsed)
+		}
+	})
+}
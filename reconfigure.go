@@ -0,0 +1,75 @@
+package reqwest
+
+import (
+	"fmt"
+	"time"
+)
+
+// Reconfigurer updates the resilience settings of a live client, e.g. from a
+// control plane, without rebuilding it and dropping warm connections. Updates
+// are atomic: requests see either the old or the new settings, and requests
+// already running keep the retry policy they started with. Clients derived
+// afterwards inherit the updated settings.
+type Reconfigurer interface {
+	// UpdateRetryConfig replaces the retry policy. nil disables retries.
+	UpdateRetryConfig(config *RetryConfig)
+	// UpdateRateLimit changes the rate and burst of a client built with
+	// WithRateLimit. Tokens already in the bucket are kept up to the new
+	// burst.
+	UpdateRateLimit(requestsPerSecond float64, burst int) error
+	// UpdateCircuitBreaker changes the thresholds of a client built with
+	// WithCircuitBreaker. Circuits already open use the new cooldown.
+	UpdateCircuitBreaker(failureThreshold int, cooldown time.Duration) error
+}
+
+func (c *HTTPClient) UpdateRetryConfig(config *RetryConfig) {
+	config = config.clone()
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+	c.retryConfig = config
+	if c.builder != nil {
+		c.builder.retryConfig = config.clone()
+	}
+}
+
+func (c *HTTPClient) UpdateRateLimit(requestsPerSecond float64, burst int) error {
+	if c.rateLimiter == nil {
+		return fmt.Errorf("failed to update rate limit: client was built without WithRateLimit")
+	}
+	if requestsPerSecond <= 0 {
+		return fmt.Errorf("failed to update rate limit: rate must be positive, got %g", requestsPerSecond)
+	}
+	c.rateLimiter.update(requestsPerSecond, burst)
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+	if c.builder != nil {
+		c.builder.rateLimit.rate = requestsPerSecond
+		c.builder.rateLimit.burst = burst
+	}
+	return nil
+}
+
+func (c *HTTPClient) UpdateCircuitBreaker(failureThreshold int, cooldown time.Duration) error {
+	if c.breaker == nil {
+		return fmt.Errorf("failed to update circuit breaker: client was built without WithCircuitBreaker")
+	}
+	if failureThreshold <= 0 {
+		return fmt.Errorf("failed to update circuit breaker: threshold must be positive, got %d", failureThreshold)
+	}
+	c.breaker.update(failureThreshold, cooldown)
+	c.configMu.Lock()
+	defer c.configMu.Unlock()
+	if c.builder != nil {
+		c.builder.breakerThreshold = failureThreshold
+		c.builder.breakerCooldown = cooldown
+	}
+	return nil
+}
+
+// currentRetryConfig returns the client's retry policy. The returned config
+// is never modified, so callers may keep using it after an update.
+func (c *HTTPClient) currentRetryConfig() *RetryConfig {
+	c.configMu.RLock()
+	defer c.configMu.RUnlock()
+	return c.retryConfig
+}
@@ -0,0 +1,107 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Reconfigurer(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	t.Run("retry config", func(t *testing.T) {
+		cli := NewClientBuilder().WithRetryConfig(fastRetryConfig(1)).Build()
+		cli.UpdateRetryConfig(fastRetryConfig(3))
+		atomic.StoreInt32(&hits, 0)
+		cli.Get(context.Background(), server.URL)
+		if got := atomic.LoadInt32(&hits); got != 4 {
+			t.Errorf("Expected 4 attempts, got %d", got)
+		}
+		if got := cli.Derive().Build().RetryPolicy().maxRetries; got != 3 {
+			t.Errorf("Expected derived clients to inherit the update, got %d retries", got)
+		}
+
+		cli.UpdateRetryConfig(nil)
+		atomic.StoreInt32(&hits, 0)
+		cli.Get(context.Background(), server.URL)
+		if got := atomic.LoadInt32(&hits); got != 1 {
+			t.Errorf("Expected retries to be disabled, got %d attempts", got)
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		cli := NewClientBuilder().WithRetryConfig(nil).WithRateLimit(0.1, 1).Build()
+		cli.Get(context.Background(), server.URL)
+		if err := cli.UpdateRateLimit(1000, 10); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		for range 5 {
+			if _, err := cli.Get(ctx, server.URL); err != nil {
+				t.Fatalf("Expected the raised limit to apply, got %v", err)
+			}
+		}
+		if got := cli.Policy().RateLimit; got.RequestsPerSecond != 1000 || got.Burst != 10 {
+			t.Errorf("Expected the policy to report the update, got %+v", got)
+		}
+		if err := cli.UpdateRateLimit(0, 1); err == nil {
+			t.Error("Expected a zero rate to be rejected")
+		}
+	})
+
+	t.Run("circuit breaker", func(t *testing.T) {
+		cli := NewClientBuilder().WithRetryConfig(nil).WithCircuitBreaker(5, time.Hour).Build()
+		if err := cli.UpdateCircuitBreaker(1, time.Hour); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		cli.Get(context.Background(), server.URL)
+		if _, err := cli.Get(context.Background(), server.URL); !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected the lowered threshold to open the circuit, got %v", err)
+		}
+		cli.UpdateCircuitBreaker(1, 0)
+		if _, err := cli.Get(context.Background(), server.URL); errors.Is(err, ErrCircuitOpen) {
+			t.Error("Expected the shorter cooldown to apply to the open circuit")
+		}
+	})
+
+	t.Run("features not configured", func(t *testing.T) {
+		cli := NewClientBuilder().Build()
+		if err := cli.UpdateRateLimit(10, 1); err == nil {
+			t.Error("Expected an error without WithRateLimit")
+		}
+		if err := cli.UpdateCircuitBreaker(3, time.Second); err == nil {
+			t.Error("Expected an error without WithCircuitBreaker")
+		}
+	})
+
+	t.Run("concurrent updates", func(t *testing.T) {
+		cli := NewClientBuilder().WithRetryConfig(fastRetryConfig(1)).WithRateLimit(1000, 10).WithCircuitBreaker(100, time.Millisecond).Build()
+		var wg sync.WaitGroup
+		for i := range 10 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				cli.UpdateRetryConfig(fastRetryConfig(i % 2))
+				cli.UpdateRateLimit(float64(1000+i), 10)
+				cli.UpdateCircuitBreaker(100+i, time.Millisecond)
+			}()
+			go func() {
+				defer wg.Done()
+				cli.Get(context.Background(), server.URL)
+				_ = cli.Policy().String()
+			}()
+		}
+		wg.Wait()
+	})
+}
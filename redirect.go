@@ -0,0 +1,109 @@
+package reqwest
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RedirectCacher exposes the client's permanent redirect cache. Without
+// WithRedirectCache flushing is a no-op.
+type RedirectCacher interface {
+	// FlushRedirects drops the redirects cached for urls, or all of them when
+	// no URL is given, e.g. after a moved resource was moved back. Relative
+	// URLs are resolved against the base URL.
+	FlushRedirects(urls ...string)
+}
+
+// maxCachedRedirectHops bounds how many cached redirects a URL is followed
+// through, so a cycle left by a misconfigured server can't loop.
+const maxCachedRedirectHops = 10
+
+// redirectCache remembers permanent redirects, so later requests for a moved
+// URL go straight to its new location instead of paying for the redirect.
+type redirectCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	clock   Clock
+	entries map[string]cachedRedirect
+}
+
+type cachedRedirect struct {
+	to string
+	// anyMethod is set for 308s; a 301 may turn a POST into a GET, so it is
+	// only replayed for GET and HEAD
+	anyMethod bool
+	expiresAt time.Time
+}
+
+func newRedirectCache(ttl time.Duration, clock Clock) *redirectCache {
+	return &redirectCache{ttl: ttl, clock: orSystemClock(clock), entries: make(map[string]cachedRedirect)}
+}
+
+// resolve returns where rawURL permanently redirects to for method, or
+// rawURL if no redirect is cached.
+func (r *redirectCache) resolve(method, rawURL string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.clock.Now()
+	for range maxCachedRedirectHops {
+		entry, ok := r.entries[rawURL]
+		if !ok {
+			break
+		}
+		if now.After(entry.expiresAt) {
+			delete(r.entries, rawURL)
+			break
+		}
+		if !entry.anyMethod && method != http.MethodGet && method != http.MethodHead {
+			break
+		}
+		rawURL = entry.to
+	}
+	return rawURL
+}
+
+// observe records the permanent redirects followed to obtain resp.
+func (r *redirectCache) observe(resp *Response) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expiresAt := r.clock.Now().Add(r.ttl)
+	for req := resp.request; req != nil && req.Response != nil && req.Response.Request != nil; req = req.Response.Request {
+		status := req.Response.StatusCode
+		if status != http.StatusMovedPermanently && status != http.StatusPermanentRedirect {
+			continue
+		}
+		r.entries[req.Response.Request.URL.String()] = cachedRedirect{
+			to:        req.URL.String(),
+			anyMethod: status == http.StatusPermanentRedirect,
+			expiresAt: expiresAt,
+		}
+	}
+}
+
+// forget drops the redirect cached for rawURL, e.g. because its target
+// stopped answering.
+func (r *redirectCache) forget(rawURL string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, rawURL)
+}
+
+// flush drops every cached redirect.
+func (r *redirectCache) flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.entries)
+}
+
+func (c *HTTPClient) FlushRedirects(urls ...string) {
+	if c.redirects == nil {
+		return
+	}
+	if len(urls) == 0 {
+		c.redirects.flush()
+	}
+	for _, url := range urls {
+		c.redirects.forget(c.buildURL(url))
+	}
+}
@@ -0,0 +1,116 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithRedirectCache(t *testing.T) {
+	newServer := func(status int) (*httptest.Server, *atomic.Int32) {
+		var redirects atomic.Int32
+		mux := http.NewServeMux()
+		mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+			redirects.Add(1)
+			http.Redirect(w, r, "/new", status)
+		})
+		mux.HandleFunc("/new", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(r.Method))
+		})
+		return httptest.NewServer(mux), &redirects
+	}
+
+	t.Run("permanent redirect is followed once", func(t *testing.T) {
+		server, redirects := newServer(http.StatusPermanentRedirect)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithRedirectCache(time.Hour).Build()
+		for i := range 3 {
+			resp, err := cli.Post(context.Background(), server.URL+"/old", []byte("{}"))
+			if err != nil {
+				t.Fatalf("Post() #%d error = %v", i+1, err)
+			}
+			if body, _ := resp.Bytes(); string(body) != http.MethodPost {
+				t.Errorf("Post() #%d reached %s, want POST", i+1, body)
+			}
+		}
+		if got := redirects.Load(); got != 1 {
+			t.Errorf("redirects = %d, want 1", got)
+		}
+	})
+
+	t.Run("301 is only replayed for GET", func(t *testing.T) {
+		server, redirects := newServer(http.StatusMovedPermanently)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithRedirectCache(time.Hour).Build()
+		for range 2 {
+			if _, err := cli.Get(context.Background(), server.URL+"/old"); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+		}
+		if _, err := cli.Post(context.Background(), server.URL+"/old", nil); err != nil {
+			t.Fatalf("Post() error = %v", err)
+		}
+		if got := redirects.Load(); got != 2 {
+			t.Errorf("redirects = %d, want 2", got)
+		}
+	})
+
+	t.Run("temporary redirects are not cached", func(t *testing.T) {
+		server, redirects := newServer(http.StatusFound)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithRedirectCache(time.Hour).Build()
+		for range 2 {
+			if _, err := cli.Get(context.Background(), server.URL+"/old"); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+		}
+		if got := redirects.Load(); got != 2 {
+			t.Errorf("redirects = %d, want 2", got)
+		}
+	})
+
+	t.Run("entries expire", func(t *testing.T) {
+		server, redirects := newServer(http.StatusPermanentRedirect)
+		defer server.Close()
+
+		clock := newManualClock(time.Now())
+		cli := NewClientBuilder().WithClock(clock).WithRedirectCache(time.Minute).Build()
+		cli.Get(context.Background(), server.URL+"/old")
+		clock.advance(2 * time.Minute)
+		cli.Get(context.Background(), server.URL+"/old")
+		if got := redirects.Load(); got != 2 {
+			t.Errorf("redirects = %d, want 2", got)
+		}
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		server, redirects := newServer(http.StatusPermanentRedirect)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithBaseURL(server.URL).WithRedirectCache(time.Hour).Build()
+		get := func() {
+			if _, err := cli.Get(context.Background(), "/old"); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+		}
+		get()
+		cli.FlushRedirects("/other")
+		get()
+		if got := redirects.Load(); got != 1 {
+			t.Errorf("redirects after flushing another URL = %d, want 1", got)
+		}
+		cli.FlushRedirects("/old")
+		get()
+		cli.FlushRedirects()
+		get()
+		if got := redirects.Load(); got != 3 {
+			t.Errorf("redirects = %d, want 3", got)
+		}
+	})
+}
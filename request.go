@@ -0,0 +1,212 @@
+package reqwest
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// Request describes a logical HTTP request executed by Client.Do. Any method
+// token is accepted, so verbs without a named helper (PUT, PATCH, DELETE,
+// PROPFIND, REPORT, ...) can be sent as well.
+type Request struct {
+	method        string
+	url           string
+	body          []byte
+	header        http.Header
+	retryConfig   *RetryConfig
+	retryOverride bool
+	timeout       time.Duration
+	byteBudget    int64
+	values        map[any]any
+	template      string
+	expect        Codec
+	checksum      *checksum
+}
+
+// NewRequest creates a request for method and url. Relative URLs are resolved
+// against the client's base URL.
+func NewRequest(method, url string) *Request {
+	return &Request{
+		method: method,
+		url:    url,
+		header: make(http.Header),
+	}
+}
+
+func (r *Request) WithBody(body []byte) *Request {
+	r.body = body
+	return r
+}
+
+// WithHeader sets a header on every attempt of the request, replacing any
+// previous value for key.
+func (r *Request) WithHeader(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+// WithQuery adds a query parameter to the request URL, escaping key and value.
+func (r *Request) WithQuery(key, value string) *Request {
+	url, fragment, hasFragment := strings.Cut(r.url, "#")
+	switch {
+	case !strings.Contains(url, "?"):
+		url += "?"
+	case !strings.HasSuffix(url, "?") && !strings.HasSuffix(url, "&"):
+		url += "&"
+	}
+	url += neturl.QueryEscape(key) + "=" + neturl.QueryEscape(value)
+	if hasFragment {
+		url += "#" + fragment
+	}
+	r.url = url
+	return r
+}
+
+// WithTimeout bounds the request to timeout, covering all retry attempts and
+// reading the response body, instead of the client's timeout. A timeout set
+// with ContextWithTimeoutOverride still wins.
+func (r *Request) WithTimeout(timeout time.Duration) *Request {
+	r.timeout = timeout
+	return r
+}
+
+// WithValue attaches a value to the request for cross-cutting concerns, e.g.
+// a tenant ID or operation name, without sending it. The client adds it to
+// the context of the call, so middleware reads it with
+// req.Context().Value(key) and hooks and metrics collectors with ctx.Value.
+// As with context.WithValue, key should be of an unexported type.
+func (r *Request) WithValue(key, value any) *Request {
+	if r.values == nil {
+		r.values = make(map[any]any)
+	}
+	r.values[key] = value
+	return r
+}
+
+// Value returns the value attached to the request for key, or nil.
+func (r *Request) Value(key any) any {
+	return r.values[key]
+}
+
+// withValues adds the request's values to ctx.
+func (r *Request) withValues(ctx context.Context) context.Context {
+	for key, value := range r.values {
+		ctx = context.WithValue(ctx, key, value)
+	}
+	return ctx
+}
+
+// Expect declares the media type the caller will decode the response as.
+// The request asks for it with an Accept header unless one is set, and
+// Response.JSON and Response.Decode fail with ErrUnexpectedContentType if the
+// server answers with another type. GoJSON and GetAllPages expect JSON.
+func (r *Request) Expect(codec Codec) *Request {
+	r.expect = codec
+	return r
+}
+
+// ExpectJSON is Expect(JSONCodec{}).
+func (r *Request) ExpectJSON() *Request {
+	return r.Expect(JSONCodec{})
+}
+
+// ExpectXML is Expect(XMLCodec{}).
+func (r *Request) ExpectXML() *Request {
+	return r.Expect(XMLCodec{})
+}
+
+// WithRetryConfig overrides the client's retry configuration for this
+// request only. Passing nil disables retries, like WithoutRetries.
+func (r *Request) WithRetryConfig(config *RetryConfig) *Request {
+	r.retryConfig = config
+	r.retryOverride = true
+	return r
+}
+
+// WithoutRetries disables retries for this request, e.g. for non-idempotent
+// endpoints on a client that otherwise retries.
+func (r *Request) WithoutRetries() *Request {
+	return r.WithRetryConfig(nil)
+}
+
+// Clone returns a copy of the request that can be modified independently,
+// e.g. to derive the request for the next page.
+func (r *Request) Clone() *Request {
+	clone := *r
+	clone.body = append([]byte(nil), r.body...)
+	clone.header = r.header.Clone()
+	clone.values = maps.Clone(r.values)
+	if clone.header == nil {
+		clone.header = make(http.Header)
+	}
+	return &clone
+}
+
+// withURL returns a copy of the request for url.
+func (r *Request) withURL(url string) *Request {
+	clone := r.Clone()
+	clone.url = url
+	return clone
+}
+
+// RequestOption customizes a single Get or Post call, without building a
+// Request:
+//
+//	resp, err := client.Get(ctx, "/search",
+//		reqwest.RequestQuery("q", "go"),
+//		reqwest.RequestTimeout(2*time.Second))
+type RequestOption func(*Request)
+
+// RequestHeader sets a header, like Request.WithHeader.
+func RequestHeader(key, value string) RequestOption {
+	return func(r *Request) { r.WithHeader(key, value) }
+}
+
+// RequestQuery adds a query parameter, like Request.WithQuery.
+func RequestQuery(key, value string) RequestOption {
+	return func(r *Request) { r.WithQuery(key, value) }
+}
+
+// RequestTimeout bounds the call to timeout, like Request.WithTimeout.
+func RequestTimeout(timeout time.Duration) RequestOption {
+	return func(r *Request) { r.WithTimeout(timeout) }
+}
+
+// RequestRetryConfig overrides the client's retries, like
+// Request.WithRetryConfig.
+func RequestRetryConfig(config *RetryConfig) RequestOption {
+	return func(r *Request) { r.WithRetryConfig(config) }
+}
+
+// RequestWithoutRetries disables retries, like Request.WithoutRetries.
+func RequestWithoutRetries() RequestOption {
+	return func(r *Request) { r.WithoutRetries() }
+}
+
+// with applies opts to the request.
+func (r *Request) with(opts []RequestOption) *Request {
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *Request) Method() string {
+	return r.method
+}
+
+func (r *Request) URL() string {
+	return r.url
+}
+
+func (r *Request) Body() []byte {
+	return r.body
+}
+
+func (r *Request) Header() http.Header {
+	return r.header
+}
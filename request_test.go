@@ -0,0 +1,293 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Compile-time checks that the concrete client satisfies every capability.
+var (
+	_ Getter   = (*HTTPClient)(nil)
+	_ Poster   = (*HTTPClient)(nil)
+	_ Prober   = (*HTTPClient)(nil)
+	_ Doer     = (*HTTPClient)(nil)
+	_ Streamer = (*HTTPClient)(nil)
+	_ Client   = (*HTTPClient)(nil)
+)
+
+func TestNewRequest(t *testing.T) {
+	req := NewRequest(http.MethodPut, "/items/1").
+		WithBody([]byte("data")).
+		WithHeader("Content-Type", "text/plain").
+		WithHeader("Content-Type", "application/json")
+
+	if req.Method() != http.MethodPut || req.URL() != "/items/1" {
+		t.Errorf("Unexpected method/url: %s %s", req.Method(), req.URL())
+	}
+	if string(req.Body()) != "data" {
+		t.Errorf("Expected body 'data', got %q", req.Body())
+	}
+	if req.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected WithHeader to replace values, got %v", req.Header().Values("Content-Type"))
+	}
+}
+
+func TestClient_Do(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Depth", r.Header.Get("Depth"))
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	cli := NewClientBuilder().WithBaseURL(server.URL).Build()
+
+	var doer Doer = cli
+	resp, err := doer.Do(context.TODO(), NewRequest("PROPFIND", "/dav/").
+		WithHeader("Depth", "1").
+		WithBody([]byte("<propfind/>")))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body().Close()
+
+	body, _ := io.ReadAll(resp.Body())
+	if resp.Header().Get("X-Method") != "PROPFIND" || resp.Header().Get("X-Depth") != "1" {
+		t.Errorf("Expected PROPFIND with Depth header, got %v", resp.Header())
+	}
+	if string(body) != "<propfind/>" {
+		t.Errorf("Expected body to be sent, got %q", body)
+	}
+}
+
+func TestDecorators_Do(t *testing.T) {
+	stub := &stubClient{results: []stubResult{
+		{statusCode: http.StatusServiceUnavailable},
+		{statusCode: http.StatusOK},
+	}}
+	collector := &recordingCollector{}
+	cli := WithMetricsAround(WithRetriesAround(stub, fastRetryConfig(2)), collector)
+
+	resp, err := cli.Do(context.TODO(), NewRequest(http.MethodDelete, "/items/1"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.StatusCode() != http.StatusOK || len(stub.calls) != 2 {
+		t.Errorf("Expected retried DELETE, got %d after %v", resp.StatusCode(), stub.calls)
+	}
+	if len(collector.observed) != 1 || collector.observed[0].Method != http.MethodDelete {
+		t.Errorf("Expected one DELETE observation, got %+v", collector.observed)
+	}
+}
+
+func TestRequest_RetryOverrides(t *testing.T) {
+	newFailingServer := func(calls *int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(calls, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+	}
+
+	t.Run("WithoutRetries on a retrying client", func(t *testing.T) {
+		var calls int32
+		server := newFailingServer(&calls)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithRetryConfig(fastRetryConfig(3)).Build()
+		resp, err := cli.Do(context.TODO(), NewRequest(http.MethodPost, server.URL).WithoutRetries())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		if atomic.LoadInt32(&calls) != 1 {
+			t.Errorf("Expected 1 attempt, got %d", calls)
+		}
+	})
+
+	t.Run("aggressive retries on a non-retrying client", func(t *testing.T) {
+		var calls int32
+		server := newFailingServer(&calls)
+		defer server.Close()
+
+		cli := NewClientBuilder().Build()
+		resp, err := cli.Do(context.TODO(), NewRequest(http.MethodGet, server.URL).WithRetryConfig(fastRetryConfig(5)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		if atomic.LoadInt32(&calls) != 6 {
+			t.Errorf("Expected 6 attempts, got %d", calls)
+		}
+		if resp.RetryAttempts() != 5 {
+			t.Errorf("Expected 5 retry attempts, got %d", resp.RetryAttempts())
+		}
+	})
+
+	t.Run("context override wins over request override", func(t *testing.T) {
+		var calls int32
+		server := newFailingServer(&calls)
+		defer server.Close()
+
+		cli := NewClientBuilder().Build()
+		req := NewRequest(http.MethodGet, server.URL).WithRetryConfig(fastRetryConfig(5))
+		resp, err := cli.Do(ContextWithNoRetry(context.TODO()), req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		if atomic.LoadInt32(&calls) != 1 {
+			t.Errorf("Expected 1 attempt, got %d", calls)
+		}
+	})
+}
+
+type tenantKey struct{}
+
+func TestRequest_WithValue(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var fromMiddleware, fromHook []any
+	cli := NewClientBuilder().
+		WithRetryConfig(fastRetryConfig(1)).
+		WithMiddleware(func(req *http.Request) error {
+			fromMiddleware = append(fromMiddleware, req.Context().Value(tenantKey{}))
+			return nil
+		}).
+		WithOnRetryContext(func(ctx context.Context, attempt int, resp *Response, err error, nextDelay time.Duration) {
+			fromHook = append(fromHook, ctx.Value(tenantKey{}))
+		}).
+		Build()
+	collector := &recordingCollector{}
+	metered := WithMetricsAround(cli, collector)
+
+	req := NewRequest(http.MethodGet, server.URL).WithValue(tenantKey{}, "acme")
+	if _, err := metered.Do(context.Background(), req); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	if len(fromMiddleware) != 2 || fromMiddleware[0] != "acme" || fromMiddleware[1] != "acme" {
+		t.Errorf("middleware saw %v, want acme on both attempts", fromMiddleware)
+	}
+	if len(fromHook) != 1 || fromHook[0] != "acme" {
+		t.Errorf("retry hook saw %v, want acme", fromHook)
+	}
+	if got := collector.observed[0].Value(tenantKey{}); got != "acme" {
+		t.Errorf("metrics Value() = %v, want acme", got)
+	}
+	if req.Header().Get("X-Tenant") != "" || len(req.Header()) != 0 {
+		t.Errorf("values leaked into headers: %v", req.Header())
+	}
+
+	clone := req.Clone().WithValue(tenantKey{}, "other")
+	if req.Value(tenantKey{}) != "acme" || clone.Value(tenantKey{}) != "other" {
+		t.Error("Clone() shares values with the original")
+	}
+}
+
+func TestClient_RequestOptions(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.Header().Set("X-Tenant", r.Header.Get("X-Tenant"))
+		w.Header().Set("X-Query", r.URL.RawQuery)
+		if r.URL.Path == "/flaky" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	cli := NewClientBuilder().WithBaseURL(server.URL).WithRetryConfig(fastRetryConfig(2)).Build()
+
+	t.Run("headers and query parameters", func(t *testing.T) {
+		resp, err := cli.Get(context.TODO(), "/search?page=2",
+			RequestHeader("X-Tenant", "acme"),
+			RequestQuery("q", "go & rust"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+		if resp.Header().Get("X-Tenant") != "acme" {
+			t.Errorf("Expected the header to be sent, got %q", resp.Header().Get("X-Tenant"))
+		}
+		if got := resp.Header().Get("X-Query"); got != "page=2&q=go+%26+rust" {
+			t.Errorf("Expected the query parameter to be added, got %q", got)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := cli.Post(context.TODO(), "/slow", nil, RequestTimeout(20*time.Millisecond), RequestWithoutRetries())
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("retry override", func(t *testing.T) {
+		calls.Store(0)
+		resp, err := cli.Get(context.TODO(), "/flaky", RequestRetryConfig(fastRetryConfig(4)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+		if calls.Load() != 5 {
+			t.Errorf("Expected 5 attempts, got %d", calls.Load())
+		}
+	})
+}
+
+func TestRequest_WithQuery(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"/items", "/items?k=a+b"},
+		{"/items?", "/items?k=a+b"},
+		{"/items?page=2", "/items?page=2&k=a+b"},
+		{"/items?page=2#top", "/items?page=2&k=a+b#top"},
+	}
+	for _, tt := range tests {
+		if got := NewRequest(http.MethodGet, tt.url).WithQuery("k", "a b").URL(); got != tt.want {
+			t.Errorf("WithQuery on %q = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestDoerFunc(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Caller", r.Header.Get("X-Caller"))
+	}))
+	defer server.Close()
+
+	cli := NewClientBuilder().WithBaseURL(server.URL).Build()
+	var doer Doer = DoerFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		return cli.Do(ctx, req.Clone().WithHeader("X-Caller", "reports"))
+	})
+
+	resp, err := doer.Do(context.TODO(), NewRequest("REPORT", "/calendars/1"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body().Close()
+	if resp.Header().Get("X-Method") != "REPORT" || resp.Header().Get("X-Caller") != "reports" {
+		t.Errorf("Expected a REPORT request through the wrapper, got %v", resp.Header())
+	}
+}
@@ -0,0 +1,68 @@
+// Package reqwestcompress provides reqwest.ContentDecoders for the
+// Content-Encodings the standard library can decode, and is the reference
+// implementation of that extension point for packages such as brotli or zstd
+// decoders:
+//
+//	client := reqwestcompress.Register(reqwest.NewClientBuilder()).Build()
+//
+// net/http only decodes gzip transparently, and stops doing so once
+// Accept-Encoding is set, which registering any decoder does. Register adds
+// gzip back alongside deflate.
+package reqwestcompress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+
+	"github.com/rbhujang/reqwest"
+)
+
+var (
+	_ reqwest.ContentDecoder = Gzip
+	_ reqwest.ContentDecoder = Deflate
+)
+
+// Register registers Gzip and Deflate on builder and returns it.
+func Register(builder *reqwest.ClientBuilder) *reqwest.ClientBuilder {
+	return builder.
+		WithContentDecoder("gzip", Gzip).
+		WithContentDecoder("deflate", Deflate)
+}
+
+// Gzip decodes a "gzip" body.
+func Gzip(body io.ReadCloser) (io.ReadCloser, error) {
+	reader, err := gzip.NewReader(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gzip header: %v", err)
+	}
+	return reader, nil
+}
+
+// Deflate decodes a "deflate" body: zlib-wrapped, as RFC 9110 specifies, or
+// raw, as some servers send it.
+func Deflate(body io.ReadCloser) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(body)
+	header, err := buffered.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read deflate header: %v", err)
+	}
+	if !isZlibHeader(header) {
+		return flate.NewReader(buffered), nil
+	}
+	reader, err := zlib.NewReader(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zlib header: %v", err)
+	}
+	return reader, nil
+}
+
+// isZlibHeader reports whether header starts a zlib stream: the deflate
+// method and a checksum making it a multiple of 31. A raw deflate stream
+// rarely starts that way.
+func isZlibHeader(header []byte) bool {
+	return len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+}
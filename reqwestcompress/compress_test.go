@@ -0,0 +1,70 @@
+package reqwestcompress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rbhujang/reqwest"
+)
+
+func TestRegister(t *testing.T) {
+	const payload = "hello, compressed world"
+	encoders := map[string]func(io.Writer) io.WriteCloser{
+		"gzip": func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"zlib": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"raw": func(w io.Writer) io.WriteCloser {
+			writer, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return writer
+		},
+	}
+	var accepted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accepted = r.Header.Get("Accept-Encoding")
+		format := r.URL.Query().Get("format")
+		encoding := "deflate"
+		if format == "gzip" {
+			encoding = "gzip"
+		}
+		var body bytes.Buffer
+		encoder := encoders[format](&body)
+		io.WriteString(encoder, payload)
+		encoder.Close()
+		w.Header().Set("Content-Encoding", encoding)
+		w.Write(body.Bytes())
+	}))
+	defer server.Close()
+
+	cli := Register(reqwest.NewClientBuilder().WithBaseURL(server.URL)).Build()
+	for format := range encoders {
+		t.Run(format, func(t *testing.T) {
+			resp, err := cli.Get(context.Background(), "/", reqwest.RequestQuery("format", format))
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			body, err := resp.Bytes()
+			if err != nil || string(body) != payload {
+				t.Errorf("Expected %q, got %q and %v", payload, body, err)
+			}
+			if accepted != "deflate, gzip" {
+				t.Errorf("Expected Accept-Encoding %q, got %q", "deflate, gzip", accepted)
+			}
+		})
+	}
+}
+
+func TestDeflate_Empty(t *testing.T) {
+	reader, err := Deflate(io.NopCloser(bytes.NewReader(nil)))
+	if err != nil {
+		t.Fatalf("Deflate() error = %v", err)
+	}
+	if _, err := io.ReadAll(reader); err == nil {
+		t.Error("Expected an error for an empty stream")
+	}
+}
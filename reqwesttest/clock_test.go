@@ -0,0 +1,85 @@
+package reqwesttest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rbhujang/reqwest"
+)
+
+func TestFakeClock(t *testing.T) {
+	t.Run("fires waiters when due", func(t *testing.T) {
+		start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		clock := NewFakeClock(start)
+		short, long := clock.After(time.Second), clock.After(time.Minute)
+
+		clock.Advance(time.Second)
+		select {
+		case at := <-short:
+			if !at.Equal(start.Add(time.Second)) {
+				t.Errorf("Expected to fire at %v, got %v", start.Add(time.Second), at)
+			}
+		default:
+			t.Fatal("Expected the due waiter to fire")
+		}
+		select {
+		case <-long:
+			t.Fatal("Expected the later waiter to keep waiting")
+		default:
+		}
+		if clock.Waiters() != 1 || !clock.Now().Equal(start.Add(time.Second)) {
+			t.Errorf("Expected 1 waiter at %v, got %d at %v", start.Add(time.Second), clock.Waiters(), clock.Now())
+		}
+	})
+
+	t.Run("retries without waiting", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		mock := NewMockClientFrom(reqwest.NewClientBuilder().
+			WithBaseURL(BaseURL).
+			WithClock(clock).
+			WithRetryConfig(reqwest.NewRetryConfigBuilder().
+				WithBackoffStrategy(reqwest.NewFixedBackoffBuilder().WithDelay(time.Hour).Build()).
+				Build()))
+		mock.On(http.MethodGet, "/jobs/1").Respond(http.StatusServiceUnavailable, "").Times(1)
+		mock.On(http.MethodGet, "/jobs/1").Respond(http.StatusOK, "done")
+
+		done := make(chan error, 1)
+		go func() {
+			resp, err := mock.Get(context.Background(), "/jobs/1")
+			if err == nil {
+				resp.Body().Close()
+			}
+			done <- err
+		}()
+		clock.BlockUntil(1)
+		clock.Advance(time.Hour)
+		if err := <-done; err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		mock.AssertCallCount(t, http.MethodGet, "/jobs/1", 2)
+	})
+
+	t.Run("circuit half-opens after the cooldown", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		mock := NewMockClientFrom(reqwest.NewClientBuilder().
+			WithBaseURL(BaseURL).
+			WithClock(clock).
+			WithCircuitBreaker(1, time.Minute))
+		mock.On(http.MethodGet, "/").Respond(http.StatusInternalServerError, "").Times(1)
+		mock.On(http.MethodGet, "/").Respond(http.StatusOK, "")
+
+		if _, err := mock.Get(context.Background(), "/"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := mock.Get(context.Background(), "/"); !errors.Is(err, reqwest.ErrCircuitOpen) {
+			t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+		}
+		clock.Advance(time.Minute)
+		if _, err := mock.Get(context.Background(), "/"); err != nil {
+			t.Errorf("Expected the probe to go through, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package reqwesttest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// LeakDetector fails a test that leaves response bodies open, the usual
+// cause of exhausted connection pools. Plug it in below the client:
+//
+//	detector := reqwesttest.NewLeakDetector(t)
+//	client := reqwest.NewClientBuilder().
+//		WithRoundTripperWrapper(detector.Wrap).
+//		Build()
+//
+// A body counts as closed once Close is called on it, directly or by the
+// client, e.g. through resp.Bytes(); reading it to the end is not enough.
+type LeakDetector struct {
+	mu   sync.Mutex
+	open map[*leakCheckedBody]struct{}
+}
+
+// NewLeakDetector returns a detector that reports every body still open when
+// t and its subtests finish, with the stack that sent its request.
+func NewLeakDetector(t testing.TB) *LeakDetector {
+	d := &LeakDetector{open: make(map[*leakCheckedBody]struct{})}
+	t.Cleanup(func() {
+		for _, leak := range d.Leaks() {
+			t.Errorf("response body was not closed: %s", leak)
+		}
+	})
+	return d
+}
+
+// Wrap is a reqwest.RoundTripperWrapper that tracks the bodies of responses
+// from next.
+func (d *LeakDetector) Wrap(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if err != nil || resp.Body == nil {
+			return resp, err
+		}
+		body := &leakCheckedBody{
+			ReadCloser: resp.Body,
+			detector:   d,
+			request:    req.Method + " " + req.URL.Redacted(),
+			stack:      string(debug.Stack()),
+		}
+		d.mu.Lock()
+		d.open[body] = struct{}{}
+		d.mu.Unlock()
+		resp.Body = body
+		return resp, nil
+	})
+}
+
+// Leaks describes the bodies that are open right now.
+func (d *LeakDetector) Leaks() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	leaks := make([]string, 0, len(d.open))
+	for body := range d.open {
+		leaks = append(leaks, fmt.Sprintf("%s, requested at\n%s", body.request, strings.TrimSpace(body.stack)))
+	}
+	return leaks
+}
+
+type leakCheckedBody struct {
+	io.ReadCloser
+	detector *LeakDetector
+	request  string
+	stack    string
+}
+
+func (b *leakCheckedBody) Close() error {
+	b.detector.mu.Lock()
+	delete(b.detector.open, b)
+	b.detector.mu.Unlock()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,64 @@
+package reqwesttest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rbhujang/reqwest"
+)
+
+// cleanupRecorder captures what a LeakDetector reports when the test ends.
+type cleanupRecorder struct {
+	testing.TB
+	cleanups []func()
+	errors   []string
+}
+
+func (r *cleanupRecorder) Cleanup(f func()) { r.cleanups = append(r.cleanups, f) }
+
+func (r *cleanupRecorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *cleanupRecorder) finish() {
+	for _, f := range r.cleanups {
+		f()
+	}
+}
+
+func TestLeakDetector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tb := &cleanupRecorder{TB: t}
+	detector := NewLeakDetector(tb)
+	client := reqwest.NewClientBuilder().
+		WithBaseURL(server.URL).
+		WithRoundTripperWrapper(detector.Wrap).
+		Build()
+
+	closed, err := client.Get(context.Background(), "/closed")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, err := closed.Bytes(); err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	if _, err := client.Get(context.Background(), "/leaked"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	tb.finish()
+	if len(tb.errors) != 1 {
+		t.Fatalf("Expected 1 leak, got %q", tb.errors)
+	}
+	if !strings.Contains(tb.errors[0], "GET "+server.URL+"/leaked") || !strings.Contains(tb.errors[0], "TestLeakDetector") {
+		t.Errorf("Expected the leaked request and where it was made, got %q", tb.errors[0])
+	}
+}
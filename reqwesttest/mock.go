@@ -0,0 +1,286 @@
+// Package reqwesttest provides test doubles for code that uses reqwest, so
+// unit tests can stub HTTP responses without starting servers.
+//
+//	mock := reqwesttest.NewMockClient()
+//	mock.On(http.MethodGet, "/users/1").RespondJSON(http.StatusOK, User{Name: "ada"})
+//	mock.On(http.MethodPost, "/users").Respond(http.StatusServiceUnavailable, "").Times(1)
+//	mock.On(http.MethodPost, "/users").Respond(http.StatusCreated, "")
+//
+//	svc := NewUserService(mock) // takes a reqwest.Client, Getter, Doer, ...
+//	...
+//	mock.AssertCallCount(t, http.MethodPost, "/users", 2)
+package reqwesttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rbhujang/reqwest"
+)
+
+// MockClient is a reqwest.Client whose requests are answered by its
+// MockTransport. Everything above the transport, such as retries, middleware
+// and decoding, runs as in production.
+type MockClient struct {
+	reqwest.Client
+	*MockTransport
+}
+
+// BaseURL is the base URL of clients returned by NewMockClient, so requests
+// can use relative URLs.
+const BaseURL = "http://reqwest.test"
+
+// NewMockClient returns a MockClient with the default client configuration
+// and BaseURL.
+func NewMockClient() *MockClient {
+	return NewMockClientFrom(reqwest.NewClientBuilder().WithBaseURL(BaseURL))
+}
+
+// NewMockClientFrom returns a MockClient configured like builder, e.g. to
+// exercise the retry policy used in production. builder is not modified.
+func NewMockClientFrom(builder *reqwest.ClientBuilder) *MockClient {
+	transport := NewMockTransport()
+	return &MockClient{
+		Client:        builder.Snapshot().WithTransport(transport).Build(),
+		MockTransport: transport,
+	}
+}
+
+// Call is a request received by a MockTransport.
+type Call struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// MockTransport is an http.RoundTripper that answers requests from
+// registered stubs and records every request. Stubs are consulted in the
+// order they were registered; requests no stub matches fail.
+type MockTransport struct {
+	mu    sync.Mutex
+	stubs []*Stub
+	calls []Call
+}
+
+// NewMockTransport returns a transport without stubs.
+func NewMockTransport() *MockTransport {
+	return &MockTransport{}
+}
+
+// On registers a stub for requests with method and url. An empty method or
+// "*" matches any method. A url starting with "/" is matched against the
+// request path (and query, if it has one), any other url against the full
+// URL; a trailing "*" matches any suffix.
+func (m *MockTransport) On(method, url string) *Stub {
+	return m.OnMatch(func(req *http.Request) bool {
+		return matchMethod(method, req.Method) && matchURL(url, req.URL)
+	})
+}
+
+// OnMatch registers a stub for requests match accepts.
+func (m *MockTransport) OnMatch(match func(*http.Request) bool) *Stub {
+	stub := &Stub{match: match, status: http.StatusOK, header: make(http.Header)}
+	m.mu.Lock()
+	m.stubs = append(m.stubs, stub)
+	m.mu.Unlock()
+	return stub
+}
+
+// RoundTrip answers req with the first matching stub that has responses
+// left.
+func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	call := Call{Method: req.Method, URL: req.URL.String(), Header: req.Header.Clone(), Body: body}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	stub := m.stubFor(req)
+	m.mu.Unlock()
+	if stub == nil {
+		return nil, fmt.Errorf("reqwesttest: no stub matches %s %s", req.Method, req.URL)
+	}
+	return stub.respond(req)
+}
+
+func (m *MockTransport) stubFor(req *http.Request) *Stub {
+	for _, stub := range m.stubs {
+		if (stub.times == 0 || stub.hits < stub.times) && stub.match(req) {
+			stub.hits++
+			return stub
+		}
+	}
+	return nil
+}
+
+// Calls returns the requests received so far, in order.
+func (m *MockTransport) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// CallCount returns how many requests matched method and url, which are
+// interpreted like in On.
+func (m *MockTransport) CallCount(method, rawURL string) int {
+	count := 0
+	for _, call := range m.Calls() {
+		u, err := url.Parse(call.URL)
+		if err == nil && matchMethod(method, call.Method) && matchURL(rawURL, u) {
+			count++
+		}
+	}
+	return count
+}
+
+// AssertCalled fails t unless a request matched method and url.
+func (m *MockTransport) AssertCalled(t testing.TB, method, url string) {
+	t.Helper()
+	if m.CallCount(method, url) == 0 {
+		t.Errorf("Expected a call to %s %s, got none", method, url)
+	}
+}
+
+// AssertNotCalled fails t if a request matched method and url.
+func (m *MockTransport) AssertNotCalled(t testing.TB, method, url string) {
+	t.Helper()
+	if got := m.CallCount(method, url); got != 0 {
+		t.Errorf("Expected no call to %s %s, got %d", method, url, got)
+	}
+}
+
+// AssertCallCount fails t unless exactly n requests matched method and url.
+func (m *MockTransport) AssertCallCount(t testing.TB, method, url string, n int) {
+	t.Helper()
+	if got := m.CallCount(method, url); got != n {
+		t.Errorf("Expected %d calls to %s %s, got %d", n, method, url, got)
+	}
+}
+
+// AssertStubsUsed fails t unless every stub answered a request, and stubs
+// limited with Times answered all of theirs.
+func (m *MockTransport) AssertStubsUsed(t testing.TB) {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, stub := range m.stubs {
+		if stub.hits == 0 || stub.hits < stub.times {
+			t.Errorf("Expected stub #%d to answer %d requests, got %d", i+1, max(stub.times, 1), stub.hits)
+		}
+	}
+}
+
+// Stub is a canned answer registered with MockTransport.On. Configure it
+// before the requests it answers are sent.
+type Stub struct {
+	match  func(*http.Request) bool
+	status int
+	header http.Header
+	body   []byte
+	err    error
+	delay  time.Duration
+	times  int
+	hits   int
+}
+
+// Respond answers with status and body.
+func (s *Stub) Respond(status int, body string) *Stub {
+	s.status = status
+	s.body = []byte(body)
+	return s
+}
+
+// RespondJSON answers with status and v encoded as JSON. It panics if v
+// cannot be encoded.
+func (s *Stub) RespondJSON(status int, v any) *Stub {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("reqwesttest: failed to marshal response: %v", err))
+	}
+	s.header.Set("Content-Type", "application/json")
+	s.status = status
+	s.body = body
+	return s
+}
+
+// WithHeader sets a response header.
+func (s *Stub) WithHeader(key, value string) *Stub {
+	s.header.Set(key, value)
+	return s
+}
+
+// WithDelay waits d before answering, or until the request is cancelled.
+func (s *Stub) WithDelay(d time.Duration) *Stub {
+	s.delay = d
+	return s
+}
+
+// WithError fails matching requests with err instead of responding, e.g. to
+// simulate a connection reset.
+func (s *Stub) WithError(err error) *Stub {
+	s.err = err
+	return s
+}
+
+// Times limits the stub to n requests; later ones fall through to stubs
+// registered after it, so sequences such as "503 once, then 200" can be
+// expressed.
+func (s *Stub) Times(n int) *Stub {
+	s.times = n
+	return s
+}
+
+func (s *Stub) respond(req *http.Request) (*http.Response, error) {
+	if s.delay > 0 {
+		timer := time.NewTimer(s.delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", s.status, http.StatusText(s.status)),
+		StatusCode:    s.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        s.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(s.body)),
+		ContentLength: int64(len(s.body)),
+		Request:       req,
+	}, nil
+}
+
+func matchMethod(pattern, method string) bool {
+	return pattern == "" || pattern == "*" || strings.EqualFold(pattern, method)
+}
+
+func matchURL(pattern string, u *url.URL) bool {
+	target := u.String()
+	if strings.HasPrefix(pattern, "/") {
+		target = u.Path
+		if strings.Contains(pattern, "?") && u.RawQuery != "" {
+			target += "?" + u.RawQuery
+		}
+	}
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return strings.HasPrefix(target, prefix)
+	}
+	return target == pattern
+}
@@ -0,0 +1,123 @@
+package reqwesttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/rbhujang/reqwest"
+)
+
+// recordingT captures assertion failures instead of failing the test.
+type recordingT struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestMockClient(t *testing.T) {
+	t.Run("canned responses", func(t *testing.T) {
+		mock := NewMockClient()
+		mock.On(http.MethodGet, "/users/1").RespondJSON(http.StatusOK, map[string]string{"name": "ada"})
+
+		resp, err := mock.Get(context.Background(), "/users/1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var user struct{ Name string }
+		if err := resp.JSON(&user); err != nil {
+			t.Fatalf("Unexpected decode error: %v", err)
+		}
+		if user.Name != "ada" || resp.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Expected the canned JSON, got %+v", user)
+		}
+		mock.AssertCalled(t, http.MethodGet, "/users/1")
+		mock.AssertStubsUsed(t)
+	})
+
+	t.Run("sequences drive the client's retries", func(t *testing.T) {
+		mock := NewMockClientFrom(reqwest.NewClientBuilder().
+			WithBaseURL(BaseURL).
+			WithRetryConfig(reqwest.NewRetryConfigBuilder().
+				WithBackoffStrategy(reqwest.NewFixedBackoffBuilder().WithDelay(time.Millisecond).Build()).
+				Build()))
+		mock.On(http.MethodPost, "/orders").Respond(http.StatusServiceUnavailable, "").Times(1)
+		mock.On(http.MethodPost, "/orders").WithError(syscall.ECONNRESET).Times(1)
+		mock.On(http.MethodPost, "/orders").Respond(http.StatusCreated, `{"id":1}`)
+
+		resp, err := mock.Post(context.Background(), "/orders", []byte(`{"item":"book"}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+		if resp.StatusCode() != http.StatusCreated {
+			t.Errorf("Expected 201 after retries, got %d", resp.StatusCode())
+		}
+		mock.AssertCallCount(t, http.MethodPost, "/orders", 3)
+		if calls := mock.Calls(); string(calls[2].Body) != `{"item":"book"}` {
+			t.Errorf("Expected the request body to be recorded, got %q", calls[2].Body)
+		}
+	})
+
+	t.Run("delays respect the context", func(t *testing.T) {
+		mock := NewMockClient()
+		mock.On("*", "/slow").WithDelay(time.Hour)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if _, err := mock.Get(ctx, "/slow"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected a deadline error, got %v", err)
+		}
+	})
+
+	t.Run("unmatched requests fail", func(t *testing.T) {
+		mock := NewMockClient()
+		mock.On(http.MethodGet, BaseURL+"/items*").Respond(http.StatusOK, "[]")
+
+		if _, err := mock.Get(context.Background(), "/items?page=2"); err != nil {
+			t.Errorf("Expected the prefix to match, got %v", err)
+		}
+		if _, err := mock.Get(context.Background(), "/other"); err == nil {
+			t.Error("Expected an error for an unmatched request")
+		}
+	})
+}
+
+func TestMockTransport_Assertions(t *testing.T) {
+	mock := NewMockClient()
+	mock.On(http.MethodGet, "/a").Respond(http.StatusOK, "")
+	mock.On(http.MethodGet, "/b").Respond(http.StatusOK, "").Times(2)
+	mock.On(http.MethodGet, "/c").Respond(http.StatusOK, "")
+	for _, path := range []string{"/a", "/a", "/b"} {
+		resp, err := mock.Get(context.Background(), path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+	}
+
+	rec := &recordingT{TB: t}
+	mock.AssertCalled(rec, http.MethodGet, "/a")
+	mock.AssertCallCount(rec, "", "/a", 2)
+	mock.AssertNotCalled(rec, http.MethodGet, "/c")
+	if len(rec.failures) != 0 {
+		t.Fatalf("Expected passing assertions, got %v", rec.failures)
+	}
+
+	mock.AssertCalled(rec, http.MethodGet, "/c")
+	mock.AssertNotCalled(rec, http.MethodGet, "/a")
+	mock.AssertCallCount(rec, http.MethodPost, "/a", 1)
+	mock.AssertStubsUsed(rec) // /b answered once of twice, /c never
+	if len(rec.failures) != 5 {
+		t.Errorf("Expected 5 failures, got %d: %v", len(rec.failures), rec.failures)
+	}
+}
@@ -0,0 +1,363 @@
+package reqwesttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/rbhujang/reqwest"
+)
+
+// Mode selects whether a Recorder talks to real servers.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette and fails requests it
+	// has no recording for, so tests run offline and deterministically.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests to the real servers and records them,
+	// replacing the cassette on Stop.
+	ModeRecord
+	// ModeReplayOrRecord replays an existing cassette, or records one if the
+	// file does not exist yet.
+	ModeReplayOrRecord
+)
+
+// Redacted replaces secrets in recorded interactions.
+const Redacted = "REDACTED"
+
+// DefaultRedactedHeaders are redacted from every recording.
+var DefaultRedactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// Interaction is a recorded request/response pair.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the recorded form of a request.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Route is the request's route (see reqwest.Request.Route), which keys
+	// baselines seeded with LearnCassette.
+	Route  string       `json:"route,omitempty"`
+	Header http.Header  `json:"header,omitempty"`
+	Body   RecordedBody `json:"body,omitempty"`
+}
+
+// RecordedResponse is the recorded form of a response.
+type RecordedResponse struct {
+	StatusCode int          `json:"status_code"`
+	Header     http.Header  `json:"header,omitempty"`
+	Body       RecordedBody `json:"body,omitempty"`
+}
+
+// RecordedBody is stored as text when it is valid UTF-8, so cassettes can be
+// reviewed, and base64-encoded otherwise.
+type RecordedBody []byte
+
+func (b RecordedBody) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *RecordedBody) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*b = RecordedBody(text)
+		return nil
+	}
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	if err != nil {
+		return fmt.Errorf("failed to decode recorded body: %v", err)
+	}
+	*b = decoded
+	return nil
+}
+
+// Recorder records real HTTP interactions to a cassette file and replays
+// them in later test runs. Plug it into a client with
+// WithRoundTripperWrapper(recorder.Wrap) and call Stop when done:
+//
+//	recorder, err := reqwesttest.NewRecorder("testdata/github.json", reqwesttest.ModeReplayOrRecord)
+//	...
+//	defer recorder.Stop()
+//	client := reqwest.NewClientBuilder().WithRoundTripperWrapper(recorder.Wrap).Build()
+//
+// Headers in DefaultRedactedHeaders, and the headers and query parameters
+// the client marks as secrets (such as WithAPIKey keys), are replaced with
+// Redacted before anything is written; requests are matched on method, URL and body after
+// redaction, each recording being served once, in order.
+type Recorder struct {
+	path        string
+	recording   bool
+	headers     []string
+	queryParams []string
+	redact      func(*Interaction)
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewRecorder returns a recorder for the cassette at path. Replaying fails
+// if the cassette cannot be read.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{path: path, headers: DefaultRedactedHeaders}
+	if mode == ModeRecord {
+		r.recording = true
+		return r, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && mode == ModeReplayOrRecord {
+		r.recording = true
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %v", err)
+	}
+	if err := json.Unmarshal(data, &r.interactions); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %v", path, err)
+	}
+	r.used = make([]bool, len(r.interactions))
+	return r, nil
+}
+
+// Recording reports whether the recorder sends requests to real servers.
+func (r *Recorder) Recording() bool {
+	return r.recording
+}
+
+// WithRedactedHeaders redacts names in addition to DefaultRedactedHeaders.
+func (r *Recorder) WithRedactedHeaders(names ...string) *Recorder {
+	r.headers = append(append([]string(nil), r.headers...), names...)
+	return r
+}
+
+// WithRedactedQueryParams redacts the values of URL query parameters, e.g.
+// API keys passed in the query string.
+func (r *Recorder) WithRedactedQueryParams(names ...string) *Recorder {
+	r.queryParams = append(r.queryParams, names...)
+	return r
+}
+
+// WithRedactor runs redact on every interaction after the built-in
+// redaction, e.g. to scrub tokens from bodies. It must be deterministic so
+// replayed requests still match their recordings.
+func (r *Recorder) WithRedactor(redact func(*Interaction)) *Recorder {
+	r.redact = redact
+	return r
+}
+
+// Wrap returns the round tripper that records or replays requests; next is
+// only used while recording.
+func (r *Recorder) Wrap(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if r.recording {
+			return r.record(next, req)
+		}
+		return r.replay(req)
+	})
+}
+
+// Stop writes the cassette when recording. Replaying makes it a no-op.
+func (r *Recorder) Stop() error {
+	if !r.recording {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %v", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %v", err)
+	}
+	return nil
+}
+
+func (r *Recorder) record(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := r.redacted(req, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Route:  reqwest.RouteFromContext(req.Context()),
+			Header: req.Header.Clone(),
+			Body:   body,
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       respBody,
+		},
+	})
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	want := r.redacted(req, Interaction{
+		Request: RecordedRequest{Method: req.Method, URL: req.URL.String(), Header: req.Header.Clone(), Body: body},
+	}).Request
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		got := interaction.Request
+		if r.used[i] || got.Method != want.Method || got.URL != want.URL || !bytes.Equal(got.Body, want.Body) {
+			continue
+		}
+		r.used[i] = true
+		recorded := interaction.Response
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recorded.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(recorded.Body)),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("reqwesttest: no recording of %s %s left in %s", want.Method, want.URL, r.path)
+}
+
+// redacted applies the recorder's redaction, and that of the secrets marked
+// on req, to interaction.
+func (r *Recorder) redacted(req *http.Request, interaction Interaction) Interaction {
+	secrets := reqwest.SecretsFromContext(req.Context())
+	for _, name := range slices.Concat(r.headers, secrets.Headers) {
+		redactHeader(interaction.Request.Header, name)
+		redactHeader(interaction.Response.Header, name)
+	}
+	if params := slices.Concat(r.queryParams, secrets.QueryParams); len(params) > 0 {
+		interaction.Request.URL = redactQuery(interaction.Request.URL, params)
+	}
+	if r.redact != nil {
+		r.redact(&interaction)
+	}
+	return interaction
+}
+
+func redactHeader(header http.Header, name string) {
+	if values := header.Values(name); len(values) > 0 {
+		header[http.CanonicalHeaderKey(name)] = []string{Redacted}
+	}
+}
+
+func redactQuery(rawURL string, params []string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := u.Query()
+	for _, param := range params {
+		if query.Has(param) {
+			query.Set(param, Redacted)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %v", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// NewDriftMonitor returns a DriftMonitor, calling onDrift, with baselines
+// seeded from the cassettes at paths (see LearnCassette), so live traffic is
+// compared against the recorded contract rather than its own first response.
+func NewDriftMonitor(onDrift func(reqwest.DriftEvent), paths ...string) (*reqwest.DriftMonitor, error) {
+	monitor := reqwest.NewDriftMonitor(onDrift)
+	for _, path := range paths {
+		if err := LearnCassette(monitor, path); err != nil {
+			return nil, err
+		}
+	}
+	return monitor, nil
+}
+
+// LearnCassette seeds monitor with the JSON bodies of the successful
+// responses recorded in the cassette at path, keyed like live responses. The
+// first recording of an endpoint wins; bodies that are not JSON are skipped.
+func LearnCassette(monitor *reqwest.DriftMonitor, path string) error {
+	recorder, err := NewRecorder(path, ModeReplay)
+	if err != nil {
+		return err
+	}
+	// Learn replaces baselines, so go backwards for the first one to stay
+	for _, interaction := range slices.Backward(recorder.interactions) {
+		status := interaction.Response.StatusCode
+		if status < 200 || status >= 300 {
+			continue
+		}
+		ctx := context.Background()
+		if route := interaction.Request.Route; route != "" {
+			ctx = reqwest.ContextWithRoute(ctx, route)
+		}
+		req, err := http.NewRequestWithContext(ctx, interaction.Request.Method, interaction.Request.URL, http.NoBody)
+		if err != nil {
+			return fmt.Errorf("failed to parse cassette %s: %v", path, err)
+		}
+		_ = monitor.LearnResponse(req, interaction.Response.Body)
+	}
+	return nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,198 @@
+package reqwesttest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rbhujang/reqwest"
+)
+
+func TestRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=s3cr3t")
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":2}`))
+			return
+		}
+		w.Write([]byte(`{"id":1,"token":"tok-123"}`))
+	}))
+	serverURL := server.URL
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	newClient := func(recorder *Recorder) reqwest.Client {
+		return reqwest.NewClientBuilder().
+			WithBaseURL(serverURL).
+			WithRoundTripperWrapper(recorder.Wrap).
+			WithMiddleware(func(req *http.Request) error {
+				req.Header.Set("Authorization", "Bearer top-secret")
+				return nil
+			}).
+			Build()
+	}
+	redactToken := func(i *Interaction) {
+		i.Response.Body = RecordedBody(strings.ReplaceAll(string(i.Response.Body), "tok-123", Redacted))
+	}
+
+	t.Run("records with redaction", func(t *testing.T) {
+		recorder, err := NewRecorder(path, ModeReplayOrRecord)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		recorder.WithRedactedQueryParams("api_key").WithRedactor(redactToken)
+		if !recorder.Recording() {
+			t.Fatal("Expected to record without a cassette")
+		}
+		cli := newClient(recorder)
+		if _, err := cli.Get(context.Background(), "/items/1?api_key=k3y"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := cli.Post(context.Background(), "/items", []byte(`{"name":"book"}`)); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := recorder.Stop(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, secret := range []string{"top-secret", "s3cr3t", "k3y", "tok-123"} {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("Expected %q to be redacted from the cassette:\n%s", secret, data)
+			}
+		}
+	})
+
+	server.Close()
+
+	t.Run("replays offline", func(t *testing.T) {
+		recorder, err := NewRecorder(path, ModeReplayOrRecord)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		recorder.WithRedactedQueryParams("api_key").WithRedactor(redactToken)
+		if recorder.Recording() {
+			t.Fatal("Expected to replay the existing cassette")
+		}
+		cli := newClient(recorder)
+
+		resp, err := cli.Post(context.Background(), "/items", []byte(`{"name":"book"}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if body, _ := resp.Bytes(); resp.StatusCode() != http.StatusCreated || string(body) != `{"id":2}` {
+			t.Errorf("Expected the recorded 201, got %d %s", resp.StatusCode(), body)
+		}
+		// Another key still matches the redacted recording
+		resp, err = cli.Get(context.Background(), "/items/1?api_key=other")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if body, _ := resp.Bytes(); string(body) != `{"id":1,"token":"REDACTED"}` {
+			t.Errorf("Expected the recorded body, got %s", body)
+		}
+
+		if _, err := cli.Get(context.Background(), "/items/1?api_key=k3y"); err == nil {
+			t.Error("Expected an error once the recording was used up")
+		}
+		if _, err := cli.Post(context.Background(), "/items", []byte(`{"name":"pen"}`)); err == nil {
+			t.Error("Expected an error for a different body")
+		}
+	})
+
+	t.Run("replay needs a cassette", func(t *testing.T) {
+		if _, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), ModeReplay); err == nil {
+			t.Error("Expected an error for a missing cassette")
+		}
+	})
+}
+
+func TestRecorder_RedactsClientSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	for _, mode := range []Mode{ModeRecord, ModeReplay} {
+		recorder, err := NewRecorder(path, mode)
+		if err != nil {
+			t.Fatalf("NewRecorder() error = %v", err)
+		}
+		client := reqwest.NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithAPIKey("api_key", "s3cr3t", reqwest.InQuery).
+			WithRoundTripperWrapper(recorder.Wrap).
+			Build()
+		if _, err := client.Get(context.Background(), "/items"); err != nil {
+			t.Fatalf("Get() in mode %d error = %v", mode, err)
+		}
+		if err := recorder.Stop(); err != nil {
+			t.Fatalf("Stop() error = %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if strings.Contains(string(data), "s3cr3t") || !strings.Contains(string(data), "api_key="+Redacted) {
+		t.Errorf("cassette = %s, want the API key redacted", data)
+	}
+}
+
+func TestNewDriftMonitor(t *testing.T) {
+	var version atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if version.Load() == 0 {
+			w.Write([]byte(`{"id":1,"name":"a"}`))
+			return
+		}
+		w.Write([]byte(`{"id":2,"email":"b"}`))
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder, err := NewRecorder(path, ModeRecord)
+	if err != nil {
+		t.Fatalf("NewRecorder() error = %v", err)
+	}
+	recording := reqwest.NewClientBuilder().WithBaseURL(server.URL).WithRoundTripperWrapper(recorder.Wrap).Build()
+	req := reqwest.NewTemplateRequest(http.MethodGet, "/users/{name}", map[string]string{"name": "alice"})
+	if _, err := recording.Do(context.Background(), req); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if err := recorder.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	var events []reqwest.DriftEvent
+	monitor, err := NewDriftMonitor(func(e reqwest.DriftEvent) { events = append(events, e) }, path)
+	if err != nil {
+		t.Fatalf("NewDriftMonitor() error = %v", err)
+	}
+	version.Store(1)
+	live := reqwest.NewClientBuilder().WithBaseURL(server.URL).WithDriftMonitor(monitor).Build()
+	resp, err := live.Do(context.Background(), reqwest.NewTemplateRequest(http.MethodGet, "/users/{name}", map[string]string{"name": "bob"}))
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	var out map[string]any
+	if err := resp.JSON(&out); err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+
+	if len(events) != 1 || events[0].Key != "GET /users/{name}" ||
+		strings.Join(events[0].Added, ",") != "email" || strings.Join(events[0].Removed, ",") != "name" {
+		t.Errorf("events = %+v, want email added and name removed against the cassette", events)
+	}
+	if _, err := NewDriftMonitor(nil, filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected an error for a missing cassette")
+	}
+}
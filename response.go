@@ -1,6 +1,8 @@
 package reqwest
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"net/http"
 	"time"
@@ -8,14 +10,52 @@ import (
 
 type Response struct {
 	statusCode    int
+	proto         string
+	header        http.Header
+	request       *http.Request
 	body          io.ReadCloser
 	retryAttempts int
 	totalDuration time.Duration
+	decode        decodeConfig
+	fromCache     bool
+	fromFallback  bool
+	coalesced     bool
+	memoized      bool
+	requestID     string
+	traceID       string
+	attemptTraces []AttemptTrace
+	apiVersion    string
+	audit         *AuditRecord
+	bodyRetry     *bodyRetry
+	jsonDoc       *any
+	route         string
+	expect        Codec
+	decodedErr    error
+	buffer        []byte
+	buffered      bool
+	decoded       bool // Content-Encoding was decoded
+}
+
+// NewResponse returns a response that was not received from a server, e.g.
+// a default value served by a WithFallback function.
+func NewResponse(statusCode int, header http.Header, body []byte) *Response {
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &Response{
+		statusCode: statusCode,
+		proto:      "HTTP/1.1",
+		header:     header,
+		body:       io.NopCloser(bytes.NewReader(body)),
+	}
 }
 
 func fromHTTPResponse(resp *http.Response) *Response {
 	return &Response{
 		statusCode: resp.StatusCode,
+		proto:      resp.Proto,
+		header:     resp.Header,
+		request:    resp.Request,
 		body:       resp.Body,
 	}
 }
@@ -24,7 +64,21 @@ func (r *Response) StatusCode() int {
 	return r.statusCode
 }
 
+// Proto reports the protocol the response was received over, e.g. "HTTP/2.0".
+func (r *Response) Proto() string {
+	return r.proto
+}
+
+func (r *Response) Header() http.Header {
+	return r.header
+}
+
+// Body returns the response body. Once the response is buffered, every call
+// returns a fresh reader over the whole body.
 func (r *Response) Body() io.ReadCloser {
+	if r.buffered {
+		return io.NopCloser(bytes.NewReader(r.buffer))
+	}
 	return r.body
 }
 
@@ -35,3 +89,83 @@ func (r *Response) RetryAttempts() int {
 func (r *Response) TotalDuration() time.Duration {
 	return r.totalDuration
 }
+
+// RequestID returns the ID stamped on the request by WithRequestID, which is
+// the same for every retry attempt of the logical request.
+func (r *Response) RequestID() string {
+	return r.requestID
+}
+
+// TraceID returns the W3C trace ID sent with the request when the client was
+// built with WithTraceContext, so logs can reference the server's trace.
+func (r *Response) TraceID() string {
+	return r.traceID
+}
+
+// Trace returns DNS, connect, TLS, time-to-first-byte and total timings of
+// every attempt of the logical request.
+func (r *Response) Trace() Trace {
+	return Trace{Route: r.route, Attempts: r.attemptTraces, Total: r.totalDuration}
+}
+
+// APIVersion returns the vendor media-type version the server answered with
+// when the client negotiates versions with WithMediaTypeVersions, e.g. "v2".
+func (r *Response) APIVersion() string {
+	return r.apiVersion
+}
+
+// Audit returns the audit record of the logical request that produced the
+// response, or nil unless the client was built with WithAuditLog.
+func (r *Response) Audit() *AuditRecord {
+	return r.audit
+}
+
+// FromCache reports whether the response was served from a client-side cache
+// without contacting the server.
+func (r *Response) FromCache() bool {
+	return r.fromCache
+}
+
+// Coalesced reports whether the response was shared with an identical
+// concurrent request instead of fetched for this call.
+func (r *Response) Coalesced() bool {
+	return r.coalesced
+}
+
+// Memoized reports whether the response was served from a WithMemo context
+// instead of fetched for this call.
+func (r *Response) Memoized() bool {
+	return r.memoized
+}
+
+// FromFallback reports whether the response was served by the client's
+// WithFallback function because the request failed.
+func (r *Response) FromFallback() bool {
+	return r.fromFallback
+}
+
+// Bytes reads and closes the body. A buffered body can be read again.
+func (r *Response) Bytes() ([]byte, error) {
+	data, err := r.readBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return data, nil
+}
+
+// JSON reads and closes the body, decoding it into v. Options override the
+// decoding defaults configured on the client for this call only.
+func (r *Response) JSON(v any, opts ...DecodeOption) error {
+	if err := r.checkContentType(); err != nil {
+		return err
+	}
+	data, err := r.readBody()
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	cfg := r.decode.with(opts)
+	if cfg.drift != nil && r.request != nil {
+		cfg.drift.observeResponse(r.request, data)
+	}
+	return decodeJSON(data, v, cfg)
+}
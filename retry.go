@@ -1,8 +1,13 @@
 package reqwest
 
 import (
+	"context"
+	"maps"
 	"math"
 	"math/rand"
+	"net/http"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -29,7 +34,12 @@ const (
 // Default retryable HTTP status codes
 var DefaultRetryableStatusCodes = []int{429, 500, 502, 503, 504}
 
-// Default retryable error strings
+// DefaultRetryableErrors are common substrings of transient network error
+// messages.
+//
+// Deprecated: DefaultRetryableErrorMatchers match these errors without
+// relying on their wording. Error strings are only consulted when set with
+// WithRetryableErrors.
 var DefaultRetryableErrors = map[string]bool{
 	"connection refused": true,
 	"timeout":            true,
@@ -41,6 +51,7 @@ type RetryConfig struct {
 	maxRetries           int
 	retryableStatusCodes []int
 	retryableError       map[string]bool
+	errorMatchers        []func(error) bool
 	backoffStrategy      BackoffStrategy
 }
 
@@ -72,11 +83,24 @@ func (r *retryConfigBuilder) WithRetryableStatusCodes(codes []int) *retryConfigB
 	return r
 }
 
+// WithRetryableErrors also retries errors whose message contains one of the
+// keys. It is ignored once WithRetryableErrorMatchers is used.
+//
+// Deprecated: use WithRetryableErrorMatchers, which also sees wrapped errors
+// and does not depend on the wording of messages.
 func (r *retryConfigBuilder) WithRetryableErrors(errors map[string]bool) *retryConfigBuilder {
 	r.config.retryableError = errors
 	return r
 }
 
+// WithRetryableErrorMatchers retries only the errors any of matchers accepts,
+// replacing DefaultRetryableErrorMatchers and any WithRetryableErrors strings.
+// Matchers should use errors.Is or errors.As so they see through wrapping.
+func (r *retryConfigBuilder) WithRetryableErrorMatchers(matchers ...func(error) bool) *retryConfigBuilder {
+	r.config.errorMatchers = matchers
+	return r
+}
+
 func (r *retryConfigBuilder) Build() *RetryConfig {
 	// Default to 3 retries
 	if r.config.maxRetries <= 0 {
@@ -90,22 +114,164 @@ func (r *retryConfigBuilder) Build() *RetryConfig {
 	if len(r.config.retryableStatusCodes) == 0 {
 		r.config.retryableStatusCodes = DefaultRetryableStatusCodes
 	}
-	// Default to probably network error strings
-	if len(r.config.retryableError) == 0 {
-		r.config.retryableError = DefaultRetryableErrors
+	// Matchers replace the error strings; without either, default to
+	// matching common transient network errors
+	if len(r.config.errorMatchers) > 0 {
+		r.config.retryableError = nil
+	} else {
+		r.config.errorMatchers = DefaultRetryableErrorMatchers
 	}
 	return r.config
 }
 
+// clone returns a deep copy of r, so a client is unaffected by later changes
+// made through the retryConfigBuilder that produced r.
+func (r *RetryConfig) clone() *RetryConfig {
+	if r == nil {
+		return nil
+	}
+	c := *r
+	c.retryableStatusCodes = slices.Clone(r.retryableStatusCodes)
+	c.retryableError = maps.Clone(r.retryableError)
+	c.errorMatchers = slices.Clone(r.errorMatchers)
+	return &c
+}
+
+// shouldRetryResponse reports whether resp should be retried. A nil config
+// never retries.
+func (r *RetryConfig) shouldRetryResponse(resp *Response) bool {
+	if r == nil || resp == nil {
+		return false
+	}
+	return r.retryableStatus(resp.StatusCode())
+}
+
+// shouldRetryError reports whether err should be retried. A nil config never
+// retries.
+func (r *RetryConfig) shouldRetryError(err error) bool {
+	if r == nil || err == nil {
+		return false
+	}
+	return r.retryableErr(err)
+}
+
+// shouldRetryAttempt is shouldRetryError for an attempt of a request with
+// method and header. A connection closed before the response may still have
+// delivered the request, so such errors are only retried for idempotent
+// methods or requests carrying an idempotency key.
+func (r *RetryConfig) shouldRetryAttempt(method string, header http.Header, err error) bool {
+	if !r.shouldRetryError(err) {
+		return false
+	}
+	return !IsUnexpectedEOF(err) || idempotentMethod(method) || header.Get(IdempotencyKeyHeader) != ""
+}
+
+// wait sleeps for the backoff delay before a retry attempt. The first attempt
+// and a nil config never wait.
+func (r *RetryConfig) wait(ctx context.Context, clock Clock, attempt int) error {
+	return sleep(ctx, clock, r.delay(attempt))
+}
+
+// delay returns the backoff before retry number attempt; the first attempt
+// is not delayed.
+func (r *RetryConfig) delay(attempt int) time.Duration {
+	if attempt == 0 || r == nil {
+		return 0
+	}
+	return r.backoffStrategy.Delay(attempt)
+}
+
+// delayAfter returns the backoff before retry number attempt, letting a
+// ContextualBackoff see the previous attempt's outcome.
+func (r *RetryConfig) delayAfter(attempt int, resp *Response, err error) time.Duration {
+	if attempt == 0 || r == nil {
+		return 0
+	}
+	if contextual, ok := r.backoffStrategy.(ContextualBackoff); ok {
+		return contextual.DelayAfter(attempt, resp, err)
+	}
+	return r.backoffStrategy.Delay(attempt)
+}
+
+// sleep waits for d on clock unless ctx is done first.
+func sleep(ctx context.Context, clock Clock, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-orSystemClock(clock).After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryableStatus reports whether a response with statusCode should be retried.
+func (r *RetryConfig) retryableStatus(statusCode int) bool {
+	for _, code := range r.retryableStatusCodes {
+		if statusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// retryableErr reports whether err is accepted by one of the error matchers
+// or contains one of the error strings set with WithRetryableErrors.
+func (r *RetryConfig) retryableErr(err error) bool {
+	for _, matches := range r.errorMatchers {
+		if matches(err) {
+			return true
+		}
+	}
+	errStr := strings.ToLower(err.Error())
+	for retryableErr := range r.retryableError {
+		if strings.Contains(errStr, retryableErr) {
+			return true
+		}
+	}
+	return false
+}
+
 type BackoffStrategy interface {
 	Delay(count int) time.Duration
 }
 
+// ContextualBackoff is a BackoffStrategy that also sees what the failed
+// attempt returned, e.g. to honor Retry-After or back off longer on specific
+// errors. resp is nil when the attempt failed without a response. The retry
+// loop calls DelayAfter instead of Delay when a strategy implements it.
+type ContextualBackoff interface {
+	BackoffStrategy
+	DelayAfter(count int, resp *Response, err error) time.Duration
+}
+
+// JitterStrategy selects how ExponentialBackoff randomizes delays.
+type JitterStrategy int
+
+const (
+	// JitterProportional varies each delay by ±25%. Retries of clients that
+	// failed together stay roughly aligned.
+	JitterProportional JitterStrategy = iota
+	// JitterFull picks each delay uniformly between zero and the exponential
+	// delay ("full jitter"), spreading retries of many clients the most.
+	JitterFull
+	// JitterDecorrelated picks each delay between the base delay and three
+	// times the previous one, capped at the max delay ("decorrelated
+	// jitter"), which grows like exponential backoff but without lockstep.
+	JitterDecorrelated
+)
+
+// decorrelatedJitterGrowth bounds how much a decorrelated jitter delay can
+// grow over the previous one.
+const decorrelatedJitterGrowth = 3
+
 type ExponentialBackoff struct {
-	baseDelay  time.Duration
-	multiplier float64
-	maxDelay   time.Duration
-	jitter     bool
+	baseDelay      time.Duration
+	multiplier     float64
+	maxDelay       time.Duration
+	jitter         bool
+	jitterStrategy JitterStrategy
 }
 
 type exponentialBackoffBuilder struct {
@@ -141,6 +307,14 @@ func (b *exponentialBackoffBuilder) WithJitter(jitter bool) *exponentialBackoffB
 	return b
 }
 
+// WithJitterStrategy enables jitter using strategy instead of the default
+// ±25%.
+func (b *exponentialBackoffBuilder) WithJitterStrategy(strategy JitterStrategy) *exponentialBackoffBuilder {
+	b.backoff.jitter = true
+	b.backoff.jitterStrategy = strategy
+	return b
+}
+
 func (b *exponentialBackoffBuilder) Build() BackoffStrategy {
 	// Default to 100 milliseconds
 	if b.backoff.baseDelay <= 0 {
@@ -158,13 +332,20 @@ func (b *exponentialBackoffBuilder) Build() BackoffStrategy {
 }
 
 func (e *ExponentialBackoff) Delay(count int) time.Duration {
-	multiplier := math.Pow(e.multiplier, float64(count))
-	delay := time.Duration(float64(e.baseDelay) * multiplier)
-	if delay > e.maxDelay {
-		delay = e.maxDelay
+	if e.jitter && e.jitterStrategy == JitterDecorrelated {
+		return e.decorrelatedDelay(count)
+	}
+
+	// Capped before converting, as high counts overflow time.Duration
+	delay := e.maxDelay
+	if scaled := float64(e.baseDelay) * math.Pow(e.multiplier, float64(count)); scaled < float64(e.maxDelay) {
+		delay = time.Duration(scaled)
 	}
 
 	// Check if we should add a jitter
+	if e.jitter && e.jitterStrategy == JitterFull {
+		return randomDelay(0, delay)
+	}
 	if e.jitter {
 		delay = time.Duration(float64(delay) + jitter25(delay))
 		if delay < 0 {
@@ -174,6 +355,26 @@ func (e *ExponentialBackoff) Delay(count int) time.Duration {
 	return delay
 }
 
+// decorrelatedDelay replays the decorrelated jitter recurrence for count
+// retries. Strategies are shared by concurrent requests, so the previous
+// delay is not kept between calls.
+func (e *ExponentialBackoff) decorrelatedDelay(count int) time.Duration {
+	delay := e.baseDelay
+	for i := 0; i < count; i++ {
+		delay = randomDelay(e.baseDelay, min(e.maxDelay, delay*decorrelatedJitterGrowth))
+	}
+	return min(delay, e.maxDelay)
+}
+
+// randomDelay returns a uniformly random delay in [lo, hi].
+func randomDelay(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	// #nosec G404 - Using math/rand for jitter is acceptable, crypto/rand not needed
+	return lo + time.Duration(rand.Int63n(int64(hi-lo)+1))
+}
+
 type FixedBackoff struct {
 	delay  time.Duration
 	jitter bool
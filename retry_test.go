@@ -1,6 +1,10 @@
 package reqwest
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -22,9 +26,12 @@ func TestRetryConfigBuilder(t *testing.T) {
 				len(config.retryableStatusCodes))
 		}
 
-		if len(config.retryableError) != len(DefaultRetryableErrors) {
-			t.Errorf("Expected %d retryable errors, got %d", len(DefaultRetryableErrors),
-				len(config.retryableError))
+		if len(config.retryableError) != 0 {
+			t.Errorf("Expected no retryable error strings, got %d", len(config.retryableError))
+		}
+		if len(config.errorMatchers) != len(DefaultRetryableErrorMatchers) {
+			t.Errorf("Expected %d error matchers, got %d", len(DefaultRetryableErrorMatchers),
+				len(config.errorMatchers))
 		}
 	})
 
@@ -88,9 +95,9 @@ func TestRetryConfigBuilder(t *testing.T) {
 				len(config.retryableStatusCodes))
 		}
 
-		if len(config.retryableError) != len(DefaultRetryableErrors) {
-			t.Errorf("Expected empty errors to use defaults, got %d errors",
-				len(config.retryableError))
+		if len(config.retryableError) != 0 || len(config.errorMatchers) != len(DefaultRetryableErrorMatchers) {
+			t.Errorf("Expected empty errors to use the default matchers, got %d errors and %d matchers",
+				len(config.retryableError), len(config.errorMatchers))
 		}
 	})
 }
@@ -157,6 +164,7 @@ func TestExponentialBackoff_Delay(t *testing.T) {
 			{"third attempt", 3, 800 * time.Millisecond},  // 100ms * 2^3
 			{"max delay reached", 4, 1 * time.Second},     // Should cap at maxDelay
 			{"beyond max", 10, 1 * time.Second},           // Should stay at maxDelay
+			{"would overflow", 100, 1 * time.Second},      // 2^100 overflows time.Duration
 		}
 
 		for _, tt := range tests {
@@ -210,6 +218,59 @@ func TestExponentialBackoff_Delay(t *testing.T) {
 	})
 }
 
+func TestExponentialBackoff_JitterStrategies(t *testing.T) {
+	build := func(strategy JitterStrategy) BackoffStrategy {
+		return NewExponentialBackoffBuilder().
+			WithBaseDelay(100 * time.Millisecond).
+			WithMaxDelay(time.Second).
+			WithJitterStrategy(strategy).
+			Build()
+	}
+
+	t.Run("full jitter spans zero to the exponential delay", func(t *testing.T) {
+		backoff := build(JitterFull)
+		below := 400 * time.Millisecond
+		for i := 0; i < 200; i++ {
+			delay := backoff.Delay(2)
+			if delay < 0 || delay > 400*time.Millisecond {
+				t.Fatalf("Delay(2) = %v, expected between 0 and 400ms", delay)
+			}
+			below = min(below, delay)
+		}
+		// A ±25% jitter never goes below 300ms
+		if below >= 300*time.Millisecond {
+			t.Errorf("Expected full jitter to reach well below the exponential delay, minimum was %v", below)
+		}
+		if got := backoff.Delay(10); got > time.Second {
+			t.Errorf("Expected the max delay to cap full jitter, got %v", got)
+		}
+	})
+
+	t.Run("decorrelated jitter stays between base and cap", func(t *testing.T) {
+		backoff := build(JitterDecorrelated)
+		for count := 1; count <= 10; count++ {
+			for i := 0; i < 50; i++ {
+				delay := backoff.Delay(count)
+				// The first retry waits at most three times the base delay
+				upper := time.Second
+				if count == 1 {
+					upper = 300 * time.Millisecond
+				}
+				if delay < 100*time.Millisecond || delay > upper {
+					t.Fatalf("Delay(%d) = %v, expected between 100ms and %v", count, delay, upper)
+				}
+			}
+		}
+	})
+
+	t.Run("proportional jitter is the default", func(t *testing.T) {
+		backoff := NewExponentialBackoffBuilder().WithJitter(true).Build().(*ExponentialBackoff)
+		if backoff.jitterStrategy != JitterProportional {
+			t.Errorf("Expected JitterProportional, got %v", backoff.jitterStrategy)
+		}
+	})
+}
+
 func TestFixedBackoff_Delay(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -429,3 +490,71 @@ func TestJitter25(t *testing.T) {
 		t.Errorf("Expected jitter25(0) = 0, got %v", zeroResult)
 	}
 }
+
+func TestClientBuilder_WithOnRetry(t *testing.T) {
+	type retryEvent struct {
+		attempt int
+		status  int
+		err     error
+		delay   time.Duration
+	}
+	fixed := NewRetryConfigBuilder().
+		WithBackoffStrategy(NewFixedBackoffBuilder().WithDelay(5 * time.Millisecond).Build()).
+		Build()
+
+	t.Run("one event per retry decision", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		var events []retryEvent
+		cli := NewClientBuilder().
+			WithRetryConfig(fixed).
+			WithOnRetry(func(attempt int, resp *Response, err error, nextDelay time.Duration) {
+				events = append(events, retryEvent{attempt, resp.StatusCode(), err, nextDelay})
+			}).
+			Build()
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		want := []retryEvent{
+			{1, http.StatusServiceUnavailable, nil, 5 * time.Millisecond},
+			{2, http.StatusServiceUnavailable, nil, 5 * time.Millisecond},
+		}
+		if !reflect.DeepEqual(events, want) {
+			t.Errorf("Expected %+v, got %+v", want, events)
+		}
+	})
+
+	t.Run("errors and no event for the final attempt", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		url := server.URL
+		server.Close()
+
+		var attempts []int
+		cli := NewClientBuilder().
+			WithRetryConfig(fixed).
+			WithOnRetry(func(attempt int, resp *Response, err error, nextDelay time.Duration) {
+				if resp != nil || err == nil {
+					t.Errorf("Expected an error without response, got %v and %v", resp, err)
+				}
+				attempts = append(attempts, attempt)
+			}).
+			Build()
+		if _, err := cli.Get(context.TODO(), url); err == nil {
+			t.Fatal("Expected an error")
+		}
+
+		if !reflect.DeepEqual(attempts, []int{1, 2, 3}) {
+			t.Errorf("Expected retries after attempts 1-3 of 4, got %v", attempts)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package reqwest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRetryAfterMaxDelay caps server-requested delays unless configured
+// otherwise, so a misbehaving server can't park requests for hours.
+const DefaultRetryAfterMaxDelay = time.Minute
+
+// RetryAfterBackoff waits as long as the server asks via Retry-After, or
+// until the rate limit window resets (RateLimit-Reset, X-RateLimit-Reset),
+// and falls back to another strategy otherwise.
+type RetryAfterBackoff struct {
+	fallback BackoffStrategy
+	maxDelay time.Duration
+	clock    Clock
+}
+
+type retryAfterBackoffBuilder struct {
+	backoff *RetryAfterBackoff
+}
+
+// NewRetryAfterBackoffBuilder creates a new builder for RetryAfterBackoff.
+// The builder is not safe for concurrent use. Each goroutine should create
+// its own builder instance.
+func NewRetryAfterBackoffBuilder() *retryAfterBackoffBuilder {
+	return &retryAfterBackoffBuilder{
+		backoff: &RetryAfterBackoff{},
+	}
+}
+
+// WithFallback sets the strategy used when the response carries no delay.
+func (b *retryAfterBackoffBuilder) WithFallback(strategy BackoffStrategy) *retryAfterBackoffBuilder {
+	b.backoff.fallback = strategy
+	return b
+}
+
+// WithMaxDelay caps the delay a server can request.
+func (b *retryAfterBackoffBuilder) WithMaxDelay(delay time.Duration) *retryAfterBackoffBuilder {
+	b.backoff.maxDelay = delay
+	return b
+}
+
+// WithClock sets the clock HTTP-date and timestamp headers are compared
+// against.
+func (b *retryAfterBackoffBuilder) WithClock(clock Clock) *retryAfterBackoffBuilder {
+	b.backoff.clock = clock
+	return b
+}
+
+func (b *retryAfterBackoffBuilder) Build() BackoffStrategy {
+	// Default fallback to exponential backoff
+	if b.backoff.fallback == nil {
+		b.backoff.fallback = NewExponentialBackoffBuilder().Build()
+	}
+	// Default maxDelay to 1 minute
+	if b.backoff.maxDelay <= 0 {
+		b.backoff.maxDelay = DefaultRetryAfterMaxDelay
+	}
+	b.backoff.clock = orSystemClock(b.backoff.clock)
+	return b.backoff
+}
+
+func (b *RetryAfterBackoff) Delay(count int) time.Duration {
+	return b.fallback.Delay(count)
+}
+
+func (b *RetryAfterBackoff) DelayAfter(count int, resp *Response, err error) time.Duration {
+	if resp != nil {
+		if delay, ok := b.serverDelay(resp.header); ok {
+			return min(delay, b.maxDelay)
+		}
+	}
+	if contextual, ok := b.fallback.(ContextualBackoff); ok {
+		return contextual.DelayAfter(count, resp, err)
+	}
+	return b.fallback.Delay(count)
+}
+
+// serverDelay reads the delay requested by header. Retry-After is either
+// seconds or an HTTP date; reset headers are seconds until the reset, or a
+// Unix timestamp as sent by some APIs for X-RateLimit-Reset.
+func (b *RetryAfterBackoff) serverDelay(header http.Header) (time.Duration, bool) {
+	if value := strings.TrimSpace(header.Get("Retry-After")); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(b.clock.Now()), 0), true
+		}
+	}
+	for _, name := range []string{"RateLimit-Reset", "X-RateLimit-Reset"} {
+		seconds, err := strconv.ParseInt(strings.TrimSpace(header.Get(name)), 10, 64)
+		if err != nil || seconds < 0 {
+			continue
+		}
+		// Deltas are small; anything past 2001-09-09 is a timestamp
+		if seconds >= 1e9 {
+			return max(time.Unix(seconds, 0).Sub(b.clock.Now()), 0), true
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	return 0, false
+}
@@ -0,0 +1,125 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryAfterBackoff_DelayAfter(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	backoff := NewRetryAfterBackoffBuilder().
+		WithFallback(NewFixedBackoffBuilder().WithDelay(time.Second).Build()).
+		WithMaxDelay(time.Minute).
+		WithClock(newManualClock(now)).
+		Build().(*RetryAfterBackoff)
+
+	withHeader := func(name, value string) *Response {
+		return &Response{header: http.Header{name: []string{value}}}
+	}
+	tests := []struct {
+		name     string
+		resp     *Response
+		expected time.Duration
+	}{
+		{"Retry-After seconds", withHeader("Retry-After", "7"), 7 * time.Second},
+		{"Retry-After HTTP date", withHeader("Retry-After", now.Add(30*time.Second).Format(http.TimeFormat)), 30 * time.Second},
+		{"Retry-After in the past", withHeader("Retry-After", now.Add(-time.Hour).Format(http.TimeFormat)), 0},
+		{"RateLimit-Reset delta", withHeader("Ratelimit-Reset", "12"), 12 * time.Second},
+		{"X-RateLimit-Reset timestamp", withHeader("X-Ratelimit-Reset", "1714564820"), 20 * time.Second},
+		{"capped at max delay", withHeader("Retry-After", "3600"), time.Minute},
+		{"invalid header falls back", withHeader("Retry-After", "soon"), time.Second},
+		{"no response falls back", nil, time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backoff.DelayAfter(1, tt.resp, errors.New("failed")); got != tt.expected {
+				t.Errorf("DelayAfter() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// statusBackoff waits longer after 503s than after other failures.
+type statusBackoff struct {
+	seen []int
+}
+
+func (b *statusBackoff) Delay(int) time.Duration { return time.Millisecond }
+
+func (b *statusBackoff) DelayAfter(_ int, resp *Response, _ error) time.Duration {
+	b.seen = append(b.seen, resp.StatusCode())
+	if resp.StatusCode() == http.StatusServiceUnavailable {
+		return 2 * time.Millisecond
+	}
+	return time.Millisecond
+}
+
+func TestContextualBackoff(t *testing.T) {
+	t.Run("strategies see each failed response", func(t *testing.T) {
+		statuses := []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK}
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(statuses[calls])
+			calls++
+		}))
+		defer server.Close()
+
+		strategy := &statusBackoff{}
+		var delays []time.Duration
+		cli := NewClientBuilder().
+			WithRetryConfig(NewRetryConfigBuilder().WithBackoffStrategy(strategy).Build()).
+			WithOnRetry(func(_ int, _ *Response, _ error, nextDelay time.Duration) {
+				delays = append(delays, nextDelay)
+			}).
+			Build()
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		if len(strategy.seen) != 2 || strategy.seen[0] != http.StatusTooManyRequests || strategy.seen[1] != http.StatusServiceUnavailable {
+			t.Errorf("Expected the strategy to see 429 and 503, got %v", strategy.seen)
+		}
+		if len(delays) != 2 || delays[0] != time.Millisecond || delays[1] != 2*time.Millisecond {
+			t.Errorf("Expected delays chosen by the strategy, got %v", delays)
+		}
+	})
+
+	t.Run("Retry-After overrides the fallback", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		}))
+		defer server.Close()
+
+		// The fallback would wait an hour
+		backoff := NewRetryAfterBackoffBuilder().
+			WithFallback(NewFixedBackoffBuilder().WithDelay(time.Hour).Build()).
+			Build()
+		for _, cli := range []Client{
+			NewClientBuilder().WithRetryConfig(NewRetryConfigBuilder().WithBackoffStrategy(backoff).Build()).Build(),
+			WithRetriesAround(NewClientBuilder().Build(), NewRetryConfigBuilder().WithBackoffStrategy(backoff).Build()),
+		} {
+			calls = 0
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			resp, err := cli.Get(ctx, server.URL)
+			cancel()
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body().Close()
+			if resp.StatusCode() != http.StatusOK {
+				t.Errorf("Expected the retry to succeed, got %d", resp.StatusCode())
+			}
+		}
+	})
+}
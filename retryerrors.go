@@ -0,0 +1,85 @@
+package reqwest
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+)
+
+// DefaultRetryableErrorMatchers are the matchers used when a RetryConfig is
+// built without WithRetryableErrorMatchers.
+var DefaultRetryableErrorMatchers = []func(error) bool{
+	IsTimeoutError,
+	IsConnectionReset,
+	IsConnectionRefused,
+	IsUnexpectedEOF,
+	IsTLSHandshakeError,
+	IsDNSError,
+}
+
+// IsTimeoutError reports whether err is a network or per-attempt timeout.
+// An expired context deadline is not one: the caller has given up.
+func IsTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, os.ErrDeadlineExceeded) || errors.Is(err, ErrAttemptTimeout) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsConnectionReset reports whether the peer reset or abandoned the
+// connection.
+func IsConnectionReset(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// IsConnectionRefused reports whether nothing accepted the connection.
+func IsConnectionRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// IsUnexpectedEOF reports whether the connection closed before a complete
+// response arrived, as servers do when closing idle connections we reuse.
+// The request may have been delivered, so clients only retry it for
+// idempotent methods or requests carrying an idempotency key.
+func IsUnexpectedEOF(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// IsTLSHandshakeError reports whether the server aborted the TLS handshake
+// with an alert. Certificate verification failures are not matched since a
+// retry would fail the same way; handshake timeouts match IsTimeoutError.
+func IsTLSHandshakeError(err error) bool {
+	var verifyErr *tls.CertificateVerificationError
+	if errors.As(err, &verifyErr) {
+		return false
+	}
+	var alert tls.AlertError
+	return errors.As(err, &alert)
+}
+
+// IsDNSError reports whether resolving the host failed.
+func IsDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// IsNetworkDown reports whether the local network looks unavailable: no
+// route to the network or host, or a resolver that can't be reached. Unlike
+// a refused connection, this says nothing about the server.
+func IsNetworkDown(err error) bool {
+	if errors.Is(err, syscall.ENETUNREACH) || errors.Is(err, syscall.ENETDOWN) || errors.Is(err, syscall.EHOSTUNREACH) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && !dnsErr.IsNotFound
+}
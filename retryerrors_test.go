@@ -0,0 +1,136 @@
+package reqwest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+)
+
+func TestDefaultRetryableErrorMatchers(t *testing.T) {
+	wrap := func(err error) error {
+		return fmt.Errorf("failed to do http request: %w", &net.OpError{Op: "read", Net: "tcp", Err: err})
+	}
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{"connection reset", wrap(syscall.ECONNRESET), true},
+		{"broken pipe", wrap(syscall.EPIPE), true},
+		{"connection refused", wrap(syscall.ECONNREFUSED), true},
+		{"i/o timeout", wrap(os.ErrDeadlineExceeded), true},
+		{"EOF", fmt.Errorf("failed to do http request: %w", io.EOF), true},
+		{"unexpected EOF", fmt.Errorf("failed to read body: %w", io.ErrUnexpectedEOF), true},
+		{"TLS alert", wrap(tls.AlertError(40)), true},
+		{"DNS failure", wrap(&net.DNSError{Err: "server misbehaving", Name: "example.com"}), true},
+		{"certificate rejected", wrap(&tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}), false},
+		{"context deadline", fmt.Errorf("failed to do http request: %w", context.DeadlineExceeded), false},
+		{"context canceled", fmt.Errorf("failed to do http request: %w", context.Canceled), false},
+		{"other error", errors.New("bad request"), false},
+	}
+	config := NewRetryConfigBuilder().WithRetryableErrors(map[string]bool{"unrelated": true}).Build()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := config.retryableErr(tt.err); got != tt.retryable {
+				t.Errorf("retryableErr(%v) = %t, want %t", tt.err, got, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestRetryConfigBuilder_WithRetryableErrorMatchers(t *testing.T) {
+	errQuota := errors.New("quota exhausted")
+
+	t.Run("replaces the default matchers", func(t *testing.T) {
+		config := NewRetryConfigBuilder().
+			WithRetryableErrors(map[string]bool{"unrelated": true}).
+			WithRetryableErrorMatchers(func(err error) bool { return errors.Is(err, errQuota) }).
+			Build()
+		if !config.retryableErr(fmt.Errorf("call failed: %w", errQuota)) {
+			t.Error("Expected a wrapped custom error to be retryable")
+		}
+		if config.retryableErr(fmt.Errorf("call failed: %w", io.EOF)) {
+			t.Error("Expected default matchers to be replaced")
+		}
+	})
+
+	t.Run("retries a dropped connection only if it is safe", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			method string
+			key    bool
+			calls  int32
+		}{
+			{"idempotent method", http.MethodPut, false, 2},
+			{"idempotency key", http.MethodPost, true, 2},
+			{"post", http.MethodPost, false, 1},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var calls int32
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if atomic.AddInt32(&calls, 1) == 1 {
+						conn, _, err := w.(http.Hijacker).Hijack()
+						if err != nil {
+							t.Errorf("Failed to hijack: %v", err)
+							return
+						}
+						conn.Close()
+					}
+				}))
+				defer server.Close()
+
+				builder := NewClientBuilder().
+					WithRetryConfig(NewRetryConfigBuilder().
+						WithBackoffStrategy(NewFixedBackoffBuilder().WithDelay(1).Build()).
+						Build())
+				if tt.key {
+					builder = builder.WithIdempotencyKeys()
+				}
+				resp, err := builder.Build().Do(context.Background(), NewRequest(tt.method, server.URL).WithBody([]byte("{}")))
+				if err == nil {
+					resp.Body().Close()
+				}
+				if got := atomic.LoadInt32(&calls); got != tt.calls {
+					t.Errorf("Expected %d calls, got %d (%v)", tt.calls, got, err)
+				}
+			})
+		}
+	})
+}
+
+func TestIsNetworkDown(t *testing.T) {
+	dial := func(err error) error {
+		return fmt.Errorf("failed to do http request: %w", &net.OpError{Op: "dial", Net: "tcp", Err: err})
+	}
+	tests := []struct {
+		name string
+		err  error
+		down bool
+	}{
+		{"network unreachable", dial(syscall.ENETUNREACH), true},
+		{"host unreachable", dial(syscall.EHOSTUNREACH), true},
+		{"network down", dial(syscall.ENETDOWN), true},
+		{"resolver unreachable", dial(&net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}), true},
+		{"no such host", dial(&net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}), false},
+		{"connection refused", dial(syscall.ECONNREFUSED), false},
+		{"other error", errors.New("bad request"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNetworkDown(tt.err); got != tt.down {
+				t.Errorf("IsNetworkDown(%v) = %t, want %t", tt.err, got, tt.down)
+			}
+		})
+	}
+}
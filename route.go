@@ -0,0 +1,121 @@
+package reqwest
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+type routeKey struct{}
+
+// NewTemplateRequest creates a request for a path template such as
+// "/users/{id}/orders/{orderID}", filling each {name} with the escaped value
+// of params[name]; placeholders without a value are left as they are. The
+// template, rather than the concrete URL, labels the request in metrics and
+// traces, so dashboards don't get a series per resource.
+func NewTemplateRequest(method, template string, params map[string]string) *Request {
+	var path strings.Builder
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		path.WriteString(rest[:start])
+		if value, ok := params[rest[start+1:end]]; ok {
+			path.WriteString(url.PathEscape(value))
+		} else {
+			path.WriteString(rest[start : end+1])
+		}
+		rest = rest[end+1:]
+	}
+	path.WriteString(rest)
+
+	req := NewRequest(method, path.String())
+	req.template = template
+	return req
+}
+
+// Route returns the label for the request in metrics and traces: its path
+// template, or the normalized URL for requests not built from a template.
+func (r *Request) Route() string {
+	if r.template != "" {
+		return r.template
+	}
+	return NormalizeRoute(r.url)
+}
+
+// RouteFromContext returns the route of the request being sent with ctx,
+// e.g. for a RoundTripperWrapper that records spans.
+func RouteFromContext(ctx context.Context) string {
+	route, _ := ctx.Value(routeKey{}).(string)
+	return route
+}
+
+// ContextWithRoute returns a copy of ctx that RouteFromContext reports route
+// for, e.g. to key a recorded request like a live one with
+// DriftMonitor.LearnResponse. Requests sent by a client get their own route.
+func ContextWithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+func withRoute(ctx context.Context, request *Request) context.Context {
+	return context.WithValue(ctx, routeKey{}, request.Route())
+}
+
+// NormalizeRoute turns an ad-hoc URL into a low-cardinality label: the query
+// is dropped and path segments that look like IDs (numbers, UUIDs, long hex
+// strings or other long tokens containing digits) become {id}, e.g.
+// "https://api.example.com/users/42/orders?page=2" becomes "/users/{id}/orders".
+func NormalizeRoute(rawURL string) string {
+	path := rawURL
+	if parsed, err := url.Parse(rawURL); err == nil {
+		path = parsed.EscapedPath()
+	} else if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if looksLikeID(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	path = strings.Join(segments, "/")
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+func looksLikeID(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	var digits, hex, other int
+	for _, r := range segment {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r >= 'a' && r <= 'f', r >= 'A' && r <= 'F':
+			hex++
+		case r == '-' || r == '_':
+		default:
+			other++
+		}
+	}
+	switch {
+	case digits == len(segment):
+		return true
+	case other == 0 && digits > 0 && digits+hex >= 16:
+		// UUIDs and hashes
+		return true
+	default:
+		// Opaque tokens such as "a8Kd93jXz02" rather than words like "v2"
+		return len(segment) >= 10 && digits >= 2
+	}
+}
@@ -0,0 +1,85 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewTemplateRequest(t *testing.T) {
+	req := NewTemplateRequest(http.MethodGet, "/users/{id}/files/{name}/{missing}", map[string]string{
+		"id":   "42",
+		"name": "a b/c",
+	})
+	if got, want := req.URL(), "/users/42/files/a%20b%2Fc/{missing}"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+	if got := req.Route(); got != "/users/{id}/files/{name}/{missing}" {
+		t.Errorf("Route() = %q, want the template", got)
+	}
+	if got := req.Clone().Route(); got != req.Route() {
+		t.Errorf("Clone().Route() = %q, want %q", got, req.Route())
+	}
+}
+
+func TestNormalizeRoute(t *testing.T) {
+	testCases := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://api.example.com/users/42/orders?page=2", want: "/users/{id}/orders"},
+		{url: "/orders/3f2b8c1e-9a4d-4e7b-8c2a-1d5e6f7a8b9c", want: "/orders/{id}"},
+		{url: "/blobs/e3b0c44298fc1c149afbf4c8996fb924", want: "/blobs/{id}"},
+		{url: "/sessions/a8Kd93jXz02", want: "/sessions/{id}"},
+		{url: "/v2/users/me", want: "/v2/users/me"},
+		{url: "/api/feed", want: "/api/feed"},
+		{url: "https://api.example.com", want: "/"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			if got := NormalizeRoute(tc.url); got != tc.want {
+				t.Errorf("NormalizeRoute(%q) = %q, want %q", tc.url, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRouteLabels(t *testing.T) {
+	var fromTransport string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	cli := NewClientBuilder().
+		WithBaseURL(server.URL).
+		WithRoundTripperWrapper(func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				fromTransport = RouteFromContext(req.Context())
+				return next.RoundTrip(req)
+			})
+		}).
+		Build()
+	collector := &recordingCollector{}
+	metered := WithMetricsAround(cli, collector)
+
+	req := NewTemplateRequest(http.MethodGet, "/users/{id}", map[string]string{"id": "42"})
+	resp, err := metered.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if got := resp.Trace().Route; got != "/users/{id}" {
+		t.Errorf("Trace().Route = %q, want /users/{id}", got)
+	}
+	if fromTransport != "/users/{id}" {
+		t.Errorf("RouteFromContext() = %q, want /users/{id}", fromTransport)
+	}
+
+	if _, err := metered.Get(context.Background(), "/users/43"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	for i, want := range []string{"/users/{id}", "/users/{id}"} {
+		if got := collector.observed[i].Route; got != want {
+			t.Errorf("observation %d Route = %q, want %q", i, got, want)
+		}
+	}
+}
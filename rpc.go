@@ -0,0 +1,318 @@
+package reqwest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/textproto"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RPCProtocol selects the wire protocol of an RPCClient.
+type RPCProtocol int
+
+const (
+	// ProtocolConnect is the Connect protocol's unary HTTP/1.1 mapping.
+	ProtocolConnect RPCProtocol = iota
+	// ProtocolGRPCWeb is gRPC-Web, with length-prefixed messages and trailers
+	// in the body.
+	ProtocolGRPCWeb
+)
+
+// RPCCode is a Connect or gRPC status code.
+type RPCCode uint32
+
+const (
+	RPCOK RPCCode = iota
+	RPCCanceled
+	RPCUnknown
+	RPCInvalidArgument
+	RPCDeadlineExceeded
+	RPCNotFound
+	RPCAlreadyExists
+	RPCPermissionDenied
+	RPCResourceExhausted
+	RPCFailedPrecondition
+	RPCAborted
+	RPCOutOfRange
+	RPCUnimplemented
+	RPCInternal
+	RPCUnavailable
+	RPCDataLoss
+	RPCUnauthenticated
+)
+
+var rpcCodeNames = [...]string{
+	"ok", "canceled", "unknown", "invalid_argument", "deadline_exceeded", "not_found", "already_exists",
+	"permission_denied", "resource_exhausted", "failed_precondition", "aborted", "out_of_range",
+	"unimplemented", "internal", "unavailable", "data_loss", "unauthenticated",
+}
+
+// String returns the Connect name of the code, e.g. "not_found".
+func (c RPCCode) String() string {
+	if int(c) < len(rpcCodeNames) {
+		return rpcCodeNames[c]
+	}
+	return "code_" + strconv.FormatUint(uint64(c), 10)
+}
+
+func parseRPCCode(name string) RPCCode {
+	for i, known := range rpcCodeNames {
+		if known == name {
+			return RPCCode(i)
+		}
+	}
+	return RPCUnknown
+}
+
+// RPCError is the error status of an RPC.
+type RPCError struct {
+	Code    RPCCode
+	Message string
+	// Details are the error details of a Connect error, still encoded.
+	Details []RPCErrorDetail
+	// Metadata are the response headers and, for gRPC-Web, trailers.
+	Metadata http.Header
+}
+
+// RPCErrorDetail is an encoded error detail, usually a protobuf message
+// named by Type.
+type RPCErrorDetail struct {
+	Type  string
+	Value []byte
+}
+
+func (e *RPCError) Error() string {
+	if e.Message == "" {
+		return "rpc error: " + e.Code.String()
+	}
+	return fmt.Sprintf("rpc error: %s: %s", e.Code, e.Message)
+}
+
+// RPCCodeOf returns the code of an *RPCError in err's chain: RPCOK for nil
+// and RPCUnknown for other errors.
+func RPCCodeOf(err error) RPCCode {
+	if err == nil {
+		return RPCOK
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code
+	}
+	return RPCUnknown
+}
+
+// RPCClient calls unary Connect or gRPC-Web procedures through a reqwest
+// client, so they get its retries, middleware and other policies:
+//
+//	rpc := reqwest.NewRPCClient(client, reqwest.ProtocolConnect, reqwest.JSONCodec{})
+//	var user User
+//	err := rpc.Call(ctx, "/acme.user.v1.UserService/GetUser", &GetUserRequest{ID: 42}, &user)
+//
+// Messages are encoded with codec: JSONCodec for the JSON encoding, or a
+// Codec wrapping a protobuf library whose content type is
+// "application/proto". Failed calls return an *RPCError.
+type RPCClient struct {
+	doer     Doer
+	protocol RPCProtocol
+	codec    Codec
+	name     string
+}
+
+// NewRPCClient returns an RPCClient sending requests through doer.
+func NewRPCClient(doer Doer, protocol RPCProtocol, codec Codec) *RPCClient {
+	return &RPCClient{doer: doer, protocol: protocol, codec: codec, name: rpcCodecName(codec)}
+}
+
+// rpcCodecName returns the name the protocols use for a codec, e.g. "proto"
+// for application/x-protobuf.
+func rpcCodecName(codec Codec) string {
+	mediaType, _, _ := mime.ParseMediaType(codec.ContentType())
+	name := strings.TrimPrefix(strings.TrimPrefix(mediaType, "application/"), "x-")
+	if name == "protobuf" {
+		return "proto"
+	}
+	return name
+}
+
+// Call sends in to procedure, a path such as "/package.Service/Method", and
+// decodes the reply into out.
+func (c *RPCClient) Call(ctx context.Context, procedure string, in, out any, opts ...RequestOption) error {
+	data, err := c.codec.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode request message: %v", err)
+	}
+	req := NewRequest(http.MethodPost, "/"+strings.TrimPrefix(procedure, "/"))
+	if c.protocol == ProtocolGRPCWeb {
+		req.WithBody(grpcWebFrame(0, data)).
+			WithHeader("Content-Type", "application/grpc-web+"+c.name).
+			WithHeader("Accept", "application/grpc-web+"+c.name).
+			WithHeader("X-Grpc-Web", "1")
+	} else {
+		req.WithBody(data).
+			WithHeader("Content-Type", "application/"+c.name).
+			WithHeader("Connect-Protocol-Version", "1")
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		// Lets the server give up when the caller does
+		ms := max(time.Until(deadline).Milliseconds(), 1)
+		if c.protocol == ProtocolGRPCWeb {
+			req.WithHeader("Grpc-Timeout", strconv.FormatInt(ms, 10)+"m")
+		} else {
+			req.WithHeader("Connect-Timeout-Ms", strconv.FormatInt(ms, 10))
+		}
+	}
+
+	resp, err := c.doer.Do(ctx, req.with(opts))
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.Err == nil {
+		// Clients built with WithErrorOnNon2xx have already consumed the reply
+		resp = &Response{statusCode: httpErr.StatusCode, header: httpErr.Header, body: io.NopCloser(bytes.NewReader(httpErr.Body))}
+	} else if err != nil {
+		return err
+	}
+	body, err := resp.Bytes()
+	if err != nil {
+		return err
+	}
+	if c.protocol == ProtocolGRPCWeb {
+		return c.grpcWebReply(resp, body, out)
+	}
+	return c.connectReply(resp, body, out)
+}
+
+func (c *RPCClient) connectReply(resp *Response, body []byte, out any) error {
+	if resp.StatusCode() != http.StatusOK {
+		return connectError(resp, body)
+	}
+	if contentType := resp.Header().Get("Content-Type"); !isMediaType(contentType, "application/"+c.name) {
+		return &RPCError{Code: RPCInternal, Message: fmt.Sprintf("unexpected content type %q", contentType), Metadata: resp.Header()}
+	}
+	if err := c.codec.Unmarshal(body, out); err != nil {
+		return &RPCError{Code: RPCInternal, Message: "failed to decode response message: " + err.Error(), Metadata: resp.Header()}
+	}
+	return nil
+}
+
+// connectError decodes a Connect error body, falling back to the code the
+// protocol maps the HTTP status to, e.g. for errors from proxies.
+func connectError(resp *Response, body []byte) *RPCError {
+	var wire struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Details []struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"details"`
+	}
+	if json.Unmarshal(body, &wire) == nil && wire.Code != "" {
+		rpcErr := &RPCError{Code: parseRPCCode(wire.Code), Message: wire.Message, Metadata: resp.Header()}
+		for _, detail := range wire.Details {
+			value, _ := base64.RawStdEncoding.DecodeString(strings.TrimRight(detail.Value, "="))
+			rpcErr.Details = append(rpcErr.Details, RPCErrorDetail{Type: detail.Type, Value: value})
+		}
+		return rpcErr
+	}
+	return &RPCError{Code: rpcCodeForStatus(resp.StatusCode()), Message: fmt.Sprintf("HTTP status %d", resp.StatusCode()), Metadata: resp.Header()}
+}
+
+func (c *RPCClient) grpcWebReply(resp *Response, body []byte, out any) error {
+	metadata := resp.Header().Clone()
+	if resp.StatusCode() != http.StatusOK {
+		return &RPCError{Code: rpcCodeForStatus(resp.StatusCode()), Message: fmt.Sprintf("HTTP status %d", resp.StatusCode()), Metadata: metadata}
+	}
+	if contentType := resp.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/grpc-web") {
+		return &RPCError{Code: RPCUnknown, Message: fmt.Sprintf("unexpected content type %q", contentType), Metadata: metadata}
+	}
+	var message []byte
+	var gotMessage bool
+	for len(body) > 0 {
+		if len(body) < 5 {
+			return &RPCError{Code: RPCInternal, Message: "truncated gRPC-Web frame", Metadata: metadata}
+		}
+		flags, size := body[0], binary.BigEndian.Uint32(body[1:5])
+		if uint64(len(body)-5) < uint64(size) {
+			return &RPCError{Code: RPCInternal, Message: "truncated gRPC-Web frame", Metadata: metadata}
+		}
+		payload := body[5 : 5+size]
+		body = body[5+size:]
+		switch {
+		case flags&0x01 != 0:
+			return &RPCError{Code: RPCInternal, Message: "compressed gRPC-Web frames are not supported", Metadata: metadata}
+		case flags&0x80 != 0:
+			// The trailers are header lines without the blank line ending them
+			reader := bufio.NewReader(io.MultiReader(bytes.NewReader(payload), strings.NewReader("\r\n")))
+			trailers, err := textproto.NewReader(reader).ReadMIMEHeader()
+			if err != nil {
+				return &RPCError{Code: RPCInternal, Message: "invalid gRPC-Web trailers", Metadata: metadata}
+			}
+			for key, values := range trailers {
+				metadata[key] = append(metadata[key], values...)
+			}
+		default:
+			message, gotMessage = payload, true
+		}
+	}
+
+	// A trailers-only reply carries the status in the headers
+	status := metadata.Get("Grpc-Status")
+	if status == "" {
+		return &RPCError{Code: RPCInternal, Message: "missing grpc-status", Metadata: metadata}
+	}
+	if code, err := strconv.ParseUint(status, 10, 32); err != nil || code != 0 {
+		msg, _ := neturl.PathUnescape(metadata.Get("Grpc-Message"))
+		rpcErr := &RPCError{Code: RPCUnknown, Message: msg, Metadata: metadata}
+		if err == nil {
+			rpcErr.Code = RPCCode(code)
+		}
+		return rpcErr
+	}
+	if !gotMessage {
+		return &RPCError{Code: RPCInternal, Message: "missing response message", Metadata: metadata}
+	}
+	if err := c.codec.Unmarshal(message, out); err != nil {
+		return &RPCError{Code: RPCInternal, Message: "failed to decode response message: " + err.Error(), Metadata: metadata}
+	}
+	return nil
+}
+
+// rpcCodeForStatus maps the HTTP status of a reply without an RPC status, e.g.
+// from a proxy, to a code as both protocols specify.
+func rpcCodeForStatus(status int) RPCCode {
+	switch status {
+	case http.StatusBadRequest:
+		return RPCInternal
+	case http.StatusUnauthorized:
+		return RPCUnauthenticated
+	case http.StatusForbidden:
+		return RPCPermissionDenied
+	case http.StatusNotFound:
+		return RPCUnimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return RPCUnavailable
+	}
+	return RPCUnknown
+}
+
+func grpcWebFrame(flags byte, payload []byte) []byte {
+	frame := make([]byte, 5, 5+len(payload))
+	frame[0] = flags
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(payload)))
+	return append(frame, payload...)
+}
+
+func isMediaType(contentType, want string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == want
+}
@@ -0,0 +1,130 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type greetRequest struct {
+	Name string `json:"name"`
+}
+
+type greetReply struct {
+	Greeting string `json:"greeting"`
+}
+
+// newGreetServer serves /greet.v1.GreetService/Greet over Connect and
+// gRPC-Web, failing with not_found for an empty name.
+func newGreetServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/greet.v1.GreetService/Greet" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		grpcWeb := r.Header.Get("Content-Type") == "application/grpc-web+json"
+		if grpcWeb {
+			if r.Header.Get("X-Grpc-Web") != "1" || len(body) < 5 || int(binary.BigEndian.Uint32(body[1:5])) != len(body)-5 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			body = body[5:]
+		} else if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("Connect-Protocol-Version") != "1" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		var in greetRequest
+		json.Unmarshal(body, &in)
+
+		switch {
+		case grpcWeb && in.Name == "":
+			w.Header().Set("Content-Type", "application/grpc-web+json")
+			w.Header().Set("Grpc-Status", "5")
+			w.Header().Set("Grpc-Message", "no%20name")
+		case grpcWeb:
+			w.Header().Set("Content-Type", "application/grpc-web+json")
+			reply, _ := json.Marshal(greetReply{Greeting: "Hello, " + in.Name})
+			w.Write(grpcWebFrame(0, reply))
+			w.Write(grpcWebFrame(0x80, []byte("grpc-status: 0\r\ngrpc-message: \r\nx-served-by: test\r\n")))
+		case in.Name == "":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"not_found","message":"no name","details":[{"type":"greet.v1.Hint","value":"aGk"}]}`))
+		default:
+			if r.Header.Get("Connect-Timeout-Ms") == "" {
+				t.Error("missing Connect-Timeout-Ms header")
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(greetReply{Greeting: "Hello, " + in.Name})
+		}
+	}))
+}
+
+func TestRPCClient(t *testing.T) {
+	server := newGreetServer(t)
+	defer server.Close()
+
+	for _, protocol := range []RPCProtocol{ProtocolConnect, ProtocolGRPCWeb} {
+		name := map[RPCProtocol]string{ProtocolConnect: "Connect", ProtocolGRPCWeb: "gRPC-Web"}[protocol]
+		t.Run(name, func(t *testing.T) {
+			rpc := NewRPCClient(NewClientBuilder().WithBaseURL(server.URL).Build(), protocol, JSONCodec{})
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			var reply greetReply
+			if err := rpc.Call(ctx, "greet.v1.GreetService/Greet", greetRequest{Name: "Ada"}, &reply); err != nil {
+				t.Fatalf("Call() error = %v", err)
+			}
+			if reply.Greeting != "Hello, Ada" {
+				t.Errorf("Greeting = %q", reply.Greeting)
+			}
+
+			err := rpc.Call(ctx, "/greet.v1.GreetService/Greet", greetRequest{}, &reply)
+			var rpcErr *RPCError
+			if !errors.As(err, &rpcErr) || rpcErr.Code != RPCNotFound || rpcErr.Message != "no name" {
+				t.Fatalf("Call() error = %v, want not_found: no name", err)
+			}
+			if protocol == ProtocolConnect && (len(rpcErr.Details) != 1 || string(rpcErr.Details[0].Value) != "hi") {
+				t.Errorf("Details = %+v", rpcErr.Details)
+			}
+
+			err = rpc.Call(ctx, "/greet.v1.GreetService/Missing", greetRequest{}, &reply)
+			if RPCCodeOf(err) != RPCUnimplemented {
+				t.Errorf("Call() of a missing procedure error = %v, want unimplemented", err)
+			}
+		})
+	}
+
+	t.Run("error statuses already turned into errors", func(t *testing.T) {
+		rpc := NewRPCClient(NewClientBuilder().WithBaseURL(server.URL).WithErrorOnNon2xx().Build(), ProtocolConnect, JSONCodec{})
+		err := rpc.Call(context.Background(), "/greet.v1.GreetService/Greet", greetRequest{}, &greetReply{})
+		if RPCCodeOf(err) != RPCNotFound {
+			t.Errorf("Call() error = %v, want not_found", err)
+		}
+	})
+}
+
+func TestGRPCWebTrailers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc-web+json")
+		w.Write(grpcWebFrame(0x80, []byte("grpc-status: 14\r\ngrpc-message: try%20later\r\n")))
+	}))
+	defer server.Close()
+
+	rpc := NewRPCClient(NewClientBuilder().WithBaseURL(server.URL).Build(), ProtocolGRPCWeb, JSONCodec{})
+	err := rpc.Call(context.Background(), "/svc/Method", struct{}{}, &struct{}{})
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != RPCUnavailable || rpcErr.Message != "try later" {
+		t.Errorf("Call() error = %v, want unavailable: try later", err)
+	}
+	if RPCCodeOf(nil) != RPCOK || RPCUnavailable.String() != "unavailable" {
+		t.Error("unexpected code helpers")
+	}
+}
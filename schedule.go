@@ -0,0 +1,170 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrClientClosed is returned by Schedule once the client has been closed.
+var ErrClientClosed = errors.New("client is closed")
+
+// Scheduler issues recurring requests, e.g. health pings, token refresh or
+// cache warming, through the client's retry, rate limit and other policies.
+type Scheduler interface {
+	// Schedule sends req whenever spec fires and passes the outcome to
+	// handler. spec is a five-field cron expression ("*/5 * * * *"), a
+	// descriptor such as "@hourly", or an interval such as "@every 30s".
+	Schedule(spec string, req *Request, handler ScheduleHandler, opts ...ScheduleOption) (*ScheduledJob, error)
+	// Close stops every scheduled job, waits for runs in progress and
+	// closes idle connections.
+	Close() error
+}
+
+// ScheduleHandler receives the outcome of one scheduled run. The response
+// body is closed once the handler returns.
+type ScheduleHandler func(resp *Response, err error)
+
+type scheduleConfig struct {
+	jitter    time.Duration
+	immediate bool
+}
+
+// ScheduleOption adjusts a single scheduled job.
+type ScheduleOption func(*scheduleConfig)
+
+// ScheduleJitter delays every run by a random duration up to max, so a fleet
+// of clients doesn't hit the server at the same instant.
+func ScheduleJitter(max time.Duration) ScheduleOption {
+	return func(cfg *scheduleConfig) {
+		cfg.jitter = max
+	}
+}
+
+// ScheduleImmediately also runs the job once as soon as it is scheduled,
+// e.g. to fetch a token before the first refresh is due.
+func ScheduleImmediately() ScheduleOption {
+	return func(cfg *scheduleConfig) {
+		cfg.immediate = true
+	}
+}
+
+// ScheduledJob is a recurring request started by Schedule.
+type ScheduledJob struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Stop cancels the job, including a run in progress. Done is closed once the
+// job has returned.
+func (j *ScheduledJob) Stop() {
+	j.cancel()
+}
+
+// Done is closed once the job has stopped.
+func (j *ScheduledJob) Done() <-chan struct{} {
+	return j.done
+}
+
+// scheduleSet tracks the jobs Close stops. Its zero value is empty.
+type scheduleSet struct {
+	mu     sync.Mutex
+	jobs   map[*ScheduledJob]struct{}
+	closed bool
+}
+
+func (s *scheduleSet) add(job *ScheduledJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClientClosed
+	}
+	if s.jobs == nil {
+		s.jobs = make(map[*ScheduledJob]struct{})
+	}
+	s.jobs[job] = struct{}{}
+	return nil
+}
+
+func (s *scheduleSet) remove(job *ScheduledJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, job)
+}
+
+// close stops every job and waits for them to return.
+func (s *scheduleSet) close() {
+	s.mu.Lock()
+	s.closed = true
+	jobs := s.jobs
+	s.jobs = nil
+	s.mu.Unlock()
+	for job := range jobs {
+		job.Stop()
+	}
+	for job := range jobs {
+		<-job.done
+	}
+}
+
+func (c *HTTPClient) Schedule(spec string, req *Request, handler ScheduleHandler, opts ...ScheduleOption) (*ScheduledJob, error) {
+	schedule, err := parseCron(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to schedule request: %w", err)
+	}
+	var cfg scheduleConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &ScheduledJob{cancel: cancel, done: make(chan struct{})}
+	if err := c.schedules.add(job); err != nil {
+		cancel()
+		return nil, err
+	}
+	go c.runSchedule(ctx, job, schedule, cfg, req, handler)
+	return job, nil
+}
+
+func (c *HTTPClient) runSchedule(ctx context.Context, job *ScheduledJob, schedule *cronSchedule, cfg scheduleConfig, req *Request, handler ScheduleHandler) {
+	defer close(job.done)
+	defer c.schedules.remove(job)
+	clock := orSystemClock(c.clock)
+	run := cfg.immediate
+	for {
+		if run {
+			resp, err := c.Do(ctx, req)
+			if ctx.Err() != nil {
+				if resp != nil {
+					resp.body.Close()
+				}
+				return
+			}
+			if handler != nil {
+				handler(resp, err)
+			}
+			if resp != nil {
+				resp.body.Close()
+			}
+		}
+		now := clock.Now()
+		next := schedule.next(now)
+		if next.IsZero() {
+			return
+		}
+		if err := sleep(ctx, clock, next.Sub(now)+randomDelay(0, cfg.jitter)); err != nil {
+			return
+		}
+		run = true
+	}
+}
+
+func (c *HTTPClient) Close() error {
+	c.schedules.close()
+	if c.httpClient != nil {
+		c.httpClient.CloseIdleConnections()
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package reqwest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClient_Schedule(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	t.Run("runs until closed", func(t *testing.T) {
+		cli := NewClientBuilder().WithBaseURL(server.URL).Build()
+		hits.Store(0)
+		results := make(chan string, 16)
+		job, err := cli.Schedule("@every 10ms", NewRequest(http.MethodGet, "/ping"), func(resp *Response, err error) {
+			if err != nil {
+				results <- err.Error()
+				return
+			}
+			data, _ := resp.Bytes()
+			results <- string(data)
+		}, ScheduleImmediately(), ScheduleJitter(time.Millisecond))
+		if err != nil {
+			t.Fatalf("Schedule() error = %v", err)
+		}
+		for range 3 {
+			if got := <-results; got != "pong" {
+				t.Fatalf("handler got %q, want pong", got)
+			}
+		}
+
+		if err := cli.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		select {
+		case <-job.Done():
+		default:
+			t.Fatal("job still running after Close")
+		}
+		after := hits.Load()
+		time.Sleep(30 * time.Millisecond)
+		if hits.Load() != after {
+			t.Error("requests sent after Close")
+		}
+		if _, err := cli.Schedule("@every 10ms", NewRequest(http.MethodGet, "/ping"), nil); !errors.Is(err, ErrClientClosed) {
+			t.Errorf("Schedule() after Close error = %v, want ErrClientClosed", err)
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		cli := NewClientBuilder().WithBaseURL(server.URL).Build()
+		defer cli.Close()
+		job, err := cli.Schedule("@every 1h", NewRequest(http.MethodGet, "/ping"), nil)
+		if err != nil {
+			t.Fatalf("Schedule() error = %v", err)
+		}
+		job.Stop()
+		select {
+		case <-job.Done():
+		case <-time.After(time.Second):
+			t.Fatal("job did not stop")
+		}
+	})
+
+	t.Run("invalid spec", func(t *testing.T) {
+		cli := NewClientBuilder().Build()
+		if _, err := cli.Schedule("every minute", NewRequest(http.MethodGet, "/"), nil); err == nil {
+			t.Error("Schedule() error = nil, want error")
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package reqwest
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultSelfCheckPath is probed by SelfCheck unless WithSelfCheckPath is set.
+const DefaultSelfCheckPath = "/"
+
+// Names of the checks performed by SelfCheck, in order
+const (
+	SelfCheckConfig = "config"
+	SelfCheckDNS    = "dns"
+	SelfCheckTLS    = "tls"
+	SelfCheckProbe  = "probe"
+)
+
+// SelfChecker validates a client's configuration end-to-end.
+type SelfChecker interface {
+	SelfCheck(ctx context.Context) *SelfCheckReport
+}
+
+// CheckResult is the outcome of a single self-check step. Skipped checks do
+// not apply to the configuration, e.g. TLS for a plain http base URL.
+type CheckResult struct {
+	Name     string        `json:"name"`
+	OK       bool          `json:"ok"`
+	Skipped  bool          `json:"skipped,omitempty"`
+	Detail   string        `json:"detail,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// SelfCheckReport collects every check run by SelfCheck.
+type SelfCheckReport struct {
+	OK     bool          `json:"ok"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Check returns the result with the given name.
+func (r *SelfCheckReport) Check(name string) (CheckResult, bool) {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return CheckResult{}, false
+}
+
+// SelfCheck verifies that the base URL is valid and resolvable, that a TLS
+// handshake succeeds for https endpoints, and that an authenticated GET of the
+// probe path returns 2xx through the full middleware and signing stack.
+// Every check runs even if an earlier one fails, so the report shows all
+// problems at once.
+func (c *HTTPClient) SelfCheck(ctx context.Context) *SelfCheckReport {
+	report := &SelfCheckReport{OK: true}
+	record := func(name string, run func() (detail string, skipped bool, err error)) {
+		start := time.Now()
+		detail, skipped, err := run()
+		result := CheckResult{
+			Name:     name,
+			OK:       err == nil,
+			Skipped:  skipped,
+			Detail:   detail,
+			Duration: time.Since(start),
+		}
+		if err != nil {
+			result.Error = err.Error()
+			report.OK = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	var base *url.URL
+	record(SelfCheckConfig, func() (string, bool, error) {
+		var err error
+		base, err = parseBaseURL(c.baseURL)
+		if err != nil {
+			return "", false, err
+		}
+		return base.String(), false, nil
+	})
+
+	record(SelfCheckDNS, func() (string, bool, error) {
+		if base == nil || net.ParseIP(base.Hostname()) != nil {
+			return "", true, nil
+		}
+		addrs, err := c.netResolver().LookupHost(ctx, base.Hostname())
+		if err != nil {
+			return "", false, fmt.Errorf("failed to resolve %s: %v", base.Hostname(), err)
+		}
+		return fmt.Sprintf("%s resolved to %v", base.Hostname(), addrs), false, nil
+	})
+
+	record(SelfCheckTLS, func() (string, bool, error) {
+		if base == nil || base.Scheme != "https" {
+			return "", true, nil
+		}
+		return c.checkTLS(ctx, base)
+	})
+
+	record(SelfCheckProbe, func() (string, bool, error) {
+		resp, err := c.execute(ctx, NewRequest(http.MethodGet, c.selfCheckProbePath()))
+		if err != nil {
+			return "", false, err
+		}
+		resp.body.Close()
+		if resp.statusCode < http.StatusOK || resp.statusCode >= http.StatusMultipleChoices {
+			return "", false, fmt.Errorf("probe returned status %d", resp.statusCode)
+		}
+		return fmt.Sprintf("GET %s returned %d", c.selfCheckProbePath(), resp.statusCode), false, nil
+	})
+
+	return report
+}
+
+func (c *HTTPClient) selfCheckProbePath() string {
+	if c.selfCheckPath == "" {
+		return DefaultSelfCheckPath
+	}
+	return c.selfCheckPath
+}
+
+func (c *HTTPClient) checkTLS(ctx context.Context, base *url.URL) (string, bool, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.httpClient != nil {
+		if transport, ok := c.httpClient.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+			config = transport.TLSClientConfig.Clone()
+		}
+	}
+	config.ServerName = base.Hostname()
+
+	port := base.Port()
+	if port == "" {
+		port = "443"
+	}
+	dialer := &tls.Dialer{Config: config}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(base.Hostname(), port))
+	if err != nil {
+		return "", false, fmt.Errorf("tls handshake failed: %v", err)
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	return fmt.Sprintf("negotiated %s", tls.VersionName(state.Version)), false, nil
+}
+
+func parseBaseURL(baseURL string) (*url.URL, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("no base URL configured")
+	}
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %v", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL: unsupported scheme %q", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("invalid base URL: missing host")
+	}
+	return parsed, nil
+}
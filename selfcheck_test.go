@@ -0,0 +1,134 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_SelfCheck(t *testing.T) {
+	t.Run("healthy https endpoint", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/healthz" || r.Header.Get("Authorization") != "Bearer ok" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().
+			WithBaseURL(server.URL).
+			WithTransport(server.Client().Transport).
+			WithMiddleware(func(req *http.Request) error {
+				req.Header.Set("Authorization", "Bearer ok")
+				return nil
+			}).
+			WithSelfCheckPath("/healthz").
+			Build()
+
+		report := cli.SelfCheck(context.TODO())
+		if !report.OK {
+			t.Fatalf("Expected healthy report, got %+v", report)
+		}
+
+		names := make([]string, 0, len(report.Checks))
+		for _, check := range report.Checks {
+			names = append(names, check.Name)
+		}
+		if strings.Join(names, ",") != "config,dns,tls,probe" {
+			t.Errorf("Unexpected checks: %v", names)
+		}
+
+		dns, _ := report.Check(SelfCheckDNS)
+		if !dns.Skipped {
+			t.Error("Expected DNS check to be skipped for an IP literal")
+		}
+		tlsCheck, _ := report.Check(SelfCheckTLS)
+		if tlsCheck.Skipped || !strings.Contains(tlsCheck.Detail, "TLS") {
+			t.Errorf("Expected TLS handshake details, got %+v", tlsCheck)
+		}
+
+		if _, err := json.Marshal(report); err != nil {
+			t.Errorf("Expected report to be serializable: %v", err)
+		}
+	})
+
+	t.Run("failing probe", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		report := NewClientBuilder().WithBaseURL(server.URL).Build().SelfCheck(context.TODO())
+		if report.OK {
+			t.Fatal("Expected failing report")
+		}
+
+		probe, _ := report.Check(SelfCheckProbe)
+		if probe.OK || !strings.Contains(probe.Error, "status 401") {
+			t.Errorf("Expected probe failure with status 401, got %+v", probe)
+		}
+		tlsCheck, _ := report.Check(SelfCheckTLS)
+		if !tlsCheck.Skipped || !tlsCheck.OK {
+			t.Errorf("Expected TLS check to be skipped for http, got %+v", tlsCheck)
+		}
+	})
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		report := NewClientBuilder().WithBaseURL(server.URL).Build().SelfCheck(context.TODO())
+		tlsCheck, _ := report.Check(SelfCheckTLS)
+		if tlsCheck.OK || !strings.Contains(tlsCheck.Error, "tls handshake failed") {
+			t.Errorf("Expected TLS failure, got %+v", tlsCheck)
+		}
+	})
+
+	t.Run("unresolvable host", func(t *testing.T) {
+		report := NewClientBuilder().WithBaseURL("http://reqwest-selfcheck.invalid").Build().SelfCheck(context.TODO())
+		dns, _ := report.Check(SelfCheckDNS)
+		if dns.OK || !strings.Contains(dns.Error, "failed to resolve") {
+			t.Errorf("Expected DNS failure, got %+v", dns)
+		}
+	})
+
+	t.Run("missing base URL", func(t *testing.T) {
+		report := NewClientBuilder().Build().SelfCheck(context.TODO())
+		config, ok := report.Check(SelfCheckConfig)
+		if !ok || config.OK || !strings.Contains(config.Error, "no base URL") {
+			t.Errorf("Expected config failure, got %+v", config)
+		}
+		if _, ok := report.Check("unknown"); ok {
+			t.Error("Expected unknown check lookup to fail")
+		}
+	})
+}
+
+func TestParseBaseURL(t *testing.T) {
+	testCases := []struct {
+		input string
+		err   string
+	}{
+		{"https://api.example.com", ""},
+		{"ftp://example.com", "unsupported scheme"},
+		{"http://", "missing host"},
+		{"http://[::1", "invalid base URL"},
+	}
+
+	for _, tc := range testCases {
+		_, err := parseBaseURL(tc.input)
+		if tc.err == "" && err != nil {
+			t.Errorf("parseBaseURL(%q) unexpected error: %v", tc.input, err)
+		}
+		if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("parseBaseURL(%q) = %v, want error containing %q", tc.input, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fixedTime(value string) func() time.Time {
+	return func() time.Time {
+		t, _ := time.Parse(awsTimeFormat, value)
+		return t
+	}
+}
+
+func TestAWSSigV4Signer(t *testing.T) {
+	credentials := AWSCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+
+	// Vectors from the AWS Signature Version 4 test suite
+	testCases := []struct {
+		name      string
+		url       string
+		signature string
+	}{
+		{
+			name:      "get-vanilla",
+			url:       "https://example.amazonaws.com/",
+			signature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:      "get-vanilla-query-order-key-case",
+			url:       "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			signature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			signer := NewAWSSigV4Signer(credentials, "us-east-1", "service")
+			signer.now = fixedTime("20150830T123600Z")
+
+			req, _ := http.NewRequest(http.MethodGet, tc.url, http.NoBody)
+			if err := signer.Sign(req, nil); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=" + tc.signature
+			if got := req.Header.Get("Authorization"); got != expected {
+				t.Errorf("Authorization mismatch\n got: %s\nwant: %s", got, expected)
+			}
+		})
+	}
+
+	t.Run("session token and s3 payload hash", func(t *testing.T) {
+		credentials.SessionToken = "token"
+		signer := NewAWSSigV4Signer(credentials, "eu-west-1", "s3")
+
+		req, _ := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/a%20b.txt", http.NoBody)
+		req.Header.Set("Content-Type", "text/plain")
+		if err := signer.Sign(req, []byte("hello")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if req.Header.Get("X-Amz-Security-Token") != "token" {
+			t.Error("Expected session token header")
+		}
+		if req.Header.Get("X-Amz-Content-Sha256") != hashHex([]byte("hello")) {
+			t.Error("Expected payload hash header for s3")
+		}
+		auth := req.Header.Get("Authorization")
+		expectedHeaders := "SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token"
+		if !strings.Contains(auth, expectedHeaders) {
+			t.Errorf("Expected %s in %s", expectedHeaders, auth)
+		}
+	})
+}
+
+func TestAWSCanonicalURI(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/a%20b/c", http.NoBody)
+
+	if got := awsCanonicalURI(req.URL, "execute-api"); got != "/a%2520b/c" {
+		t.Errorf("Expected double encoded path, got %s", got)
+	}
+	if got := awsCanonicalURI(req.URL, awsServiceS3); got != "/a%20b/c" {
+		t.Errorf("Expected single encoded path for s3, got %s", got)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "https://example.com", http.NoBody)
+	if got := awsCanonicalURI(req.URL, "service"); got != "/" {
+		t.Errorf("Expected / for empty path, got %s", got)
+	}
+}
+
+func TestHMACSigner(t *testing.T) {
+	var signature, timestamp string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Hub-Signature")
+		timestamp = r.Header.Get(HMACTimestampHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	signer := NewHMACSigner([]byte("secret"), "X-Hub-Signature")
+	signer.now = func() time.Time { return time.Unix(1700000000, 0) }
+	cli := NewClientBuilder().
+		WithMiddleware(func(req *http.Request) error {
+			req.URL.RawQuery = "signed=after-middleware"
+			return nil
+		}).
+		WithSigner(signer).
+		Build()
+
+	resp, err := cli.Post(context.TODO(), server.URL+"/hooks", []byte(`{"event":"x"}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body().Close()
+
+	payload := "POST\n/hooks?signed=after-middleware\n1700000000\n" + hashHex([]byte(`{"event":"x"}`))
+	expected := hexHMAC("secret", payload)
+	if signature != expected {
+		t.Errorf("Expected signature %s, got %s", expected, signature)
+	}
+	if timestamp != "1700000000" {
+		t.Errorf("Expected timestamp 1700000000, got %s", timestamp)
+	}
+
+	if NewHMACSigner(nil, "").header != DefaultHMACSignatureHeader {
+		t.Error("Expected default signature header")
+	}
+}
+
+type failingSigner struct{}
+
+func (failingSigner) Sign(*http.Request, []byte) error { return errors.New("no credentials") }
+
+func TestClientBuilder_WithSigner_Error(t *testing.T) {
+	cli := NewClientBuilder().WithSigner(failingSigner{}).Build()
+
+	_, err := cli.Get(context.TODO(), "http://example.com")
+	if err == nil || !strings.Contains(err.Error(), "signer error") {
+		t.Errorf("Expected signer error, got %v", err)
+	}
+}
+
+func hexHMAC(secret, payload string) string {
+	return hex.EncodeToString(hmacSHA256([]byte(secret), payload))
+}
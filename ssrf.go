@@ -0,0 +1,234 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrHostBlocked is returned, wrapped, for requests to a destination blocked
+// by WithBlockedCIDRs.
+var ErrHostBlocked = errors.New("destination host is blocked")
+
+// DefaultBlockedCIDRs are the ranges that shouldn't be reachable through user
+// supplied URLs: loopback, private, shared, link-local (including the
+// 169.254.169.254 cloud metadata endpoint), multicast and reserved addresses.
+var DefaultBlockedCIDRs = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// hostGuard refuses connections to blocked addresses unless the host was
+// explicitly allowed.
+type hostGuard struct {
+	allowedHosts    []string
+	allowedNames    []string
+	allowedPrefixes []netip.Prefix
+	blocked         []netip.Prefix
+}
+
+func newHostGuard(allowed []string, blocked []netip.Prefix) *hostGuard {
+	g := &hostGuard{allowedHosts: allowed, blocked: blocked}
+	for _, pattern := range allowed {
+		if prefix, err := netip.ParsePrefix(pattern); err == nil {
+			g.allowedPrefixes = append(g.allowedPrefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(pattern); err == nil {
+			g.allowedPrefixes = append(g.allowedPrefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else {
+			g.allowedNames = append(g.allowedNames, normalizeHostname(pattern))
+		}
+	}
+	return g
+}
+
+func normalizeHostname(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// allowed reports whether host, a name or IP address, is exempt from the
+// blocked ranges.
+func (g *hostGuard) allowed(host string) bool {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return containsAddr(g.allowedPrefixes, addr)
+	}
+	host = normalizeHostname(host)
+	for _, pattern := range g.allowedNames {
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// check refuses a request URL whose host is a blocked IP address before
+// anything is dialed. Names are checked once they are resolved.
+func (g *hostGuard) check(host string) error {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	addr, err := netip.ParseAddr(strings.Trim(host, "[]"))
+	if err != nil || g.allowed(addr.String()) || !containsAddr(g.blocked, addr) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrHostBlocked, host)
+}
+
+// guardExemptKey marks, in the context of a dial, that the dialed host is
+// exempt from the blocked ranges.
+type guardExemptKey struct{}
+
+// dialerOption installs the check of every address the transport connects
+// to, before the connection is made. It is applied before the DNS cache and
+// dual-stack options, so it sees the resolved addresses they dial. With the
+// default dialer the check runs in net.Dialer's ControlContext, after DNS
+// resolution and for every address tried. replace says the transport's
+// dialer is the default one.
+func (g *hostGuard) dialerOption(replace bool) transportOption {
+	return func(t *http.Transport) {
+		if replace || t.DialContext == nil {
+			dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, ControlContext: g.control}
+			t.DialContext = dialer.DialContext
+			return
+		}
+		t.DialContext = g.checked(t.DialContext)
+	}
+}
+
+// transportOption marks the dials to allowed hosts, which only it sees by
+// name, and checks the targets of proxied requests. It is applied after the
+// DNS cache and dual-stack options. Together with dialerOption, neither DNS
+// rebinding nor redirects can reach a blocked address.
+func (g *hostGuard) transportOption() transportOption {
+	return func(t *http.Transport) {
+		if dial := t.DialContext; dial != nil {
+			t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+				host, _, _ := net.SplitHostPort(addr)
+				return dial(context.WithValue(ctx, guardExemptKey{}, g.allowed(host)), network, addr)
+			}
+		}
+		if proxy := t.Proxy; proxy != nil {
+			t.Proxy = func(req *http.Request) (*url.URL, error) {
+				proxyURL, err := proxy(req)
+				if err != nil || proxyURL == nil {
+					return proxyURL, err
+				}
+				// The proxy connects to the target, so check it here
+				if err := g.checkTarget(req.Context(), req.URL.Hostname()); err != nil {
+					return nil, err
+				}
+				return proxyURL, nil
+			}
+		}
+	}
+}
+
+func exempt(ctx context.Context) bool {
+	allowed, _ := ctx.Value(guardExemptKey{}).(bool)
+	return allowed
+}
+
+// control refuses to connect to address, an IP address and port.
+func (g *hostGuard) control(ctx context.Context, network, address string, _ syscall.RawConn) error {
+	if exempt(ctx) {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil || !g.blockedAddr(addrPort.Addr()) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrHostBlocked, addrPort.Addr().Unmap())
+}
+
+// checked guards a custom dialer. IP addresses are checked before dialing;
+// a name it resolves itself can only be checked once connected.
+func (g *hostGuard) checked(next DialContextFunc) DialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if exempt(ctx) {
+			return next(ctx, network, addr)
+		}
+		host, _, _ := net.SplitHostPort(addr)
+		if ip, err := netip.ParseAddr(host); err == nil && g.blockedAddr(ip) {
+			return nil, fmt.Errorf("%w: %s", ErrHostBlocked, ip.Unmap())
+		}
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		tcp, ok := conn.RemoteAddr().(*net.TCPAddr)
+		if !ok {
+			// Unix sockets and other custom transports have no IP to check
+			return conn, nil
+		}
+		if remote := tcp.AddrPort().Addr(); g.blockedAddr(remote) {
+			conn.Close()
+			return nil, fmt.Errorf("%w: %s resolves to %s", ErrHostBlocked, host, remote.Unmap())
+		}
+		return conn, nil
+	}
+}
+
+// checkTarget refuses a proxied request to host if it is, or resolves to, a
+// blocked address. The proxy resolves the name again, so it should block
+// internal ranges itself too.
+func (g *hostGuard) checkTarget(ctx context.Context, host string) error {
+	if g.allowed(host) {
+		return nil
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		if g.blockedAddr(ip) {
+			return fmt.Errorf("%w: %s", ErrHostBlocked, host)
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("failed to check proxied host %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if g.blockedAddr(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrHostBlocked, host, addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// blockedAddr reports whether addr is in a blocked range and not allowed.
+func (g *hostGuard) blockedAddr(addr netip.Addr) bool {
+	return containsAddr(g.blocked, addr) && !containsAddr(g.allowedPrefixes, addr)
+}
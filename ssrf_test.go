@@ -0,0 +1,172 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithBlockedCIDRs(t *testing.T) {
+	var hits atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer target.Close()
+	port := target.URL[strings.LastIndex(target.URL, ":")+1:]
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer redirector.Close()
+	redirectorPort := redirector.URL[strings.LastIndex(redirector.URL, ":")+1:]
+
+	testCases := []struct {
+		name    string
+		allowed []string
+		url     string
+		blocked bool
+	}{
+		{name: "IP address", url: target.URL, blocked: true},
+		{name: "name resolving to a blocked address", url: "http://localhost:" + port, blocked: true},
+		{name: "allowed name", allowed: []string{"LOCALHOST"}, url: "http://localhost:" + port},
+		{name: "allowed range", allowed: []string{"127.0.0.0/8"}, url: target.URL},
+		{name: "redirect to a blocked address", allowed: []string{"localhost"}, url: "http://localhost:" + redirectorPort, blocked: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hits.Store(0)
+			cli := NewClientBuilder().
+				WithBlockedCIDRs(DefaultBlockedCIDRs...).
+				WithAllowedHosts(tc.allowed...).
+				Build()
+			_, err := cli.Get(context.Background(), tc.url)
+			if tc.blocked {
+				if !errors.Is(err, ErrHostBlocked) {
+					t.Errorf("Get() error = %v, want ErrHostBlocked", err)
+				}
+				if hits.Load() != 0 {
+					t.Error("the blocked server received the request")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if hits.Load() != 1 {
+				t.Errorf("hits = %d, want 1", hits.Load())
+			}
+		})
+	}
+}
+
+func TestHostGuard_Allowed(t *testing.T) {
+	guard := newHostGuard([]string{"api.example.com", "*.internal.example.com", "10.1.0.0/16", "fd00::1"}, DefaultBlockedCIDRs)
+	testCases := []struct {
+		host string
+		want bool
+	}{
+		{"api.example.com", true},
+		{"API.example.com.", true},
+		{"www.example.com", false},
+		{"billing.internal.example.com", true},
+		{"internal.example.com", false},
+		{"10.1.2.3", true},
+		{"10.2.0.1", false},
+		{"fd00::1", true},
+		{"169.254.169.254", false},
+	}
+	for _, tc := range testCases {
+		if got := guard.allowed(tc.host); got != tc.want {
+			t.Errorf("allowed(%q) = %v, want %v", tc.host, got, tc.want)
+		}
+	}
+
+	if err := guard.check("169.254.169.254"); !errors.Is(err, ErrHostBlocked) {
+		t.Errorf("check(metadata endpoint) error = %v, want ErrHostBlocked", err)
+	}
+	if err := guard.check("[::ffff:127.0.0.1]:8080"); !errors.Is(err, ErrHostBlocked) {
+		t.Errorf("check(IPv4-mapped loopback) error = %v, want ErrHostBlocked", err)
+	}
+	if err := guard.check("93.184.216.34"); err != nil {
+		t.Errorf("check(public address) error = %v", err)
+	}
+	if containsAddr(DefaultBlockedCIDRs, netip.MustParseAddr("8.8.8.8")) {
+		t.Error("DefaultBlockedCIDRs block a public address")
+	}
+}
+
+func TestWithBlockedCIDRs_NoConnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	var accepted atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	for _, name := range []string{"default dialer", "DNS cache", "dual-stack"} {
+		t.Run(name, func(t *testing.T) {
+			builder := NewClientBuilder().WithBlockedCIDRs(DefaultBlockedCIDRs...)
+			switch name {
+			case "DNS cache":
+				builder = builder.WithDNSCache(time.Minute)
+			case "dual-stack":
+				builder = builder.WithDualStack(DualStack{Preference: PreferIPv4})
+			}
+			_, err := builder.Build().Get(context.Background(), fmt.Sprintf("http://localhost:%d", port))
+			if !errors.Is(err, ErrHostBlocked) {
+				t.Errorf("Get() error = %v, want ErrHostBlocked", err)
+			}
+			if got := accepted.Load(); got != 0 {
+				t.Errorf("accepted %d connections, want none", got)
+			}
+		})
+	}
+}
+
+func TestWithBlockedCIDRs_Proxy(t *testing.T) {
+	var proxied atomic.Int32
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	cli := NewClientBuilder().
+		WithBlockedCIDRs(DefaultBlockedCIDRs...).
+		WithAllowedHosts(proxyURL.Hostname()).
+		WithProxy(http.ProxyURL(proxyURL)).
+		Build()
+
+	if _, err := cli.Get(context.Background(), "http://169.254.169.254/latest/meta-data/"); !errors.Is(err, ErrHostBlocked) {
+		t.Errorf("Get(metadata endpoint) error = %v, want ErrHostBlocked", err)
+	}
+	if got := proxied.Load(); got != 0 {
+		t.Errorf("proxy received %d requests, want none", got)
+	}
+	if _, err := cli.Get(context.Background(), "http://93.184.216.34/"); err != nil {
+		t.Fatalf("Get(public address) error = %v", err)
+	}
+	if got := proxied.Load(); got != 1 {
+		t.Errorf("proxy received %d requests, want 1", got)
+	}
+}
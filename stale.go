@@ -0,0 +1,61 @@
+package reqwest
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"syscall"
+)
+
+// staleEndpoints remembers hosts that have answered before, so failures that
+// only happen once an endpoint was moved, like NXDOMAIN for a name that used
+// to resolve or a refused connection to a former address, can be told apart
+// from hosts that were never reachable.
+type staleEndpoints struct {
+	mu   sync.Mutex
+	good map[string]bool
+}
+
+func newStaleEndpoints() *staleEndpoints {
+	return &staleEndpoints{good: make(map[string]bool)}
+}
+
+// observe records the outcome of an attempt against host and reports whether
+// it failed because a previously good endpoint went stale. A stale host must
+// answer again before it is retried transparently a second time.
+func (s *staleEndpoints) observe(host string, err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.good[host] = true
+		return false
+	}
+	if !s.good[host] || !isStaleEndpointErr(err) {
+		return false
+	}
+	delete(s.good, host)
+	return true
+}
+
+// isStaleEndpointErr reports whether err means the request never reached a
+// server because the name or address it was sent to is gone. Nothing was
+// sent, so the request can be replayed whatever its method.
+func isStaleEndpointErr(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// forgetEndpoint drops cached lookups and pooled connections for host, so the
+// next attempt resolves and dials it afresh.
+func (c *HTTPClient) forgetEndpoint(host string) {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	c.InvalidateDNS(host)
+	if c.httpClient != nil {
+		c.httpClient.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,86 @@
+package reqwest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+)
+
+func TestStaleEndpointRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	testCases := []struct {
+		name string
+		err  error
+	}{
+		{name: "connection refused", err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}},
+		{name: "NXDOMAIN", err: &net.DNSError{Err: "no such host", Name: "api.internal", IsNotFound: true}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var dials, failures atomic.Int32
+			dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+				dials.Add(1)
+				if failures.Load() > 0 {
+					failures.Add(-1)
+					return nil, tc.err
+				}
+				return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+			}
+			cli := NewClientBuilder().
+				WithDialContext(dial).
+				WithStaleEndpointRetry().
+				Build()
+
+			if _, err := cli.Get(context.Background(), "http://api.internal/"); err != nil {
+				t.Fatalf("first Get() error = %v", err)
+			}
+
+			// The endpoint moves: the first dial after the cutover fails
+			failures.Store(1)
+			resp, err := cli.Get(context.Background(), "http://api.internal/")
+			if err != nil {
+				t.Fatalf("Get() after cutover error = %v", err)
+			}
+			if resp.RetryAttempts() != 0 {
+				t.Errorf("RetryAttempts() = %d, want 0", resp.RetryAttempts())
+			}
+			if got := dials.Load(); got != 3 {
+				t.Errorf("dials = %d, want 3", got)
+			}
+
+			// Only one transparent retry per failure
+			failures.Store(2)
+			if _, err := cli.Get(context.Background(), "http://api.internal/"); err == nil {
+				t.Fatal("Get() error = nil, want error after the retry fails too")
+			}
+		})
+	}
+
+	t.Run("never reached host is not retried", func(t *testing.T) {
+		var dials atomic.Int32
+		cli := NewClientBuilder().
+			WithDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+				dials.Add(1)
+				return nil, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+			}).
+			WithStaleEndpointRetry().
+			Build()
+
+		if _, err := cli.Get(context.Background(), "http://api.internal/"); err == nil {
+			t.Fatal("Get() error = nil, want connection refused")
+		}
+		if got := dials.Load(); got != 1 {
+			t.Errorf("dials = %d, want 1", got)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package reqwest
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StateStore persists resilience state (circuit breakers, rate limits) so
+// horizontally scaled replicas can share one view of upstream health, e.g.
+// backed by Redis. Load returns nil and no error for a missing key. Values are
+// small JSON documents.
+type StateStore interface {
+	Load(ctx context.Context, key string) ([]byte, error)
+	Store(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// MemoryStateStore is an in-process StateStore. It is the default, keeping
+// state local to one client.
+type MemoryStateStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	clock   Clock
+}
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryStateStore returns an empty in-process store.
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{entries: make(map[string]memoryEntry)}
+}
+
+func (s *MemoryStateStore) Load(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok || (!entry.expires.IsZero() && orSystemClock(s.clock).Now().After(entry.expires)) {
+		return nil, nil
+	}
+	return entry.value, nil
+}
+
+func (s *MemoryStateStore) Store(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := memoryEntry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expires = orSystemClock(s.clock).Now().Add(ttl)
+	}
+	s.entries[key] = entry
+	return nil
+}
@@ -0,0 +1,247 @@
+package reqwest
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// StatsReporter reports how a client uses its connection pool.
+type StatsReporter interface {
+	// Stats returns a snapshot of the pool, which is empty unless the
+	// client was built with WithConnectionStats.
+	Stats() ClientStats
+}
+
+// ClientStats is a snapshot of a client's connection pool, for tuning pool
+// settings such as MaxIdleConnsPerHost from data.
+type ClientStats struct {
+	OpenConnections int `json:"open_connections"`
+	IdleConnections int `json:"idle_connections"`
+	// InFlight counts requests sent and not finished, including those still
+	// waiting for a connection.
+	InFlight int `json:"in_flight"`
+	// Hosts breaks the numbers down by dialed address ("host:port"), the
+	// proxy's when one is used.
+	Hosts map[string]HostStats `json:"hosts,omitempty"`
+}
+
+// HostStats are the ClientStats of one address.
+type HostStats struct {
+	OpenConnections int `json:"open_connections"`
+	IdleConnections int `json:"idle_connections"`
+	// InFlight counts requests holding a connection to the address.
+	InFlight int `json:"in_flight"`
+	// NewConnections and ReusedConnections count the requests that got a
+	// freshly dialed and a pooled connection.
+	NewConnections    int64 `json:"new_connections"`
+	ReusedConnections int64 `json:"reused_connections"`
+}
+
+// connStats instruments a transport: its dialer, to see connections open and
+// close, and its requests, to see which connection each one uses and when it
+// is done with it.
+type connStats struct {
+	mu       sync.Mutex
+	conns    map[*statsConn]struct{}
+	inflight int
+	hosts    map[string]*HostStats
+}
+
+func newConnStats() *connStats {
+	return &connStats{conns: make(map[*statsConn]struct{}), hosts: make(map[string]*HostStats)}
+}
+
+// host returns the counters of addr. The caller holds s.mu.
+func (s *connStats) host(addr string) *HostStats {
+	host := s.hosts[addr]
+	if host == nil {
+		host = &HostStats{}
+		s.hosts[addr] = host
+	}
+	return host
+}
+
+// transportOption wraps the dialer. Like connLimits, it is applied after the
+// other options so it wraps a custom dialer too.
+func (s *connStats) transportOption() transportOption {
+	return func(t *http.Transport) {
+		dial := t.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+		}
+		t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dial(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			counted := &statsConn{Conn: conn, addr: addr, stats: s}
+			s.mu.Lock()
+			s.conns[counted] = struct{}{}
+			s.host(addr)
+			s.mu.Unlock()
+			return counted, nil
+		}
+	}
+}
+
+// wrapper tracks each request from the start until its response body is
+// read or closed.
+func (s *connStats) wrapper() RoundTripperWrapper {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			use := &connUse{stats: s}
+			s.mu.Lock()
+			s.inflight++
+			s.mu.Unlock()
+			trace := &httptrace.ClientTrace{GotConn: use.got}
+			resp, err := next.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+			if err != nil {
+				use.done()
+				return nil, err
+			}
+			if resp.StatusCode == http.StatusSwitchingProtocols {
+				// The connection now belongs to the caller, e.g. a WebSocket,
+				// and stays busy until closed
+				s.mu.Lock()
+				use.conn = nil
+				s.mu.Unlock()
+				use.done()
+				return resp, nil
+			}
+			resp.Body = &statsBody{ReadCloser: resp.Body, use: use}
+			return resp, nil
+		})
+	}
+}
+
+// connUse is one request's use of a connection.
+type connUse struct {
+	stats *connStats
+	conn  *statsConn
+	once  sync.Once
+}
+
+func (u *connUse) got(info httptrace.GotConnInfo) {
+	conn := asStatsConn(info.Conn)
+	if conn == nil {
+		return
+	}
+	s := u.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	host := s.host(conn.addr)
+	if info.Reused {
+		host.ReusedConnections++
+	} else {
+		host.NewConnections++
+	}
+	conn.active++
+	u.conn = conn
+}
+
+func (u *connUse) done() {
+	u.once.Do(func() {
+		s := u.stats
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.inflight--
+		if u.conn != nil {
+			u.conn.active--
+		}
+	})
+}
+
+func (s *connStats) snapshot() ClientStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := ClientStats{InFlight: s.inflight, Hosts: make(map[string]HostStats, len(s.hosts))}
+	for addr, host := range s.hosts {
+		stats.Hosts[addr] = HostStats{NewConnections: host.NewConnections, ReusedConnections: host.ReusedConnections}
+	}
+	for conn := range s.conns {
+		host := stats.Hosts[conn.addr]
+		host.OpenConnections++
+		host.InFlight += conn.active
+		if conn.active == 0 {
+			host.IdleConnections++
+		}
+		stats.Hosts[conn.addr] = host
+	}
+	for _, host := range stats.Hosts {
+		stats.OpenConnections += host.OpenConnections
+		stats.IdleConnections += host.IdleConnections
+	}
+	return stats
+}
+
+// statsConn is a connection counted by connStats. active, guarded by the
+// stats' mutex, counts the requests using it: HTTP/2 multiplexes several.
+type statsConn struct {
+	net.Conn
+	addr   string
+	stats  *connStats
+	active int
+	once   sync.Once
+}
+
+func (c *statsConn) Close() error {
+	c.once.Do(func() {
+		c.stats.mu.Lock()
+		delete(c.stats.conns, c)
+		c.stats.mu.Unlock()
+	})
+	return c.Conn.Close()
+}
+
+// NetConn returns the wrapped connection, so asLimitedConn sees through it.
+func (c *statsConn) NetConn() net.Conn {
+	return c.Conn
+}
+
+// asStatsConn finds the statsConn under conn, looking through TLS.
+func asStatsConn(conn net.Conn) *statsConn {
+	for conn != nil {
+		if counted, ok := conn.(*statsConn); ok {
+			return counted
+		}
+		wrapper, ok := conn.(interface{ NetConn() net.Conn })
+		if !ok {
+			return nil
+		}
+		conn = wrapper.NetConn()
+	}
+	return nil
+}
+
+// statsBody ends the request's use of its connection once the body is read
+// to the end or closed.
+type statsBody struct {
+	io.ReadCloser
+	use *connUse
+}
+
+func (b *statsBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.use.done()
+	}
+	return n, err
+}
+
+func (b *statsBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.use.done()
+	return err
+}
+
+func (c *HTTPClient) Stats() ClientStats {
+	if c.stats == nil {
+		return ClientStats{}
+	}
+	return c.stats.snapshot()
+}
@@ -0,0 +1,86 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientBuilder_WithConnectionStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "http://")
+
+	t.Run("counts connections and reuse", func(t *testing.T) {
+		cli := NewClientBuilder().WithBaseURL(server.URL).WithConnectionStats().Build()
+		for range 3 {
+			resp, err := cli.Get(context.Background(), "/")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			resp.Bytes()
+		}
+		stats := cli.Stats()
+		host := stats.Hosts[addr]
+		if stats.OpenConnections != 1 || stats.IdleConnections != 1 || stats.InFlight != 0 {
+			t.Errorf("Stats() = %+v, want one idle connection", stats)
+		}
+		if host.NewConnections != 1 || host.ReusedConnections != 2 || host.OpenConnections != 1 {
+			t.Errorf("Stats().Hosts[%s] = %+v, want 1 new and 2 reused", addr, host)
+		}
+		if !cli.Policy().ConnectionStats {
+			t.Error("Policy().ConnectionStats = false, want true")
+		}
+
+		if err := cli.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		if stats := cli.Stats(); stats.OpenConnections != 0 {
+			t.Errorf("Stats().OpenConnections = %d after Close, want 0", stats.OpenConnections)
+		}
+	})
+
+	t.Run("busy until the body is done", func(t *testing.T) {
+		cli := NewClientBuilder().WithBaseURL(server.URL).WithConnectionStats().Build()
+		defer cli.Close()
+		resp, err := cli.Get(context.Background(), "/")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if stats := cli.Stats(); stats.InFlight != 1 || stats.IdleConnections != 0 || stats.Hosts[addr].InFlight != 1 {
+			t.Errorf("Stats() = %+v with an unread body, want the request in flight", stats)
+		}
+		resp.Body().Close()
+		if stats := cli.Stats(); stats.InFlight != 0 || stats.Hosts[addr].InFlight != 0 {
+			t.Errorf("Stats() = %+v after Close, want nothing in flight", stats)
+		}
+	})
+
+	t.Run("derived clients share the pool", func(t *testing.T) {
+		cli := NewClientBuilder().WithBaseURL(server.URL).WithConnectionStats().Build()
+		defer cli.Close()
+		derived := cli.Derive().WithTimeout(0).Build()
+		resp, err := derived.Get(context.Background(), "/")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Bytes()
+		if stats := cli.Stats(); stats.Hosts[addr].NewConnections != 1 {
+			t.Errorf("Stats() = %+v, want the derived client's request", stats)
+		}
+	})
+
+	t.Run("off", func(t *testing.T) {
+		cli := NewClientBuilder().WithBaseURL(server.URL).Build()
+		if _, err := cli.Get(context.Background(), "/"); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if stats := cli.Stats(); stats.OpenConnections != 0 || stats.Hosts != nil {
+			t.Errorf("Stats() = %+v, want it empty", stats)
+		}
+	})
+}
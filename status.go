@@ -0,0 +1,61 @@
+package reqwest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// MaxErrorBodySnippet is how much of an error response's body an HTTPError
+// keeps.
+const MaxErrorBodySnippet = 1024
+
+// HTTPError is returned by clients built with WithErrorOnNon2xx for responses
+// whose status is not 2xx, and for error responses a WithErrorDecoder decoder
+// turned into Err. The response body is closed; Body keeps its first
+// MaxErrorBodySnippet bytes for logging.
+type HTTPError struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Err        error
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Err != nil {
+		return msg + ": " + e.Err.Error()
+	}
+	if snippet := strings.TrimSpace(string(e.Body)); snippet != "" {
+		msg += ": " + snippet
+	}
+	return msg
+}
+
+func (e *HTTPError) Unwrap() error { return e.Err }
+
+// IsSuccess reports whether the status is 2xx.
+func (r *Response) IsSuccess() bool {
+	return r.statusCode >= 200 && r.statusCode < 300
+}
+
+// IsClientError reports whether the status is 4xx.
+func (r *Response) IsClientError() bool {
+	return r.statusCode >= 400 && r.statusCode < 500
+}
+
+// IsServerError reports whether the status is 5xx.
+func (r *Response) IsServerError() bool {
+	return r.statusCode >= 500 && r.statusCode < 600
+}
+
+// httpError reads a snippet of resp's body and closes it.
+func httpError(resp *Response) *HTTPError {
+	err := &HTTPError{StatusCode: resp.statusCode, Header: resp.header, Err: resp.decodedErr}
+	if resp.body != nil {
+		err.Body, _ = io.ReadAll(io.LimitReader(resp.body, MaxErrorBodySnippet))
+		resp.body.Close()
+	}
+	return err
+}
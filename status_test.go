@@ -0,0 +1,108 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResponse_StatusClass(t *testing.T) {
+	testCases := []struct {
+		status                        int
+		success, clientErr, serverErr bool
+	}{
+		{status: http.StatusOK, success: true},
+		{status: http.StatusNoContent, success: true},
+		{status: http.StatusNotModified},
+		{status: http.StatusNotFound, clientErr: true},
+		{status: http.StatusServiceUnavailable, serverErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			resp := NewResponse(tc.status, nil, nil)
+			if got := resp.IsSuccess(); got != tc.success {
+				t.Errorf("IsSuccess() = %v, want %v", got, tc.success)
+			}
+			if got := resp.IsClientError(); got != tc.clientErr {
+				t.Errorf("IsClientError() = %v, want %v", got, tc.clientErr)
+			}
+			if got := resp.IsServerError(); got != tc.serverErr {
+				t.Errorf("IsServerError() = %v, want %v", got, tc.serverErr)
+			}
+		})
+	}
+}
+
+func TestWithErrorOnNon2xx(t *testing.T) {
+	t.Run("non-2xx fails with HTTPError", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Error-Code", "missing")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"no such item"}` + strings.Repeat(" ", 2*MaxErrorBodySnippet)))
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().WithErrorOnNon2xx().Build()
+		resp, err := cli.Get(context.Background(), server.URL)
+		if resp != nil {
+			t.Errorf("Get() response = %v, want nil", resp)
+		}
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) {
+			t.Fatalf("Get() error = %v, want *HTTPError", err)
+		}
+		if httpErr.StatusCode != http.StatusNotFound {
+			t.Errorf("StatusCode = %d, want %d", httpErr.StatusCode, http.StatusNotFound)
+		}
+		if got := httpErr.Header.Get("X-Error-Code"); got != "missing" {
+			t.Errorf("Header X-Error-Code = %q, want %q", got, "missing")
+		}
+		if len(httpErr.Body) != MaxErrorBodySnippet {
+			t.Errorf("len(Body) = %d, want %d", len(httpErr.Body), MaxErrorBodySnippet)
+		}
+		if want := `unexpected status 404 Not Found: {"error":"no such item"}`; err.Error() != want {
+			t.Errorf("Error() = %q, want %q", err.Error(), want)
+		}
+	})
+
+	t.Run("error after retries", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().WithRetryConfig(fastRetryConfig(2)).WithErrorOnNon2xx().Build()
+		resp, err := cli.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if !resp.IsSuccess() || resp.RetryAttempts() != 1 {
+			t.Errorf("status = %d after %d retries, want 200 after 1", resp.StatusCode(), resp.RetryAttempts())
+		}
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		resp, err := NewClientBuilder().Build().Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if !resp.IsClientError() {
+			t.Errorf("IsClientError() = false for status %d", resp.StatusCode())
+		}
+	})
+}
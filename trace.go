@@ -0,0 +1,98 @@
+package reqwest
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Trace breaks down where the time of a logical request went.
+type Trace struct {
+	// Route is the path template of the request, or its normalized URL,
+	// for labelling spans without a series per resource.
+	Route    string         `json:"route"`
+	Attempts []AttemptTrace `json:"attempts"`
+	// Total covers all attempts and backoff waits, like
+	// Response.TotalDuration.
+	Total time.Duration `json:"total"`
+}
+
+// AttemptTrace holds the timings of a single attempt. Phases that did not
+// happen, such as DNS and connect on a reused connection, are zero.
+type AttemptTrace struct {
+	Number          int           `json:"number"`
+	DNSLookup       time.Duration `json:"dns_lookup"`
+	Connect         time.Duration `json:"connect"`
+	TLSHandshake    time.Duration `json:"tls_handshake"`
+	TimeToFirstByte time.Duration `json:"time_to_first_byte"`
+	Total           time.Duration `json:"total"`
+	ConnReused      bool          `json:"conn_reused"`
+}
+
+// attemptTracer records an attempt's timings from httptrace hooks, which
+// may run on the transport's dialing goroutines.
+type attemptTracer struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	trace        AttemptTrace
+}
+
+// traceAttempt returns a context that records the timings of attempt
+// number. Hooks already on ctx keep being called.
+func traceAttempt(ctx context.Context, number int) (context.Context, *attemptTracer) {
+	t := &attemptTracer{start: time.Now(), trace: AttemptTrace{Number: number}}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.since(&t.dnsStart, &t.trace.DNSLookup) },
+		ConnectStart: func(_, _ string) {
+			// Dialing several addresses in parallel times the first attempt
+			t.mu.Lock()
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+			t.mu.Unlock()
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				t.since(&t.connectStart, &t.trace.Connect)
+			}
+		},
+		TLSHandshakeStart: func() { t.mark(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.since(&t.tlsStart, &t.trace.TLSHandshake) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.trace.ConnReused = info.Reused
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() { t.since(&t.start, &t.trace.TimeToFirstByte) },
+	}), t
+}
+
+func (t *attemptTracer) mark(at *time.Time) {
+	t.mu.Lock()
+	*at = time.Now()
+	t.mu.Unlock()
+}
+
+// since stores the time elapsed since start in d, keeping the first value.
+func (t *attemptTracer) since(start *time.Time, d *time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !start.IsZero() && *d == 0 {
+		*d = time.Since(*start)
+	}
+}
+
+// finish returns the attempt's timings once its response headers arrived or
+// it failed.
+func (t *attemptTracer) finish() AttemptTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.trace.Total = time.Since(t.start)
+	return t.trace
+}
@@ -0,0 +1,96 @@
+package reqwest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResponse_Trace(t *testing.T) {
+	t.Run("TLS attempt phases and connection reuse", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().WithTransport(server.Client().Transport).Build()
+		get := func() Trace {
+			t.Helper()
+			resp, err := cli.Get(context.TODO(), server.URL)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			resp.Body().Close()
+			return resp.Trace()
+		}
+
+		first := get()
+		if len(first.Attempts) != 1 {
+			t.Fatalf("Expected 1 attempt, got %+v", first)
+		}
+		attempt := first.Attempts[0]
+		if attempt.Number != 1 || attempt.Connect <= 0 || attempt.TLSHandshake <= 0 || attempt.ConnReused {
+			t.Errorf("Expected connect and TLS timings on a new connection, got %+v", attempt)
+		}
+		if attempt.TimeToFirstByte <= 0 || attempt.Total < attempt.TimeToFirstByte || first.Total < attempt.Total {
+			t.Errorf("Expected TTFB <= attempt total <= total, got %+v", first)
+		}
+
+		reused := get().Attempts[0]
+		if !reused.ConnReused || reused.Connect != 0 || reused.TLSHandshake != 0 {
+			t.Errorf("Expected no connect or TLS timings on a reused connection, got %+v", reused)
+		}
+	})
+
+	t.Run("one entry per retry attempt", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		cli := NewClientBuilder().
+			WithRetryConfig(NewRetryConfigBuilder().
+				WithBackoffStrategy(NewFixedBackoffBuilder().WithDelay(time.Millisecond).Build()).
+				Build()).
+			Build()
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		trace := resp.Trace()
+		if len(trace.Attempts) != 3 {
+			t.Fatalf("Expected 3 attempts, got %d", len(trace.Attempts))
+		}
+		for i, attempt := range trace.Attempts {
+			if attempt.Number != i+1 {
+				t.Errorf("Expected attempt %d, got %d", i+1, attempt.Number)
+			}
+		}
+	})
+
+	t.Run("DNS lookups through the DNS cache", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+		_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+		cli := NewClientBuilder().WithDNSCache(time.Minute).Build()
+		resp, err := cli.Get(context.TODO(), "http://localhost:"+port)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		resp.Body().Close()
+
+		if attempt := resp.Trace().Attempts[0]; attempt.DNSLookup <= 0 {
+			t.Errorf("Expected a DNS lookup timing, got %+v", attempt)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package reqwest
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// HTTPVersion selects the HTTP protocol versions the client may use.
+type HTTPVersion int
+
+const (
+	// HTTPVersionAuto negotiates HTTP/2 over TLS and falls back to HTTP/1.1.
+	HTTPVersionAuto HTTPVersion = iota
+	// HTTPVersion1 forces HTTP/1.1 even when the server offers HTTP/2.
+	HTTPVersion1
+	// HTTPVersion2 requires HTTP/2 negotiated over TLS.
+	HTTPVersion2
+	// HTTPVersion2PriorKnowledge speaks HTTP/2 over cleartext (h2c) without an
+	// upgrade, and HTTP/2 over TLS for https URLs.
+	HTTPVersion2PriorKnowledge
+)
+
+type transportOption func(*http.Transport)
+
+func withHTTPVersion(version HTTPVersion) transportOption {
+	return func(t *http.Transport) {
+		protocols := new(http.Protocols)
+		switch version {
+		case HTTPVersion1:
+			protocols.SetHTTP1(true)
+			withoutALPN(t, "h2")
+		case HTTPVersion2:
+			protocols.SetHTTP2(true)
+		case HTTPVersion2PriorKnowledge:
+			protocols.SetHTTP2(true)
+			protocols.SetUnencryptedHTTP2(true)
+		default:
+			t.Protocols = nil
+			return
+		}
+		t.Protocols = protocols
+	}
+}
+
+// DialContextFunc establishes the network connection for a request.
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+func withDialContext(dial DialContextFunc) transportOption {
+	return func(t *http.Transport) {
+		t.DialContext = dial
+	}
+}
+
+func withTLSConfig(config *tls.Config) transportOption {
+	return func(t *http.Transport) {
+		t.TLSClientConfig = config.Clone()
+	}
+}
+
+func withProxy(proxy func(*http.Request) (*url.URL, error)) transportOption {
+	return func(t *http.Transport) {
+		t.Proxy = proxy
+	}
+}
+
+// withTimeouts sets the non-zero transport timeouts of timeouts. The
+// connection lifetime is enforced by the client, not the transport.
+func withTimeouts(timeouts Timeouts) transportOption {
+	return func(t *http.Transport) {
+		if timeouts.TLSHandshake > 0 {
+			t.TLSHandshakeTimeout = timeouts.TLSHandshake
+		}
+		if timeouts.ResponseHeader > 0 {
+			t.ResponseHeaderTimeout = timeouts.ResponseHeader
+		}
+		if timeouts.ExpectContinue > 0 {
+			t.ExpectContinueTimeout = timeouts.ExpectContinue
+		}
+		if timeouts.IdleConnection > 0 {
+			t.IdleConnTimeout = timeouts.IdleConnection
+		}
+	}
+}
+
+// unixSocketDialer ignores the address derived from the request URL and
+// always connects to the socket at path.
+func unixSocketDialer(path string) DialContextFunc {
+	dialer := &net.Dialer{}
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", path)
+	}
+}
+
+// buildHTTPClient returns the shared default client unless the builder
+// customized the transport, in which case a dedicated transport is created
+// so settings never leak into http.DefaultTransport.
+func buildHTTPClient(roundTripper http.RoundTripper, options []transportOption, wrappers []RoundTripperWrapper) *http.Client {
+	if roundTripper == nil && len(options) == 0 && len(wrappers) == 0 {
+		return http.DefaultClient
+	}
+	if roundTripper == nil {
+		roundTripper = http.DefaultTransport
+	}
+	if transport, ok := roundTripper.(*http.Transport); ok && len(options) > 0 {
+		transport = transport.Clone()
+		for _, option := range options {
+			option(transport)
+		}
+		roundTripper = transport
+	}
+	base := roundTripper
+	for _, wrap := range wrappers {
+		roundTripper = wrap(roundTripper)
+	}
+	if closer, ok := base.(idleCloser); ok && len(wrappers) > 0 {
+		// Wrappers hide the transport's pool from http.Client
+		roundTripper = closeIdleVia{RoundTripper: roundTripper, closer: closer}
+	}
+	return &http.Client{Transport: roundTripper}
+}
+
+type idleCloser interface {
+	CloseIdleConnections()
+}
+
+// closeIdleVia is a wrapped transport that still closes the idle connections
+// of the transport it wraps.
+type closeIdleVia struct {
+	http.RoundTripper
+	closer idleCloser
+}
+
+func (t closeIdleVia) CloseIdleConnections() {
+	t.closer.CloseIdleConnections()
+}
+
+// withoutALPN removes a protocol from explicitly configured ALPN values, which
+// would otherwise let the server negotiate a protocol the client refuses.
+func withoutALPN(t *http.Transport, proto string) {
+	if t.TLSClientConfig == nil || !slices.Contains(t.TLSClientConfig.NextProtos, proto) {
+		return
+	}
+	t.TLSClientConfig = t.TLSClientConfig.Clone()
+	t.TLSClientConfig.NextProtos = slices.DeleteFunc(slices.Clone(t.TLSClientConfig.NextProtos),
+		func(p string) bool { return p == proto })
+}
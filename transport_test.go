@@ -0,0 +1,182 @@
+package reqwest
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func newProtoServer() *httptest.Server {
+	return httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proto", r.Proto)
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestClientBuilder_WithHTTPVersion(t *testing.T) {
+	t.Run("HTTP/1.1 forced over TLS", func(t *testing.T) {
+		server := newProtoServer()
+		server.EnableHTTP2 = true
+		server.StartTLS()
+		defer server.Close()
+
+		cli := NewClientBuilder().
+			WithTransport(server.Client().Transport).
+			WithHTTPVersion(HTTPVersion1).
+			Build()
+
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer resp.Body().Close()
+
+		if resp.Proto() != "HTTP/1.1" {
+			t.Errorf("Expected HTTP/1.1, got %s", resp.Proto())
+		}
+	})
+
+	t.Run("HTTP/2 over TLS", func(t *testing.T) {
+		server := newProtoServer()
+		server.EnableHTTP2 = true
+		server.StartTLS()
+		defer server.Close()
+
+		cli := NewClientBuilder().
+			WithTransport(server.Client().Transport).
+			WithHTTPVersion(HTTPVersion2).
+			Build()
+
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer resp.Body().Close()
+
+		if resp.Proto() != "HTTP/2.0" {
+			t.Errorf("Expected HTTP/2.0, got %s", resp.Proto())
+		}
+	})
+
+	t.Run("h2c prior knowledge", func(t *testing.T) {
+		server := newProtoServer()
+		server.Config.Protocols = new(http.Protocols)
+		server.Config.Protocols.SetUnencryptedHTTP2(true)
+		server.Start()
+		defer server.Close()
+
+		cli := NewClientBuilder().WithHTTPVersion(HTTPVersion2PriorKnowledge).Build()
+
+		resp, err := cli.Get(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer resp.Body().Close()
+
+		if resp.Proto() != "HTTP/2.0" {
+			t.Errorf("Expected HTTP/2.0, got %s", resp.Proto())
+		}
+	})
+
+	t.Run("auto resets protocol restrictions", func(t *testing.T) {
+		transport := &http.Transport{Protocols: new(http.Protocols)}
+		withHTTPVersion(HTTPVersionAuto)(transport)
+
+		if transport.Protocols != nil {
+			t.Error("Expected HTTPVersionAuto to clear protocol restrictions")
+		}
+	})
+}
+
+func TestBuildHTTPClient(t *testing.T) {
+	t.Run("default client without customization", func(t *testing.T) {
+		if buildHTTPClient(nil, nil, nil) != http.DefaultClient {
+			t.Error("Expected http.DefaultClient when nothing is customized")
+		}
+	})
+
+	t.Run("options never mutate the default transport", func(t *testing.T) {
+		httpClient := buildHTTPClient(nil, []transportOption{withHTTPVersion(HTTPVersion1)}, nil)
+
+		if httpClient.Transport == http.DefaultTransport {
+			t.Error("Expected a cloned transport")
+		}
+		if http.DefaultTransport.(*http.Transport).Protocols != nil {
+			t.Error("Default transport was mutated")
+		}
+	})
+
+	t.Run("custom round tripper is used as-is", func(t *testing.T) {
+		rt := roundTripperFunc(func(*http.Request) (*http.Response, error) { return nil, nil })
+		httpClient := buildHTTPClient(rt, []transportOption{withHTTPVersion(HTTPVersion1)}, nil)
+
+		if httpClient.Transport == nil {
+			t.Fatal("Expected transport to be set")
+		}
+		if _, ok := httpClient.Transport.(roundTripperFunc); !ok {
+			t.Errorf("Expected custom round tripper, got %T", httpClient.Transport)
+		}
+	})
+}
+
+func TestClientBuilder_WithUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "daemon.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	cli := NewClientBuilder().
+		WithBaseURL("http://docker").
+		WithUnixSocket(socketPath).
+		Build()
+
+	resp, err := cli.Get(context.TODO(), "/v1.43/containers/json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body().Close()
+
+	body, _ := io.ReadAll(resp.Body())
+	if string(body) != "/v1.43/containers/json" {
+		t.Errorf("Expected path to reach the daemon, got %q", body)
+	}
+}
+
+func TestClientBuilder_WithDialContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var dialed []string
+	dialer := &net.Dialer{}
+	cli := NewClientBuilder().
+		WithDialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed = append(dialed, addr)
+			// Route a logical host name to the test server
+			return dialer.DialContext(ctx, network, server.Listener.Addr().String())
+		}).
+		Build()
+
+	resp, err := cli.Get(context.TODO(), "http://service.internal:9999/health")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	resp.Body().Close()
+
+	if len(dialed) != 1 || dialed[0] != "service.internal:9999" {
+		t.Errorf("Expected custom dialer to receive service.internal:9999, got %v", dialed)
+	}
+}
@@ -0,0 +1,117 @@
+package reqwest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// TypedClient sends TReq bodies and decodes TResp responses, so endpoints are
+// checked at compile time instead of with hand-written marshalling:
+//
+//	users := reqwest.NewTypedClient[NewUser, User](client)
+//	created, err := users.Post(ctx, "/users", NewUser{Name: "Ada"})
+//
+// Bodies are JSON unless WithCodec says otherwise. A non-2xx response fails
+// with an *HTTPError.
+type TypedClient[TReq, TResp any] struct {
+	doer  Doer
+	codec Codec
+}
+
+// NewTypedClient returns a TypedClient sending its requests through doer.
+func NewTypedClient[TReq, TResp any](doer Doer) *TypedClient[TReq, TResp] {
+	return &TypedClient[TReq, TResp]{doer: doer, codec: JSONCodec{}}
+}
+
+// WithCodec returns a copy of c that encodes and expects codec's media type.
+func (c *TypedClient[TReq, TResp]) WithCodec(codec Codec) *TypedClient[TReq, TResp] {
+	clone := *c
+	clone.codec = codec
+	return &clone
+}
+
+// Get fetches url and decodes the response.
+func (c *TypedClient[TReq, TResp]) Get(ctx context.Context, url string, opts ...RequestOption) (TResp, error) {
+	return c.send(ctx, NewRequest(http.MethodGet, url), opts)
+}
+
+// Post sends body to url and decodes the response.
+func (c *TypedClient[TReq, TResp]) Post(ctx context.Context, url string, body TReq, opts ...RequestOption) (TResp, error) {
+	return c.Do(ctx, http.MethodPost, url, body, opts...)
+}
+
+// Put sends body to url with PUT and decodes the response.
+func (c *TypedClient[TReq, TResp]) Put(ctx context.Context, url string, body TReq, opts ...RequestOption) (TResp, error) {
+	return c.Do(ctx, http.MethodPut, url, body, opts...)
+}
+
+// Do sends body with any method and decodes the response.
+func (c *TypedClient[TReq, TResp]) Do(ctx context.Context, method, url string, body TReq, opts ...RequestOption) (TResp, error) {
+	data, err := c.codec.Marshal(body)
+	if err != nil {
+		var zero TResp
+		return zero, fmt.Errorf("failed to encode request body: %v", err)
+	}
+	req := NewRequest(method, url).WithBody(data).WithHeader("Content-Type", c.codec.ContentType())
+	return c.send(ctx, req, opts)
+}
+
+func (c *TypedClient[TReq, TResp]) send(ctx context.Context, req *Request, opts []RequestOption) (TResp, error) {
+	return doTyped[TResp](ctx, c.doer, req.with(opts), c.codec)
+}
+
+// doTyped executes req expecting codec's media type and decodes a 2xx
+// response into a T.
+func doTyped[T any](ctx context.Context, doer Doer, req *Request, codec Codec) (T, error) {
+	var value T
+	resp, err := doer.Do(ctx, req.Expect(codec))
+	if err != nil {
+		return value, err
+	}
+	if !resp.IsSuccess() {
+		return value, httpError(resp)
+	}
+	if resp.StatusCode() == http.StatusNoContent {
+		resp.Body().Close()
+		return value, nil
+	}
+	if _, ok := codec.(JSONCodec); ok {
+		// Unlike Decode, JSON also reads bodies mislabelled as text/plain
+		err = resp.JSON(&value)
+	} else {
+		err = resp.Decode(&value)
+	}
+	return value, err
+}
+
+// DoJSON executes req and decodes its JSON response into a T. A 204 No
+// Content gives the zero value, other statuses that aren't 2xx an
+// *HTTPError.
+func DoJSON[T any](ctx context.Context, doer Doer, req *Request) (T, error) {
+	return doTyped[T](ctx, doer, req, JSONCodec{})
+}
+
+// Send executes req for its status only: a 2xx response's body is discarded
+// and any other status fails with an *HTTPError.
+func Send(ctx context.Context, doer Doer, req *Request) error {
+	resp, err := doer.Do(ctx, req)
+	if err != nil {
+		return err
+	}
+	if !resp.IsSuccess() {
+		return httpError(resp)
+	}
+	return resp.Body().Close()
+}
+
+// GetJSON fetches url and decodes its JSON response into a T.
+func GetJSON[T any](ctx context.Context, doer Doer, url string, opts ...RequestOption) (T, error) {
+	return NewTypedClient[struct{}, T](doer).Get(ctx, url, opts...)
+}
+
+// PostJSON sends body to url as JSON and decodes the JSON response into a
+// TResp.
+func PostJSON[TReq, TResp any](ctx context.Context, doer Doer, url string, body TReq, opts ...RequestOption) (TResp, error) {
+	return NewTypedClient[TReq, TResp](doer).Post(ctx, url, body, opts...)
+}
@@ -0,0 +1,129 @@
+package reqwest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type typedUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type typedNewUser struct {
+	Name string `json:"name"`
+}
+
+func TestTypedClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/users/1":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":1,"name":"Ada"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/users":
+			var in typedNewUser
+			if r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&in) != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(typedUser{ID: 2, Name: in.Name})
+		case r.Method == http.MethodPut:
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cli := NewClientBuilder().WithBaseURL(server.URL).Build()
+	users := NewTypedClient[typedNewUser, typedUser](cli)
+
+	t.Run("Get", func(t *testing.T) {
+		user, err := users.Get(context.TODO(), "/users/1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if user != (typedUser{ID: 1, Name: "Ada"}) {
+			t.Errorf("Expected Ada, got %+v", user)
+		}
+	})
+
+	t.Run("Post", func(t *testing.T) {
+		user, err := users.Post(context.TODO(), "/users", typedNewUser{Name: "Grace"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if user != (typedUser{ID: 2, Name: "Grace"}) {
+			t.Errorf("Expected Grace, got %+v", user)
+		}
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		user, err := users.Put(context.TODO(), "/users/1", typedNewUser{Name: "Ada"})
+		if err != nil || user != (typedUser{}) {
+			t.Errorf("Expected a zero value, got %+v, %v", user, err)
+		}
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		_, err := users.Get(context.TODO(), "/users/404")
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected an HTTPError for 404, got %v", err)
+		}
+	})
+
+	t.Run("unexpected content type", func(t *testing.T) {
+		_, err := users.Get(context.TODO(), "/html")
+		if !errors.Is(err, ErrUnexpectedContentType) {
+			t.Errorf("Expected ErrUnexpectedContentType, got %v", err)
+		}
+	})
+
+	t.Run("GetJSON and PostJSON", func(t *testing.T) {
+		user, err := GetJSON[typedUser](context.TODO(), cli, "/users/1")
+		if err != nil || user.Name != "Ada" {
+			t.Errorf("GetJSON() = %+v, %v", user, err)
+		}
+		created, err := PostJSON[typedNewUser, typedUser](context.TODO(), cli, "/users", typedNewUser{Name: "Linus"})
+		if err != nil || created.Name != "Linus" {
+			t.Errorf("PostJSON() = %+v, %v", created, err)
+		}
+	})
+}
+
+func TestDoJSONAndSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "REPORT":
+			w.Write([]byte(`{"id":1,"name":"Ada"}`))
+		case http.MethodDelete:
+			w.Write([]byte("deleted"))
+		default:
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer server.Close()
+	cli := NewClientBuilder().WithBaseURL(server.URL).Build()
+
+	user, err := DoJSON[typedUser](context.TODO(), cli, NewRequest("REPORT", "/users/1"))
+	if err != nil || user.Name != "Ada" {
+		t.Errorf("DoJSON() = %+v, %v", user, err)
+	}
+	if err := Send(context.TODO(), cli, NewRequest(http.MethodDelete, "/users/1")); err != nil {
+		t.Errorf("Send() error = %v", err)
+	}
+	var httpErr *HTTPError
+	if err := Send(context.TODO(), cli, NewRequest(http.MethodPut, "/users/1")); !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusConflict {
+		t.Errorf("Send() error = %v, want an HTTPError for 409", err)
+	}
+}
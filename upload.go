@@ -0,0 +1,238 @@
+package reqwest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UploadRequestFunc builds the request uploading the file at path, a
+// slash-separated path relative to the uploaded directory.
+type UploadRequestFunc func(path string, content []byte) (*Request, error)
+
+// PutUploads PUTs every file to baseURL followed by its path.
+func PutUploads(baseURL string) UploadRequestFunc {
+	return func(path string, content []byte) (*Request, error) {
+		url := strings.TrimRight(baseURL, "/") + "/" + path
+		return NewRequest(http.MethodPut, url).
+			WithBody(content).
+			WithHeader("Content-Type", "application/octet-stream"), nil
+	}
+}
+
+// MultipartUploads POSTs every file to url as a multipart/form-data form
+// with the file in field, named after its path.
+func MultipartUploads(url, field string) UploadRequestFunc {
+	return func(path string, content []byte) (*Request, error) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile(field, path)
+		if err != nil {
+			return nil, err
+		}
+		part.Write(content)
+		if err := form.Close(); err != nil {
+			return nil, err
+		}
+		return NewRequest(http.MethodPost, url).
+			WithBody(body.Bytes()).
+			WithHeader("Content-Type", form.FormDataContentType()), nil
+	}
+}
+
+// ManifestEntry records the upload of one file.
+type ManifestEntry struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	SHA256     string    `json:"sha256"`
+	Uploaded   bool      `json:"uploaded"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	UploadedAt time.Time `json:"uploaded_at,omitzero"`
+}
+
+// UploadManifest lists the files of an UploadDir run, sorted by path.
+type UploadManifest struct {
+	Files []ManifestEntry `json:"files"`
+}
+
+// Failed returns the entries of files that were not uploaded.
+func (m *UploadManifest) Failed() []ManifestEntry {
+	var failed []ManifestEntry
+	for _, entry := range m.Files {
+		if !entry.Uploaded {
+			failed = append(failed, entry)
+		}
+	}
+	return failed
+}
+
+// UploadConfig configures UploadDir.
+type UploadConfig struct {
+	// Request builds the upload request of each file, e.g. PutUploads or
+	// MultipartUploads.
+	Request UploadRequestFunc
+	// ManifestPath is where the manifest is kept. An existing manifest
+	// resumes a previous run; "" keeps none.
+	ManifestPath string
+	// Match selects the files to upload; nil uploads every regular file.
+	Match func(path string) bool
+	// Concurrency bounds how many files are uploaded at once, 1 if zero.
+	Concurrency int
+}
+
+// UploadDir uploads the regular files of fsys through doer, e.g. the
+// contents of os.DirFS(dir). Files are read into memory one at a time per
+// concurrent upload. The manifest records each file's size, SHA-256 checksum
+// and outcome and is saved after every file, so a run resumed with the same
+// manifest skips files already uploaded unless their content changed.
+//
+// Failed uploads don't stop the run; their errors are joined into the
+// returned error along with the manifest.
+func UploadDir(ctx context.Context, doer Doer, fsys fs.FS, cfg UploadConfig) (*UploadManifest, error) {
+	previous, err := loadManifest(cfg.ManifestPath)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	err = fs.WalkDir(fsys, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() && (cfg.Match == nil || cfg.Match(path)) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %v", err)
+	}
+
+	// Until a file is visited its previous entry stands, so an interrupted
+	// run doesn't forget earlier uploads.
+	manifest := &UploadManifest{Files: make([]ManifestEntry, len(paths))}
+	for i, path := range paths {
+		manifest.Files[i] = previous[path]
+	}
+	var mu sync.Mutex
+	var errs []error
+	record := func(i int, entry ManifestEntry, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		manifest.Files[i] = entry
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if saveErr := manifest.save(cfg.ManifestPath); saveErr != nil {
+			errs = append(errs, saveErr)
+		}
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range max(cfg.Concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				entry, err := uploadFile(ctx, doer, fsys, paths[i], cfg.Request, previous[paths[i]])
+				record(i, entry, err)
+			}
+		}()
+	}
+	for i := range paths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return manifest, errors.Join(errs...)
+}
+
+// uploadFile uploads path unless previous shows the same content was
+// uploaded already.
+func uploadFile(ctx context.Context, doer Doer, fsys fs.FS, path string, build UploadRequestFunc, previous ManifestEntry) (ManifestEntry, error) {
+	content, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return ManifestEntry{Path: path, Error: err.Error()}, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	sum := sha256.Sum256(content)
+	entry := ManifestEntry{Path: path, Size: int64(len(content)), SHA256: hex.EncodeToString(sum[:])}
+	if previous.Uploaded && previous.SHA256 == entry.SHA256 {
+		return previous, nil
+	}
+	fail := func(err error) (ManifestEntry, error) {
+		entry.Error = err.Error()
+		return entry, fmt.Errorf("failed to upload %s: %w", path, err)
+	}
+	req, err := build(path, content)
+	if err != nil {
+		return fail(err)
+	}
+	resp, err := doer.Do(ctx, req)
+	if err != nil {
+		return fail(err)
+	}
+	resp.Body().Close()
+	entry.StatusCode = resp.StatusCode()
+	if entry.StatusCode < http.StatusOK || entry.StatusCode >= http.StatusMultipleChoices {
+		return fail(fmt.Errorf("unexpected status %d", entry.StatusCode))
+	}
+	entry.Uploaded = true
+	entry.UploadedAt = time.Now().UTC()
+	return entry, nil
+}
+
+// loadManifest returns the entries of the manifest at path by file path. A
+// missing manifest is empty.
+func loadManifest(path string) (map[string]ManifestEntry, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %v", err)
+	}
+	var manifest UploadManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %v", err)
+	}
+	entries := make(map[string]ManifestEntry, len(manifest.Files))
+	for _, entry := range manifest.Files {
+		entries[entry.Path] = entry
+	}
+	return entries, nil
+}
+
+// save writes the entries recorded so far to path, replacing the file
+// atomically so an interrupted run leaves a readable manifest.
+func (m *UploadManifest) save(path string) error {
+	if path == "" {
+		return nil
+	}
+	recorded := &UploadManifest{Files: slices.DeleteFunc(slices.Clone(m.Files), func(e ManifestEntry) bool { return e.Path == "" })}
+	data, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %v", err)
+	}
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to write manifest: %v", err)
+	}
+	return nil
+}
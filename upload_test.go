@@ -0,0 +1,102 @@
+package reqwest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+func TestUploadDir(t *testing.T) {
+	var mu sync.Mutex
+	stored := map[string]string{}
+	failures := map[string]int{"/docs/flaky.txt": 1}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures[r.URL.Path] > 0 {
+			failures[r.URL.Path]--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if r.Method == http.MethodPost {
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("Failed to parse form: %v", err)
+				return
+			}
+			body, _ := io.ReadAll(file)
+			stored[header.Filename] = string(body)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		stored[r.URL.Path] = string(body)
+	}))
+	defer server.Close()
+	client := NewClientBuilder().Build()
+	uploads := func() map[string]string {
+		mu.Lock()
+		defer mu.Unlock()
+		got := stored
+		stored = map[string]string{}
+		return got
+	}
+
+	t.Run("resumes with a manifest", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"a.txt":     {Data: []byte("alpha")},
+			"b/c.txt":   {Data: []byte("gamma")},
+			"flaky.txt": {Data: []byte("flaky")},
+			"skip.log":  {Data: []byte("ignored")},
+		}
+		cfg := UploadConfig{
+			Request:      PutUploads(server.URL + "/docs/"),
+			ManifestPath: filepath.Join(t.TempDir(), "manifest.json"),
+			Match:        func(path string) bool { return strings.HasSuffix(path, ".txt") },
+			Concurrency:  2,
+		}
+
+		manifest, err := UploadDir(context.Background(), client, fsys, cfg)
+		if err == nil || !strings.Contains(err.Error(), "flaky.txt") {
+			t.Errorf("Expected flaky.txt to fail, got %v", err)
+		}
+		if failed := manifest.Failed(); len(failed) != 1 || failed[0].StatusCode != http.StatusInternalServerError {
+			t.Errorf("Expected one failed entry, got %+v", failed)
+		}
+		if got := uploads(); len(got) != 2 || got["/docs/b/c.txt"] != "gamma" {
+			t.Errorf("Expected two uploads, got %v", got)
+		}
+		if entry := manifest.Files[0]; entry.Path != "a.txt" || entry.Size != 5 ||
+			entry.SHA256 != "8ed3f6ad685b959ead7022518e1af76cd816f8e8ec7ccdda1ed4018e8f2223f8" {
+			t.Errorf("Expected a.txt with its checksum, got %+v", entry)
+		}
+
+		fsys["a.txt"] = &fstest.MapFile{Data: []byte("changed")}
+		manifest, err = UploadDir(context.Background(), client, fsys, cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := uploads(); len(got) != 2 || got["/docs/a.txt"] != "changed" || got["/docs/flaky.txt"] != "flaky" {
+			t.Errorf("Expected only the changed and failed files to be uploaded, got %v", got)
+		}
+		if len(manifest.Failed()) != 0 || len(manifest.Files) != 3 {
+			t.Errorf("Expected every file to be uploaded, got %+v", manifest.Files)
+		}
+	})
+
+	t.Run("multipart", func(t *testing.T) {
+		fsys := fstest.MapFS{"report.csv": {Data: []byte("a,b")}}
+		_, err := UploadDir(context.Background(), client, fsys, UploadConfig{Request: MultipartUploads(server.URL+"/upload", "file")})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := uploads(); got["report.csv"] != "a,b" {
+			t.Errorf("Expected the file in the form, got %v", got)
+		}
+	})
+}
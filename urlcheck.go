@@ -0,0 +1,131 @@
+package reqwest
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// URLError reports a URL rejected by a client built with WithStrictURLs.
+type URLError struct {
+	URL    string
+	Reason string
+	Err    error
+}
+
+func (e *URLError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("invalid URL %q: %s: %v", e.URL, e.Reason, e.Err)
+	}
+	return fmt.Sprintf("invalid URL %q: %s", e.URL, e.Reason)
+}
+
+func (e *URLError) Unwrap() error { return e.Err }
+
+// normalizeURL validates rawURL as an absolute http or https URL and returns
+// it normalized: scheme and host lower-cased, dot segments and duplicate
+// slashes removed from the path, characters that aren't allowed unescaped
+// percent-encoded, and the fragment, which is never sent, dropped.
+func normalizeURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", &URLError{URL: rawURL, Reason: "malformed", Err: err}
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	switch {
+	case scheme == "":
+		return "", &URLError{URL: rawURL, Reason: "not absolute"}
+	case scheme != "http" && scheme != "https":
+		return "", &URLError{URL: rawURL, Reason: "unsupported scheme " + strconv.Quote(parsed.Scheme)}
+	case parsed.Opaque != "":
+		return "", &URLError{URL: rawURL, Reason: "opaque URLs are not supported"}
+	case parsed.Hostname() == "":
+		return "", &URLError{URL: rawURL, Reason: "missing host"}
+	}
+	if port := parsed.Port(); port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return "", &URLError{URL: rawURL, Reason: "invalid port " + strconv.Quote(port)}
+		}
+	}
+
+	normalized := scheme + "://"
+	if parsed.User != nil {
+		normalized += parsed.User.String() + "@"
+	}
+	normalized += strings.ToLower(parsed.Host) + normalizePath(parsed.EscapedPath())
+	if parsed.RawQuery != "" || parsed.ForceQuery {
+		normalized += "?" + escapeUnsafe(parsed.RawQuery, "/?")
+	}
+	return normalized, nil
+}
+
+// normalizePath removes dot segments (RFC 3986, section 5.2.4) and empty
+// segments from an escaped path, keeping a trailing slash.
+func normalizePath(path string) string {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		switch strings.ToLower(segment) {
+		case "", ".", "%2e":
+		case "..", ".%2e", "%2e.", "%2e%2e":
+			if len(segments) > 0 {
+				segments = segments[:len(segments)-1]
+			}
+		default:
+			segments = append(segments, escapeUnsafe(segment, ""))
+		}
+	}
+	normalized := "/" + strings.Join(segments, "/")
+	if len(segments) > 0 && isDirectory(path) {
+		normalized += "/"
+	}
+	return normalized
+}
+
+// isDirectory reports whether path ends in a slash or a dot segment, which
+// both name a directory.
+func isDirectory(path string) bool {
+	last := strings.ToLower(path[strings.LastIndex(path, "/")+1:])
+	switch last {
+	case "", ".", "..", "%2e", ".%2e", "%2e.", "%2e%2e":
+		return true
+	}
+	return false
+}
+
+// escapeUnsafe percent-encodes the bytes of s that may not appear unescaped
+// in a path segment (RFC 3986 pchar), or additionally extra, leaving valid
+// escapes alone.
+func escapeUnsafe(s, extra string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			b.WriteString(strings.ToUpper(s[i : i+3]))
+			i += 2
+		case isPathChar(c) || strings.IndexByte(extra, c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func isPathChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("-._~!$&'()*+,;=:@", c) >= 0
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
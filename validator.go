@@ -0,0 +1,78 @@
+package reqwest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrRequestTooLarge is returned, before anything is sent, when a request
+// body exceeds the limit set with WithMaxRequestBytes.
+var ErrRequestTooLarge = errors.New("request body exceeds size limit")
+
+// ErrRequestRejected wraps errors returned by a Validator.
+var ErrRequestRejected = errors.New("request rejected by validator")
+
+// ErrHeadersTooLarge is returned, before anything is sent, when the request
+// headers after middleware and signing exceed the limits set with
+// WithHeaderLimits, e.g. because a middleware appends a header on every
+// retry.
+var ErrHeadersTooLarge = errors.New("request headers exceed limit")
+
+// ErrHeaderFieldsRejected is returned when the server answers 431 Request
+// Header Fields Too Large.
+var ErrHeaderFieldsRejected = errors.New("server rejected request headers as too large")
+
+// Validator inspects an outgoing request just before it is sent, after
+// middleware and signing. req.ContentLength holds the body length. Returning
+// an error aborts the request, e.g. to enforce "no PII in query strings".
+type Validator func(req *http.Request) error
+
+func (c *HTTPClient) validate(req *http.Request) error {
+	for _, validator := range c.validators {
+		if err := validator(req); err != nil {
+			return fmt.Errorf("%w: %v", ErrRequestRejected, err)
+		}
+	}
+	return nil
+}
+
+func (c *HTTPClient) checkRequestSize(body []byte) error {
+	if c.maxRequestBytes > 0 && int64(len(body)) > c.maxRequestBytes {
+		return fmt.Errorf("%w: %d bytes, limit is %d", ErrRequestTooLarge, len(body), c.maxRequestBytes)
+	}
+	return nil
+}
+
+// headerSize returns the number of header lines and their size on the wire.
+func headerSize(header http.Header) (count, size int) {
+	for name, values := range header {
+		for _, value := range values {
+			count++
+			size += len(name) + len(value) + len(": \r\n")
+		}
+	}
+	return count, size
+}
+
+func (c *HTTPClient) checkHeaders(req *http.Request) error {
+	if c.maxHeaderCount <= 0 && c.maxHeaderBytes <= 0 {
+		return nil
+	}
+	count, size := headerSize(req.Header)
+	if c.maxHeaderCount > 0 && count > c.maxHeaderCount {
+		return fmt.Errorf("%w: %d headers, limit is %d", ErrHeadersTooLarge, count, c.maxHeaderCount)
+	}
+	if c.maxHeaderBytes > 0 && size > c.maxHeaderBytes {
+		return fmt.Errorf("%w: %d bytes, limit is %d", ErrHeadersTooLarge, size, c.maxHeaderBytes)
+	}
+	return nil
+}
+
+// headerFieldsRejected turns a 431 response into ErrHeaderFieldsRejected,
+// reporting what was sent to help find the offending header.
+func headerFieldsRejected(resp *http.Response) error {
+	resp.Body.Close()
+	count, size := headerSize(resp.Request.Header)
+	return fmt.Errorf("%w: sent %d headers, %d bytes", ErrHeaderFieldsRejected, count, size)
+}
@@ -0,0 +1,129 @@
+package reqwest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_MaxRequestBytes(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	client := NewClientBuilder().WithMaxRequestBytes(4).Build()
+
+	if _, err := client.Post(context.Background(), server.URL, []byte("1234")); err != nil {
+		t.Fatalf("Unexpected error for body at the limit: %v", err)
+	}
+	_, err := client.Post(context.Background(), server.URL, []byte("12345"))
+	if !errors.Is(err, ErrRequestTooLarge) {
+		t.Fatalf("Expected ErrRequestTooLarge, got %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("Expected the oversized request not to be sent, got %d hits", got)
+	}
+}
+
+func TestClient_WithValidator(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer server.Close()
+
+	var seenLength int64
+	noEmailInQuery := func(req *http.Request) error {
+		seenLength = req.ContentLength
+		if strings.Contains(req.URL.RawQuery, "email=") {
+			return fmt.Errorf("query string contains PII")
+		}
+		return nil
+	}
+	client := NewClientBuilder().WithValidator(noEmailInQuery).Build()
+
+	t.Run("accepted", func(t *testing.T) {
+		if _, err := client.Post(context.Background(), server.URL+"/users", []byte("hello")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if seenLength != 5 {
+			t.Errorf("Expected validator to see body length 5, got %d", seenLength)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		_, err := client.Get(context.Background(), server.URL+"/users?email=a@example.com")
+		if !errors.Is(err, ErrRequestRejected) {
+			t.Fatalf("Expected ErrRequestRejected, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "PII") {
+			t.Errorf("Expected validator reason in error, got %v", err)
+		}
+		if got := atomic.LoadInt32(&hits); got != 1 {
+			t.Errorf("Expected rejected request not to be sent, got %d hits", got)
+		}
+	})
+}
+
+func TestClient_WithHeaderLimits(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if len(r.Header.Get("X-Big")) > 100 {
+			w.WriteHeader(http.StatusRequestHeaderFieldsTooLarge)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("count limit includes middleware headers", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		client := NewClientBuilder().
+			WithHeaderLimits(1, 0).
+			WithMiddleware(func(req *http.Request) error {
+				req.Header.Add("X-Trace", "a")
+				req.Header.Add("X-Trace", "b")
+				return nil
+			}).
+			Build()
+
+		_, err := client.Get(context.Background(), server.URL)
+		if !errors.Is(err, ErrHeadersTooLarge) {
+			t.Fatalf("Expected ErrHeadersTooLarge, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "limit is 1") {
+			t.Errorf("Expected the limit in the error, got %v", err)
+		}
+		if got := atomic.LoadInt32(&hits); got != 0 {
+			t.Errorf("Expected the request not to be sent, got %d hits", got)
+		}
+	})
+
+	t.Run("byte limit", func(t *testing.T) {
+		client := NewClientBuilder().WithHeaderLimits(0, 64).Build()
+
+		resp, err := client.Do(context.Background(), NewRequest(http.MethodGet, server.URL).WithHeader("X-Small", "ok"))
+		if err != nil {
+			t.Fatalf("Unexpected error for small headers: %v", err)
+		}
+		resp.Body().Close()
+		_, err = client.Do(context.Background(), NewRequest(http.MethodGet, server.URL).WithHeader("X-Big", strings.Repeat("x", 64)))
+		if !errors.Is(err, ErrHeadersTooLarge) {
+			t.Fatalf("Expected ErrHeadersTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("server 431 is a typed error", func(t *testing.T) {
+		client := NewClientBuilder().Build()
+		_, err := client.Do(context.Background(), NewRequest(http.MethodGet, server.URL).WithHeader("X-Big", strings.Repeat("x", 200)))
+		if !errors.Is(err, ErrHeaderFieldsRejected) {
+			t.Fatalf("Expected ErrHeaderFieldsRejected, got %v", err)
+		}
+	})
+}
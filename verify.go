@@ -0,0 +1,401 @@
+package reqwest
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResponseVerifier checks that a response is authentic before the client
+// returns it. It receives the complete body, after any Content-Encoding was
+// decoded, and fails with a *SignatureError if the response was not signed by
+// a trusted key.
+type ResponseVerifier interface {
+	Verify(resp *http.Response, body []byte) error
+}
+
+// SignatureError reports a response whose signature is missing or invalid.
+type SignatureError struct {
+	Reason string
+	Err    error
+}
+
+func (e *SignatureError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("response signature verification failed: %s: %v", e.Reason, e.Err)
+	}
+	return "response signature verification failed: " + e.Reason
+}
+
+func (e *SignatureError) Unwrap() error { return e.Err }
+
+func signatureError(format string, args ...any) error {
+	return &SignatureError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// verifyResponse reads the body of response and checks it with the client's
+// verifier, leaving the body readable for the caller.
+func (c *HTTPClient) verifyResponse(resp *http.Response, response *Response) error {
+	data, err := response.readBody()
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if err := c.verifier.Verify(resp, data); err != nil {
+		return err
+	}
+	response.body = io.NopCloser(bytes.NewReader(data))
+	return nil
+}
+
+// VerificationKeyFunc returns the key for the key ID and algorithm named by
+// a signature: a []byte secret for HMAC, or an *rsa.PublicKey,
+// *ecdsa.PublicKey or ed25519.PublicKey. Returning an error rejects the
+// signature, e.g. for unknown key IDs.
+type VerificationKeyFunc func(keyID, alg string) (any, error)
+
+// StaticKey trusts a single key regardless of the key ID.
+func StaticKey(key any) VerificationKeyFunc {
+	return func(string, string) (any, error) {
+		return key, nil
+	}
+}
+
+// JWSVerifier verifies a detached JSON Web Signature (RFC 7515, Appendix F)
+// of the body, sent in a header as "<protected>..<signature>". Unencoded
+// payloads ("b64": false, RFC 7797) are supported. Accepted algorithms are
+// HS256, RS256, ES256 and EdDSA.
+type JWSVerifier struct {
+	header string
+	keys   VerificationKeyFunc
+}
+
+// DefaultJWSHeader is the header JWSVerifier reads unless told otherwise.
+const DefaultJWSHeader = "X-JWS-Signature"
+
+// NewJWSVerifier verifies the detached JWS in header with keys from keys. An
+// empty header defaults to X-JWS-Signature.
+func NewJWSVerifier(header string, keys VerificationKeyFunc) *JWSVerifier {
+	if header == "" {
+		header = DefaultJWSHeader
+	}
+	return &JWSVerifier{header: header, keys: keys}
+}
+
+var jwsAlgorithms = map[string]string{
+	"HS256": "hmac-sha256",
+	"RS256": "rsa-v1_5-sha256",
+	"ES256": "ecdsa-p256-sha256",
+	"EdDSA": "ed25519",
+}
+
+func (v *JWSVerifier) Verify(resp *http.Response, body []byte) error {
+	value := resp.Header.Get(v.header)
+	if value == "" {
+		return signatureError("missing %s header", v.header)
+	}
+	protected, signature, ok := strings.Cut(value, "..")
+	if !ok || strings.Contains(signature, ".") {
+		return signatureError("%s is not a detached JWS", v.header)
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(protected)
+	if err != nil {
+		return &SignatureError{Reason: "invalid protected header", Err: err}
+	}
+	var header struct {
+		Alg  string   `json:"alg"`
+		Kid  string   `json:"kid"`
+		B64  *bool    `json:"b64"`
+		Crit []string `json:"crit"`
+	}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return &SignatureError{Reason: "invalid protected header", Err: err}
+	}
+	alg, ok := jwsAlgorithms[header.Alg]
+	if !ok {
+		return signatureError("unsupported algorithm %q", header.Alg)
+	}
+	for _, name := range header.Crit {
+		if name != "b64" {
+			return signatureError("unsupported critical header %q", name)
+		}
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return &SignatureError{Reason: "invalid signature encoding", Err: err}
+	}
+	key, err := v.keys(header.Kid, header.Alg)
+	if err != nil {
+		return &SignatureError{Reason: fmt.Sprintf("no key for %q", header.Kid), Err: err}
+	}
+	payload := base64.RawURLEncoding.EncodeToString(body)
+	if header.B64 != nil && !*header.B64 {
+		payload = string(body)
+	}
+	return verifySignature(alg, key, []byte(protected+"."+payload), sig)
+}
+
+// MessageSignatureVerifier verifies HTTP Message Signatures (RFC 9421) on
+// responses. The first signature in Signature-Input is checked; it must cover
+// the Content-Digest header, which in turn must match the body, so both the
+// status and headers it covers and the content are authenticated. Accepted
+// algorithms are hmac-sha256, rsa-v1_5-sha256, rsa-pss-sha512,
+// ecdsa-p256-sha256 and ed25519.
+type MessageSignatureVerifier struct {
+	keys VerificationKeyFunc
+	now  func() time.Time
+}
+
+// NewMessageSignatureVerifier verifies message signatures with keys from
+// keys.
+func NewMessageSignatureVerifier(keys VerificationKeyFunc) *MessageSignatureVerifier {
+	return &MessageSignatureVerifier{keys: keys, now: time.Now}
+}
+
+func (v *MessageSignatureVerifier) Verify(resp *http.Response, body []byte) error {
+	label, params, err := firstSignatureInput(resp.Header.Get("Signature-Input"))
+	if err != nil {
+		return err
+	}
+	components, attributes, err := parseSignatureParams(params)
+	if err != nil {
+		return err
+	}
+	sig, err := signatureValue(resp.Header.Get("Signature"), label)
+	if err != nil {
+		return err
+	}
+	if expires, ok := attributes["expires"]; ok {
+		seconds, err := strconv.ParseInt(expires, 10, 64)
+		if err != nil || v.now().Unix() > seconds {
+			return signatureError("signature %s expired", label)
+		}
+	}
+
+	var base strings.Builder
+	coversDigest := false
+	for _, component := range components {
+		var value string
+		switch {
+		case component == "@status":
+			value = strconv.Itoa(resp.StatusCode)
+		case strings.HasPrefix(component, "@"):
+			return signatureError("unsupported component %q", component)
+		default:
+			values := resp.Header.Values(component)
+			if len(values) == 0 {
+				return signatureError("covered header %q is missing", component)
+			}
+			for i := range values {
+				values[i] = strings.TrimSpace(values[i])
+			}
+			value = strings.Join(values, ", ")
+			coversDigest = coversDigest || component == "content-digest"
+		}
+		fmt.Fprintf(&base, "%q: %s\n", component, value)
+	}
+	fmt.Fprintf(&base, "%q: %s", "@signature-params", params)
+	if !coversDigest {
+		return signatureError("signature %s does not cover content-digest", label)
+	}
+	if err := verifyContentDigest(resp.Header.Get("Content-Digest"), body); err != nil {
+		return err
+	}
+
+	keyID, alg := attributes["keyid"], attributes["alg"]
+	key, err := v.keys(keyID, alg)
+	if err != nil {
+		return &SignatureError{Reason: fmt.Sprintf("no key for %q", keyID), Err: err}
+	}
+	if alg == "" {
+		alg = defaultAlgorithm(key)
+	}
+	return verifySignature(alg, key, []byte(base.String()), sig)
+}
+
+// firstSignatureInput returns the label and serialized parameters of the
+// first signature in a Signature-Input header.
+func firstSignatureInput(header string) (label, params string, err error) {
+	label, rest, ok := strings.Cut(strings.TrimSpace(header), "=")
+	if !ok || !strings.HasPrefix(rest, "(") {
+		return "", "", signatureError("missing or invalid Signature-Input header")
+	}
+	// Signatures are separated by commas outside quotes and parentheses
+	depth, quoted := 0, false
+	for i, r := range rest {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			return label, rest[:i], nil
+		}
+	}
+	return label, rest, nil
+}
+
+// parseSignatureParams splits serialized signature parameters such as
+// ("@status" "content-digest");keyid="k";created=1 into the covered
+// components and the attributes.
+func parseSignatureParams(params string) ([]string, map[string]string, error) {
+	end := strings.IndexByte(params, ')')
+	if end < 0 {
+		return nil, nil, signatureError("invalid Signature-Input header")
+	}
+	var components []string
+	for _, item := range strings.Fields(params[1:end]) {
+		name, err := strconv.Unquote(item)
+		if err != nil || strings.Contains(item, ";") {
+			return nil, nil, signatureError("unsupported component %s", item)
+		}
+		components = append(components, strings.ToLower(name))
+	}
+	attributes := make(map[string]string)
+	for _, attribute := range strings.Split(params[end+1:], ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(attribute), "=")
+		if name == "" {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		attributes[name] = value
+	}
+	return components, attributes, nil
+}
+
+// signatureValue returns the signature labelled label in a Signature header.
+func signatureValue(header, label string) ([]byte, error) {
+	for _, member := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(member), "=")
+		if name != label {
+			continue
+		}
+		encoded, ok := strings.CutPrefix(value, ":")
+		if encoded, ok = strings.CutSuffix(encoded, ":"); !ok {
+			break
+		}
+		sig, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, &SignatureError{Reason: "invalid signature encoding", Err: err}
+		}
+		return sig, nil
+	}
+	return nil, signatureError("missing signature %s", label)
+}
+
+// verifyContentDigest checks a Content-Digest header (RFC 9530) with a
+// sha-256 or sha-512 digest against body.
+func verifyContentDigest(header string, body []byte) error {
+	for _, member := range strings.Split(header, ",") {
+		alg, value, _ := strings.Cut(strings.TrimSpace(member), "=")
+		var sum []byte
+		switch alg {
+		case "sha-256":
+			digest := sha256.Sum256(body)
+			sum = digest[:]
+		case "sha-512":
+			digest := sha512.Sum512(body)
+			sum = digest[:]
+		default:
+			continue
+		}
+		want := ":" + base64.StdEncoding.EncodeToString(sum) + ":"
+		if value != want {
+			return signatureError("content-digest does not match the body")
+		}
+		return nil
+	}
+	return signatureError("missing sha-256 or sha-512 content-digest")
+}
+
+func defaultAlgorithm(key any) string {
+	switch key.(type) {
+	case []byte:
+		return "hmac-sha256"
+	case ed25519.PublicKey:
+		return "ed25519"
+	case *ecdsa.PublicKey:
+		return "ecdsa-p256-sha256"
+	case *rsa.PublicKey:
+		return "rsa-v1_5-sha256"
+	}
+	return ""
+}
+
+// verifySignature checks sig over input with key, using an algorithm named
+// as in the HTTP Signature Algorithms registry.
+func verifySignature(alg string, key any, input, sig []byte) error {
+	invalid := func(err error) error {
+		return &SignatureError{Reason: "signature does not match", Err: err}
+	}
+	wrongKey := signatureError("key of type %T can't verify %s", key, alg)
+	digest256 := sha256.Sum256(input)
+	switch alg {
+	case "hmac-sha256":
+		secret, ok := key.([]byte)
+		if !ok {
+			return wrongKey
+		}
+		if !hmac.Equal(hmacSHA256(secret, string(input)), sig) {
+			return invalid(nil)
+		}
+	case "ed25519":
+		public, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return wrongKey
+		}
+		if !ed25519.Verify(public, input, sig) {
+			return invalid(nil)
+		}
+	case "ecdsa-p256-sha256":
+		public, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return wrongKey
+		}
+		if len(sig) != 64 {
+			return invalid(fmt.Errorf("signature is %d bytes, want 64", len(sig)))
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(public, digest256[:], r, s) {
+			return invalid(nil)
+		}
+	case "rsa-v1_5-sha256":
+		public, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return wrongKey
+		}
+		if err := rsa.VerifyPKCS1v15(public, crypto.SHA256, digest256[:], sig); err != nil {
+			return invalid(err)
+		}
+	case "rsa-pss-sha512":
+		public, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return wrongKey
+		}
+		digest := sha512.Sum512(input)
+		if err := rsa.VerifyPSS(public, crypto.SHA512, digest[:], sig, nil); err != nil {
+			return invalid(err)
+		}
+	default:
+		return signatureError("unsupported algorithm %q", alg)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package reqwest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func signJWS(t *testing.T, header string, payload []byte, sign func([]byte) []byte) string {
+	t.Helper()
+	protected := base64.RawURLEncoding.EncodeToString([]byte(header))
+	input := protected + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return protected + ".." + base64.RawURLEncoding.EncodeToString(sign([]byte(input)))
+}
+
+func TestJWSVerifier(t *testing.T) {
+	secret := []byte("secret")
+	hs256 := func(input []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(input)
+		return mac.Sum(nil)
+	}
+	body := []byte(`{"version":"1.2.3"}`)
+
+	t.Run("valid HS256", func(t *testing.T) {
+		resp := &http.Response{Header: http.Header{}}
+		resp.Header.Set(DefaultJWSHeader, signJWS(t, `{"alg":"HS256","kid":"k1"}`, body, hs256))
+		var gotKid string
+		verifier := NewJWSVerifier("", func(kid, alg string) (any, error) {
+			gotKid = kid
+			return secret, nil
+		})
+		if err := verifier.Verify(resp, body); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		if gotKid != "k1" {
+			t.Errorf("key ID = %q, want k1", gotKid)
+		}
+	})
+
+	t.Run("valid ES256", func(t *testing.T) {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		es256 := func(input []byte) []byte {
+			digest := sha256.Sum256(input)
+			r, s, _ := ecdsa.Sign(rand.Reader, key, digest[:])
+			return append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		}
+		resp := &http.Response{Header: http.Header{}}
+		resp.Header.Set("X-Sig", signJWS(t, `{"alg":"ES256"}`, body, es256))
+		if err := NewJWSVerifier("X-Sig", StaticKey(&key.PublicKey)).Verify(resp, body); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		valid := signJWS(t, `{"alg":"HS256"}`, body, hs256)
+		testCases := []struct {
+			name   string
+			header string
+			body   []byte
+			key    any
+		}{
+			{name: "tampered body", header: valid, body: []byte(`{"version":"6.6.6"}`), key: secret},
+			{name: "wrong key", header: valid, body: body, key: []byte("other")},
+			{name: "wrong key type", header: valid, body: body, key: ed25519.PublicKey(make([]byte, 32))},
+			{name: "missing header", body: body, key: secret},
+			{name: "attached payload", header: strings.Replace(valid, "..", ".e30.", 1), body: body, key: secret},
+			{name: "alg none", header: signJWS(t, `{"alg":"none"}`, body, func([]byte) []byte { return nil }), body: body, key: secret},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				resp := &http.Response{Header: http.Header{}}
+				if tc.header != "" {
+					resp.Header.Set(DefaultJWSHeader, tc.header)
+				}
+				err := NewJWSVerifier("", StaticKey(tc.key)).Verify(resp, tc.body)
+				var sigErr *SignatureError
+				if !errors.As(err, &sigErr) {
+					t.Fatalf("Verify() error = %v, want *SignatureError", err)
+				}
+			})
+		}
+	})
+}
+
+// signMessage adds RFC 9421 signature headers covering components to resp.
+func signMessage(resp *http.Response, body []byte, components string, key ed25519.PrivateKey) {
+	digest := sha256.Sum256(body)
+	resp.Header.Set("Content-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(digest[:])+":")
+	params := fmt.Sprintf(`(%s);created=1618884473;keyid="test-key-ed25519"`, components)
+	var base strings.Builder
+	for _, component := range strings.Fields(components) {
+		name := strings.Trim(component, `"`)
+		value := resp.Header.Get(name)
+		if name == "@status" {
+			value = fmt.Sprint(resp.StatusCode)
+		}
+		fmt.Fprintf(&base, "%s: %s\n", component, value)
+	}
+	base.WriteString(`"@signature-params": ` + params)
+	resp.Header.Set("Signature-Input", "sig-b26="+params)
+	resp.Header.Set("Signature", "sig-b26=:"+base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(base.String())))+":")
+}
+
+func TestMessageSignatureVerifier(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	body := []byte(`{"hello": "world"}`)
+	newResponse := func() *http.Response {
+		resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+		resp.Header.Set("Content-Type", "application/json")
+		return resp
+	}
+	keys := func(keyID, alg string) (any, error) {
+		if keyID != "test-key-ed25519" {
+			return nil, fmt.Errorf("unknown key %q", keyID)
+		}
+		return public, nil
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		resp := newResponse()
+		signMessage(resp, body, `"@status" "content-type" "content-digest"`, private)
+		if err := NewMessageSignatureVerifier(keys).Verify(resp, body); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+	})
+
+	testCases := []struct {
+		name   string
+		modify func(resp *http.Response) []byte
+	}{
+		{name: "tampered body", modify: func(resp *http.Response) []byte {
+			return []byte(`{"hello": "mallory"}`)
+		}},
+		{name: "tampered status", modify: func(resp *http.Response) []byte {
+			resp.StatusCode = http.StatusNotFound
+			return body
+		}},
+		{name: "tampered covered header", modify: func(resp *http.Response) []byte {
+			resp.Header.Set("Content-Type", "text/html")
+			return body
+		}},
+		{name: "unknown key", modify: func(resp *http.Response) []byte {
+			resp.Header.Set("Signature-Input", strings.Replace(resp.Header.Get("Signature-Input"), "test-key", "other-key", 1))
+			return body
+		}},
+		{name: "unsigned", modify: func(resp *http.Response) []byte {
+			resp.Header.Del("Signature-Input")
+			return body
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := newResponse()
+			signMessage(resp, body, `"@status" "content-type" "content-digest"`, private)
+			err := NewMessageSignatureVerifier(keys).Verify(resp, tc.modify(resp))
+			var sigErr *SignatureError
+			if !errors.As(err, &sigErr) {
+				t.Fatalf("Verify() error = %v, want *SignatureError", err)
+			}
+		})
+	}
+
+	t.Run("content digest not covered", func(t *testing.T) {
+		resp := newResponse()
+		signMessage(resp, body, `"@status" "content-type"`, private)
+		err := NewMessageSignatureVerifier(keys).Verify(resp, body)
+		if err == nil || !strings.Contains(err.Error(), "does not cover content-digest") {
+			t.Fatalf("Verify() error = %v, want uncovered content-digest", err)
+		}
+	})
+}
+
+func TestWithResponseVerifier(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	tamper := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := []byte("release artifact")
+		resp := &http.Response{StatusCode: http.StatusOK, Header: w.Header()}
+		signMessage(resp, body, `"@status" "content-digest"`, private)
+		if tamper {
+			body = []byte("backdoored artifact")
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	cli := NewClientBuilder().
+		WithResponseVerifier(NewMessageSignatureVerifier(StaticKey(public))).
+		Build()
+
+	resp, err := cli.Get(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, _ := resp.Bytes()
+	if string(data) != "release artifact" {
+		t.Errorf("body = %q, want verified body", data)
+	}
+
+	tamper = true
+	_, err = cli.Get(context.Background(), server.URL)
+	var sigErr *SignatureError
+	if !errors.As(err, &sigErr) {
+		t.Fatalf("Get() error = %v, want *SignatureError", err)
+	}
+}
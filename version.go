@@ -0,0 +1,88 @@
+package reqwest
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// VersionDowngrade describes a response served with a media-type version
+// other than the client's most preferred one.
+type VersionDowngrade struct {
+	Method    string
+	URL       string
+	Preferred string
+	Served    string
+}
+
+// versionConfig negotiates vendor media types of the form
+// application/vnd.<vendor>.<version>+json.
+type versionConfig struct {
+	vendor      string
+	versions    []string
+	onDowngrade func(VersionDowngrade)
+}
+
+func (v versionConfig) clone() versionConfig {
+	v.versions = slices.Clone(v.versions)
+	return v
+}
+
+func (v versionConfig) enabled() bool {
+	return v.vendor != "" && len(v.versions) > 0
+}
+
+func (v versionConfig) mediaType(version string) string {
+	return "application/vnd." + v.vendor + "." + version + "+json"
+}
+
+// accept lists the configured versions in order of preference, weighting
+// each one lower than the last.
+func (v versionConfig) accept() string {
+	parts := make([]string, len(v.versions))
+	for i, version := range v.versions {
+		parts[i] = v.mediaType(version)
+		if i > 0 {
+			q := 1 - float64(i)/10
+			if q < 0.1 {
+				q = 0.1
+			}
+			parts[i] += fmt.Sprintf(";q=%.1f", q)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// served extracts the version from a vendor Content-Type, or returns "" if
+// the response isn't one of the vendor's media types.
+func (v versionConfig) served(header http.Header) string {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	prefix := "application/vnd." + v.vendor + "."
+	if !strings.HasPrefix(mediaType, prefix) {
+		return ""
+	}
+	version := strings.TrimPrefix(mediaType, prefix)
+	if i := strings.IndexByte(version, '+'); i >= 0 {
+		version = version[:i]
+	}
+	return version
+}
+
+// observe records the served version on resp and reports downgrades.
+func (v versionConfig) observe(resp *Response, method, url string) {
+	resp.apiVersion = v.served(resp.header)
+	if resp.apiVersion == "" || resp.apiVersion == v.versions[0] || v.onDowngrade == nil {
+		return
+	}
+	v.onDowngrade(VersionDowngrade{
+		Method:    method,
+		URL:       url,
+		Preferred: v.versions[0],
+		Served:    resp.apiVersion,
+	})
+}
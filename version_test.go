@@ -0,0 +1,68 @@
+package reqwest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_MediaTypeVersions(t *testing.T) {
+	var gotAccept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAccept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", "application/vnd.foo.v1+json; charset=utf-8")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var downgrades []VersionDowngrade
+	client := NewClientBuilder().
+		WithMediaTypeVersions("foo", "v3", "v2", "v1").
+		WithOnVersionDowngrade(func(d VersionDowngrade) { downgrades = append(downgrades, d) }).
+		Build()
+
+	t.Run("negotiates and records version", func(t *testing.T) {
+		resp, err := client.Get(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		want := "application/vnd.foo.v3+json, application/vnd.foo.v2+json;q=0.9, application/vnd.foo.v1+json;q=0.8"
+		if gotAccept != want {
+			t.Errorf("Expected Accept %q, got %q", want, gotAccept)
+		}
+		if resp.APIVersion() != "v1" {
+			t.Errorf("Expected APIVersion v1, got %q", resp.APIVersion())
+		}
+		if len(downgrades) != 1 || downgrades[0].Preferred != "v3" || downgrades[0].Served != "v1" {
+			t.Errorf("Expected one v3 -> v1 downgrade, got %+v", downgrades)
+		}
+	})
+
+	t.Run("caller Accept header wins", func(t *testing.T) {
+		req := NewRequest(http.MethodGet, server.URL).WithHeader("Accept", "application/vnd.foo.v1+json")
+		if _, err := client.Do(context.Background(), req); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if gotAccept != "application/vnd.foo.v1+json" {
+			t.Errorf("Expected caller Accept header to be kept, got %q", gotAccept)
+		}
+	})
+}
+
+func TestVersionConfig_Served(t *testing.T) {
+	cfg := versionConfig{vendor: "foo", versions: []string{"v2"}}
+	tests := map[string]string{
+		"application/vnd.foo.v2+json":              "v2",
+		"application/vnd.foo.2024-01-01+json; q=1": "2024-01-01",
+		"application/json":                         "",
+		"application/vnd.bar.v2+json":              "",
+		"":                                         "",
+	}
+	for contentType, want := range tests {
+		header := http.Header{"Content-Type": {contentType}}
+		if got := cfg.served(header); got != want {
+			t.Errorf("served(%q) = %q, want %q", contentType, got, want)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package reqwest
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" // #nosec G505 - SHA-1 is mandated by RFC 6455 for the handshake
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrWebSocketProtocol is returned by Conn.ReadMessage when the server sends
+// a malformed frame or a message larger than the read limit. The connection
+// is closed.
+var ErrWebSocketProtocol = errors.New("websocket protocol error")
+
+// DefaultWebSocketReadLimit is the largest message Conn.ReadMessage accepts
+// unless WithWebSocketReadLimit says otherwise.
+const DefaultWebSocketReadLimit = 32 << 20
+
+// MessageType identifies the payload type of a WebSocket data message.
+type MessageType int
+
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+)
+
+// WebSocket opcodes and limits from RFC 6455
+const (
+	wsOpContinuation = 0x0
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+
+	wsFinalBit         = 0x80
+	wsMaskBit          = 0x80
+	wsOpcodeMask       = 0x0F
+	wsLengthMask       = 0x7F
+	wsMaxShortLength   = 125
+	wsExtended16Length = 126
+	wsExtended64Length = 127
+	wsMaskKeySize      = 4
+	wsNonceSize        = 16
+	wsCloseNormal      = 1000
+	wsCloseProtocol    = 1002
+	wsCloseTooBig      = 1009
+
+	wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+// Conn is a client WebSocket connection established by Client.Dial.
+// Reads and writes may happen concurrently with each other, but only one
+// goroutine may read and one may write at a time.
+type Conn struct {
+	rwc       io.ReadWriteCloser
+	reader    *bufio.Reader
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+	closeErr  error
+	release   func()
+	readLimit int64
+}
+
+type wsFrame struct {
+	final   bool
+	opcode  byte
+	masked  bool
+	payload []byte
+}
+
+// wsProtocolError is an ErrWebSocketProtocol with the close code to send.
+type wsProtocolError struct {
+	code   int
+	reason string
+}
+
+func (e *wsProtocolError) Error() string { return ErrWebSocketProtocol.Error() + ": " + e.reason }
+func (e *wsProtocolError) Unwrap() error { return ErrWebSocketProtocol }
+
+// Dial upgrades a GET request to the given URL into a WebSocket connection.
+// The handshake goes through the same base URL resolution, headers and
+// credentials (default headers, middleware, token source, API key and
+// signer), host guard and HTTP transport (TLS configuration and proxy) as
+// regular requests; ws:// and wss:// URLs are accepted as aliases for
+// http:// and https://.
+func (c *HTTPClient) Dial(ctx context.Context, url string) (*Conn, error) {
+	ctx, release := c.cancels.track(ctx)
+	defer release()
+	nonce := make([]byte, wsNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate websocket key: %v", err)
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	if c.apiKey != nil {
+		ctx = c.apiKey.withSecrets(ctx)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.buildURL(websocketToHTTPURL(url)), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make http request: %v", err)
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := c.prepare(ctx, req, nil); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if c.apiKey != nil {
+			err = c.apiKey.redact(err)
+		}
+		return nil, fmt.Errorf("failed to do http request: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close()
+		return nil, fmt.Errorf("websocket handshake failed: unexpected status %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != websocketAccept(key) {
+		resp.Body.Close()
+		return nil, fmt.Errorf("websocket handshake failed: invalid Sec-WebSocket-Accept")
+	}
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, fmt.Errorf("websocket handshake failed: transport does not support protocol upgrades")
+	}
+
+	conn := &Conn{rwc: rwc, reader: bufio.NewReader(rwc), readLimit: c.wsReadLimit}
+	// CancelAll drops the connection without a closing handshake, which
+	// unblocks pending reads and writes.
+	conn.release = c.cancels.register(func(error) { _ = rwc.Close() })
+	return conn, nil
+}
+
+// ReadMessage returns the next data message, reassembling fragments and
+// answering pings transparently. It returns io.EOF after a normal close, and
+// closes the connection with ErrWebSocketProtocol if the server sends a
+// malformed frame or a message over the read limit.
+func (conn *Conn) ReadMessage() (MessageType, []byte, error) {
+	var messageType MessageType
+	var message []byte
+	for {
+		limit := int64(-1)
+		if conn.readLimit > 0 {
+			limit = conn.readLimit - int64(len(message))
+		}
+		frame, err := readWebSocketFrame(conn.reader, limit)
+		if err == nil && frame.masked {
+			err = &wsProtocolError{code: wsCloseProtocol, reason: "masked frame from server"}
+		}
+		if err != nil {
+			var protocolErr *wsProtocolError
+			if errors.As(err, &protocolErr) {
+				_ = conn.closeWith(protocolErr.code)
+			}
+			return 0, nil, err
+		}
+
+		switch frame.opcode {
+		case wsOpPing:
+			if err := conn.writeFrame(wsOpPong, frame.payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			_ = conn.Close()
+			if code := closeCode(frame.payload); code != 0 && code != wsCloseNormal {
+				return 0, nil, fmt.Errorf("websocket closed by peer with code %d", code)
+			}
+			return 0, nil, io.EOF
+		case wsOpContinuation:
+			if messageType == 0 {
+				_ = conn.closeWith(wsCloseProtocol)
+				return 0, nil, &wsProtocolError{code: wsCloseProtocol, reason: "unexpected continuation frame"}
+			}
+		case byte(TextMessage), byte(BinaryMessage):
+			if messageType != 0 {
+				_ = conn.closeWith(wsCloseProtocol)
+				return 0, nil, &wsProtocolError{code: wsCloseProtocol, reason: "data frame inside a fragmented message"}
+			}
+			messageType = MessageType(frame.opcode)
+		default:
+			_ = conn.closeWith(wsCloseProtocol)
+			return 0, nil, &wsProtocolError{code: wsCloseProtocol, reason: fmt.Sprintf("reserved opcode %#x", frame.opcode)}
+		}
+
+		message = append(message, frame.payload...)
+		if frame.final {
+			return messageType, message, nil
+		}
+	}
+}
+
+// WriteMessage sends data as a single masked frame.
+func (conn *Conn) WriteMessage(messageType MessageType, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("unsupported websocket message type %d", messageType)
+	}
+	return conn.writeFrame(byte(messageType), data)
+}
+
+// Close sends a best-effort normal closure frame and closes the underlying
+// connection.
+func (conn *Conn) Close() error {
+	return conn.closeWith(wsCloseNormal)
+}
+
+// closeWith is Close with the given close code.
+func (conn *Conn) closeWith(code int) error {
+	conn.closeOnce.Do(func() {
+		payload := make([]byte, 2)
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		_ = conn.writeFrame(wsOpClose, payload)
+		conn.closeErr = conn.rwc.Close()
+		if conn.release != nil {
+			conn.release()
+		}
+	})
+	return conn.closeErr
+}
+
+func (conn *Conn) writeFrame(opcode byte, payload []byte) error {
+	conn.writeMu.Lock()
+	defer conn.writeMu.Unlock()
+	return writeWebSocketFrame(conn.rwc, opcode, payload, true)
+}
+
+func writeWebSocketFrame(w io.Writer, opcode byte, payload []byte, masked bool) error {
+	header := []byte{wsFinalBit | opcode, 0}
+	maskBit := byte(0)
+	if masked {
+		maskBit = wsMaskBit
+	}
+
+	length := len(payload)
+	switch {
+	case length <= wsMaxShortLength:
+		header[1] = maskBit | byte(length)
+	case length <= 0xFFFF:
+		header[1] = maskBit | wsExtended16Length
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = maskBit | wsExtended64Length
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	data := payload
+	if masked {
+		maskKey := make([]byte, wsMaskKeySize)
+		if _, err := rand.Read(maskKey); err != nil {
+			return fmt.Errorf("failed to generate websocket mask: %v", err)
+		}
+		header = append(header, maskKey...)
+		data = make([]byte, length)
+		for i := range payload {
+			data[i] = payload[i] ^ maskKey[i%wsMaskKeySize]
+		}
+	}
+
+	if _, err := w.Write(append(header, data...)); err != nil {
+		return fmt.Errorf("failed to write websocket frame: %w", err)
+	}
+	return nil
+}
+
+// readWebSocketFrame reads one frame of at most limit bytes, or of any size
+// if limit is negative.
+func readWebSocketFrame(r io.Reader, limit int64) (wsFrame, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return wsFrame{}, err
+	}
+
+	frame := wsFrame{
+		final:  header[0]&wsFinalBit != 0,
+		opcode: header[0] & wsOpcodeMask,
+		masked: header[1]&wsMaskBit != 0,
+	}
+
+	length := uint64(header[1] & wsLengthMask)
+	switch length {
+	case wsExtended16Length:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(r, extended); err != nil {
+			return wsFrame{}, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case wsExtended64Length:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(r, extended); err != nil {
+			return wsFrame{}, err
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+	if frame.opcode >= wsOpClose && (!frame.final || length > wsMaxShortLength) {
+		return wsFrame{}, &wsProtocolError{code: wsCloseProtocol, reason: "fragmented or oversized control frame"}
+	}
+	if length > math.MaxInt64 || limit >= 0 && length > uint64(limit) {
+		return wsFrame{}, &wsProtocolError{code: wsCloseTooBig, reason: "message exceeds the read limit"}
+	}
+
+	var maskKey []byte
+	if frame.masked {
+		maskKey = make([]byte, wsMaskKeySize)
+		if _, err := io.ReadFull(r, maskKey); err != nil {
+			return wsFrame{}, err
+		}
+	}
+
+	frame.payload = make([]byte, length)
+	if _, err := io.ReadFull(r, frame.payload); err != nil {
+		return wsFrame{}, err
+	}
+	for i := range frame.payload {
+		if frame.masked {
+			frame.payload[i] ^= maskKey[i%wsMaskKeySize]
+		}
+	}
+	return frame, nil
+}
+
+func closeCode(payload []byte) int {
+	if len(payload) < 2 {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(payload))
+}
+
+func websocketAccept(key string) string {
+	// #nosec G401 - SHA-1 is mandated by RFC 6455 for the handshake
+	sum := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func websocketToHTTPURL(url string) string {
+	switch {
+	case strings.HasPrefix(url, "ws://"):
+		return "http://" + strings.TrimPrefix(url, "ws://")
+	case strings.HasPrefix(url, "wss://"):
+		return "https://" + strings.TrimPrefix(url, "wss://")
+	default:
+		return url
+	}
+}
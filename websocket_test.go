@@ -0,0 +1,288 @@
+package reqwest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+// newEchoWebSocketServer upgrades every request and echoes data frames back
+// until the client closes the connection.
+func newEchoWebSocketServer(t *testing.T, check func(r *http.Request)) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			check(r)
+		}
+		if r.Header.Get("Upgrade") != "websocket" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("Failed to hijack connection: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+			"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + websocketAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		_ = rw.Flush()
+
+		for {
+			frame, err := readWebSocketFrame(rw.Reader, -1)
+			if err != nil {
+				return
+			}
+			if frame.opcode == wsOpClose {
+				_ = writeWebSocketFrame(conn, wsOpClose, frame.payload, false)
+				return
+			}
+			if frame.opcode == wsOpPong {
+				continue
+			}
+			// Ping before echoing to exercise transparent pong handling
+			_ = writeWebSocketFrame(conn, wsOpPing, []byte("hb"), false)
+			_ = writeWebSocketFrame(conn, frame.opcode, frame.payload, false)
+		}
+	}))
+}
+
+func TestClient_Dial(t *testing.T) {
+	t.Run("echo messages through base URL and middleware", func(t *testing.T) {
+		server := newEchoWebSocketServer(t, func(r *http.Request) {
+			if r.URL.Path != "/api/ws" {
+				t.Errorf("Expected path /api/ws, got %s", r.URL.Path)
+			}
+			if r.Header.Get("Authorization") != "Bearer token" {
+				t.Errorf("Expected middleware auth header, got %q", r.Header.Get("Authorization"))
+			}
+		})
+		defer server.Close()
+
+		cli := NewClientBuilder().
+			WithBaseURL(server.URL + "/api").
+			WithMiddleware(func(req *http.Request) error {
+				req.Header.Set("Authorization", "Bearer token")
+				return nil
+			}).
+			Build()
+
+		conn, err := cli.Dial(context.TODO(), "/ws")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer conn.Close()
+
+		large := bytes.Repeat([]byte("x"), 70000)
+		messages := []struct {
+			messageType MessageType
+			data        []byte
+		}{
+			{TextMessage, []byte("hello")},
+			{BinaryMessage, bytes.Repeat([]byte{1}, 300)},
+			{BinaryMessage, large},
+		}
+
+		for _, msg := range messages {
+			if err := conn.WriteMessage(msg.messageType, msg.data); err != nil {
+				t.Fatalf("Failed to write message: %v", err)
+			}
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatalf("Failed to read message: %v", err)
+			}
+			if messageType != msg.messageType || !bytes.Equal(data, msg.data) {
+				t.Errorf("Expected echo of %d bytes (type %d), got %d bytes (type %d)",
+					len(msg.data), msg.messageType, len(data), messageType)
+			}
+		}
+
+		if err := conn.Close(); err != nil {
+			t.Errorf("Unexpected close error: %v", err)
+		}
+	})
+
+	t.Run("handshake carries the client's credentials", func(t *testing.T) {
+		var authorization, apiKey string
+		server := newEchoWebSocketServer(t, func(r *http.Request) {
+			authorization, apiKey = r.Header.Get("Authorization"), r.Header.Get("X-Api-Key")
+		})
+		defer server.Close()
+
+		cli := NewClientBuilder().WithAPIKey("X-Api-Key", "k3y", InHeader).Build()
+		cli.SetBearerToken("rotated")
+		conn, err := cli.Dial(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		conn.Close()
+		if authorization != "Bearer rotated" || apiKey != "k3y" {
+			t.Errorf("Expected the bearer token and API key, got %q and %q", authorization, apiKey)
+		}
+	})
+
+	t.Run("blocked host", func(t *testing.T) {
+		cli := NewClientBuilder().WithBlockedCIDRs(netip.MustParsePrefix("127.0.0.0/8")).Build()
+		if _, err := cli.Dial(context.TODO(), "ws://127.0.0.1:1"); !errors.Is(err, ErrHostBlocked) {
+			t.Errorf("Expected ErrHostBlocked, got %v", err)
+		}
+	})
+
+	t.Run("ws scheme is accepted", func(t *testing.T) {
+		server := newEchoWebSocketServer(t, nil)
+		defer server.Close()
+
+		cli := NewClientBuilder().WithBaseURL("https://unused.example.com").Build()
+		conn, err := cli.Dial(context.TODO(), strings.Replace(server.URL, "http://", "ws://", 1))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		conn.Close()
+	})
+
+	t.Run("non-upgrade response fails the handshake", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		_, err := NewClientBuilder().Build().Dial(context.TODO(), server.URL)
+		if err == nil || !strings.Contains(err.Error(), "unexpected status 401") {
+			t.Errorf("Expected handshake error, got %v", err)
+		}
+	})
+
+	t.Run("read limit", func(t *testing.T) {
+		server := newEchoWebSocketServer(t, nil)
+		defer server.Close()
+
+		conn, err := NewClientBuilder().WithWebSocketReadLimit(4).Build().Dial(context.TODO(), server.URL)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		defer conn.Close()
+		if err := conn.WriteMessage(TextMessage, []byte("hello")); err != nil {
+			t.Fatalf("Failed to write message: %v", err)
+		}
+		if _, _, err := conn.ReadMessage(); !errors.Is(err, ErrWebSocketProtocol) {
+			t.Errorf("Expected ErrWebSocketProtocol, got %v", err)
+		}
+	})
+
+	t.Run("middleware error aborts dial", func(t *testing.T) {
+		cli := NewClientBuilder().
+			WithMiddleware(func(req *http.Request) error { return errors.New("no token") }).
+			Build()
+
+		_, err := cli.Dial(context.TODO(), "ws://example.com")
+		if err == nil || !strings.Contains(err.Error(), "middleware error") {
+			t.Errorf("Expected middleware error, got %v", err)
+		}
+	})
+
+	t.Run("invalid message type", func(t *testing.T) {
+		conn := &Conn{rwc: nopReadWriteCloser{}}
+		if err := conn.WriteMessage(MessageType(9), nil); err == nil {
+			t.Error("Expected error for unsupported message type")
+		}
+	})
+}
+
+func TestReadWebSocketFrame(t *testing.T) {
+	t.Run("close with error code", func(t *testing.T) {
+		var buf bytes.Buffer
+		_ = writeWebSocketFrame(&buf, wsOpClose, []byte{0x03, 0xF3}, false) // 1011
+		conn := &Conn{rwc: nopReadWriteCloser{}, reader: bufio.NewReader(&buf)}
+
+		_, _, err := conn.ReadMessage()
+		if err == nil || !strings.Contains(err.Error(), "code 1011") {
+			t.Errorf("Expected close code error, got %v", err)
+		}
+	})
+
+	t.Run("fragmented message", func(t *testing.T) {
+		var buf bytes.Buffer
+		buf.Write([]byte{byte(TextMessage), 3})
+		buf.WriteString("abc")
+		_ = writeWebSocketFrame(&buf, wsOpContinuation, []byte("def"), false)
+		conn := &Conn{rwc: nopReadWriteCloser{}, reader: bufio.NewReader(&buf)}
+
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if messageType != TextMessage || string(data) != "abcdef" {
+			t.Errorf("Expected reassembled text message, got %d %q", messageType, data)
+		}
+	})
+
+	t.Run("unexpected continuation", func(t *testing.T) {
+		var buf bytes.Buffer
+		_ = writeWebSocketFrame(&buf, wsOpContinuation, []byte("x"), false)
+		conn := &Conn{rwc: nopReadWriteCloser{}, reader: bufio.NewReader(&buf)}
+
+		if _, _, err := conn.ReadMessage(); err == nil {
+			t.Error("Expected protocol error")
+		}
+	})
+
+	t.Run("truncated frame", func(t *testing.T) {
+		_, err := readWebSocketFrame(bytes.NewReader([]byte{0x81, 0x05, 'a'}), -1)
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("Expected unexpected EOF, got %v", err)
+		}
+	})
+}
+
+func TestReadMessage_ProtocolErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		frames    []byte
+		closeCode int
+	}{
+		{"huge length", []byte{0x82, 127, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, wsCloseTooBig},
+		{"frame over limit", []byte{0x82, 126, 0x01, 0x00}, wsCloseTooBig},
+		{"fragments over limit", append(append([]byte{0x02, 100}, make([]byte, 100)...), 0x80, 100), wsCloseTooBig},
+		{"long ping", append([]byte{0x89, 126, 0x00, 126}, make([]byte, 126)...), wsCloseProtocol},
+		{"fragmented ping", []byte{0x09, 0x00}, wsCloseProtocol},
+		{"masked frame", []byte{0x81, 0x81, 1, 2, 3, 4, 'a' ^ 1}, wsCloseProtocol},
+		{"reserved opcode", []byte{0x83, 0x00}, wsCloseProtocol},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rwc := &recordingReadWriteCloser{}
+			conn := &Conn{rwc: rwc, reader: bufio.NewReader(bytes.NewReader(tt.frames)), readLimit: 128}
+
+			if _, _, err := conn.ReadMessage(); !errors.Is(err, ErrWebSocketProtocol) {
+				t.Fatalf("Expected ErrWebSocketProtocol, got %v", err)
+			}
+			frame, err := readWebSocketFrame(&rwc.written, -1)
+			if err != nil || frame.opcode != wsOpClose || closeCode(frame.payload) != tt.closeCode {
+				t.Errorf("Expected close code %d, got %+v (%v)", tt.closeCode, frame, err)
+			}
+		})
+	}
+}
+
+type recordingReadWriteCloser struct {
+	nopReadWriteCloser
+	written bytes.Buffer
+}
+
+func (rwc *recordingReadWriteCloser) Write(p []byte) (int, error) { return rwc.written.Write(p) }
+
+type nopReadWriteCloser struct{}
+
+func (nopReadWriteCloser) Read([]byte) (int, error)    { return 0, io.EOF }
+func (nopReadWriteCloser) Write(p []byte) (int, error) { return len(p), nil }
+func (nopReadWriteCloser) Close() error                { return nil }